@@ -2,9 +2,10 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/0xOnah/bank/doc"
@@ -13,8 +14,15 @@ import (
 	"github.com/0xOnah/bank/internal/db/repo"
 	"github.com/0xOnah/bank/internal/db/sqlc"
 	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/health"
 	"github.com/0xOnah/bank/internal/sdk/jobs"
 	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/mailer"
+	"github.com/0xOnah/bank/internal/sdk/metrics"
+	"github.com/0xOnah/bank/internal/sdk/netutil"
+	"github.com/0xOnah/bank/internal/sdk/ratelimit"
+	"github.com/0xOnah/bank/internal/sdk/totp"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
 	"github.com/0xOnah/bank/internal/service"
 	grpctransport "github.com/0xOnah/bank/internal/transport/grpc"
 	httptransport "github.com/0xOnah/bank/internal/transport/http"
@@ -22,13 +30,19 @@ import (
 	"github.com/0xOnah/bank/pb"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+const healthCheckInterval = 10 * time.Second
+
 func main() {
 	//config
 	config, err := config.LoadConfig(".")
@@ -42,6 +56,17 @@ func main() {
 		log.Fatal().Err(err).Send()
 	}
 
+	//tracing
+	shutdownTracer, err := tracing.InitTracer(context.Background(), &config)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to initialize tracing")
+	}
+	defer func() {
+		if err := shutdownTracer(context.Background()); err != nil {
+			logger.Error().Err(err).Msg("failed to flush traces")
+		}
+	}()
+
 	//db setup
 	logger.Info().Msg(config.DSN)
 	database, err := db.NewDBClient(config.DSN)
@@ -72,30 +97,203 @@ func main() {
 	}
 	taskQueue := jobs.NewTaskQueue(redisOpts, logger)
 
+	//health checks
+	checker := health.NewChecker(logger, pb.UserService_ServiceDesc.ServiceName)
+	checker.Register("postgres", database.Ping)
+	checker.Register("redis", func(ctx context.Context) error {
+		return taskQueue.Ping()
+	})
+	checker.Register("migrations", database.MigrationStatus)
+	go checker.Watch(context.Background(), healthCheckInterval)
+
+	//metrics
+	appMetrics := metrics.New()
+	err = appMetrics.Register(
+		metrics.NewDBStatsCollector(database.Client),
+		metrics.NewQueueCollector(asynq.NewInspector(redisOpts)),
+	)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to register metrics collectors")
+	}
+
+	//rate limiting
+	redisClient := redis.NewClient(&redis.Options{Addr: config.REDIS_ADDRESS})
+	limiter, err := ratelimit.NewLimiter(&config, redisClient)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to initialize rate limiter")
+	}
+	loginLimit := ratelimit.PerMinute(config.RATE_LIMIT_LOGIN)
+	//grpc methods and their gateway and gin routes share an operation, so each has one budget
+	login := ratelimit.Operation{Name: "login", Rule: loginLimit}
+	//reset emails are throttled like logins, per ip
+	passwordReset := ratelimit.Operation{Name: "password_reset", Rule: loginLimit}
+	//second factor codes are six digits, so guesses are throttled like passwords
+	verifyMFA := ratelimit.Operation{Name: "verify_mfa", Rule: loginLimit}
+	confirmTOTP := ratelimit.Operation{Name: "confirm_totp", Rule: loginLimit}
+	disableTOTP := ratelimit.Operation{Name: "disable_totp", Rule: loginLimit}
+	reauthenticate := ratelimit.Operation{Name: "reauthenticate", Rule: loginLimit}
+	//login links are throttled like reset emails and logins
+	requestLoginLink := ratelimit.Operation{Name: "request_login_link", Rule: loginLimit}
+	consumeLoginLink := ratelimit.Operation{Name: "consume_login_link", Rule: loginLimit}
+	//every ceremony begun stores a challenge, so passkey logins are throttled too
+	beginPasskeyLogin := ratelimit.Operation{Name: "begin_passkey_login", Rule: loginLimit}
+	finishPasskeyLogin := ratelimit.Operation{Name: "finish_passkey_login", Rule: loginLimit}
+	finishPasskeyRegistration := ratelimit.Operation{Name: "finish_passkey_registration", Rule: loginLimit}
+	enforcer := ratelimit.NewEnforcer(limiter, ratelimit.Policy{
+		pb.UserService_LoginUser_FullMethodName:                 login,
+		"POST /v1/login_user":                                   login,
+		"POST /login":                                           login,
+		"POST /transfer":                                        {Name: "transfer", Rule: ratelimit.PerMinute(config.RATE_LIMIT_TRANSFER)},
+		pb.UserService_RequestPasswordReset_FullMethodName:      passwordReset,
+		"POST /v1/password_reset/request":                       passwordReset,
+		pb.UserService_VerifyMFA_FullMethodName:                 verifyMFA,
+		"POST /v1/login_user/mfa":                               verifyMFA,
+		"POST /login/mfa":                                       verifyMFA,
+		pb.UserService_ConfirmTOTP_FullMethodName:               confirmTOTP,
+		"POST /v1/mfa/totp/confirm":                             confirmTOTP,
+		pb.UserService_DisableTOTP_FullMethodName:               disableTOTP,
+		"POST /v1/mfa/totp/disable":                             disableTOTP,
+		pb.UserService_Reauthenticate_FullMethodName:            reauthenticate,
+		"POST /v1/reauthenticate":                               reauthenticate,
+		"POST /reauthenticate":                                  reauthenticate,
+		pb.UserService_RequestLoginLink_FullMethodName:          requestLoginLink,
+		"POST /v1/login_link/request":                           requestLoginLink,
+		pb.UserService_ConsumeLoginLink_FullMethodName:          consumeLoginLink,
+		"POST /v1/login_link":                                   consumeLoginLink,
+		pb.UserService_BeginPasskeyLogin_FullMethodName:         beginPasskeyLogin,
+		"POST /v1/passkeys/login/begin":                         beginPasskeyLogin,
+		pb.UserService_FinishPasskeyLogin_FullMethodName:        finishPasskeyLogin,
+		"POST /v1/passkeys/login/finish":                        finishPasskeyLogin,
+		pb.UserService_FinishPasskeyRegistration_FullMethodName: finishPasskeyRegistration,
+		"POST /v1/passkeys/register/finish":                     finishPasskeyRegistration,
+	})
+
 	//authenticator
-	auth, err := auth.NewJWTMaker(config.TOKEN_SYMMETRIC_KEY)
+	auth, err := auth.NewAuthenticator(&config)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("jwt-maker not initialized")
+		logger.Fatal().Err(err).Msg("token maker not initialized")
 	}
-	// RunHttpServer(store, config, auth)
-	go runJobService(redisOpts, store, logger)
-	RunGrpcServer(config, store, auth, logger, taskQueue)
-	// RunGatewayServer(config, store, auth, logger)
+	// RunHttpServer(store, config, auth, appMetrics, enforcer, logger)
+	go runJobService(redisOpts, store, config, auth, logger)
+	go RunMetricsServer(config, appMetrics, logger)
+	go RunGatewayServer(config, store, auth, logger, taskQueue, checker, appMetrics, enforcer)
+	RunGrpcServer(config, store, auth, logger, taskQueue, checker, appMetrics, enforcer)
 }
 
-func runJobService(redisOpts asynq.RedisClientOpt, store *sqlc.SQLStore, logger *zerolog.Logger) {
+func runJobService(redisOpts asynq.RedisClientOpt, store *sqlc.SQLStore, config config.Config, tokenMaker auth.Authenticator, logger *zerolog.Logger) {
 	UserRepo := repo.NewUserRepo(store)
-	taskProcessor := jobs.NewWorkerService(redisOpts, UserRepo, logger)
+	verifyEmailRepo := repo.NewVerifyEmailRepo(store)
+	mail, err := mailer.New(&config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("mailer not initialized")
+	}
+	taskProcessor := jobs.NewWorkerService(redisOpts, UserRepo, verifyEmailRepo, repo.NewPasswordResetRepo(store), repo.NewKnownDeviceRepo(store), repo.NewLoginLinkRepo(store), tokenMaker, mail, config.APP_BASE_URL, logger)
 	log.Info().Msg("starting task processor")
-	err := taskProcessor.Start()
+	err = taskProcessor.Start()
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create redis server")
 	}
 }
+
+// newMFAService builds the TOTP service. Without TOTP_ENCRYPTION_KEY users cannot enroll.
+func newMFAService(store *sqlc.SQLStore, config config.Config, log *zerolog.Logger) *service.MFAService {
+	var sealer *totp.Sealer
+	if config.TOTP_ENCRYPTION_KEY != "" {
+		var err error
+		sealer, err = totp.NewSealer(config.TOTP_ENCRYPTION_KEY)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid TOTP_ENCRYPTION_KEY")
+		}
+	} else {
+		log.Warn().Msg("TOTP_ENCRYPTION_KEY is not set, two-factor enrollment is disabled")
+	}
+	return service.NewMFAService(repo.NewTOTPRepo(store), sealer, config.TOTP_ISSUER)
+}
+
+// newLoginLockout locks usernames and ips out after repeated failed logins. Without alerts users
+// are not emailed when their account is locked.
+func newLoginLockout(store *sqlc.SQLStore, config config.Config, alerts service.SecurityAlertQueue, log *zerolog.Logger) *service.LoginLockout {
+	return service.NewLoginLockout(repo.NewLoginFailureRepo(store), alerts, service.LockoutPolicy{
+		Threshold:   config.LOCKOUT_THRESHOLD,
+		IPThreshold: config.LOCKOUT_IP_THRESHOLD,
+		Window:      config.LOCKOUT_WINDOW,
+		Duration:    config.LOCKOUT_DURATION,
+		MaxDuration: config.LOCKOUT_MAX_DURATION,
+	}, log)
+}
+
+// newDeviceDetector flags logins from devices a user hasn't used before. Without alerts new
+// devices are only logged.
+func newDeviceDetector(store *sqlc.SQLStore, config config.Config, alerts service.DeviceAlertQueue, log *zerolog.Logger) *service.DeviceDetector {
+	return service.NewDeviceDetector(repo.NewKnownDeviceRepo(store), alerts, config.DEVICE_CONFIRMATION, log)
+}
+
+// trustedProxies reads TRUSTED_PROXIES, the proxies whose X-Forwarded-For is believed.
+func trustedProxies(config config.Config, log *zerolog.Logger) netutil.Proxies {
+	proxies, err := netutil.ParseProxies(config.TRUSTED_PROXIES)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid TRUSTED_PROXIES")
+	}
+	return proxies
+}
+
+// newPasskeys sets up passkey sign-in, or returns nil to leave it off when WEBAUTHN_RP_ID is
+// not set.
+func newPasskeys(store *sqlc.SQLStore, config config.Config, log *zerolog.Logger) *service.Passkeys {
+	if config.WEBAUTHN_RP_ID == "" {
+		log.Warn().Msg("WEBAUTHN_RP_ID is not set, passkeys are disabled")
+		return nil
+	}
+	var origins []string
+	if config.WEBAUTHN_RP_ORIGINS != "" {
+		origins = strings.Split(config.WEBAUTHN_RP_ORIGINS, ",")
+	}
+	passkeys, err := service.NewPasskeys(repo.NewWebAuthnRepo(store), service.RelyingParty{
+		ID:      config.WEBAUTHN_RP_ID,
+		Name:    config.WEBAUTHN_RP_NAME,
+		Origins: origins,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid webauthn relying party")
+	}
+	return passkeys
+}
+
+// newPasswordPolicy is what new passwords must satisfy. PASSWORD_BREACHED_DIR, if set, holds the
+// breached password range files.
+func newPasswordPolicy(config config.Config) *auth.PasswordPolicy {
+	policy := &auth.PasswordPolicy{
+		MinLength:      config.PASSWORD_MIN_LENGTH,
+		MaxLength:      auth.DefaultPasswordPolicy.MaxLength,
+		MinCharClasses: config.PASSWORD_MIN_CHAR_CLASS,
+	}
+	if config.PASSWORD_BREACHED_DIR != "" {
+		policy.Breached = auth.NewBreachedPasswords(os.DirFS(config.PASSWORD_BREACHED_DIR))
+	}
+	return policy
+}
+
+// stepUpPolicy lists the operations that need a recent password or second factor check,
+// even with a valid access token.
+func stepUpPolicy(config config.Config) auth.StepUpPolicy {
+	recent := auth.Requirement{MaxAge: config.STEP_UP_MAX_AGE, Assurance: auth.AssurancePassword}
+	return auth.StepUpPolicy{
+		auth.OpHighValueTransfer: recent,
+		auth.OpChangeEmail:       recent,
+		auth.OpChangePassword:    recent,
+		auth.OpShareAccounts:     recent,
+		auth.OpRegisterPasskey:   recent,
+		auth.OpAuthorizeClient:   recent,
+	}
+}
+
 func RunHttpServer(
 	store *sqlc.SQLStore,
 	config config.Config,
-	auth auth.Authenticator,
+	tokenMaker auth.Authenticator,
+	appMetrics *metrics.Metrics,
+	enforcer *ratelimit.Enforcer,
+	log *zerolog.Logger,
 ) {
 	accountRepo := repo.NewAccountRepo(store)
 	transfRepo := repo.NewTransferRepo(store)
@@ -104,17 +302,32 @@ func RunHttpServer(
 
 	//services setup
 	accountSvc := service.NewAccountService(accountRepo)
-	transferSvc := service.NewTransferService(transfRepo, accountRepo)
-	usrSvc := service.NewUserService(UserRepo, auth, config, sessionRepo)
+	transferSvc := service.NewTransferService(transfRepo, accountRepo, appMetrics)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:     UserRepo,
+		Token:     tokenMaker,
+		Config:    config,
+		Sessions:  sessionRepo,
+		MFA:       newMFAService(store, config, log),
+		Lockout:   newLoginLockout(store, config, nil, log),
+		Devices:   newDeviceDetector(store, config, nil, log),
+		Audit:     repo.NewAuditRepo(store),
+		Passwords: newPasswordPolicy(config),
+		Logger:    log,
+	})
+	saSvc := service.NewServiceAccountService(repo.NewServiceAccountRepo(store))
+	oauthSvc := service.NewOAuthService(repo.NewOAuthRepo(store), sessionRepo, tokenMaker, config, log)
+	accessAuth := auth.WithAPIKeys(auth.WithSessionCheck(tokenMaker, usrSvc, oauthSvc), saSvc)
 	//handlers
-	accountHand := httptransport.NewAccountHandler(accountSvc, auth)
-	transfHand := httptransport.NewTranserHandler(transferSvc, auth)
-	userHand := httptransport.NewUserHandler(usrSvc, auth)
+	limit := middleware.RateLimit(enforcer, tokenMaker, log)
+	accountHand := httptransport.NewAccountHandler(accountSvc, accessAuth)
+	transfHand := httptransport.NewTranserHandler(transferSvc, accessAuth, limit, stepUpPolicy(config), config.STEP_UP_TRANSFER_AMOUNT)
+	userHand := httptransport.NewUserHandler(usrSvc, accessAuth, limit)
 
 	//router & routes setup
 	router := httptransport.NewRouter(accountHand, transfHand, userHand)
 
-	if err := router.Serve(config.HTTP_SERVER_ADDRESS); err != nil {
+	if err := router.Serve(config.HTTP_SERVER_ADDRESS, trustedProxies(config, log)); err != nil {
 		return
 	}
 }
@@ -125,24 +338,51 @@ func RunGatewayServer(
 	tokenMaker auth.Authenticator,
 	log *zerolog.Logger,
 	taskqueue jobs.TaskDistributor,
-
+	checker *health.Checker,
+	appMetrics *metrics.Metrics,
+	enforcer *ratelimit.Enforcer,
 ) {
 	ur := repo.NewUserRepo(store)
 	sr := repo.NewSessionRepo(store)
 	UserRepo := repo.NewUserRepo(store)
 
-	usrSvc := service.NewUserService(ur, tokenMaker, config, sr)
+	auditRepo := repo.NewAuditRepo(store)
+	mfaSvc := newMFAService(store, config, log)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:     ur,
+		Token:     tokenMaker,
+		Config:    config,
+		Sessions:  sr,
+		MFA:       mfaSvc,
+		Lockout:   newLoginLockout(store, config, taskqueue, log),
+		Devices:   newDeviceDetector(store, config, taskqueue, log),
+		Audit:     auditRepo,
+		Links:     service.NewLoginLinks(repo.NewLoginLinkRepo(store), taskqueue),
+		Passkeys:  newPasskeys(store, config, log),
+		Passwords: newPasswordPolicy(config),
+		Logger:    log,
+	})
+	verifySvc := service.NewVerifyEmailService(repo.NewVerifyEmailRepo(store), ur, taskqueue)
+	resetSvc := service.NewPasswordResetService(repo.NewPasswordResetRepo(store), taskqueue, newPasswordPolicy(config))
 	svcLogger := logger.ServiceLogger(log, "auth_Service")
-	UserHandler := grpctransport.NewUserHandler(usrSvc, UserRepo, tokenMaker, svcLogger, taskqueue)
-
-	httpGateWayMux := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
-		MarshalOptions: protojson.MarshalOptions{
-			UseProtoNames: true,
-		},
-		UnmarshalOptions: protojson.UnmarshalOptions{
-			DiscardUnknown: true,
-		},
-	}))
+	saSvc := service.NewServiceAccountService(repo.NewServiceAccountRepo(store))
+	oauthSvc := service.NewOAuthService(repo.NewOAuthRepo(store), sr, tokenMaker, config, log)
+	accessAuth := auth.WithAPIKeys(auth.WithSessionCheck(tokenMaker, usrSvc, oauthSvc), saSvc)
+	consentSvc := service.NewAccountConsentService(repo.NewAccountConsentRepo(store), repo.NewAccountRepo(store), repo.NewEntryRepo(store), repo.NewOAuthRepo(store))
+	UserHandler := grpctransport.NewUserHandler(usrSvc, verifySvc, resetSvc, mfaSvc, saSvc, service.NewAuditService(auditRepo), oauthSvc, consentSvc, UserRepo, accessAuth, stepUpPolicy(config), svcLogger, taskqueue)
+
+	httpGateWayMux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithMiddlewares(middleware.GatewayRoute, middleware.GatewayRateLimit(enforcer, tokenMaker, log)),
+		runtime.WithErrorHandler(grpctransport.GatewayErrorHandler),
+	)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -157,12 +397,12 @@ func RunGatewayServer(
 	fs := http.FileServer(http.FS(doc.SwaggerFs))
 	httpmux.Handle("/swagger/", http.StripPrefix("/swagger/", fs))
 
-	httpmux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(map[string]string{"status": "ok"}); err != nil {
-			w.WriteHeader(500)
-		}
-	})
+	httpmux.HandleFunc("/livez", checker.LivenessHandler())
+	httpmux.HandleFunc("/readyz", checker.ReadinessHandler())
+	httpmux.HandleFunc("/health", checker.ReadinessHandler())
+	if jwks, ok := tokenMaker.(auth.JWKSProvider); ok {
+		httpmux.Handle("/.well-known/jwks.json", auth.JWKSHandler(jwks))
+	}
 
 	listener, err := net.Listen("tcp", config.HTTP_SERVER_ADDRESS)
 	if err != nil {
@@ -170,13 +410,34 @@ func RunGatewayServer(
 	}
 
 	log.Info().Str("port", config.GRPC_SERVER_ADDRESS).Msg("starting grpc-gateway server")
-	reqlog := middleware.LogRequest(log)
-	err = http.Serve(listener, reqlog(httpmux))
+	reqID := middleware.RequestID(log)
+	reqlog := middleware.LogRequest(log, appMetrics)
+	err = http.Serve(listener, reqID(reqlog(middleware.ClientInfo(trustedProxies(config, log))(otelhttp.NewHandler(httpmux, "grpc-gateway")))))
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to start-up grpc-gateway server")
 	}
 }
 
+// RunMetricsServer serves /metrics on METRICS_ADDRESS, a listener of its own so the
+// gateway's public port doesn't expose them.
+func RunMetricsServer(config config.Config, appMetrics *metrics.Metrics, log *zerolog.Logger) {
+	if config.METRICS_ADDRESS == "" {
+		log.Warn().Msg("METRICS_ADDRESS is not set, metrics are not served")
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", appMetrics.Handler())
+
+	listener, err := net.Listen("tcp", config.METRICS_ADDRESS)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create metrics listener")
+	}
+	log.Info().Str("port", config.METRICS_ADDRESS).Msg("starting metrics server")
+	if err := http.Serve(listener, mux); err != nil {
+		log.Fatal().Err(err).Msg("failed to start-up metrics server")
+	}
+}
+
 // starting a grpc server
 func RunGrpcServer(
 	config config.Config,
@@ -184,21 +445,52 @@ func RunGrpcServer(
 	tokenMaker auth.Authenticator,
 	log *zerolog.Logger,
 	taskqueue jobs.TaskDistributor,
+	checker *health.Checker,
+	appMetrics *metrics.Metrics,
+	enforcer *ratelimit.Enforcer,
 ) {
 	ur := repo.NewUserRepo(store)
 	sr := repo.NewSessionRepo(store)
 	UserRepo := repo.NewUserRepo(store)
-	usrSvc := service.NewUserService(ur, tokenMaker, config, sr)
-	UserHandler := grpctransport.NewUserHandler(usrSvc, UserRepo, tokenMaker, log, taskqueue)
-
-	logger := grpctransport.LoggingInterceptor(log)
+	auditRepo := repo.NewAuditRepo(store)
+	mfaSvc := newMFAService(store, config, log)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:     ur,
+		Token:     tokenMaker,
+		Config:    config,
+		Sessions:  sr,
+		MFA:       mfaSvc,
+		Lockout:   newLoginLockout(store, config, taskqueue, log),
+		Devices:   newDeviceDetector(store, config, taskqueue, log),
+		Audit:     auditRepo,
+		Links:     service.NewLoginLinks(repo.NewLoginLinkRepo(store), taskqueue),
+		Passkeys:  newPasskeys(store, config, log),
+		Passwords: newPasswordPolicy(config),
+		Logger:    log,
+	})
+	verifySvc := service.NewVerifyEmailService(repo.NewVerifyEmailRepo(store), ur, taskqueue)
+	resetSvc := service.NewPasswordResetService(repo.NewPasswordResetRepo(store), taskqueue, newPasswordPolicy(config))
+	saSvc := service.NewServiceAccountService(repo.NewServiceAccountRepo(store))
+	oauthSvc := service.NewOAuthService(repo.NewOAuthRepo(store), sr, tokenMaker, config, log)
+	accessAuth := auth.WithAPIKeys(auth.WithSessionCheck(tokenMaker, usrSvc, oauthSvc), saSvc)
+	consentSvc := service.NewAccountConsentService(repo.NewAccountConsentRepo(store), repo.NewAccountRepo(store), repo.NewEntryRepo(store), repo.NewOAuthRepo(store))
+	UserHandler := grpctransport.NewUserHandler(usrSvc, verifySvc, resetSvc, mfaSvc, saSvc, service.NewAuditService(auditRepo), oauthSvc, consentSvc, UserRepo, accessAuth, stepUpPolicy(config), log, taskqueue)
+
+	reqID := grpctransport.RequestIDInterceptor(log)
+	clientInfo := grpctransport.ClientInfoInterceptor(trustedProxies(config, log))
+	rateLimit := grpctransport.RateLimitInterceptor(enforcer, tokenMaker, log)
+	logger := grpctransport.LoggingInterceptor(log, appMetrics)
 	recoverPanic := grpctransport.UnaryRecoverPanicInterceptor(log)
 
-	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(recoverPanic, logger))
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(reqID, clientInfo, recoverPanic, logger, rateLimit),
+	)
 
 	reflection.Register(grpcServer)
 
 	pb.RegisterUserServiceServer(grpcServer, UserHandler)
+	healthpb.RegisterHealthServer(grpcServer, checker.GRPCServer())
 
 	listener, err := net.Listen("tcp", config.GRPC_SERVER_ADDRESS)
 	if err != nil {
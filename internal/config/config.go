@@ -8,15 +8,52 @@ import (
 )
 
 type Config struct {
-	DSN                     string        `mapstructure:"DSN"`
-	HTTP_SERVER_ADDRESS     string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GRPC_SERVER_ADDRESS     string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	TOKEN_SYMMETRIC_KEY     string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	ACCESS_TOKEN_DURATATION time.Duration `mapstructure:"ACCESS_TOKEN_DURATATION"`
-	REFRESH_TOKEN_DURATION  time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	ENVIRONMENT             string        `mapstructure:"ENVIRONMENT"`
-	LOG_LEVEL               string        `mapstructure:"LOG_LEVEL"`
-	REDIS_ADDRESS           string        `mapstructure:"REDIS_ADDRESS"`
+	DSN                      string        `mapstructure:"DSN"`
+	HTTP_SERVER_ADDRESS      string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	GRPC_SERVER_ADDRESS      string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	METRICS_ADDRESS          string        `mapstructure:"METRICS_ADDRESS"`
+	TOKEN_TYPE               string        `mapstructure:"TOKEN_TYPE"`
+	TOKEN_SYMMETRIC_KEY      string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TOKEN_PRIVATE_KEY        string        `mapstructure:"TOKEN_PRIVATE_KEY"`
+	TOKEN_SIGNING_KEY_FILE   string        `mapstructure:"TOKEN_SIGNING_KEY_FILE"`
+	TOKEN_PREVIOUS_KEY_FILES string        `mapstructure:"TOKEN_PREVIOUS_KEY_FILES"`
+	ACCESS_TOKEN_DURATATION  time.Duration `mapstructure:"ACCESS_TOKEN_DURATATION"`
+	REFRESH_TOKEN_DURATION   time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	ENVIRONMENT              string        `mapstructure:"ENVIRONMENT"`
+	LOG_LEVEL                string        `mapstructure:"LOG_LEVEL"`
+	REDIS_ADDRESS            string        `mapstructure:"REDIS_ADDRESS"`
+	TRACING_EXPORTER         string        `mapstructure:"TRACING_EXPORTER"`
+	TRACING_OTLP_ENDPOINT    string        `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TRACING_SAMPLE_RATIO     float64       `mapstructure:"TRACING_SAMPLE_RATIO"`
+	RATE_LIMIT_STORE         string        `mapstructure:"RATE_LIMIT_STORE"`
+	RATE_LIMIT_LOGIN         int           `mapstructure:"RATE_LIMIT_LOGIN"`
+	RATE_LIMIT_TRANSFER      int           `mapstructure:"RATE_LIMIT_TRANSFER"`
+	APP_BASE_URL             string        `mapstructure:"APP_BASE_URL"`
+	MAIL_DRIVER              string        `mapstructure:"MAIL_DRIVER"`
+	MAIL_FROM                string        `mapstructure:"MAIL_FROM"`
+	MAIL_DIR                 string        `mapstructure:"MAIL_DIR"`
+	SMTP_HOST                string        `mapstructure:"SMTP_HOST"`
+	SMTP_PORT                int           `mapstructure:"SMTP_PORT"`
+	SMTP_USERNAME            string        `mapstructure:"SMTP_USERNAME"`
+	SMTP_PASSWORD            string        `mapstructure:"SMTP_PASSWORD"`
+	SMTP_TLS                 string        `mapstructure:"SMTP_TLS"`
+	TOTP_ENCRYPTION_KEY      string        `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	TOTP_ISSUER              string        `mapstructure:"TOTP_ISSUER"`
+	STEP_UP_MAX_AGE          time.Duration `mapstructure:"STEP_UP_MAX_AGE"`
+	STEP_UP_TRANSFER_AMOUNT  int64         `mapstructure:"STEP_UP_TRANSFER_AMOUNT"`
+	LOCKOUT_THRESHOLD        int           `mapstructure:"LOCKOUT_THRESHOLD"`
+	LOCKOUT_IP_THRESHOLD     int           `mapstructure:"LOCKOUT_IP_THRESHOLD"`
+	LOCKOUT_WINDOW           time.Duration `mapstructure:"LOCKOUT_WINDOW"`
+	LOCKOUT_DURATION         time.Duration `mapstructure:"LOCKOUT_DURATION"`
+	LOCKOUT_MAX_DURATION     time.Duration `mapstructure:"LOCKOUT_MAX_DURATION"`
+	PASSWORD_MIN_LENGTH      int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PASSWORD_MIN_CHAR_CLASS  int           `mapstructure:"PASSWORD_MIN_CHAR_CLASS"`
+	PASSWORD_BREACHED_DIR    string        `mapstructure:"PASSWORD_BREACHED_DIR"`
+	DEVICE_CONFIRMATION      bool          `mapstructure:"DEVICE_CONFIRMATION"`
+	WEBAUTHN_RP_ID           string        `mapstructure:"WEBAUTHN_RP_ID"`
+	WEBAUTHN_RP_NAME         string        `mapstructure:"WEBAUTHN_RP_NAME"`
+	WEBAUTHN_RP_ORIGINS      string        `mapstructure:"WEBAUTHN_RP_ORIGINS"`
+	TRUSTED_PROXIES          string        `mapstructure:"TRUSTED_PROXIES"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -25,6 +62,41 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
+	//prometheus scrapes /metrics here, away from the public listeners. Set it to a private
+	//interface for scrapers on other hosts, or to empty to stop serving metrics
+	viper.SetDefault("METRICS_ADDRESS", "localhost:9100")
+	//requests per minute, per user and per ip
+	viper.SetDefault("RATE_LIMIT_LOGIN", 5)
+	viper.SetDefault("RATE_LIMIT_TRANSFER", 30)
+	//links in emails point here
+	viper.SetDefault("APP_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("MAIL_DRIVER", "file")
+	viper.SetDefault("MAIL_FROM", "Bank <no-reply@localhost>")
+	viper.SetDefault("MAIL_DIR", "mail")
+	//name authenticator apps show next to the code
+	viper.SetDefault("TOTP_ISSUER", "Bank")
+	//sensitive operations need a password or code check this recent
+	viper.SetDefault("STEP_UP_MAX_AGE", "5m")
+	viper.SetDefault("STEP_UP_TRANSFER_AMOUNT", 1000)
+	//failed logins within the window before a username or ip is locked out
+	viper.SetDefault("LOCKOUT_THRESHOLD", 5)
+	viper.SetDefault("LOCKOUT_IP_THRESHOLD", 20)
+	viper.SetDefault("LOCKOUT_WINDOW", "15m")
+	//the first lock lasts this long, each one after twice as long as the last, up to the max
+	viper.SetDefault("LOCKOUT_DURATION", "1m")
+	viper.SetDefault("LOCKOUT_MAX_DURATION", "1h")
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 10)
+	//how many of lowercase, uppercase, digits and symbols a new password mixes
+	viper.SetDefault("PASSWORD_MIN_CHAR_CLASS", 2)
+	//sign-ins from unrecognized devices wait for an emailed confirmation instead of only alerting
+	viper.SetDefault("DEVICE_CONFIRMATION", false)
+	//passkeys are off without a relying party id, the domain they are bound to. Origins are
+	//comma separated, like TOKEN_PREVIOUS_KEY_FILES, and default to https:// plus the id
+	viper.SetDefault("WEBAUTHN_RP_NAME", "Bank")
+	//comma separated networks of the load balancers in front of the servers. X-Forwarded-For
+	//is ignored unless the connection comes from one of them
+	viper.SetDefault("TRUSTED_PROXIES", "")
+
 	//reading from enviroment varaibles
 	if err = viper.BindEnv("DSN"); err != nil {
 		return Config{}, fmt.Errorf("bind 'DSN' env variable: %w", err)
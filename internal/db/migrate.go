@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"errors"
+	"fmt"
 	"io/fs"
+	"os"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -67,3 +70,59 @@ func (dc *DBClient) MigrateUP() error {
 	}
 	return nil
 }
+
+// latestVersion walks the embedded migrations and returns the highest version.
+func latestVersion() (uint, error) {
+	migrations, err := virtualFS()
+	if err != nil {
+		return 0, err
+	}
+	sourceDriver, err := iofs.New(migrations, ".")
+	if err != nil {
+		return 0, err
+	}
+	defer sourceDriver.Close()
+
+	version, err := sourceDriver.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := sourceDriver.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// MigrationStatus reports an error when the schema is dirty or behind the embedded migrations.
+// It reads the schema_migrations table directly so it does not hold on to a pool connection.
+func (dc *DBClient) MigrationStatus(ctx context.Context) error {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := dc.Client.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("no migrations applied")
+		}
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+
+	latest, err := latestVersion()
+	if err != nil {
+		return err
+	}
+	if uint(version) < latest {
+		return fmt.Errorf("schema at version %d, expected %d", version, latest)
+	}
+	return nil
+}
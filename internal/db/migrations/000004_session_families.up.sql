@@ -0,0 +1,8 @@
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid;
+ALTER TABLE "sessions" ADD COLUMN "parent_id" uuid REFERENCES "sessions" ("id");
+ALTER TABLE "sessions" ADD COLUMN "rotated_at" timestamptz;
+
+UPDATE "sessions" SET "family_id" = "id" WHERE "family_id" IS NULL;
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+
+CREATE INDEX ON "sessions" ("family_id");
@@ -0,0 +1,17 @@
+CREATE TABLE "user_totp" (
+  "username" varchar PRIMARY KEY REFERENCES "users" ("username"),
+  "secret" varchar NOT NULL,
+  "confirmed_at" timestamptz,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "code_hash" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "used_at" timestamptz
+);
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "code_hash");
@@ -0,0 +1,2 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer'
+  CHECK ("role" IN ('customer', 'teller', 'admin'));
@@ -0,0 +1,20 @@
+CREATE TABLE "service_accounts" (
+  "name" varchar PRIMARY KEY,
+  "description" varchar NOT NULL DEFAULT '',
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "account_ids" bigint[] NOT NULL DEFAULT '{}',
+  "created_by" varchar NOT NULL REFERENCES "users" ("username"),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "service_account" varchar NOT NULL REFERENCES "service_accounts" ("name"),
+  "prefix" varchar UNIQUE NOT NULL,
+  "key_hash" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz
+);
+
+CREATE INDEX ON "api_keys" ("service_account");
@@ -0,0 +1,9 @@
+-- subject is "user:<username>" or "ip:<address>". There is deliberately no reference to users so
+-- usernames that don't exist are tracked exactly like ones that do.
+CREATE TABLE "login_failures" (
+  "subject" varchar PRIMARY KEY,
+  "failed_attempts" int NOT NULL DEFAULT 0,
+  "lockouts" int NOT NULL DEFAULT 0,
+  "locked_until" timestamptz,
+  "last_failed_at" timestamptz NOT NULL DEFAULT (now())
+);
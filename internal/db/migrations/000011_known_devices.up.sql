@@ -0,0 +1,17 @@
+-- a device is a user agent seen from an ip range. Rows start out unconfirmed when sign-ins from
+-- new devices need an emailed confirmation; confirm_token_hash is the hash of that link's token.
+CREATE TABLE "known_devices" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "user_agent_hash" varchar NOT NULL,
+  "ip_range" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "last_ip" varchar NOT NULL,
+  "confirmed_at" timestamptz,
+  "confirm_token_hash" varchar UNIQUE,
+  "confirm_expires_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_seen_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "known_devices" ("username", "user_agent_hash", "ip_range");
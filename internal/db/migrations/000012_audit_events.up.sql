@@ -0,0 +1,29 @@
+-- audit_events is append-only: rows are written in the same transaction as the action they
+-- record and can never be changed or removed afterwards.
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "action" varchar NOT NULL,
+  "actor" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "request_id" varchar NOT NULL DEFAULT '',
+  "details" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_events" ("actor");
+CREATE INDEX ON "audit_events" ("subject");
+CREATE INDEX ON "audit_events" ("action");
+CREATE INDEX ON "audit_events" ("created_at");
+
+CREATE FUNCTION "audit_events_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_no_update_delete" BEFORE UPDATE OR DELETE ON "audit_events"
+  FOR EACH ROW EXECUTE FUNCTION "audit_events_append_only"();
+CREATE TRIGGER "audit_events_no_truncate" BEFORE TRUNCATE ON "audit_events"
+  FOR EACH STATEMENT EXECUTE FUNCTION "audit_events_append_only"();
@@ -0,0 +1,10 @@
+-- only the id of a signed login link token is stored; using the row spends the link
+CREATE TABLE "login_links" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz
+);
+
+CREATE INDEX ON "login_links" ("username");
@@ -0,0 +1,35 @@
+-- authenticators identify a user by this random handle rather than the username
+CREATE TABLE "webauthn_users" (
+  "username" varchar PRIMARY KEY REFERENCES "users" ("username"),
+  "user_handle" bytea UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webauthn_credentials" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "webauthn_users" ("username"),
+  "name" varchar NOT NULL DEFAULT '',
+  "credential_id" bytea UNIQUE NOT NULL,
+  "public_key" bytea NOT NULL,
+  "attestation_type" varchar NOT NULL,
+  "transports" varchar[] NOT NULL DEFAULT '{}',
+  "aaguid" bytea NOT NULL,
+  "backup_eligible" boolean NOT NULL DEFAULT false,
+  "backup_state" boolean NOT NULL DEFAULT false,
+  "sign_count" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_used_at" timestamptz
+);
+
+CREATE INDEX ON "webauthn_credentials" ("username");
+
+-- the challenge of a registration or login between its begin and finish calls. Finishing
+-- deletes the row, so each challenge is answered once.
+CREATE TABLE "webauthn_ceremonies" (
+  "id" uuid PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "username" varchar REFERENCES "users" ("username"),
+  "session_data" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL
+);
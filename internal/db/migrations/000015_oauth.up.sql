@@ -0,0 +1,57 @@
+-- partner applications. Public clients have no secret and can only use the authorization
+-- code grant, which always requires PKCE.
+CREATE TABLE "oauth_clients" (
+  "id" varchar PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "secret_hash" varchar,
+  "redirect_uris" varchar[] NOT NULL DEFAULT '{}',
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "grant_types" varchar[] NOT NULL DEFAULT '{}',
+  "created_by" varchar NOT NULL REFERENCES "users" ("username"),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "revoked_at" timestamptz
+);
+
+-- what a user has let a client do for them. Revoking keeps the row; granting again starts a new one.
+CREATE TABLE "oauth_consents" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "client_id" varchar NOT NULL REFERENCES "oauth_clients" ("id"),
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "revoked_at" timestamptz
+);
+
+CREATE UNIQUE INDEX ON "oauth_consents" ("username", "client_id") WHERE "revoked_at" IS NULL;
+
+-- only a hash of each code is kept; using the row spends the code
+CREATE TABLE "oauth_authorization_codes" (
+  "code_hash" varchar PRIMARY KEY,
+  "client_id" varchar NOT NULL REFERENCES "oauth_clients" ("id"),
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "redirect_uri" varchar NOT NULL,
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "code_challenge" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz
+);
+
+-- client credentials tokens act for no user, so they have no session; this row is what
+-- revocation and introspection look at instead
+CREATE TABLE "oauth_client_tokens" (
+  "id" uuid PRIMARY KEY,
+  "client_id" varchar NOT NULL REFERENCES "oauth_clients" ("id"),
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz
+);
+
+CREATE INDEX ON "oauth_client_tokens" ("client_id");
+
+-- sessions started through the authorization code grant belong to the client
+ALTER TABLE "sessions" ADD COLUMN "client_id" varchar REFERENCES "oauth_clients" ("id");
+
+CREATE INDEX ON "sessions" ("client_id");
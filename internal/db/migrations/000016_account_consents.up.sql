@@ -0,0 +1,14 @@
+-- what a user lets an OAuth client read about specific accounts, until expires_at. Unlike
+-- oauth_consents each grant is its own row, which the client names on every read.
+CREATE TABLE "account_consents" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "client_id" varchar NOT NULL REFERENCES "oauth_clients" ("id"),
+  "account_ids" bigint[] NOT NULL,
+  "permissions" varchar[] NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz
+);
+
+CREATE INDEX ON "account_consents" ("username");
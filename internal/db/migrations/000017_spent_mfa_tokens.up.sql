@@ -0,0 +1,9 @@
+-- ids of MFA tokens already exchanged for a session, kept until the tokens expire
+CREATE TABLE "spent_mfa_tokens" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "spent_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "spent_mfa_tokens" ("expires_at");
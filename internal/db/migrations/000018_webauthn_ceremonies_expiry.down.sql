@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "webauthn_ceremonies_expires_at_idx";
@@ -0,0 +1,2 @@
+-- expired ceremonies are deleted whenever a new one starts
+CREATE INDEX ON "webauthn_ceremonies" ("expires_at");
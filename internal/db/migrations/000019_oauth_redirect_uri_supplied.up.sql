@@ -0,0 +1,3 @@
+-- whether the authorization request named its redirect uri; only then must the token request
+-- repeat it (RFC 6749 section 4.1.3)
+ALTER TABLE "oauth_authorization_codes" ADD COLUMN "redirect_uri_supplied" boolean NOT NULL DEFAULT true;
@@ -0,0 +1,2 @@
+-- the hashes can't be undone, so codes sent before the rollback stop working
+ALTER TABLE "verify_emails" RENAME COLUMN "secret_code_hash" TO "secret_code";
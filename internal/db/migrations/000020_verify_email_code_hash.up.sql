@@ -0,0 +1,4 @@
+-- codes are stored hashed like every other emailed secret; outstanding ones are hashed in place
+-- so links already sent keep working
+ALTER TABLE "verify_emails" RENAME COLUMN "secret_code" TO "secret_code_hash";
+UPDATE "verify_emails" SET "secret_code_hash" = encode(sha256(convert_to("secret_code_hash", 'UTF8')), 'hex');
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/0xOnah/bank/service (interfaces: AccountRepository)
+// Source: github.com/0xOnah/bank/internal/service (interfaces: AccountRepository)
 //
 // Generated by this command:
 //
-//	mockgen -package mockdb -destination db/mock/account.go github.com/0xOnah/bank/service AccountRepository
+//	mockgen -package mockdb -destination internal/db/mock/account.go github.com/0xOnah/bank/internal/service AccountRepository
 //
 
 // Package mockdb is a generated GoMock package.
@@ -13,7 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
-	"github.com/0xOnah/bank/internal/entity"
+	entity "github.com/0xOnah/bank/internal/entity"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -57,18 +57,18 @@ func (mr *MockAccountRepositoryMockRecorder) AddAccountBalance(ctx, arg any) *go
 }
 
 // CreateAccount mocks base method.
-func (m *MockAccountRepository) CreateAccount(ctx context.Context, arg entity.CreateAccountInput) (*entity.Account, error) {
+func (m *MockAccountRepository) CreateAccount(ctx context.Context, arg entity.CreateAccountInput, event entity.AuditEvent) (*entity.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAccount", ctx, arg)
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, arg, event)
 	ret0, _ := ret[0].(*entity.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateAccount indicates an expected call of CreateAccount.
-func (mr *MockAccountRepositoryMockRecorder) CreateAccount(ctx, arg any) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) CreateAccount(ctx, arg, event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockAccountRepository)(nil).CreateAccount), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockAccountRepository)(nil).CreateAccount), ctx, arg, event)
 }
 
 // DeleteAccount mocks base method.
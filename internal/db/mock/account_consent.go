@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: AccountConsentRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/account_consent.go github.com/0xOnah/bank/internal/service AccountConsentRepository
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAccountConsentRepository is a mock of AccountConsentRepository interface.
+type MockAccountConsentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountConsentRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAccountConsentRepositoryMockRecorder is the mock recorder for MockAccountConsentRepository.
+type MockAccountConsentRepositoryMockRecorder struct {
+	mock *MockAccountConsentRepository
+}
+
+// NewMockAccountConsentRepository creates a new mock instance.
+func NewMockAccountConsentRepository(ctrl *gomock.Controller) *MockAccountConsentRepository {
+	mock := &MockAccountConsentRepository{ctrl: ctrl}
+	mock.recorder = &MockAccountConsentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountConsentRepository) EXPECT() *MockAccountConsentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAccountConsent mocks base method.
+func (m *MockAccountConsentRepository) CreateAccountConsent(ctx context.Context, arg entity.AccountConsent, event entity.AuditEvent) (*entity.AccountConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountConsent", ctx, arg, event)
+	ret0, _ := ret[0].(*entity.AccountConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountConsent indicates an expected call of CreateAccountConsent.
+func (mr *MockAccountConsentRepositoryMockRecorder) CreateAccountConsent(ctx, arg, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountConsent", reflect.TypeOf((*MockAccountConsentRepository)(nil).CreateAccountConsent), ctx, arg, event)
+}
+
+// GetAccountConsent mocks base method.
+func (m *MockAccountConsentRepository) GetAccountConsent(ctx context.Context, id uuid.UUID) (*entity.AccountConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountConsent", ctx, id)
+	ret0, _ := ret[0].(*entity.AccountConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountConsent indicates an expected call of GetAccountConsent.
+func (mr *MockAccountConsentRepositoryMockRecorder) GetAccountConsent(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountConsent", reflect.TypeOf((*MockAccountConsentRepository)(nil).GetAccountConsent), ctx, id)
+}
+
+// ListAccountConsents mocks base method.
+func (m *MockAccountConsentRepository) ListAccountConsents(ctx context.Context, username string) ([]*entity.AccountConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountConsents", ctx, username)
+	ret0, _ := ret[0].([]*entity.AccountConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountConsents indicates an expected call of ListAccountConsents.
+func (mr *MockAccountConsentRepositoryMockRecorder) ListAccountConsents(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountConsents", reflect.TypeOf((*MockAccountConsentRepository)(nil).ListAccountConsents), ctx, username)
+}
+
+// RevokeAccountConsent mocks base method.
+func (m *MockAccountConsentRepository) RevokeAccountConsent(ctx context.Context, id uuid.UUID, username string, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccountConsent", ctx, id, username, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccountConsent indicates an expected call of RevokeAccountConsent.
+func (mr *MockAccountConsentRepositoryMockRecorder) RevokeAccountConsent(ctx, id, username, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccountConsent", reflect.TypeOf((*MockAccountConsentRepository)(nil).RevokeAccountConsent), ctx, id, username, event)
+}
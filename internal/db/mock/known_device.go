@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: KnownDeviceRepository,DeviceAlertQueue)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/known_device.go github.com/0xOnah/bank/internal/service KnownDeviceRepository,DeviceAlertQueue
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	jobs "github.com/0xOnah/bank/internal/sdk/jobs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockKnownDeviceRepository is a mock of KnownDeviceRepository interface.
+type MockKnownDeviceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockKnownDeviceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockKnownDeviceRepositoryMockRecorder is the mock recorder for MockKnownDeviceRepository.
+type MockKnownDeviceRepositoryMockRecorder struct {
+	mock *MockKnownDeviceRepository
+}
+
+// NewMockKnownDeviceRepository creates a new mock instance.
+func NewMockKnownDeviceRepository(ctrl *gomock.Controller) *MockKnownDeviceRepository {
+	mock := &MockKnownDeviceRepository{ctrl: ctrl}
+	mock.recorder = &MockKnownDeviceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKnownDeviceRepository) EXPECT() *MockKnownDeviceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConfirmKnownDevice mocks base method.
+func (m *MockKnownDeviceRepository) ConfirmKnownDevice(ctx context.Context, tokenHash string, event entity.AuditEvent) (*entity.KnownDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmKnownDevice", ctx, tokenHash, event)
+	ret0, _ := ret[0].(*entity.KnownDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmKnownDevice indicates an expected call of ConfirmKnownDevice.
+func (mr *MockKnownDeviceRepositoryMockRecorder) ConfirmKnownDevice(ctx, tokenHash, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmKnownDevice", reflect.TypeOf((*MockKnownDeviceRepository)(nil).ConfirmKnownDevice), ctx, tokenHash, event)
+}
+
+// ListKnownDevices mocks base method.
+func (m *MockKnownDeviceRepository) ListKnownDevices(ctx context.Context, username string) ([]*entity.KnownDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKnownDevices", ctx, username)
+	ret0, _ := ret[0].([]*entity.KnownDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKnownDevices indicates an expected call of ListKnownDevices.
+func (mr *MockKnownDeviceRepositoryMockRecorder) ListKnownDevices(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKnownDevices", reflect.TypeOf((*MockKnownDeviceRepository)(nil).ListKnownDevices), ctx, username)
+}
+
+// SaveKnownDevice mocks base method.
+func (m *MockKnownDeviceRepository) SaveKnownDevice(ctx context.Context, arg entity.KnownDevice, confirm bool) (*entity.KnownDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKnownDevice", ctx, arg, confirm)
+	ret0, _ := ret[0].(*entity.KnownDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveKnownDevice indicates an expected call of SaveKnownDevice.
+func (mr *MockKnownDeviceRepositoryMockRecorder) SaveKnownDevice(ctx, arg, confirm any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKnownDevice", reflect.TypeOf((*MockKnownDeviceRepository)(nil).SaveKnownDevice), ctx, arg, confirm)
+}
+
+// MockDeviceAlertQueue is a mock of DeviceAlertQueue interface.
+type MockDeviceAlertQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeviceAlertQueueMockRecorder
+	isgomock struct{}
+}
+
+// MockDeviceAlertQueueMockRecorder is the mock recorder for MockDeviceAlertQueue.
+type MockDeviceAlertQueueMockRecorder struct {
+	mock *MockDeviceAlertQueue
+}
+
+// NewMockDeviceAlertQueue creates a new mock instance.
+func NewMockDeviceAlertQueue(ctrl *gomock.Controller) *MockDeviceAlertQueue {
+	mock := &MockDeviceAlertQueue{ctrl: ctrl}
+	mock.recorder = &MockDeviceAlertQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeviceAlertQueue) EXPECT() *MockDeviceAlertQueueMockRecorder {
+	return m.recorder
+}
+
+// JobDeviceConfirmation mocks base method.
+func (m *MockDeviceAlertQueue) JobDeviceConfirmation(ctx context.Context, payload *jobs.DeviceConfirmationPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JobDeviceConfirmation", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// JobDeviceConfirmation indicates an expected call of JobDeviceConfirmation.
+func (mr *MockDeviceAlertQueueMockRecorder) JobDeviceConfirmation(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobDeviceConfirmation", reflect.TypeOf((*MockDeviceAlertQueue)(nil).JobDeviceConfirmation), ctx, payload)
+}
+
+// JobSecurityAlert mocks base method.
+func (m *MockDeviceAlertQueue) JobSecurityAlert(ctx context.Context, payload *jobs.SecurityAlertPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JobSecurityAlert", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// JobSecurityAlert indicates an expected call of JobSecurityAlert.
+func (mr *MockDeviceAlertQueueMockRecorder) JobSecurityAlert(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobSecurityAlert", reflect.TypeOf((*MockDeviceAlertQueue)(nil).JobSecurityAlert), ctx, payload)
+}
@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: LoginFailureRepository,SecurityAlertQueue)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/login_failure.go github.com/0xOnah/bank/internal/service LoginFailureRepository,SecurityAlertQueue
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	jobs "github.com/0xOnah/bank/internal/sdk/jobs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginFailureRepository is a mock of LoginFailureRepository interface.
+type MockLoginFailureRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginFailureRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLoginFailureRepositoryMockRecorder is the mock recorder for MockLoginFailureRepository.
+type MockLoginFailureRepositoryMockRecorder struct {
+	mock *MockLoginFailureRepository
+}
+
+// NewMockLoginFailureRepository creates a new mock instance.
+func NewMockLoginFailureRepository(ctrl *gomock.Controller) *MockLoginFailureRepository {
+	mock := &MockLoginFailureRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginFailureRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginFailureRepository) EXPECT() *MockLoginFailureRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClearLoginFailures mocks base method.
+func (m *MockLoginFailureRepository) ClearLoginFailures(ctx context.Context, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearLoginFailures", ctx, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearLoginFailures indicates an expected call of ClearLoginFailures.
+func (mr *MockLoginFailureRepositoryMockRecorder) ClearLoginFailures(ctx, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLoginFailures", reflect.TypeOf((*MockLoginFailureRepository)(nil).ClearLoginFailures), ctx, subject)
+}
+
+// ListLoginLocks mocks base method.
+func (m *MockLoginFailureRepository) ListLoginLocks(ctx context.Context, subjects []string) ([]*entity.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoginLocks", ctx, subjects)
+	ret0, _ := ret[0].([]*entity.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoginLocks indicates an expected call of ListLoginLocks.
+func (mr *MockLoginFailureRepositoryMockRecorder) ListLoginLocks(ctx, subjects any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginLocks", reflect.TypeOf((*MockLoginFailureRepository)(nil).ListLoginLocks), ctx, subjects)
+}
+
+// LockLoginSubject mocks base method.
+func (m *MockLoginFailureRepository) LockLoginSubject(ctx context.Context, subject string, until time.Time) (*entity.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLoginSubject", ctx, subject, until)
+	ret0, _ := ret[0].(*entity.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockLoginSubject indicates an expected call of LockLoginSubject.
+func (mr *MockLoginFailureRepositoryMockRecorder) LockLoginSubject(ctx, subject, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLoginSubject", reflect.TypeOf((*MockLoginFailureRepository)(nil).LockLoginSubject), ctx, subject, until)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockLoginFailureRepository) RecordLoginFailure(ctx context.Context, subject string, windowStart time.Time) (*entity.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, subject, windowStart)
+	ret0, _ := ret[0].(*entity.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockLoginFailureRepositoryMockRecorder) RecordLoginFailure(ctx, subject, windowStart any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockLoginFailureRepository)(nil).RecordLoginFailure), ctx, subject, windowStart)
+}
+
+// UnlockLoginSubject mocks base method.
+func (m *MockLoginFailureRepository) UnlockLoginSubject(ctx context.Context, subject string, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockLoginSubject", ctx, subject, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockLoginSubject indicates an expected call of UnlockLoginSubject.
+func (mr *MockLoginFailureRepositoryMockRecorder) UnlockLoginSubject(ctx, subject, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockLoginSubject", reflect.TypeOf((*MockLoginFailureRepository)(nil).UnlockLoginSubject), ctx, subject, event)
+}
+
+// MockSecurityAlertQueue is a mock of SecurityAlertQueue interface.
+type MockSecurityAlertQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecurityAlertQueueMockRecorder
+	isgomock struct{}
+}
+
+// MockSecurityAlertQueueMockRecorder is the mock recorder for MockSecurityAlertQueue.
+type MockSecurityAlertQueueMockRecorder struct {
+	mock *MockSecurityAlertQueue
+}
+
+// NewMockSecurityAlertQueue creates a new mock instance.
+func NewMockSecurityAlertQueue(ctrl *gomock.Controller) *MockSecurityAlertQueue {
+	mock := &MockSecurityAlertQueue{ctrl: ctrl}
+	mock.recorder = &MockSecurityAlertQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecurityAlertQueue) EXPECT() *MockSecurityAlertQueueMockRecorder {
+	return m.recorder
+}
+
+// JobSecurityAlert mocks base method.
+func (m *MockSecurityAlertQueue) JobSecurityAlert(ctx context.Context, payload *jobs.SecurityAlertPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JobSecurityAlert", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// JobSecurityAlert indicates an expected call of JobSecurityAlert.
+func (mr *MockSecurityAlertQueueMockRecorder) JobSecurityAlert(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobSecurityAlert", reflect.TypeOf((*MockSecurityAlertQueue)(nil).JobSecurityAlert), ctx, payload)
+}
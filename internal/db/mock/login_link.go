@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: LoginLinkRepository,LoginLinkQueue)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/login_link.go github.com/0xOnah/bank/internal/service LoginLinkRepository,LoginLinkQueue
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	jobs "github.com/0xOnah/bank/internal/sdk/jobs"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginLinkRepository is a mock of LoginLinkRepository interface.
+type MockLoginLinkRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginLinkRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLoginLinkRepositoryMockRecorder is the mock recorder for MockLoginLinkRepository.
+type MockLoginLinkRepositoryMockRecorder struct {
+	mock *MockLoginLinkRepository
+}
+
+// NewMockLoginLinkRepository creates a new mock instance.
+func NewMockLoginLinkRepository(ctrl *gomock.Controller) *MockLoginLinkRepository {
+	mock := &MockLoginLinkRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginLinkRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginLinkRepository) EXPECT() *MockLoginLinkRepositoryMockRecorder {
+	return m.recorder
+}
+
+// UseLoginLink mocks base method.
+func (m *MockLoginLinkRepository) UseLoginLink(ctx context.Context, id uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLoginLink", ctx, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseLoginLink indicates an expected call of UseLoginLink.
+func (mr *MockLoginLinkRepositoryMockRecorder) UseLoginLink(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLoginLink", reflect.TypeOf((*MockLoginLinkRepository)(nil).UseLoginLink), ctx, id)
+}
+
+// MockLoginLinkQueue is a mock of LoginLinkQueue interface.
+type MockLoginLinkQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginLinkQueueMockRecorder
+	isgomock struct{}
+}
+
+// MockLoginLinkQueueMockRecorder is the mock recorder for MockLoginLinkQueue.
+type MockLoginLinkQueueMockRecorder struct {
+	mock *MockLoginLinkQueue
+}
+
+// NewMockLoginLinkQueue creates a new mock instance.
+func NewMockLoginLinkQueue(ctrl *gomock.Controller) *MockLoginLinkQueue {
+	mock := &MockLoginLinkQueue{ctrl: ctrl}
+	mock.recorder = &MockLoginLinkQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginLinkQueue) EXPECT() *MockLoginLinkQueueMockRecorder {
+	return m.recorder
+}
+
+// JobLoginLink mocks base method.
+func (m *MockLoginLinkQueue) JobLoginLink(ctx context.Context, payload *jobs.LoginLinkPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JobLoginLink", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// JobLoginLink indicates an expected call of JobLoginLink.
+func (mr *MockLoginLinkQueueMockRecorder) JobLoginLink(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobLoginLink", reflect.TypeOf((*MockLoginLinkQueue)(nil).JobLoginLink), ctx, payload)
+}
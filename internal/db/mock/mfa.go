@@ -0,0 +1,181 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: MFARepository,MFAVerifier)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/mfa.go github.com/0xOnah/bank/internal/service MFARepository,MFAVerifier
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMFARepository is a mock of MFARepository interface.
+type MockMFARepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFARepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMFARepositoryMockRecorder is the mock recorder for MockMFARepository.
+type MockMFARepositoryMockRecorder struct {
+	mock *MockMFARepository
+}
+
+// NewMockMFARepository creates a new mock instance.
+func NewMockMFARepository(ctrl *gomock.Controller) *MockMFARepository {
+	mock := &MockMFARepository{ctrl: ctrl}
+	mock.recorder = &MockMFARepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFARepository) EXPECT() *MockMFARepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockMFARepository) ConfirmTOTP(ctx context.Context, username string, step int64, recoveryCodeHashes []string, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, username, step, recoveryCodeHashes, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockMFARepositoryMockRecorder) ConfirmTOTP(ctx, username, step, recoveryCodeHashes, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockMFARepository)(nil).ConfirmTOTP), ctx, username, step, recoveryCodeHashes, event)
+}
+
+// CreatePendingTOTP mocks base method.
+func (m *MockMFARepository) CreatePendingTOTP(ctx context.Context, username, secret string) (*entity.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePendingTOTP", ctx, username, secret)
+	ret0, _ := ret[0].(*entity.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePendingTOTP indicates an expected call of CreatePendingTOTP.
+func (mr *MockMFARepositoryMockRecorder) CreatePendingTOTP(ctx, username, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingTOTP", reflect.TypeOf((*MockMFARepository)(nil).CreatePendingTOTP), ctx, username, secret)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockMFARepository) DisableTOTP(ctx context.Context, username string, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, username, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockMFARepositoryMockRecorder) DisableTOTP(ctx, username, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockMFARepository)(nil).DisableTOTP), ctx, username, event)
+}
+
+// GetUserTOTP mocks base method.
+func (m *MockMFARepository) GetUserTOTP(ctx context.Context, username string) (*entity.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTP", ctx, username)
+	ret0, _ := ret[0].(*entity.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTP indicates an expected call of GetUserTOTP.
+func (mr *MockMFARepositoryMockRecorder) GetUserTOTP(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTP", reflect.TypeOf((*MockMFARepository)(nil).GetUserTOTP), ctx, username)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, username, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, username, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockMFARepositoryMockRecorder) UseRecoveryCode(ctx, username, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockMFARepository)(nil).UseRecoveryCode), ctx, username, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockMFARepository) UseTOTPStep(ctx context.Context, username string, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, username, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockMFARepositoryMockRecorder) UseTOTPStep(ctx, username, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockMFARepository)(nil).UseTOTPStep), ctx, username, step)
+}
+
+// MockMFAVerifier is a mock of MFAVerifier interface.
+type MockMFAVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFAVerifierMockRecorder
+	isgomock struct{}
+}
+
+// MockMFAVerifierMockRecorder is the mock recorder for MockMFAVerifier.
+type MockMFAVerifierMockRecorder struct {
+	mock *MockMFAVerifier
+}
+
+// NewMockMFAVerifier creates a new mock instance.
+func NewMockMFAVerifier(ctrl *gomock.Controller) *MockMFAVerifier {
+	mock := &MockMFAVerifier{ctrl: ctrl}
+	mock.recorder = &MockMFAVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFAVerifier) EXPECT() *MockMFAVerifierMockRecorder {
+	return m.recorder
+}
+
+// MFAEnabled mocks base method.
+func (m *MockMFAVerifier) MFAEnabled(ctx context.Context, username string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MFAEnabled", ctx, username)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MFAEnabled indicates an expected call of MFAEnabled.
+func (mr *MockMFAVerifierMockRecorder) MFAEnabled(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MFAEnabled", reflect.TypeOf((*MockMFAVerifier)(nil).MFAEnabled), ctx, username)
+}
+
+// VerifyCode mocks base method.
+func (m *MockMFAVerifier) VerifyCode(ctx context.Context, username, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCode", ctx, username, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyCode indicates an expected call of VerifyCode.
+func (mr *MockMFAVerifierMockRecorder) VerifyCode(ctx, username, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCode", reflect.TypeOf((*MockMFAVerifier)(nil).VerifyCode), ctx, username, code)
+}
@@ -0,0 +1,204 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: OAuthRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/oauth.go github.com/0xOnah/bank/internal/service OAuthRepository
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuthRepository is a mock of OAuthRepository interface.
+type MockOAuthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOAuthRepositoryMockRecorder is the mock recorder for MockOAuthRepository.
+type MockOAuthRepositoryMockRecorder struct {
+	mock *MockOAuthRepository
+}
+
+// NewMockOAuthRepository creates a new mock instance.
+func NewMockOAuthRepository(ctrl *gomock.Controller) *MockOAuthRepository {
+	mock := &MockOAuthRepository{ctrl: ctrl}
+	mock.recorder = &MockOAuthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthRepository) EXPECT() *MockOAuthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockOAuthRepository) CreateOAuthClient(ctx context.Context, arg entity.OAuthClient, event entity.AuditEvent) (*entity.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", ctx, arg, event)
+	ret0, _ := ret[0].(*entity.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockOAuthRepositoryMockRecorder) CreateOAuthClient(ctx, arg, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockOAuthRepository)(nil).CreateOAuthClient), ctx, arg, event)
+}
+
+// CreateOAuthClientToken mocks base method.
+func (m *MockOAuthRepository) CreateOAuthClientToken(ctx context.Context, arg entity.OAuthClientToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClientToken", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOAuthClientToken indicates an expected call of CreateOAuthClientToken.
+func (mr *MockOAuthRepositoryMockRecorder) CreateOAuthClientToken(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClientToken", reflect.TypeOf((*MockOAuthRepository)(nil).CreateOAuthClientToken), ctx, arg)
+}
+
+// GetOAuthClient mocks base method.
+func (m *MockOAuthRepository) GetOAuthClient(ctx context.Context, id string) (*entity.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClient", ctx, id)
+	ret0, _ := ret[0].(*entity.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClient indicates an expected call of GetOAuthClient.
+func (mr *MockOAuthRepositoryMockRecorder) GetOAuthClient(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClient", reflect.TypeOf((*MockOAuthRepository)(nil).GetOAuthClient), ctx, id)
+}
+
+// GetOAuthClientToken mocks base method.
+func (m *MockOAuthRepository) GetOAuthClientToken(ctx context.Context, id uuid.UUID) (*entity.OAuthClientToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClientToken", ctx, id)
+	ret0, _ := ret[0].(*entity.OAuthClientToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClientToken indicates an expected call of GetOAuthClientToken.
+func (mr *MockOAuthRepositoryMockRecorder) GetOAuthClientToken(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClientToken", reflect.TypeOf((*MockOAuthRepository)(nil).GetOAuthClientToken), ctx, id)
+}
+
+// GetOAuthConsent mocks base method.
+func (m *MockOAuthRepository) GetOAuthConsent(ctx context.Context, username, clientID string) (*entity.OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthConsent", ctx, username, clientID)
+	ret0, _ := ret[0].(*entity.OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthConsent indicates an expected call of GetOAuthConsent.
+func (mr *MockOAuthRepositoryMockRecorder) GetOAuthConsent(ctx, username, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthConsent", reflect.TypeOf((*MockOAuthRepository)(nil).GetOAuthConsent), ctx, username, clientID)
+}
+
+// GrantOAuthConsent mocks base method.
+func (m *MockOAuthRepository) GrantOAuthConsent(ctx context.Context, code entity.OAuthAuthorizationCode, event entity.AuditEvent) (*entity.OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantOAuthConsent", ctx, code, event)
+	ret0, _ := ret[0].(*entity.OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantOAuthConsent indicates an expected call of GrantOAuthConsent.
+func (mr *MockOAuthRepositoryMockRecorder) GrantOAuthConsent(ctx, code, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantOAuthConsent", reflect.TypeOf((*MockOAuthRepository)(nil).GrantOAuthConsent), ctx, code, event)
+}
+
+// ListOAuthConsents mocks base method.
+func (m *MockOAuthRepository) ListOAuthConsents(ctx context.Context, username string) ([]*entity.OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOAuthConsents", ctx, username)
+	ret0, _ := ret[0].([]*entity.OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthConsents indicates an expected call of ListOAuthConsents.
+func (mr *MockOAuthRepositoryMockRecorder) ListOAuthConsents(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthConsents", reflect.TypeOf((*MockOAuthRepository)(nil).ListOAuthConsents), ctx, username)
+}
+
+// RevokeOAuthClient mocks base method.
+func (m *MockOAuthRepository) RevokeOAuthClient(ctx context.Context, id string, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOAuthClient", ctx, id, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOAuthClient indicates an expected call of RevokeOAuthClient.
+func (mr *MockOAuthRepositoryMockRecorder) RevokeOAuthClient(ctx, id, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuthClient", reflect.TypeOf((*MockOAuthRepository)(nil).RevokeOAuthClient), ctx, id, event)
+}
+
+// RevokeOAuthClientToken mocks base method.
+func (m *MockOAuthRepository) RevokeOAuthClientToken(ctx context.Context, id uuid.UUID, clientID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOAuthClientToken", ctx, id, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOAuthClientToken indicates an expected call of RevokeOAuthClientToken.
+func (mr *MockOAuthRepositoryMockRecorder) RevokeOAuthClientToken(ctx, id, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuthClientToken", reflect.TypeOf((*MockOAuthRepository)(nil).RevokeOAuthClientToken), ctx, id, clientID)
+}
+
+// RevokeOAuthConsent mocks base method.
+func (m *MockOAuthRepository) RevokeOAuthConsent(ctx context.Context, username, clientID string, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOAuthConsent", ctx, username, clientID, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOAuthConsent indicates an expected call of RevokeOAuthConsent.
+func (mr *MockOAuthRepositoryMockRecorder) RevokeOAuthConsent(ctx, username, clientID, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuthConsent", reflect.TypeOf((*MockOAuthRepository)(nil).RevokeOAuthConsent), ctx, username, clientID, event)
+}
+
+// UseOAuthAuthorizationCode mocks base method.
+func (m *MockOAuthRepository) UseOAuthAuthorizationCode(ctx context.Context, codeHash string) (*entity.OAuthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOAuthAuthorizationCode", ctx, codeHash)
+	ret0, _ := ret[0].(*entity.OAuthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseOAuthAuthorizationCode indicates an expected call of UseOAuthAuthorizationCode.
+func (mr *MockOAuthRepositoryMockRecorder) UseOAuthAuthorizationCode(ctx, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOAuthAuthorizationCode", reflect.TypeOf((*MockOAuthRepository)(nil).UseOAuthAuthorizationCode), ctx, codeHash)
+}
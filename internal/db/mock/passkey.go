@@ -0,0 +1,160 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: PasskeyRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/passkey.go github.com/0xOnah/bank/internal/service PasskeyRepository
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPasskeyRepository is a mock of PasskeyRepository interface.
+type MockPasskeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPasskeyRepositoryMockRecorder is the mock recorder for MockPasskeyRepository.
+type MockPasskeyRepositoryMockRecorder struct {
+	mock *MockPasskeyRepository
+}
+
+// NewMockPasskeyRepository creates a new mock instance.
+func NewMockPasskeyRepository(ctrl *gomock.Controller) *MockPasskeyRepository {
+	mock := &MockPasskeyRepository{ctrl: ctrl}
+	mock.recorder = &MockPasskeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasskeyRepository) EXPECT() *MockPasskeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateWebAuthnCeremony mocks base method.
+func (m *MockPasskeyRepository) CreateWebAuthnCeremony(ctx context.Context, arg entity.WebAuthnCeremony) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebAuthnCeremony", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebAuthnCeremony indicates an expected call of CreateWebAuthnCeremony.
+func (mr *MockPasskeyRepositoryMockRecorder) CreateWebAuthnCeremony(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebAuthnCeremony", reflect.TypeOf((*MockPasskeyRepository)(nil).CreateWebAuthnCeremony), ctx, arg)
+}
+
+// CreateWebAuthnCredential mocks base method.
+func (m *MockPasskeyRepository) CreateWebAuthnCredential(ctx context.Context, arg entity.WebAuthnCredential, event entity.AuditEvent) (*entity.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebAuthnCredential", ctx, arg, event)
+	ret0, _ := ret[0].(*entity.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebAuthnCredential indicates an expected call of CreateWebAuthnCredential.
+func (mr *MockPasskeyRepositoryMockRecorder) CreateWebAuthnCredential(ctx, arg, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebAuthnCredential", reflect.TypeOf((*MockPasskeyRepository)(nil).CreateWebAuthnCredential), ctx, arg, event)
+}
+
+// DeleteWebAuthnCredential mocks base method.
+func (m *MockPasskeyRepository) DeleteWebAuthnCredential(ctx context.Context, username string, id int64, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebAuthnCredential", ctx, username, id, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebAuthnCredential indicates an expected call of DeleteWebAuthnCredential.
+func (mr *MockPasskeyRepositoryMockRecorder) DeleteWebAuthnCredential(ctx, username, id, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebAuthnCredential", reflect.TypeOf((*MockPasskeyRepository)(nil).DeleteWebAuthnCredential), ctx, username, id, event)
+}
+
+// GetWebAuthnUsername mocks base method.
+func (m *MockPasskeyRepository) GetWebAuthnUsername(ctx context.Context, handle []byte) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebAuthnUsername", ctx, handle)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebAuthnUsername indicates an expected call of GetWebAuthnUsername.
+func (mr *MockPasskeyRepositoryMockRecorder) GetWebAuthnUsername(ctx, handle any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebAuthnUsername", reflect.TypeOf((*MockPasskeyRepository)(nil).GetWebAuthnUsername), ctx, handle)
+}
+
+// ListWebAuthnCredentials mocks base method.
+func (m *MockPasskeyRepository) ListWebAuthnCredentials(ctx context.Context, username string) ([]*entity.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebAuthnCredentials", ctx, username)
+	ret0, _ := ret[0].([]*entity.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebAuthnCredentials indicates an expected call of ListWebAuthnCredentials.
+func (mr *MockPasskeyRepositoryMockRecorder) ListWebAuthnCredentials(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebAuthnCredentials", reflect.TypeOf((*MockPasskeyRepository)(nil).ListWebAuthnCredentials), ctx, username)
+}
+
+// SaveWebAuthnUser mocks base method.
+func (m *MockPasskeyRepository) SaveWebAuthnUser(ctx context.Context, username string, handle []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebAuthnUser", ctx, username, handle)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWebAuthnUser indicates an expected call of SaveWebAuthnUser.
+func (mr *MockPasskeyRepositoryMockRecorder) SaveWebAuthnUser(ctx, username, handle any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebAuthnUser", reflect.TypeOf((*MockPasskeyRepository)(nil).SaveWebAuthnUser), ctx, username, handle)
+}
+
+// TakeWebAuthnCeremony mocks base method.
+func (m *MockPasskeyRepository) TakeWebAuthnCeremony(ctx context.Context, id uuid.UUID, kind string) (*entity.WebAuthnCeremony, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeWebAuthnCeremony", ctx, id, kind)
+	ret0, _ := ret[0].(*entity.WebAuthnCeremony)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeWebAuthnCeremony indicates an expected call of TakeWebAuthnCeremony.
+func (mr *MockPasskeyRepositoryMockRecorder) TakeWebAuthnCeremony(ctx, id, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeWebAuthnCeremony", reflect.TypeOf((*MockPasskeyRepository)(nil).TakeWebAuthnCeremony), ctx, id, kind)
+}
+
+// UseWebAuthnCredential mocks base method.
+func (m *MockPasskeyRepository) UseWebAuthnCredential(ctx context.Context, credentialID []byte, signCount uint32, backupState bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseWebAuthnCredential", ctx, credentialID, signCount, backupState)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseWebAuthnCredential indicates an expected call of UseWebAuthnCredential.
+func (mr *MockPasskeyRepositoryMockRecorder) UseWebAuthnCredential(ctx, credentialID, signCount, backupState any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseWebAuthnCredential", reflect.TypeOf((*MockPasskeyRepository)(nil).UseWebAuthnCredential), ctx, credentialID, signCount, backupState)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: PasswordResetRepository,PasswordResetQueue)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/password_reset.go github.com/0xOnah/bank/internal/service PasswordResetRepository,PasswordResetQueue
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	jobs "github.com/0xOnah/bank/internal/sdk/jobs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPasswordResetRepository is a mock of PasswordResetRepository interface.
+type MockPasswordResetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPasswordResetRepositoryMockRecorder is the mock recorder for MockPasswordResetRepository.
+type MockPasswordResetRepositoryMockRecorder struct {
+	mock *MockPasswordResetRepository
+}
+
+// NewMockPasswordResetRepository creates a new mock instance.
+func NewMockPasswordResetRepository(ctrl *gomock.Controller) *MockPasswordResetRepository {
+	mock := &MockPasswordResetRepository{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetRepository) EXPECT() *MockPasswordResetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetPasswordResetUser mocks base method.
+func (m *MockPasswordResetRepository) GetPasswordResetUser(ctx context.Context, tokenHash string) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetUser", ctx, tokenHash)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetUser indicates an expected call of GetPasswordResetUser.
+func (mr *MockPasswordResetRepositoryMockRecorder) GetPasswordResetUser(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetUser", reflect.TypeOf((*MockPasswordResetRepository)(nil).GetPasswordResetUser), ctx, tokenHash)
+}
+
+// ResetPassword mocks base method.
+func (m *MockPasswordResetRepository) ResetPassword(ctx context.Context, tokenHash, hashedPassword string, event entity.AuditEvent) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, tokenHash, hashedPassword, event)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockPasswordResetRepositoryMockRecorder) ResetPassword(ctx, tokenHash, hashedPassword, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockPasswordResetRepository)(nil).ResetPassword), ctx, tokenHash, hashedPassword, event)
+}
+
+// MockPasswordResetQueue is a mock of PasswordResetQueue interface.
+type MockPasswordResetQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetQueueMockRecorder
+	isgomock struct{}
+}
+
+// MockPasswordResetQueueMockRecorder is the mock recorder for MockPasswordResetQueue.
+type MockPasswordResetQueueMockRecorder struct {
+	mock *MockPasswordResetQueue
+}
+
+// NewMockPasswordResetQueue creates a new mock instance.
+func NewMockPasswordResetQueue(ctrl *gomock.Controller) *MockPasswordResetQueue {
+	mock := &MockPasswordResetQueue{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetQueue) EXPECT() *MockPasswordResetQueueMockRecorder {
+	return m.recorder
+}
+
+// JobPasswordReset mocks base method.
+func (m *MockPasswordResetQueue) JobPasswordReset(ctx context.Context, payload *jobs.PasswordResetPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JobPasswordReset", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// JobPasswordReset indicates an expected call of JobPasswordReset.
+func (mr *MockPasswordResetQueueMockRecorder) JobPasswordReset(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobPasswordReset", reflect.TypeOf((*MockPasswordResetQueue)(nil).JobPasswordReset), ctx, payload)
+}
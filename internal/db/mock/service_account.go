@@ -0,0 +1,146 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: ServiceAccountRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/service_account.go github.com/0xOnah/bank/internal/service ServiceAccountRepository
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockServiceAccountRepository is a mock of ServiceAccountRepository interface.
+type MockServiceAccountRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceAccountRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceAccountRepositoryMockRecorder is the mock recorder for MockServiceAccountRepository.
+type MockServiceAccountRepositoryMockRecorder struct {
+	mock *MockServiceAccountRepository
+}
+
+// NewMockServiceAccountRepository creates a new mock instance.
+func NewMockServiceAccountRepository(ctrl *gomock.Controller) *MockServiceAccountRepository {
+	mock := &MockServiceAccountRepository{ctrl: ctrl}
+	mock.recorder = &MockServiceAccountRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceAccountRepository) EXPECT() *MockServiceAccountRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockServiceAccountRepository) CreateServiceAccount(ctx context.Context, arg entity.ServiceAccount, prefix, keyHash string, event entity.AuditEvent) (*entity.ServiceAccount, *entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", ctx, arg, prefix, keyHash, event)
+	ret0, _ := ret[0].(*entity.ServiceAccount)
+	ret1, _ := ret[1].(*entity.APIKey)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockServiceAccountRepositoryMockRecorder) CreateServiceAccount(ctx, arg, prefix, keyHash, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockServiceAccountRepository)(nil).CreateServiceAccount), ctx, arg, prefix, keyHash, event)
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockServiceAccountRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockServiceAccountRepositoryMockRecorder) GetAPIKeyByPrefix(ctx, prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockServiceAccountRepository)(nil).GetAPIKeyByPrefix), ctx, prefix)
+}
+
+// GetServiceAccount mocks base method.
+func (m *MockServiceAccountRepository) GetServiceAccount(ctx context.Context, name string) (*entity.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceAccount", ctx, name)
+	ret0, _ := ret[0].(*entity.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceAccount indicates an expected call of GetServiceAccount.
+func (mr *MockServiceAccountRepositoryMockRecorder) GetServiceAccount(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceAccount", reflect.TypeOf((*MockServiceAccountRepository)(nil).GetServiceAccount), ctx, name)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockServiceAccountRepository) ListAPIKeys(ctx context.Context, serviceAccount string) ([]*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, serviceAccount)
+	ret0, _ := ret[0].([]*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockServiceAccountRepositoryMockRecorder) ListAPIKeys(ctx, serviceAccount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockServiceAccountRepository)(nil).ListAPIKeys), ctx, serviceAccount)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockServiceAccountRepository) RevokeAPIKey(ctx context.Context, prefix string, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, prefix, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockServiceAccountRepositoryMockRecorder) RevokeAPIKey(ctx, prefix, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockServiceAccountRepository)(nil).RevokeAPIKey), ctx, prefix, event)
+}
+
+// RotateAPIKey mocks base method.
+func (m *MockServiceAccountRepository) RotateAPIKey(ctx context.Context, serviceAccount, prefix, keyHash string, event entity.AuditEvent) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAPIKey", ctx, serviceAccount, prefix, keyHash, event)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateAPIKey indicates an expected call of RotateAPIKey.
+func (mr *MockServiceAccountRepositoryMockRecorder) RotateAPIKey(ctx, serviceAccount, prefix, keyHash, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAPIKey", reflect.TypeOf((*MockServiceAccountRepository)(nil).RotateAPIKey), ctx, serviceAccount, prefix, keyHash, event)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockServiceAccountRepository) TouchAPIKey(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockServiceAccountRepositoryMockRecorder) TouchAPIKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockServiceAccountRepository)(nil).TouchAPIKey), ctx, id)
+}
@@ -12,6 +12,7 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/0xOnah/bank/internal/entity"
 	uuid "github.com/google/uuid"
@@ -42,19 +43,61 @@ func (m *MockSessionRepository) EXPECT() *MockSessionRepositoryMockRecorder {
 	return m.recorder
 }
 
+// BlockOtherSessionFamilies mocks base method.
+func (m *MockSessionRepository) BlockOtherSessionFamilies(ctx context.Context, username string, keepFamilyID uuid.UUID, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockOtherSessionFamilies", ctx, username, keepFamilyID, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockOtherSessionFamilies indicates an expected call of BlockOtherSessionFamilies.
+func (mr *MockSessionRepositoryMockRecorder) BlockOtherSessionFamilies(ctx, username, keepFamilyID, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockOtherSessionFamilies", reflect.TypeOf((*MockSessionRepository)(nil).BlockOtherSessionFamilies), ctx, username, keepFamilyID, event)
+}
+
+// BlockSessionFamily mocks base method.
+func (m *MockSessionRepository) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSessionFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSessionFamily indicates an expected call of BlockSessionFamily.
+func (mr *MockSessionRepositoryMockRecorder) BlockSessionFamily(ctx, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionFamily", reflect.TypeOf((*MockSessionRepository)(nil).BlockSessionFamily), ctx, familyID)
+}
+
+// BlockUserSessionFamily mocks base method.
+func (m *MockSessionRepository) BlockUserSessionFamily(ctx context.Context, username string, familyID uuid.UUID, event entity.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessionFamily", ctx, username, familyID, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUserSessionFamily indicates an expected call of BlockUserSessionFamily.
+func (mr *MockSessionRepositoryMockRecorder) BlockUserSessionFamily(ctx, username, familyID, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessionFamily", reflect.TypeOf((*MockSessionRepository)(nil).BlockUserSessionFamily), ctx, username, familyID, event)
+}
+
 // CreateSession mocks base method.
-func (m *MockSessionRepository) CreateSession(ctx context.Context, arg entity.Session) (*entity.Session, error) {
+func (m *MockSessionRepository) CreateSession(ctx context.Context, arg entity.Session, event entity.AuditEvent) (*entity.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", ctx, arg)
+	ret := m.ctrl.Call(m, "CreateSession", ctx, arg, event)
 	ret0, _ := ret[0].(*entity.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateSession indicates an expected call of CreateSession.
-func (mr *MockSessionRepositoryMockRecorder) CreateSession(ctx, arg any) *gomock.Call {
+func (mr *MockSessionRepositoryMockRecorder) CreateSession(ctx, arg, event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionRepository)(nil).CreateSession), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionRepository)(nil).CreateSession), ctx, arg, event)
 }
 
 // GetSession mocks base method.
@@ -71,3 +114,47 @@ func (mr *MockSessionRepositoryMockRecorder) GetSession(ctx, id any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSessionRepository)(nil).GetSession), ctx, id)
 }
+
+// ListActiveSessions mocks base method.
+func (m *MockSessionRepository) ListActiveSessions(ctx context.Context, username string) ([]*entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveSessions", ctx, username)
+	ret0, _ := ret[0].([]*entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveSessions indicates an expected call of ListActiveSessions.
+func (mr *MockSessionRepositoryMockRecorder) ListActiveSessions(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveSessions", reflect.TypeOf((*MockSessionRepository)(nil).ListActiveSessions), ctx, username)
+}
+
+// RotateSession mocks base method.
+func (m *MockSessionRepository) RotateSession(ctx context.Context, previousID uuid.UUID, next entity.Session) (*entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", ctx, previousID, next)
+	ret0, _ := ret[0].(*entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockSessionRepositoryMockRecorder) RotateSession(ctx, previousID, next any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockSessionRepository)(nil).RotateSession), ctx, previousID, next)
+}
+
+// SpendMFAToken mocks base method.
+func (m *MockSessionRepository) SpendMFAToken(ctx context.Context, id uuid.UUID, username string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpendMFAToken", ctx, id, username, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SpendMFAToken indicates an expected call of SpendMFAToken.
+func (mr *MockSessionRepositoryMockRecorder) SpendMFAToken(ctx, id, username, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpendMFAToken", reflect.TypeOf((*MockSessionRepository)(nil).SpendMFAToken), ctx, id, username, expiresAt)
+}
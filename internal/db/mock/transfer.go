@@ -57,18 +57,18 @@ func (mr *MockTransferRepositoryMockRecorder) CreateTransfer(ctx, arg any) *gomo
 }
 
 // CreateTransferTX mocks base method.
-func (m *MockTransferRepository) CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput) (*entity.TransferTxResult, error) {
+func (m *MockTransferRepository) CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput, event entity.AuditEvent) (*entity.TransferTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransferTX", ctx, arg)
+	ret := m.ctrl.Call(m, "CreateTransferTX", ctx, arg, event)
 	ret0, _ := ret[0].(*entity.TransferTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateTransferTX indicates an expected call of CreateTransferTX.
-func (mr *MockTransferRepositoryMockRecorder) CreateTransferTX(ctx, arg any) *gomock.Call {
+func (mr *MockTransferRepositoryMockRecorder) CreateTransferTX(ctx, arg, event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferTX", reflect.TypeOf((*MockTransferRepository)(nil).CreateTransferTX), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferTX", reflect.TypeOf((*MockTransferRepository)(nil).CreateTransferTX), ctx, arg, event)
 }
 
 // GetTransfer mocks base method.
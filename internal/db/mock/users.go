@@ -42,18 +42,18 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 }
 
 // CreateUser mocks base method.
-func (m *MockUserRepository) CreateUser(ctx context.Context, arg entity.User) (*entity.User, error) {
+func (m *MockUserRepository) CreateUser(ctx context.Context, arg entity.User, event entity.AuditEvent) (*entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, arg)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, arg, event)
 	ret0, _ := ret[0].(*entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockUserRepositoryMockRecorder) CreateUser(ctx, arg any) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) CreateUser(ctx, arg, event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), ctx, arg, event)
 }
 
 // GetUser mocks base method.
@@ -70,3 +70,17 @@ func (mr *MockUserRepositoryMockRecorder) GetUser(ctx, username any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserRepository)(nil).GetUser), ctx, username)
 }
+
+// RehashPassword mocks base method.
+func (m *MockUserRepository) RehashPassword(ctx context.Context, username, oldHash, newHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashPassword", ctx, username, oldHash, newHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RehashPassword indicates an expected call of RehashPassword.
+func (mr *MockUserRepositoryMockRecorder) RehashPassword(ctx, username, oldHash, newHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashPassword", reflect.TypeOf((*MockUserRepository)(nil).RehashPassword), ctx, username, oldHash, newHash)
+}
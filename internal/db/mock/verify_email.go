@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/0xOnah/bank/internal/service (interfaces: VerifyEmailRepository,VerifyEmailQueue)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination internal/db/mock/verify_email.go github.com/0xOnah/bank/internal/service VerifyEmailRepository,VerifyEmailQueue
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/0xOnah/bank/internal/entity"
+	jobs "github.com/0xOnah/bank/internal/sdk/jobs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockVerifyEmailRepository is a mock of VerifyEmailRepository interface.
+type MockVerifyEmailRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVerifyEmailRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockVerifyEmailRepositoryMockRecorder is the mock recorder for MockVerifyEmailRepository.
+type MockVerifyEmailRepositoryMockRecorder struct {
+	mock *MockVerifyEmailRepository
+}
+
+// NewMockVerifyEmailRepository creates a new mock instance.
+func NewMockVerifyEmailRepository(ctrl *gomock.Controller) *MockVerifyEmailRepository {
+	mock := &MockVerifyEmailRepository{ctrl: ctrl}
+	mock.recorder = &MockVerifyEmailRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVerifyEmailRepository) EXPECT() *MockVerifyEmailRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetLastVerifyEmail mocks base method.
+func (m *MockVerifyEmailRepository) GetLastVerifyEmail(ctx context.Context, username string) (*entity.VerifyEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastVerifyEmail", ctx, username)
+	ret0, _ := ret[0].(*entity.VerifyEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastVerifyEmail indicates an expected call of GetLastVerifyEmail.
+func (mr *MockVerifyEmailRepositoryMockRecorder) GetLastVerifyEmail(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastVerifyEmail", reflect.TypeOf((*MockVerifyEmailRepository)(nil).GetLastVerifyEmail), ctx, username)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockVerifyEmailRepository) VerifyEmail(ctx context.Context, emailID int64, secretCodeHash string) (*entity.VerifyEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, emailID, secretCodeHash)
+	ret0, _ := ret[0].(*entity.VerifyEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockVerifyEmailRepositoryMockRecorder) VerifyEmail(ctx, emailID, secretCodeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockVerifyEmailRepository)(nil).VerifyEmail), ctx, emailID, secretCodeHash)
+}
+
+// MockVerifyEmailQueue is a mock of VerifyEmailQueue interface.
+type MockVerifyEmailQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockVerifyEmailQueueMockRecorder
+	isgomock struct{}
+}
+
+// MockVerifyEmailQueueMockRecorder is the mock recorder for MockVerifyEmailQueue.
+type MockVerifyEmailQueueMockRecorder struct {
+	mock *MockVerifyEmailQueue
+}
+
+// NewMockVerifyEmailQueue creates a new mock instance.
+func NewMockVerifyEmailQueue(ctrl *gomock.Controller) *MockVerifyEmailQueue {
+	mock := &MockVerifyEmailQueue{ctrl: ctrl}
+	mock.recorder = &MockVerifyEmailQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVerifyEmailQueue) EXPECT() *MockVerifyEmailQueueMockRecorder {
+	return m.recorder
+}
+
+// JobVerifyEmail mocks base method.
+func (m *MockVerifyEmailQueue) JobVerifyEmail(ctx context.Context, payload *jobs.VerifyEmailPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JobVerifyEmail", ctx, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// JobVerifyEmail indicates an expected call of JobVerifyEmail.
+func (mr *MockVerifyEmailQueueMockRecorder) JobVerifyEmail(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobVerifyEmail", reflect.TypeOf((*MockVerifyEmailQueue)(nil).JobVerifyEmail), ctx, payload)
+}
@@ -0,0 +1,30 @@
+-- name: CreateAccountConsent :one
+INSERT INTO account_consents (
+    id,
+    username,
+    client_id,
+    account_ids,
+    permissions,
+    expires_at
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetAccountConsent :one
+SELECT * FROM account_consents
+WHERE id = $1 LIMIT 1;
+
+-- name: ListAccountConsents :many
+SELECT * FROM account_consents
+WHERE username = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeAccountConsent :execrows
+UPDATE account_consents
+SET revoked_at = now()
+WHERE id = $1 AND username = $2 AND revoked_at IS NULL;
+
+-- name: RevokeClientAccountConsents :exec
+UPDATE account_consents
+SET revoked_at = now()
+WHERE username = $1 AND client_id = $2 AND revoked_at IS NULL;
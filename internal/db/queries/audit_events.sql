@@ -0,0 +1,24 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+    action,
+    actor,
+    subject,
+    client_ip,
+    user_agent,
+    request_id,
+    details
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListAuditEvents :many
+-- newest first; each filter applies only when set. Page with before_id, the last id seen.
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(subject)::varchar IS NULL OR subject = sqlc.narg(subject))
+  AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR created_at >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR created_at < sqlc.narg(until))
+  AND (sqlc.narg(before_id)::bigint IS NULL OR id < sqlc.narg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_size);
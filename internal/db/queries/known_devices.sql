@@ -0,0 +1,37 @@
+-- name: ListKnownDevices :many
+SELECT * FROM known_devices
+WHERE username = $1
+ORDER BY last_seen_at DESC;
+
+-- name: SaveKnownDevice :one
+-- adds the device, or marks an existing one as just seen. A confirmed_at confirms it if it isn't yet.
+INSERT INTO known_devices (
+    username,
+    user_agent_hash,
+    ip_range,
+    user_agent,
+    last_ip,
+    confirmed_at
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (username, user_agent_hash, ip_range) DO UPDATE
+SET last_ip = excluded.last_ip,
+    confirmed_at = COALESCE(known_devices.confirmed_at, excluded.confirmed_at),
+    last_seen_at = now()
+RETURNING *;
+
+-- name: SetDeviceConfirmation :exec
+UPDATE known_devices
+SET confirm_token_hash = $2,
+    confirm_expires_at = $3
+WHERE id = $1 AND confirmed_at IS NULL;
+
+-- name: ConfirmKnownDevice :one
+UPDATE known_devices
+SET confirmed_at = now(),
+    confirm_token_hash = NULL,
+    confirm_expires_at = NULL
+WHERE confirm_token_hash = $1
+  AND confirm_expires_at > now()
+  AND confirmed_at IS NULL
+RETURNING *;
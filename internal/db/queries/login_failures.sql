@@ -0,0 +1,28 @@
+-- name: RecordLoginFailure :one
+-- attempts older than window_start no longer count towards a lock
+INSERT INTO login_failures (subject, failed_attempts, last_failed_at)
+VALUES (sqlc.arg(subject), 1, now())
+ON CONFLICT (subject) DO UPDATE
+SET failed_attempts = CASE
+        WHEN login_failures.last_failed_at < sqlc.arg(window_start) THEN 1
+        ELSE login_failures.failed_attempts + 1
+    END,
+    last_failed_at = now()
+RETURNING *;
+
+-- name: LockLoginSubject :one
+UPDATE login_failures
+SET failed_attempts = 0,
+    lockouts = lockouts + 1,
+    locked_until = $2
+WHERE subject = $1
+RETURNING *;
+
+-- name: ListLoginLocks :many
+SELECT * FROM login_failures
+WHERE subject = ANY(sqlc.arg(subjects)::varchar[])
+  AND locked_until > now();
+
+-- name: DeleteLoginFailures :execrows
+DELETE FROM login_failures
+WHERE subject = $1;
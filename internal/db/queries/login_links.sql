@@ -0,0 +1,21 @@
+-- name: CountLoginLinksSince :one
+SELECT count(*) FROM login_links
+WHERE username = $1
+  AND created_at > $2;
+
+-- name: CreateLoginLink :one
+INSERT INTO login_links (
+    id,
+    username,
+    expires_at
+)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UseLoginLink :one
+UPDATE login_links
+SET used_at = now()
+WHERE id = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
@@ -0,0 +1,88 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+    id,
+    name,
+    secret_hash,
+    redirect_uris,
+    scopes,
+    grant_types,
+    created_by
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients
+WHERE id = $1 LIMIT 1;
+
+-- name: RevokeOAuthClient :execrows
+UPDATE oauth_clients
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: GrantOAuthConsent :one
+-- granting more scopes to a client the user already consented to widens the same consent
+INSERT INTO oauth_consents (
+    username,
+    client_id,
+    scopes
+)
+VALUES ($1, $2, $3)
+ON CONFLICT (username, client_id) WHERE revoked_at IS NULL DO UPDATE
+SET scopes = ARRAY(SELECT DISTINCT s FROM unnest(oauth_consents.scopes || excluded.scopes) AS s ORDER BY s),
+    updated_at = now()
+RETURNING *;
+
+-- name: GetOAuthConsent :one
+SELECT * FROM oauth_consents
+WHERE username = $1 AND client_id = $2 AND revoked_at IS NULL
+LIMIT 1;
+
+-- name: ListOAuthConsents :many
+SELECT * FROM oauth_consents
+WHERE username = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: RevokeOAuthConsent :execrows
+UPDATE oauth_consents
+SET revoked_at = now()
+WHERE username = $1 AND client_id = $2 AND revoked_at IS NULL;
+
+-- name: CreateOAuthAuthorizationCode :exec
+INSERT INTO oauth_authorization_codes (
+    code_hash,
+    client_id,
+    username,
+    redirect_uri,
+    scopes,
+    redirect_uri_supplied,
+    code_challenge,
+    expires_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: UseOAuthAuthorizationCode :one
+UPDATE oauth_authorization_codes
+SET used_at = now()
+WHERE code_hash = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
+
+-- name: CreateOAuthClientToken :exec
+INSERT INTO oauth_client_tokens (
+    id,
+    client_id,
+    scopes,
+    expires_at
+)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetOAuthClientToken :one
+SELECT * FROM oauth_client_tokens
+WHERE id = $1 LIMIT 1;
+
+-- name: RevokeOAuthClientToken :exec
+UPDATE oauth_client_tokens
+SET revoked_at = now()
+WHERE id = $1 AND client_id = $2 AND revoked_at IS NULL;
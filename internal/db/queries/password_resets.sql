@@ -0,0 +1,30 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+    username,
+    token_hash,
+    expires_at
+)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET used_at = now()
+WHERE token_hash = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
+
+-- name: ExpireUserPasswordResets :exec
+UPDATE password_resets
+SET used_at = now()
+WHERE username = $1 AND used_at IS NULL;
+
+-- name: GetPasswordResetUser :one
+-- the user a usable reset token belongs to, without spending it
+SELECT users.* FROM password_resets
+JOIN users ON users.username = password_resets.username
+WHERE password_resets.token_hash = $1
+  AND password_resets.used_at IS NULL
+  AND password_resets.expires_at > now()
+LIMIT 1;
@@ -0,0 +1,49 @@
+-- name: CreateServiceAccount :one
+INSERT INTO service_accounts (
+    name,
+    description,
+    scopes,
+    account_ids,
+    created_by
+)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetServiceAccount :one
+SELECT * FROM service_accounts
+WHERE name = $1 LIMIT 1;
+
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    service_account,
+    prefix,
+    key_hash
+)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT * FROM api_keys
+WHERE prefix = $1 LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE service_account = $1
+ORDER BY created_at DESC;
+
+-- name: TouchAPIKey :exec
+-- last use is only recorded once a minute to keep busy keys from writing on every request
+UPDATE api_keys
+SET last_used_at = now()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = now()
+WHERE prefix = $1 AND revoked_at IS NULL;
+
+-- name: RevokeOtherAPIKeys :exec
+UPDATE api_keys
+SET revoked_at = now()
+WHERE service_account = $1 AND id <> $2 AND revoked_at IS NULL;
@@ -6,11 +6,57 @@ INSERT INTO sessions (
     user_agent,
     client_ip,
     is_blocked,
-    expires_at
+    expires_at,
+    family_id,
+    parent_id
 )
-VALUES ($1,$2,$3,$4,$5,$6,$7)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
 RETURNING * ;
 
 -- name: GetSession :one
 SELECT * FROM sessions
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: MarkSessionRotated :one
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1 AND rotated_at IS NULL AND is_blocked = false
+RETURNING *;
+
+-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1;
+
+-- name: ListActiveSessions :many
+SELECT * FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: BlockUserSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1 AND username = $2;
+
+-- name: BlockOtherSessionFamilies :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND family_id <> $2;
+
+-- name: BlockUserSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1;
+
+-- name: BlockClientSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE client_id = $1;
+
+-- name: BlockUserClientSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND client_id = $2;
@@ -0,0 +1,12 @@
+-- name: SpendMFAToken :execrows
+INSERT INTO spent_mfa_tokens (
+    id,
+    username,
+    expires_at
+)
+VALUES ($1, $2, $3)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: DeleteExpiredMFATokens :exec
+DELETE FROM spent_mfa_tokens
+WHERE expires_at <= now();
@@ -0,0 +1,51 @@
+-- name: CreatePendingTOTP :one
+INSERT INTO user_totp (
+    username,
+    secret
+)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+SET secret = EXCLUDED.secret,
+    last_used_step = 0,
+    created_at = now()
+WHERE user_totp.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT * FROM user_totp
+WHERE username = $1 LIMIT 1;
+
+-- name: ConfirmUserTOTP :execrows
+UPDATE user_totp
+SET confirmed_at = now(),
+    last_used_step = $2
+WHERE username = $1 AND confirmed_at IS NULL;
+
+-- name: UseTOTPStep :execrows
+UPDATE user_totp
+SET last_used_step = $2
+WHERE username = $1
+  AND confirmed_at IS NOT NULL
+  AND last_used_step < $2;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE username = $1;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+    username,
+    code_hash
+)
+VALUES ($1, $2);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = now()
+WHERE username = $1
+  AND code_hash = $2
+  AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1;
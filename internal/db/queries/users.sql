@@ -19,6 +19,23 @@ SET
     full_name = coalesce(sqlc.narg('full_name'), full_name),
     hashed_password = coalesce(sqlc.narg('hashed_password'), hashed_password),
     email = coalesce(sqlc.narg('email'), email),
-    password_changed_at = coalesce(sqlc.narg('password_changed_at'), password_changed_at)
+    password_changed_at = coalesce(sqlc.narg('password_changed_at'), password_changed_at),
+    role = coalesce(sqlc.narg('role'), role),
+    -- a new address has to be verified again
+    is_email_verified = is_email_verified AND coalesce(sqlc.narg('email') = email, true)
 WHERE username = sqlc.arg('username')
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+-- name: MarkEmailVerified :execrows
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1 AND email = $2;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
+-- name: RehashPassword :execrows
+-- only replaces the hash it was computed from, so a password changed meanwhile is kept
+UPDATE users
+SET hashed_password = sqlc.arg('new_hash')
+WHERE username = sqlc.arg('username') AND hashed_password = sqlc.arg('old_hash');
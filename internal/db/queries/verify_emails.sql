@@ -0,0 +1,23 @@
+-- name: CreateVerifyEmail :one
+INSERT INTO verify_emails (
+    username,
+    email,
+    secret_code_hash
+)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetLastVerifyEmail :one
+SELECT * FROM verify_emails
+WHERE username = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: UseVerifyEmail :one
+UPDATE verify_emails
+SET is_used = true
+WHERE id = $1
+  AND secret_code_hash = $2
+  AND is_used = false
+  AND expired_at > now()
+RETURNING *;
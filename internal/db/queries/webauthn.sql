@@ -0,0 +1,67 @@
+-- name: SaveWebAuthnUser :one
+-- stores the user handle, or returns the one the user already has.
+INSERT INTO webauthn_users (
+    username,
+    user_handle
+)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+SET username = excluded.username
+RETURNING *;
+
+-- name: GetWebAuthnUserByHandle :one
+SELECT * FROM webauthn_users
+WHERE user_handle = $1;
+
+-- name: CreateWebAuthnCredential :one
+INSERT INTO webauthn_credentials (
+    username,
+    name,
+    credential_id,
+    public_key,
+    attestation_type,
+    transports,
+    aaguid,
+    backup_eligible,
+    backup_state,
+    sign_count
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: ListWebAuthnCredentials :many
+SELECT * FROM webauthn_credentials
+WHERE username = $1
+ORDER BY created_at;
+
+-- name: UseWebAuthnCredential :exec
+UPDATE webauthn_credentials
+SET sign_count = $2,
+    backup_state = $3,
+    last_used_at = now()
+WHERE credential_id = $1;
+
+-- name: DeleteWebAuthnCredential :execrows
+DELETE FROM webauthn_credentials
+WHERE id = $1 AND username = $2;
+
+-- name: CreateWebAuthnCeremony :exec
+INSERT INTO webauthn_ceremonies (
+    id,
+    kind,
+    username,
+    session_data,
+    expires_at
+)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: TakeWebAuthnCeremony :one
+DELETE FROM webauthn_ceremonies
+WHERE id = $1
+  AND kind = $2
+  AND expires_at > now()
+RETURNING *;
+
+-- name: DeleteExpiredWebAuthnCeremonies :exec
+DELETE FROM webauthn_ceremonies
+WHERE expires_at <= now();
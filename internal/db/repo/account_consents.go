@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/google/uuid"
+)
+
+var ErrAccountConsentNotFound = errors.New("account consent not found")
+
+type AccountConsentRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewAccountConsentRepo(db *sqlc.SQLStore) *AccountConsentRepo {
+	return &AccountConsentRepo{db: db}
+}
+
+func toEntityAccountConsent(c *sqlc.AccountConsent) *entity.AccountConsent {
+	return &entity.AccountConsent{
+		ID:          c.ID,
+		Username:    c.Username,
+		ClientID:    c.ClientID,
+		AccountIDs:  c.AccountIds,
+		Permissions: c.Permissions,
+		CreatedAt:   c.CreatedAt,
+		ExpiresAt:   c.ExpiresAt,
+		RevokedAt:   c.RevokedAt.Time,
+	}
+}
+
+// CreateAccountConsent stores a consent and records event with it.
+func (ac *AccountConsentRepo) CreateAccountConsent(ctx context.Context, arg entity.AccountConsent, event entity.AuditEvent) (*entity.AccountConsent, error) {
+	var consent *sqlc.AccountConsent
+	err := ac.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		var err error
+		consent, err = q.CreateAccountConsent(ctx, sqlc.CreateAccountConsentParams{
+			ID:          arg.ID,
+			Username:    arg.Username,
+			ClientID:    arg.ClientID,
+			AccountIds:  arg.AccountIDs,
+			Permissions: arg.Permissions,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toEntityAccountConsent(consent), nil
+}
+
+func (ac *AccountConsentRepo) GetAccountConsent(ctx context.Context, id uuid.UUID) (*entity.AccountConsent, error) {
+	consent, err := ac.db.GetAccountConsent(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAccountConsentNotFound
+		}
+		return nil, err
+	}
+	return toEntityAccountConsent(consent), nil
+}
+
+func (ac *AccountConsentRepo) ListAccountConsents(ctx context.Context, username string) ([]*entity.AccountConsent, error) {
+	consents, err := ac.db.ListAccountConsents(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*entity.AccountConsent, 0, len(consents))
+	for _, c := range consents {
+		result = append(result, toEntityAccountConsent(c))
+	}
+	return result, nil
+}
+
+// RevokeAccountConsent revokes one of username's consents. It returns ErrAccountConsentNotFound
+// when they have no such consent, or it was already revoked.
+func (ac *AccountConsentRepo) RevokeAccountConsent(ctx context.Context, id uuid.UUID, username string, event entity.AuditEvent) error {
+	return ac.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		n, err := q.RevokeAccountConsent(ctx, sqlc.RevokeAccountConsentParams{
+			ID:       id,
+			Username: username,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrAccountConsentNotFound
+		}
+		return nil
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/0xOnah/bank/internal/db/sqlc"
 	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
 	"github.com/lib/pq"
 )
 
@@ -36,11 +37,21 @@ func (r *accountRepo) AddAccountBalance(ctx context.Context, arg entity.AddAccou
 	return toEntityAccount(result), err
 }
 
-func (r *accountRepo) CreateAccount(ctx context.Context, arg entity.CreateAccountInput) (*entity.Account, error) {
-	result, err := r.db.CreateAccount(ctx, sqlc.CreateAccountParams{
-		Owner:    arg.Owner,
-		Balance:  arg.Balance,
-		Currency: arg.Currency,
+// CreateAccount opens an account and records event with the new account as its subject.
+func (r *accountRepo) CreateAccount(ctx context.Context, arg entity.CreateAccountInput, event entity.AuditEvent) (*entity.Account, error) {
+	var result *sqlc.Account
+	err := r.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, event *sqlc.CreateAuditEventParams) error {
+		var err error
+		result, err = q.CreateAccount(ctx, sqlc.CreateAccountParams{
+			Owner:    arg.Owner,
+			Balance:  arg.Balance,
+			Currency: arg.Currency,
+		})
+		if err != nil {
+			return err
+		}
+		event.Subject = audit.AccountSubject(result.ID)
+		return nil
 	})
 
 	if err != nil {
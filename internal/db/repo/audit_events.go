@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+)
+
+type AuditRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewAuditRepo(db *sqlc.SQLStore) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+func toAuditParams(e entity.AuditEvent) sqlc.CreateAuditEventParams {
+	details := json.RawMessage(`{}`)
+	if len(e.Details) > 0 {
+		//a map of strings always marshals
+		details, _ = json.Marshal(e.Details)
+	}
+	return sqlc.CreateAuditEventParams{
+		Action:    e.Action,
+		Actor:     e.Actor,
+		Subject:   e.Subject,
+		ClientIp:  e.ClientIP,
+		UserAgent: e.UserAgent,
+		RequestID: e.RequestID,
+		Details:   details,
+	}
+}
+
+func toEntityAuditEvent(e *sqlc.AuditEvent) (*entity.AuditEvent, error) {
+	var details map[string]string
+	if err := json.Unmarshal(e.Details, &details); err != nil {
+		return nil, err
+	}
+	return &entity.AuditEvent{
+		ID:        e.ID,
+		Action:    e.Action,
+		Actor:     e.Actor,
+		Subject:   e.Subject,
+		ClientIP:  e.ClientIp,
+		UserAgent: e.UserAgent,
+		RequestID: e.RequestID,
+		Details:   details,
+		CreatedAt: e.CreatedAt,
+	}, nil
+}
+
+// RecordAuditEvent stores an event that has no write of its own to share a transaction with,
+// like a failed login.
+func (ar *AuditRepo) RecordAuditEvent(ctx context.Context, event entity.AuditEvent) error {
+	_, err := ar.db.CreateAuditEvent(ctx, toAuditParams(event))
+	return err
+}
+
+func (ar *AuditRepo) ListAuditEvents(ctx context.Context, filter entity.AuditEventFilter) ([]*entity.AuditEvent, error) {
+	events, err := ar.db.ListAuditEvents(ctx, sqlc.ListAuditEventsParams{
+		Actor:    sql.NullString{String: filter.Actor, Valid: filter.Actor != ""},
+		Subject:  sql.NullString{String: filter.Subject, Valid: filter.Subject != ""},
+		Action:   sql.NullString{String: filter.Action, Valid: filter.Action != ""},
+		Since:    sql.NullTime{Time: filter.Since, Valid: !filter.Since.IsZero()},
+		Until:    sql.NullTime{Time: filter.Until, Valid: !filter.Until.IsZero()},
+		BeforeID: sql.NullInt64{Int64: filter.BeforeID, Valid: filter.BeforeID > 0},
+		PageSize: int32(filter.PageSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*entity.AuditEvent, 0, len(events))
+	for _, e := range events {
+		event, err := toEntityAuditEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
@@ -8,7 +8,7 @@ import (
 )
 
 type entryRepo struct {
-	db sqlc.SQLStore
+	db *sqlc.SQLStore
 }
 
 func toEntityEntry(e *sqlc.Entry) entity.Entry {
@@ -20,7 +20,7 @@ func toEntityEntry(e *sqlc.Entry) entity.Entry {
 	}
 }
 
-func NewEntryRepo(db sqlc.SQLStore) *entryRepo {
+func NewEntryRepo(db *sqlc.SQLStore) *entryRepo {
 	return &entryRepo{db: db}
 }
 
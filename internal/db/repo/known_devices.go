@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+)
+
+var ErrInvalidDeviceToken = errors.New("device confirmation token is invalid, used or expired")
+
+type KnownDeviceRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewKnownDeviceRepo(db *sqlc.SQLStore) *KnownDeviceRepo {
+	return &KnownDeviceRepo{db: db}
+}
+
+func toEntityKnownDevice(d *sqlc.KnownDevice) *entity.KnownDevice {
+	return &entity.KnownDevice{
+		ID:            d.ID,
+		Username:      d.Username,
+		UserAgentHash: d.UserAgentHash,
+		IPRange:       d.IpRange,
+		UserAgent:     d.UserAgent,
+		LastIP:        d.LastIp,
+		ConfirmedAt:   d.ConfirmedAt.Time,
+		CreatedAt:     d.CreatedAt,
+		LastSeenAt:    d.LastSeenAt,
+	}
+}
+
+func (kr *KnownDeviceRepo) ListKnownDevices(ctx context.Context, username string) ([]*entity.KnownDevice, error) {
+	devices, err := kr.db.ListKnownDevices(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*entity.KnownDevice, 0, len(devices))
+	for _, d := range devices {
+		result = append(result, toEntityKnownDevice(d))
+	}
+	return result, nil
+}
+
+// SaveKnownDevice adds the device or marks an existing one as just seen from its LastIP. With
+// confirm set the device ends up confirmed; without it an existing confirmation is kept.
+func (kr *KnownDeviceRepo) SaveKnownDevice(ctx context.Context, arg entity.KnownDevice, confirm bool) (*entity.KnownDevice, error) {
+	d, err := kr.db.SaveKnownDevice(ctx, sqlc.SaveKnownDeviceParams{
+		Username:      arg.Username,
+		UserAgentHash: arg.UserAgentHash,
+		IpRange:       arg.IPRange,
+		UserAgent:     arg.UserAgent,
+		LastIp:        arg.LastIP,
+		ConfirmedAt:   sql.NullTime{Time: time.Now(), Valid: confirm},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toEntityKnownDevice(d), nil
+}
+
+// SetDeviceConfirmation stores the hash of the token emailed to confirm an unconfirmed device,
+// replacing any earlier one.
+func (kr *KnownDeviceRepo) SetDeviceConfirmation(ctx context.Context, id int64, tokenHash string, expiresAt time.Time) error {
+	return kr.db.SetDeviceConfirmation(ctx, sqlc.SetDeviceConfirmationParams{
+		ID:               id,
+		ConfirmTokenHash: sql.NullString{String: tokenHash, Valid: true},
+		ConfirmExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+	})
+}
+
+// ConfirmKnownDevice confirms the device the token was emailed for and records event. The token
+// is all the request carries, so the event's actor and subject are filled in from the device.
+func (kr *KnownDeviceRepo) ConfirmKnownDevice(ctx context.Context, tokenHash string, event entity.AuditEvent) (*entity.KnownDevice, error) {
+	var d *sqlc.KnownDevice
+	err := kr.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, event *sqlc.CreateAuditEventParams) error {
+		var err error
+		d, err = q.ConfirmKnownDevice(ctx, sql.NullString{String: tokenHash, Valid: true})
+		if err != nil {
+			return err
+		}
+		event.Actor, event.Subject = d.Username, audit.DeviceSubject(d.ID)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidDeviceToken
+		}
+		return nil, err
+	}
+	return toEntityKnownDevice(d), nil
+}
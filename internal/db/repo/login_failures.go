@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+)
+
+type LoginFailureRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewLoginFailureRepo(db *sqlc.SQLStore) *LoginFailureRepo {
+	return &LoginFailureRepo{db: db}
+}
+
+func toEntityLoginFailure(f *sqlc.LoginFailure) *entity.LoginFailure {
+	return &entity.LoginFailure{
+		Subject:        f.Subject,
+		FailedAttempts: int(f.FailedAttempts),
+		Lockouts:       int(f.Lockouts),
+		LockedUntil:    f.LockedUntil.Time,
+		LastFailedAt:   f.LastFailedAt,
+	}
+}
+
+// ListLoginLocks returns the subjects that are locked out right now.
+func (lr *LoginFailureRepo) ListLoginLocks(ctx context.Context, subjects []string) ([]*entity.LoginFailure, error) {
+	locks, err := lr.db.ListLoginLocks(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*entity.LoginFailure, 0, len(locks))
+	for _, f := range locks {
+		result = append(result, toEntityLoginFailure(f))
+	}
+	return result, nil
+}
+
+// RecordLoginFailure counts a failed login for subject, forgetting failures from before windowStart.
+func (lr *LoginFailureRepo) RecordLoginFailure(ctx context.Context, subject string, windowStart time.Time) (*entity.LoginFailure, error) {
+	f, err := lr.db.RecordLoginFailure(ctx, sqlc.RecordLoginFailureParams{Subject: subject, WindowStart: windowStart})
+	if err != nil {
+		return nil, err
+	}
+	return toEntityLoginFailure(f), nil
+}
+
+func (lr *LoginFailureRepo) LockLoginSubject(ctx context.Context, subject string, until time.Time) (*entity.LoginFailure, error) {
+	f, err := lr.db.LockLoginSubject(ctx, sqlc.LockLoginSubjectParams{
+		Subject:     subject,
+		LockedUntil: sql.NullTime{Time: until, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toEntityLoginFailure(f), nil
+}
+
+// ClearLoginFailures forgets subject's failures and lockouts, including any lock in force.
+func (lr *LoginFailureRepo) ClearLoginFailures(ctx context.Context, subject string) error {
+	_, err := lr.db.DeleteLoginFailures(ctx, subject)
+	return err
+}
+
+// UnlockLoginSubject clears subject like ClearLoginFailures and records event in the same
+// transaction, for unlocks an admin asked for.
+func (lr *LoginFailureRepo) UnlockLoginSubject(ctx context.Context, subject string, event entity.AuditEvent) error {
+	return lr.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		_, err := q.DeleteLoginFailures(ctx, subject)
+		return err
+	})
+}
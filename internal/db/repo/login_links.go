@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidLoginLink = errors.New("login link is invalid, used or expired")
+
+type LoginLinkRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewLoginLinkRepo(db *sqlc.SQLStore) *LoginLinkRepo {
+	return &LoginLinkRepo{db: db}
+}
+
+func (lr *LoginLinkRepo) CreateLoginLink(ctx context.Context, id uuid.UUID, username string, expiresAt time.Time) error {
+	_, err := lr.db.CreateLoginLink(ctx, sqlc.CreateLoginLinkParams{
+		ID:        id,
+		Username:  username,
+		ExpiresAt: expiresAt,
+	})
+	return err
+}
+
+// CountLoginLinksSince returns how many login links were created for username after since.
+func (lr *LoginLinkRepo) CountLoginLinksSince(ctx context.Context, username string, since time.Time) (int64, error) {
+	return lr.db.CountLoginLinksSince(ctx, sqlc.CountLoginLinksSinceParams{
+		Username:  username,
+		CreatedAt: since,
+	})
+}
+
+// UseLoginLink spends the link with id and returns the user it signs in.
+func (lr *LoginLinkRepo) UseLoginLink(ctx context.Context, id uuid.UUID) (string, error) {
+	link, err := lr.db.UseLoginLink(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrInvalidLoginLink
+		}
+		return "", err
+	}
+	return link.Username, nil
+}
@@ -0,0 +1,246 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrOAuthClientNotFound      = errors.New("oauth client not found")
+	ErrDuplicateOAuthClient     = errors.New("oauth client already exists")
+	ErrOAuthConsentNotFound     = errors.New("oauth consent not found")
+	ErrInvalidAuthorizationCode = errors.New("authorization code is unknown, used or expired")
+	ErrOAuthClientTokenNotFound = errors.New("oauth client token not found")
+)
+
+type OAuthRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewOAuthRepo(db *sqlc.SQLStore) *OAuthRepo {
+	return &OAuthRepo{db: db}
+}
+
+func toEntityOAuthClient(c *sqlc.OauthClient) *entity.OAuthClient {
+	return &entity.OAuthClient{
+		ID:           c.ID,
+		Name:         c.Name,
+		SecretHash:   c.SecretHash.String,
+		RedirectURIs: c.RedirectUris,
+		Scopes:       c.Scopes,
+		GrantTypes:   c.GrantTypes,
+		CreatedBy:    c.CreatedBy,
+		CreatedAt:    c.CreatedAt,
+		RevokedAt:    c.RevokedAt.Time,
+	}
+}
+
+func toEntityOAuthConsent(c *sqlc.OauthConsent) *entity.OAuthConsent {
+	return &entity.OAuthConsent{
+		ID:        c.ID,
+		Username:  c.Username,
+		ClientID:  c.ClientID,
+		Scopes:    c.Scopes,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// CreateOAuthClient registers a client and records event with it.
+func (oa *OAuthRepo) CreateOAuthClient(ctx context.Context, arg entity.OAuthClient, event entity.AuditEvent) (*entity.OAuthClient, error) {
+	var client *sqlc.OauthClient
+	err := oa.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		var err error
+		client, err = q.CreateOAuthClient(ctx, sqlc.CreateOAuthClientParams{
+			ID:           arg.ID,
+			Name:         arg.Name,
+			SecretHash:   sql.NullString{String: arg.SecretHash, Valid: arg.SecretHash != ""},
+			RedirectUris: arg.RedirectURIs,
+			Scopes:       arg.Scopes,
+			GrantTypes:   arg.GrantTypes,
+			CreatedBy:    arg.CreatedBy,
+		})
+		return err
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return nil, ErrDuplicateOAuthClient
+		}
+		return nil, err
+	}
+	return toEntityOAuthClient(client), nil
+}
+
+func (oa *OAuthRepo) GetOAuthClient(ctx context.Context, id string) (*entity.OAuthClient, error) {
+	client, err := oa.db.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+	return toEntityOAuthClient(client), nil
+}
+
+// RevokeOAuthClient stops a client from getting tokens and blocks every session users granted it.
+func (oa *OAuthRepo) RevokeOAuthClient(ctx context.Context, id string, event entity.AuditEvent) error {
+	return oa.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		n, err := q.RevokeOAuthClient(ctx, id)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrOAuthClientNotFound
+		}
+		return q.BlockClientSessions(ctx, sql.NullString{String: id, Valid: true})
+	})
+}
+
+// GrantOAuthConsent records that the user let the client use code's scopes, adding to any
+// consent they gave it before, and stores code for the client to exchange.
+func (oa *OAuthRepo) GrantOAuthConsent(ctx context.Context, code entity.OAuthAuthorizationCode, event entity.AuditEvent) (*entity.OAuthConsent, error) {
+	var consent *sqlc.OauthConsent
+	err := oa.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		var err error
+		consent, err = q.GrantOAuthConsent(ctx, sqlc.GrantOAuthConsentParams{
+			Username: code.Username,
+			ClientID: code.ClientID,
+			Scopes:   code.Scopes,
+		})
+		if err != nil {
+			return err
+		}
+		return q.CreateOAuthAuthorizationCode(ctx, sqlc.CreateOAuthAuthorizationCodeParams{
+			CodeHash:            code.CodeHash,
+			ClientID:            code.ClientID,
+			Username:            code.Username,
+			RedirectUri:         code.RedirectURI,
+			Scopes:              code.Scopes,
+			RedirectUriSupplied: code.RedirectURISupplied,
+			CodeChallenge:       code.CodeChallenge,
+			ExpiresAt:           code.ExpiresAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toEntityOAuthConsent(consent), nil
+}
+
+func (oa *OAuthRepo) GetOAuthConsent(ctx context.Context, username, clientID string) (*entity.OAuthConsent, error) {
+	consent, err := oa.db.GetOAuthConsent(ctx, sqlc.GetOAuthConsentParams{
+		Username: username,
+		ClientID: clientID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOAuthConsentNotFound
+		}
+		return nil, err
+	}
+	return toEntityOAuthConsent(consent), nil
+}
+
+func (oa *OAuthRepo) ListOAuthConsents(ctx context.Context, username string) ([]*entity.OAuthConsent, error) {
+	consents, err := oa.db.ListOAuthConsents(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*entity.OAuthConsent, 0, len(consents))
+	for _, c := range consents {
+		result = append(result, toEntityOAuthConsent(c))
+	}
+	return result, nil
+}
+
+// RevokeOAuthConsent withdraws the user's consent to the client, along with the account consents
+// given to it, and blocks the sessions it started with it. It returns ErrOAuthConsentNotFound when there is no active consent.
+func (oa *OAuthRepo) RevokeOAuthConsent(ctx context.Context, username, clientID string, event entity.AuditEvent) error {
+	return oa.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		n, err := q.RevokeOAuthConsent(ctx, sqlc.RevokeOAuthConsentParams{
+			Username: username,
+			ClientID: clientID,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrOAuthConsentNotFound
+		}
+		//the account consents were given to the client on the strength of this one
+		err = q.RevokeClientAccountConsents(ctx, sqlc.RevokeClientAccountConsentsParams{
+			Username: username,
+			ClientID: clientID,
+		})
+		if err != nil {
+			return err
+		}
+		return q.BlockUserClientSessions(ctx, sqlc.BlockUserClientSessionsParams{
+			Username: username,
+			ClientID: sql.NullString{String: clientID, Valid: true},
+		})
+	})
+}
+
+// UseOAuthAuthorizationCode spends the code with hash codeHash. It returns
+// ErrInvalidAuthorizationCode when the code is unknown, already used or expired.
+func (oa *OAuthRepo) UseOAuthAuthorizationCode(ctx context.Context, codeHash string) (*entity.OAuthAuthorizationCode, error) {
+	code, err := oa.db.UseOAuthAuthorizationCode(ctx, codeHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidAuthorizationCode
+		}
+		return nil, err
+	}
+	return &entity.OAuthAuthorizationCode{
+		CodeHash:            code.CodeHash,
+		ClientID:            code.ClientID,
+		Username:            code.Username,
+		RedirectURI:         code.RedirectUri,
+		RedirectURISupplied: code.RedirectUriSupplied,
+		Scopes:              code.Scopes,
+		CodeChallenge:       code.CodeChallenge,
+		ExpiresAt:           code.ExpiresAt,
+	}, nil
+}
+
+func (oa *OAuthRepo) CreateOAuthClientToken(ctx context.Context, arg entity.OAuthClientToken) error {
+	return oa.db.CreateOAuthClientToken(ctx, sqlc.CreateOAuthClientTokenParams{
+		ID:        arg.ID,
+		ClientID:  arg.ClientID,
+		Scopes:    arg.Scopes,
+		ExpiresAt: arg.ExpiresAt,
+	})
+}
+
+func (oa *OAuthRepo) GetOAuthClientToken(ctx context.Context, id uuid.UUID) (*entity.OAuthClientToken, error) {
+	token, err := oa.db.GetOAuthClientToken(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOAuthClientTokenNotFound
+		}
+		return nil, err
+	}
+	return &entity.OAuthClientToken{
+		ID:        token.ID,
+		ClientID:  token.ClientID,
+		Scopes:    token.Scopes,
+		ExpiresAt: token.ExpiresAt,
+		RevokedAt: token.RevokedAt.Time,
+	}, nil
+}
+
+// RevokeOAuthClientToken revokes a client credentials token, if clientID was the client issued it.
+func (oa *OAuthRepo) RevokeOAuthClientToken(ctx context.Context, id uuid.UUID, clientID string) error {
+	return oa.db.RevokeOAuthClientToken(ctx, sqlc.RevokeOAuthClientTokenParams{
+		ID:       id,
+		ClientID: clientID,
+	})
+}
@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+)
+
+var ErrInvalidResetToken = errors.New("password reset token is invalid, used or expired")
+
+type PasswordResetRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewPasswordResetRepo(db *sqlc.SQLStore) *PasswordResetRepo {
+	return &PasswordResetRepo{db: db}
+}
+
+func (pr *PasswordResetRepo) CreatePasswordReset(ctx context.Context, username, tokenHash string, expiresAt time.Time) error {
+	_, err := pr.db.CreatePasswordReset(ctx, sqlc.CreatePasswordResetParams{
+		Username:  username,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	})
+	return err
+}
+
+// ResetPassword spends the reset token, stores the new password, blocks every session of the
+// user and records event, all in one transaction.
+func (pr *PasswordResetRepo) ResetPassword(ctx context.Context, tokenHash, hashedPassword string, event entity.AuditEvent) (*entity.User, error) {
+	audit := toAuditParams(event)
+	user, err := pr.db.ResetPasswordTx(ctx, sqlc.ResetPasswordTxParams{
+		TokenHash:      tokenHash,
+		HashedPassword: hashedPassword,
+		Audit:          &audit,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidResetToken
+		}
+		return nil, err
+	}
+	return ToUser(user)
+}
+
+// GetPasswordResetUser returns the user a reset token is for while it can still be used.
+func (pr *PasswordResetRepo) GetPasswordResetUser(ctx context.Context, tokenHash string) (*entity.User, error) {
+	user, err := pr.db.GetPasswordResetUser(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidResetToken
+		}
+		return nil, err
+	}
+	return ToUser(user)
+}
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrServiceAccountNotFound  = errors.New("service account not found")
+	ErrDuplicateServiceAccount = errors.New("service account already exists")
+	ErrAPIKeyNotFound          = errors.New("api key not found or revoked")
+)
+
+type ServiceAccountRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewServiceAccountRepo(db *sqlc.SQLStore) *ServiceAccountRepo {
+	return &ServiceAccountRepo{db: db}
+}
+
+func toEntityServiceAccount(sa *sqlc.ServiceAccount) *entity.ServiceAccount {
+	return &entity.ServiceAccount{
+		Name:        sa.Name,
+		Description: sa.Description,
+		Scopes:      sa.Scopes,
+		AccountIDs:  sa.AccountIds,
+		CreatedBy:   sa.CreatedBy,
+		CreatedAt:   sa.CreatedAt,
+	}
+}
+
+func toEntityAPIKey(k *sqlc.ApiKey) *entity.APIKey {
+	return &entity.APIKey{
+		ID:             k.ID,
+		ServiceAccount: k.ServiceAccount,
+		Prefix:         k.Prefix,
+		KeyHash:        k.KeyHash,
+		CreatedAt:      k.CreatedAt,
+		LastUsedAt:     k.LastUsedAt.Time,
+		RevokedAt:      k.RevokedAt.Time,
+	}
+}
+
+// CreateServiceAccount stores the service account with its first key and records event, all in
+// one transaction.
+func (sr *ServiceAccountRepo) CreateServiceAccount(ctx context.Context, arg entity.ServiceAccount, prefix, keyHash string, event entity.AuditEvent) (*entity.ServiceAccount, *entity.APIKey, error) {
+	var (
+		sa  *sqlc.ServiceAccount
+		key *sqlc.ApiKey
+	)
+	err := sr.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		var err error
+		sa, err = q.CreateServiceAccount(ctx, sqlc.CreateServiceAccountParams{
+			Name:        arg.Name,
+			Description: arg.Description,
+			Scopes:      arg.Scopes,
+			AccountIds:  arg.AccountIDs,
+			CreatedBy:   arg.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+		key, err = q.CreateAPIKey(ctx, sqlc.CreateAPIKeyParams{
+			ServiceAccount: sa.Name,
+			Prefix:         prefix,
+			KeyHash:        keyHash,
+		})
+		return err
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return nil, nil, ErrDuplicateServiceAccount
+		}
+		return nil, nil, err
+	}
+	return toEntityServiceAccount(sa), toEntityAPIKey(key), nil
+}
+
+func (sr *ServiceAccountRepo) GetServiceAccount(ctx context.Context, name string) (*entity.ServiceAccount, error) {
+	sa, err := sr.db.GetServiceAccount(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, err
+	}
+	return toEntityServiceAccount(sa), nil
+}
+
+// RotateAPIKey stores a new key, revokes every other key of the service account and records
+// event, all in one transaction.
+func (sr *ServiceAccountRepo) RotateAPIKey(ctx context.Context, serviceAccount, prefix, keyHash string, event entity.AuditEvent) (*entity.APIKey, error) {
+	audit := toAuditParams(event)
+	key, err := sr.db.RotateAPIKeyTx(ctx, sqlc.RotateAPIKeyTxParams{
+		ServiceAccount: serviceAccount,
+		Prefix:         prefix,
+		KeyHash:        keyHash,
+		Audit:          &audit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toEntityAPIKey(key), nil
+}
+
+func (sr *ServiceAccountRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	key, err := sr.db.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return toEntityAPIKey(key), nil
+}
+
+func (sr *ServiceAccountRepo) ListAPIKeys(ctx context.Context, serviceAccount string) ([]*entity.APIKey, error) {
+	keys, err := sr.db.ListAPIKeys(ctx, serviceAccount)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*entity.APIKey, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, toEntityAPIKey(k))
+	}
+	return result, nil
+}
+
+func (sr *ServiceAccountRepo) RevokeAPIKey(ctx context.Context, prefix string, event entity.AuditEvent) error {
+	return sr.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		n, err := q.RevokeAPIKey(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrAPIKeyNotFound
+		}
+		return nil
+	})
+}
+
+func (sr *ServiceAccountRepo) TouchAPIKey(ctx context.Context, id int64) error {
+	return sr.db.TouchAPIKey(ctx, id)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/0xOnah/bank/internal/db/sqlc"
 	"github.com/0xOnah/bank/internal/entity"
@@ -13,6 +14,8 @@ import (
 var (
 	ErrFailedToCreateSession = errors.New("failed to create session")
 	ErrSessionNotFound       = errors.New("session not found")
+	ErrSessionReused         = errors.New("session refresh token reused")
+	ErrMFATokenSpent         = errors.New("mfa token already used")
 )
 
 type sessionRepo struct {
@@ -28,16 +31,22 @@ func toEntitySession(s *sqlc.Session) *entity.Session {
 		ClientIp:     s.ClientIp,
 		IsBlocked:    s.IsBlocked,
 		ExpiresAt:    s.ExpiresAt,
+		CreatedAt:    s.CreatedAt,
+		FamilyID:     s.FamilyID,
+		ParentID:     s.ParentID.UUID,
+		RotatedAt:    s.RotatedAt.Time,
+		ClientID:     s.ClientID.String,
 	}
 
 }
 
-func NewSessionRepo(db *sqlc.SQLStore) *sessionRepo {
-	return &sessionRepo{db: db}
-}
-
-func (s *sessionRepo) CreateSession(ctx context.Context, arg entity.Session) (*entity.Session, error) {
-	result, err := s.db.CreateSession(ctx, sqlc.CreateSessionParams{
+func toCreateSessionParams(arg entity.Session) sqlc.CreateSessionParams {
+	familyID := arg.FamilyID
+	if familyID == uuid.Nil {
+		//a fresh login starts its own family
+		familyID = arg.ID
+	}
+	return sqlc.CreateSessionParams{
 		ID:           arg.ID,
 		Username:     arg.Username,
 		RefreshToken: arg.RefreshToken,
@@ -45,8 +54,28 @@ func (s *sessionRepo) CreateSession(ctx context.Context, arg entity.Session) (*e
 		ClientIp:     arg.ClientIp,
 		IsBlocked:    arg.IsBlocked,
 		ExpiresAt:    arg.ExpiresAt,
+		FamilyID:     familyID,
+		ParentID:     uuid.NullUUID{UUID: arg.ParentID, Valid: arg.ParentID != uuid.Nil},
+		ClientID:     sql.NullString{String: arg.ClientID, Valid: arg.ClientID != ""},
+	}
+}
+
+func NewSessionRepo(db *sqlc.SQLStore) *sessionRepo {
+	return &sessionRepo{db: db}
+}
+
+// CreateSession stores the session of a new login and records event with it.
+func (s *sessionRepo) CreateSession(ctx context.Context, arg entity.Session, event entity.AuditEvent) (*entity.Session, error) {
+	var result *sqlc.Session
+	err := s.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		var err error
+		result, err = q.CreateSession(ctx, toCreateSessionParams(arg))
+		return err
 	})
-	return toEntitySession(result), err
+	if err != nil {
+		return nil, err
+	}
+	return toEntitySession(result), nil
 }
 
 func (s *sessionRepo) GetSession(ctx context.Context, id uuid.UUID) (*entity.Session, error) {
@@ -55,6 +84,87 @@ func (s *sessionRepo) GetSession(ctx context.Context, id uuid.UUID) (*entity.Ses
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrSessionNotFound
 		}
+		return nil, err
+	}
+	return toEntitySession(session), nil
+}
+
+// RotateSession retires the session previousID and stores next in its place. It returns
+// ErrSessionReused when previousID was already rotated or blocked.
+func (s *sessionRepo) RotateSession(ctx context.Context, previousID uuid.UUID, next entity.Session) (*entity.Session, error) {
+	session, err := s.db.RotateSessionTx(ctx, sqlc.RotateSessionTxParams{
+		PreviousID: previousID,
+		Next:       toCreateSessionParams(next),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionReused
+		}
+		return nil, err
 	}
 	return toEntitySession(session), nil
 }
+
+func (s *sessionRepo) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	return s.db.BlockSessionFamily(ctx, familyID)
+}
+
+func (s *sessionRepo) ListActiveSessions(ctx context.Context, username string) ([]*entity.Session, error) {
+	result, err := s.db.ListActiveSessions(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*entity.Session, 0, len(result))
+	for _, session := range result {
+		sessions = append(sessions, toEntitySession(session))
+	}
+	return sessions, nil
+}
+
+// BlockUserSessionFamily blocks a session family owned by username. It returns
+// ErrSessionNotFound when username has no such family.
+func (s *sessionRepo) BlockUserSessionFamily(ctx context.Context, username string, familyID uuid.UUID, event entity.AuditEvent) error {
+	return s.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		n, err := q.BlockUserSessionFamily(ctx, sqlc.BlockUserSessionFamilyParams{
+			FamilyID: familyID,
+			Username: username,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrSessionNotFound
+		}
+		return nil
+	})
+}
+
+func (s *sessionRepo) BlockOtherSessionFamilies(ctx context.Context, username string, keepFamilyID uuid.UUID, event entity.AuditEvent) error {
+	return s.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		return q.BlockOtherSessionFamilies(ctx, sqlc.BlockOtherSessionFamiliesParams{
+			Username: username,
+			FamilyID: keepFamilyID,
+		})
+	})
+}
+
+// SpendMFAToken marks the MFA token id as exchanged for a session. It returns ErrMFATokenSpent
+// when it already was. Rows of expired tokens are dropped on the way, as those tokens no
+// longer verify.
+func (s *sessionRepo) SpendMFAToken(ctx context.Context, id uuid.UUID, username string, expiresAt time.Time) error {
+	if err := s.db.DeleteExpiredMFATokens(ctx); err != nil {
+		return err
+	}
+	n, err := s.db.SpendMFAToken(ctx, sqlc.SpendMFATokenParams{
+		ID:        id,
+		Username:  username,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrMFATokenSpent
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+)
+
+var (
+	ErrTOTPNotFound        = errors.New("totp authenticator not found")
+	ErrTOTPAlreadyEnabled  = errors.New("totp authenticator already enabled")
+	ErrTOTPCodeReused      = errors.New("totp code already used")
+	ErrInvalidRecoveryCode = errors.New("recovery code is invalid or used")
+)
+
+type TOTPRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewTOTPRepo(db *sqlc.SQLStore) *TOTPRepo {
+	return &TOTPRepo{db: db}
+}
+
+func toEntityTOTP(t *sqlc.UserTotp) *entity.UserTOTP {
+	return &entity.UserTOTP{
+		Username:     t.Username,
+		Secret:       t.Secret,
+		ConfirmedAt:  t.ConfirmedAt.Time,
+		LastUsedStep: t.LastUsedStep,
+		CreatedAt:    t.CreatedAt,
+	}
+}
+
+// CreatePendingTOTP stores a new unconfirmed secret, replacing any earlier unconfirmed one.
+func (tr *TOTPRepo) CreatePendingTOTP(ctx context.Context, username, secret string) (*entity.UserTOTP, error) {
+	result, err := tr.db.CreatePendingTOTP(ctx, sqlc.CreatePendingTOTPParams{
+		Username: username,
+		Secret:   secret,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTOTPAlreadyEnabled
+		}
+		return nil, err
+	}
+	return toEntityTOTP(result), nil
+}
+
+func (tr *TOTPRepo) GetUserTOTP(ctx context.Context, username string) (*entity.UserTOTP, error) {
+	result, err := tr.db.GetUserTOTP(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTOTPNotFound
+		}
+		return nil, err
+	}
+	return toEntityTOTP(result), nil
+}
+
+// ConfirmTOTP enables the pending secret and replaces the user's recovery codes, recording event
+// in the same transaction.
+func (tr *TOTPRepo) ConfirmTOTP(ctx context.Context, username string, step int64, recoveryCodeHashes []string, event entity.AuditEvent) error {
+	audit := toAuditParams(event)
+	err := tr.db.ConfirmTOTPTx(ctx, sqlc.ConfirmTOTPTxParams{
+		Username:           username,
+		Step:               step,
+		RecoveryCodeHashes: recoveryCodeHashes,
+		Audit:              &audit,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTOTPNotFound
+	}
+	return err
+}
+
+// UseTOTPStep records step as spent, failing if it or a later step was already used.
+func (tr *TOTPRepo) UseTOTPStep(ctx context.Context, username string, step int64) error {
+	n, err := tr.db.UseTOTPStep(ctx, sqlc.UseTOTPStepParams{
+		Username:     username,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTOTPCodeReused
+	}
+	return nil
+}
+
+func (tr *TOTPRepo) UseRecoveryCode(ctx context.Context, username, codeHash string) error {
+	n, err := tr.db.UseRecoveryCode(ctx, sqlc.UseRecoveryCodeParams{
+		Username: username,
+		CodeHash: codeHash,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidRecoveryCode
+	}
+	return nil
+}
+
+func (tr *TOTPRepo) DisableTOTP(ctx context.Context, username string, event entity.AuditEvent) error {
+	audit := toAuditParams(event)
+	return tr.db.DisableTOTPTx(ctx, sqlc.DisableTOTPTxParams{
+		Username: username,
+		Audit:    &audit,
+	})
+}
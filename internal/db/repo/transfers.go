@@ -61,11 +61,14 @@ func NewTransferTxResponse(txResult *sqlc.TransferTxResult) *entity.TransferTxRe
 	}
 }
 
-func (r *transferRepo) CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput) (*entity.TransferTxResult, error) {
+// CreateTransferTX moves the money and records event in the same transaction.
+func (r *transferRepo) CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput, event entity.AuditEvent) (*entity.TransferTxResult, error) {
+	audit := toAuditParams(event)
 	result, err := r.db.TransferTx(ctx, sqlc.TransferTxParams{
 		FromAccountID: arg.FromAccountID,
 		ToAccountID:   arg.ToAccountID,
 		Amount:        arg.Amount,
+		Audit:         &audit,
 	})
 	return NewTransferTxResponse(result), err
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/0xOnah/bank/internal/db/sqlc"
 	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/google/uuid"
 )
 
 type UserRepo struct {
@@ -31,14 +33,23 @@ func ToUser(u *sqlc.User) (*entity.User, error) {
 		FullName:          u.FullName,
 		CreatedAt:         u.CreatedAt,
 		PasswordChangedAt: u.PasswordChangedAt,
+		IsEmailVerified:   u.IsEmailVerified,
+		Role:              u.Role,
 	}, nil
 }
-func (ur *UserRepo) CreateUser(ctx context.Context, arg entity.User) (*entity.User, error) {
-	user, err := ur.db.CreateUser(ctx, sqlc.CreateUserParams{
-		Username:       arg.Username,
-		HashedPassword: arg.HashedPassword,
-		FullName:       arg.FullName,
-		Email:          arg.Email.String(),
+
+// CreateUser stores a new user and records event with it.
+func (ur *UserRepo) CreateUser(ctx context.Context, arg entity.User, event entity.AuditEvent) (*entity.User, error) {
+	var user *sqlc.User
+	err := ur.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		var err error
+		user, err = q.CreateUser(ctx, sqlc.CreateUserParams{
+			Username:       arg.Username,
+			HashedPassword: arg.HashedPassword,
+			FullName:       arg.FullName,
+			Email:          arg.Email.String(),
+		})
+		return err
 	})
 	if err != nil {
 		return nil, err
@@ -65,6 +76,8 @@ type UpdateUserParams struct {
 	Email             *string
 	Username          string
 	PasswordChangedAt *time.Time
+	Role              *auth.Role
+	KeepSessionID     *uuid.UUID
 }
 
 func deref(s *string) string {
@@ -73,8 +86,18 @@ func deref(s *string) string {
 	}
 	return ""
 }
-func (ur *UserRepo) UpdateUser(ctx context.Context, arg UpdateUserParams) (*entity.User, error) {
-	user, err := ur.db.UpdateUser(ctx, sqlc.UpdateUserParams{
+func derefRole(r *auth.Role) auth.Role {
+	if r != nil {
+		return *r
+	}
+	return ""
+}
+
+// UpdateUser changes the fields of arg that are set and records event with the change.
+// Setting Role also blocks the user's sessions. Setting HashedPassword blocks them too, apart
+// from the family of KeepSessionID when it is set.
+func (ur *UserRepo) UpdateUser(ctx context.Context, arg UpdateUserParams, event entity.AuditEvent) (*entity.User, error) {
+	params := sqlc.UpdateUserParams{
 		FullName: sql.NullString{
 			String: deref(arg.FullName),
 			Valid:  arg.FullName != nil,
@@ -91,7 +114,35 @@ func (ur *UserRepo) UpdateUser(ctx context.Context, arg UpdateUserParams) (*enti
 			Time:  time.Now(),
 			Valid: arg.HashedPassword != nil,
 		},
+		Role: sql.NullString{
+			String: string(derefRole(arg.Role)),
+			Valid:  arg.Role != nil,
+		},
 		Username: arg.Username,
+	}
+	var user *sqlc.User
+	err := ur.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		var err error
+		user, err = q.UpdateUser(ctx, params)
+		switch {
+		case err != nil:
+			return err
+		case arg.Role != nil:
+			//tokens carry the role, so sessions started under the old one end with it
+			return q.BlockUserSessions(ctx, arg.Username)
+		case arg.HashedPassword == nil:
+			return nil
+		case arg.KeepSessionID == nil:
+			return q.BlockUserSessions(ctx, arg.Username)
+		}
+		current, err := q.GetSession(ctx, *arg.KeepSessionID)
+		if err != nil {
+			return err
+		}
+		return q.BlockOtherSessionFamilies(ctx, sqlc.BlockOtherSessionFamiliesParams{
+			Username: arg.Username,
+			FamilyID: current.FamilyID,
+		})
 	})
 	if err != nil {
 		return nil, err
@@ -105,7 +156,7 @@ func (ur *UserRepo) WithTx(ctx context.Context, fn func(*sqlc.Queries) error) er
 		return err
 	}
 
-	queries := sqlc.New(tx)
+	queries := sqlc.New(sqlc.WithTracing(tx))
 	err = fn(queries)
 
 	if err != nil {
@@ -113,3 +164,25 @@ func (ur *UserRepo) WithTx(ctx context.Context, fn func(*sqlc.Queries) error) er
 	}
 	return tx.Commit()
 }
+
+func (ur *UserRepo) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	user, err := ur.db.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return ToUser(user)
+}
+
+// RehashPassword swaps the stored hash for one of the same password made with current settings.
+// It leaves the user alone if the hash is no longer oldHash.
+func (ur *UserRepo) RehashPassword(ctx context.Context, username, oldHash, newHash string) error {
+	_, err := ur.db.RehashPassword(ctx, sqlc.RehashPasswordParams{
+		NewHash:  newHash,
+		Username: username,
+		OldHash:  oldHash,
+	})
+	return err
+}
@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+)
+
+var (
+	ErrVerifyEmailNotFound = errors.New("verify email not found")
+	ErrInvalidVerifyCode   = errors.New("verification code is invalid, used or expired")
+)
+
+type VerifyEmailRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewVerifyEmailRepo(db *sqlc.SQLStore) *VerifyEmailRepo {
+	return &VerifyEmailRepo{db: db}
+}
+
+func toEntityVerifyEmail(v *sqlc.VerifyEmail) *entity.VerifyEmail {
+	return &entity.VerifyEmail{
+		ID:             v.ID,
+		Username:       v.Username,
+		Email:          v.Email,
+		SecretCodeHash: v.SecretCodeHash,
+		IsUsed:         v.IsUsed,
+		CreatedAt:      v.CreatedAt,
+		ExpiredAt:      v.ExpiredAt,
+	}
+}
+
+func (vr *VerifyEmailRepo) CreateVerifyEmail(ctx context.Context, username, email, secretCodeHash string) (*entity.VerifyEmail, error) {
+	result, err := vr.db.CreateVerifyEmail(ctx, sqlc.CreateVerifyEmailParams{
+		Username:       username,
+		Email:          email,
+		SecretCodeHash: secretCodeHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toEntityVerifyEmail(result), nil
+}
+
+func (vr *VerifyEmailRepo) GetLastVerifyEmail(ctx context.Context, username string) (*entity.VerifyEmail, error) {
+	result, err := vr.db.GetLastVerifyEmail(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVerifyEmailNotFound
+		}
+		return nil, err
+	}
+	return toEntityVerifyEmail(result), nil
+}
+
+// VerifyEmail consumes the code with the given hash and marks the address it was sent to verified.
+func (vr *VerifyEmailRepo) VerifyEmail(ctx context.Context, emailID int64, secretCodeHash string) (*entity.VerifyEmail, error) {
+	result, err := vr.db.VerifyEmailTx(ctx, sqlc.VerifyEmailTxParams{
+		EmailID:        emailID,
+		SecretCodeHash: secretCodeHash,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidVerifyCode
+		}
+		return nil, err
+	}
+	return toEntityVerifyEmail(result), nil
+}
@@ -0,0 +1,176 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/0xOnah/bank/internal/db/sqlc"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrWebAuthnUserNotFound        = errors.New("no passkeys registered for user handle")
+	ErrWebAuthnCredentialNotFound  = errors.New("passkey not found")
+	ErrDuplicateWebAuthnCredential = errors.New("passkey already registered")
+	ErrInvalidWebAuthnCeremony     = errors.New("passkey ceremony is unknown, finished or expired")
+)
+
+type WebAuthnRepo struct {
+	db *sqlc.SQLStore
+}
+
+func NewWebAuthnRepo(db *sqlc.SQLStore) *WebAuthnRepo {
+	return &WebAuthnRepo{db: db}
+}
+
+func toEntityWebAuthnCredential(c *sqlc.WebauthnCredential) *entity.WebAuthnCredential {
+	return &entity.WebAuthnCredential{
+		ID:              c.ID,
+		Username:        c.Username,
+		Name:            c.Name,
+		CredentialID:    c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transports:      c.Transports,
+		AAGUID:          c.Aaguid,
+		BackupEligible:  c.BackupEligible,
+		BackupState:     c.BackupState,
+		SignCount:       uint32(c.SignCount),
+		CreatedAt:       c.CreatedAt,
+		LastUsedAt:      c.LastUsedAt.Time,
+	}
+}
+
+// SaveWebAuthnUser gives username the user handle handle, unless it already has one; either
+// way the user's handle is returned.
+func (wr *WebAuthnRepo) SaveWebAuthnUser(ctx context.Context, username string, handle []byte) ([]byte, error) {
+	user, err := wr.db.SaveWebAuthnUser(ctx, sqlc.SaveWebAuthnUserParams{
+		Username:   username,
+		UserHandle: handle,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user.UserHandle, nil
+}
+
+// GetWebAuthnUsername returns the user a passkey's user handle belongs to.
+func (wr *WebAuthnRepo) GetWebAuthnUsername(ctx context.Context, handle []byte) (string, error) {
+	user, err := wr.db.GetWebAuthnUserByHandle(ctx, handle)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrWebAuthnUserNotFound
+		}
+		return "", err
+	}
+	return user.Username, nil
+}
+
+func (wr *WebAuthnRepo) ListWebAuthnCredentials(ctx context.Context, username string) ([]*entity.WebAuthnCredential, error) {
+	credentials, err := wr.db.ListWebAuthnCredentials(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*entity.WebAuthnCredential, 0, len(credentials))
+	for _, c := range credentials {
+		result = append(result, toEntityWebAuthnCredential(c))
+	}
+	return result, nil
+}
+
+// CreateWebAuthnCredential stores a newly registered passkey and records event with the passkey
+// as its subject. The user must have been given a handle by SaveWebAuthnUser first.
+func (wr *WebAuthnRepo) CreateWebAuthnCredential(ctx context.Context, arg entity.WebAuthnCredential, event entity.AuditEvent) (*entity.WebAuthnCredential, error) {
+	var credential *sqlc.WebauthnCredential
+	err := wr.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, event *sqlc.CreateAuditEventParams) error {
+		var err error
+		credential, err = q.CreateWebAuthnCredential(ctx, sqlc.CreateWebAuthnCredentialParams{
+			Username:        arg.Username,
+			Name:            arg.Name,
+			CredentialID:    arg.CredentialID,
+			PublicKey:       arg.PublicKey,
+			AttestationType: arg.AttestationType,
+			Transports:      arg.Transports,
+			Aaguid:          arg.AAGUID,
+			BackupEligible:  arg.BackupEligible,
+			BackupState:     arg.BackupState,
+			SignCount:       int64(arg.SignCount),
+		})
+		if err != nil {
+			return err
+		}
+		event.Subject = audit.PasskeySubject(credential.ID)
+		return nil
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return nil, ErrDuplicateWebAuthnCredential
+		}
+		return nil, err
+	}
+	return toEntityWebAuthnCredential(credential), nil
+}
+
+// UseWebAuthnCredential stores what a successful login reported about the passkey.
+func (wr *WebAuthnRepo) UseWebAuthnCredential(ctx context.Context, credentialID []byte, signCount uint32, backupState bool) error {
+	return wr.db.UseWebAuthnCredential(ctx, sqlc.UseWebAuthnCredentialParams{
+		CredentialID: credentialID,
+		SignCount:    int64(signCount),
+		BackupState:  backupState,
+	})
+}
+
+// DeleteWebAuthnCredential removes a passkey owned by username and records event with it.
+func (wr *WebAuthnRepo) DeleteWebAuthnCredential(ctx context.Context, username string, id int64, event entity.AuditEvent) error {
+	return wr.db.AuditTx(ctx, toAuditParams(event), func(q *sqlc.Queries, _ *sqlc.CreateAuditEventParams) error {
+		n, err := q.DeleteWebAuthnCredential(ctx, sqlc.DeleteWebAuthnCredentialParams{
+			ID:       id,
+			Username: username,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrWebAuthnCredentialNotFound
+		}
+		return nil
+	})
+}
+
+// CreateWebAuthnCeremony stores a started ceremony. Ceremonies that expired unfinished are
+// deleted first, so abandoned ones don't pile up.
+func (wr *WebAuthnRepo) CreateWebAuthnCeremony(ctx context.Context, arg entity.WebAuthnCeremony) error {
+	if err := wr.db.DeleteExpiredWebAuthnCeremonies(ctx); err != nil {
+		return err
+	}
+	return wr.db.CreateWebAuthnCeremony(ctx, sqlc.CreateWebAuthnCeremonyParams{
+		ID:          arg.ID,
+		Kind:        arg.Kind,
+		Username:    sql.NullString{String: arg.Username, Valid: arg.Username != ""},
+		SessionData: arg.SessionData,
+		ExpiresAt:   arg.ExpiresAt,
+	})
+}
+
+// TakeWebAuthnCeremony removes and returns an unexpired ceremony of the given kind.
+func (wr *WebAuthnRepo) TakeWebAuthnCeremony(ctx context.Context, id uuid.UUID, kind string) (*entity.WebAuthnCeremony, error) {
+	c, err := wr.db.TakeWebAuthnCeremony(ctx, sqlc.TakeWebAuthnCeremonyParams{ID: id, Kind: kind})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidWebAuthnCeremony
+		}
+		return nil, err
+	}
+	return &entity.WebAuthnCeremony{
+		ID:          c.ID,
+		Kind:        c.Kind,
+		Username:    c.Username.String,
+		SessionData: c.SessionData,
+		ExpiresAt:   c.ExpiresAt,
+	}, nil
+}
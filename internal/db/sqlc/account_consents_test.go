@@ -0,0 +1,75 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccountConsent(t *testing.T) *AccountConsent {
+	account := createRandomAccount(t)
+	arg := CreateAccountConsentParams{
+		ID:          uuid.New(),
+		Username:    account.Owner,
+		ClientID:    createRandomOAuthClient(t).ID,
+		AccountIds:  []int64{account.ID},
+		Permissions: []string{"balances", "transactions"},
+		ExpiresAt:   time.Now().Add(24 * time.Hour),
+	}
+	consent, err := testQueries.CreateAccountConsent(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, consent.ID)
+	require.Equal(t, arg.AccountIds, consent.AccountIds)
+	require.Equal(t, arg.Permissions, consent.Permissions)
+	require.WithinDuration(t, arg.ExpiresAt, consent.ExpiresAt, time.Second)
+	require.False(t, consent.RevokedAt.Valid)
+	return consent
+}
+
+func TestListAccountConsents(t *testing.T) {
+	consent := createRandomAccountConsent(t)
+
+	consents, err := testQueries.ListAccountConsents(context.Background(), consent.Username)
+	require.NoError(t, err)
+	require.Len(t, consents, 1)
+	require.Equal(t, consent.ID, consents[0].ID)
+}
+
+func TestRevokeAccountConsent(t *testing.T) {
+	consent := createRandomAccountConsent(t)
+
+	//only the user who granted it can revoke it
+	n, err := testQueries.RevokeAccountConsent(context.Background(), RevokeAccountConsentParams{ID: consent.ID, Username: createRandomUser(t).Username})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	arg := RevokeAccountConsentParams{ID: consent.ID, Username: consent.Username}
+	n, err = testQueries.RevokeAccountConsent(context.Background(), arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	n, err = testQueries.RevokeAccountConsent(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	got, err := testQueries.GetAccountConsent(context.Background(), consent.ID)
+	require.NoError(t, err)
+	require.True(t, got.RevokedAt.Valid)
+}
+
+func TestRevokeClientAccountConsents(t *testing.T) {
+	consent := createRandomAccountConsent(t)
+	other := createRandomAccountConsent(t)
+
+	err := testQueries.RevokeClientAccountConsents(context.Background(), RevokeClientAccountConsentsParams{Username: consent.Username, ClientID: consent.ClientID})
+	require.NoError(t, err)
+
+	got, err := testQueries.GetAccountConsent(context.Background(), consent.ID)
+	require.NoError(t, err)
+	require.True(t, got.RevokedAt.Valid)
+	got, err = testQueries.GetAccountConsent(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, got.RevokedAt.Valid)
+}
@@ -0,0 +1,114 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAuditEvent(t *testing.T, actor string) *AuditEvent {
+	event, err := testQueries.CreateAuditEvent(context.Background(), CreateAuditEventParams{
+		Action:    "user.login",
+		Actor:     actor,
+		Subject:   "user:" + actor,
+		ClientIp:  "203.0.113.7",
+		UserAgent: "curl/8.0",
+		RequestID: util.RandomString(16),
+		Details:   json.RawMessage(`{"assurance":"password"}`),
+	})
+	require.NoError(t, err)
+	return event
+}
+
+func TestListAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	actor := util.RandomOwner() + util.RandomString(6)
+	first := createRandomAuditEvent(t, actor)
+	second := createRandomAuditEvent(t, actor)
+	createRandomAuditEvent(t, util.RandomOwner()+util.RandomString(6))
+
+	events, err := testQueries.ListAuditEvents(ctx, ListAuditEventsParams{
+		Actor:    sql.NullString{String: actor, Valid: true},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	//newest first
+	require.Equal(t, second.ID, events[0].ID)
+	require.Equal(t, first.ID, events[1].ID)
+	require.JSONEq(t, `{"assurance":"password"}`, string(events[0].Details))
+
+	events, err = testQueries.ListAuditEvents(ctx, ListAuditEventsParams{
+		Actor:    sql.NullString{String: actor, Valid: true},
+		BeforeID: sql.NullInt64{Int64: second.ID, Valid: true},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, first.ID, events[0].ID)
+
+	events, err = testQueries.ListAuditEvents(ctx, ListAuditEventsParams{
+		Actor:    sql.NullString{String: actor, Valid: true},
+		Action:   sql.NullString{String: "user.logout", Valid: true},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	events, err = testQueries.ListAuditEvents(ctx, ListAuditEventsParams{
+		Actor:    sql.NullString{String: actor, Valid: true},
+		Since:    sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestAuditEventsAppendOnly(t *testing.T) {
+	event := createRandomAuditEvent(t, util.RandomOwner())
+
+	_, err := testDB.Exec(`UPDATE audit_events SET actor = 'someone' WHERE id = $1`, event.ID)
+	require.ErrorContains(t, err, "append-only")
+	_, err = testDB.Exec(`DELETE FROM audit_events WHERE id = $1`, event.ID)
+	require.ErrorContains(t, err, "append-only")
+}
+
+func TestAuditTx(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	user := createRandomUser(t)
+	event := CreateAuditEventParams{Action: "account.create", Actor: user.Username, Details: json.RawMessage(`{}`)}
+
+	var account *Account
+	err := store.AuditTx(ctx, event, func(q *Queries, event *CreateAuditEventParams) error {
+		var err error
+		account, err = q.CreateAccount(ctx, CreateAccountParams{Owner: user.Username, Currency: "USD"})
+		if err != nil {
+			return err
+		}
+		event.Subject = fmt.Sprintf("account:%d", account.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotNil(t, account)
+	events, err := store.ListAuditEvents(ctx, ListAuditEventsParams{Actor: sql.NullString{String: user.Username, Valid: true}, PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	//a failed action leaves no record
+	failed := errors.New("failed")
+	err = store.AuditTx(ctx, event, func(q *Queries, event *CreateAuditEventParams) error {
+		return failed
+	})
+	require.ErrorIs(t, err, failed)
+	events, err = store.ListAuditEvents(ctx, ListAuditEventsParams{Actor: sql.NullString{String: user.Username, Valid: true}, PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+}
@@ -0,0 +1,88 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKnownDevices(t *testing.T) {
+	ctx := context.Background()
+	user := createRandomUser(t)
+	arg := SaveKnownDeviceParams{
+		Username:      user.Username,
+		UserAgentHash: util.RandomString(64),
+		IpRange:       "203.0.113.0/24",
+		UserAgent:     "Mozilla/5.0",
+		LastIp:        "203.0.113.7",
+	}
+
+	device, err := testQueries.SaveKnownDevice(ctx, arg)
+	require.NoError(t, err)
+	require.False(t, device.ConfirmedAt.Valid)
+
+	//saving it again marks it as seen
+	arg.LastIp = "203.0.113.9"
+	again, err := testQueries.SaveKnownDevice(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, device.ID, again.ID)
+	require.Equal(t, "203.0.113.9", again.LastIp)
+	require.False(t, again.ConfirmedAt.Valid)
+
+	tokenHash := sql.NullString{String: util.RandomString(64), Valid: true}
+	err = testQueries.SetDeviceConfirmation(ctx, SetDeviceConfirmationParams{
+		ID:               device.ID,
+		ConfirmTokenHash: tokenHash,
+		ConfirmExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+
+	confirmed, err := testQueries.ConfirmKnownDevice(ctx, tokenHash)
+	require.NoError(t, err)
+	require.Equal(t, device.ID, confirmed.ID)
+	require.True(t, confirmed.ConfirmedAt.Valid)
+	require.False(t, confirmed.ConfirmTokenHash.Valid)
+
+	//saving a confirmed device keeps its confirmation time
+	arg.ConfirmedAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	again, err = testQueries.SaveKnownDevice(ctx, arg)
+	require.NoError(t, err)
+	require.WithinDuration(t, confirmed.ConfirmedAt.Time, again.ConfirmedAt.Time, time.Second)
+
+	//a token only works once
+	_, err = testQueries.ConfirmKnownDevice(ctx, tokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	devices, err := testQueries.ListKnownDevices(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	require.Equal(t, device.ID, devices[0].ID)
+}
+
+func TestConfirmKnownDeviceExpired(t *testing.T) {
+	ctx := context.Background()
+	user := createRandomUser(t)
+	device, err := testQueries.SaveKnownDevice(ctx, SaveKnownDeviceParams{
+		Username:      user.Username,
+		UserAgentHash: util.RandomString(64),
+		IpRange:       "198.51.100.0/24",
+		UserAgent:     "curl/8.0",
+		LastIp:        "198.51.100.1",
+	})
+	require.NoError(t, err)
+
+	tokenHash := sql.NullString{String: util.RandomString(64), Valid: true}
+	err = testQueries.SetDeviceConfirmation(ctx, SetDeviceConfirmationParams{
+		ID:               device.ID,
+		ConfirmTokenHash: tokenHash,
+		ConfirmExpiresAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.ConfirmKnownDevice(ctx, tokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
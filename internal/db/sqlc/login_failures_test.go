@@ -0,0 +1,52 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginFailures(t *testing.T) {
+	ctx := context.Background()
+	subject := "user:" + util.RandomOwner()
+	window := func() time.Time { return time.Now().Add(-15 * time.Minute) }
+
+	for i := 1; i <= 3; i++ {
+		failure, err := testQueries.RecordLoginFailure(ctx, RecordLoginFailureParams{Subject: subject, WindowStart: window()})
+		require.NoError(t, err)
+		require.EqualValues(t, i, failure.FailedAttempts)
+	}
+
+	//failures from before the window start the count over
+	failure, err := testQueries.RecordLoginFailure(ctx, RecordLoginFailureParams{Subject: subject, WindowStart: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, failure.FailedAttempts)
+
+	locks, err := testQueries.ListLoginLocks(ctx, []string{subject})
+	require.NoError(t, err)
+	require.Empty(t, locks)
+
+	locked, err := testQueries.LockLoginSubject(ctx, LockLoginSubjectParams{
+		Subject:     subject,
+		LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, locked.FailedAttempts)
+	require.EqualValues(t, 1, locked.Lockouts)
+
+	locks, err = testQueries.ListLoginLocks(ctx, []string{subject, "ip:" + util.RandomString(8)})
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	require.Equal(t, subject, locks[0].Subject)
+
+	n, err := testQueries.DeleteLoginFailures(ctx, subject)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	locks, err = testQueries.ListLoginLocks(ctx, []string{subject})
+	require.NoError(t, err)
+	require.Empty(t, locks)
+}
@@ -0,0 +1,64 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseLoginLink(t *testing.T) {
+	user := createRandomUser(t)
+
+	link, err := testQueries.CreateLoginLink(context.Background(), CreateLoginLinkParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, link.Username)
+	require.False(t, link.UsedAt.Valid)
+
+	used, err := testQueries.UseLoginLink(context.Background(), link.ID)
+	require.NoError(t, err)
+	require.Equal(t, link.ID, used.ID)
+	require.True(t, used.UsedAt.Valid)
+
+	//a link works once
+	_, err = testQueries.UseLoginLink(context.Background(), link.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	expired, err := testQueries.CreateLoginLink(context.Background(), CreateLoginLinkParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	_, err = testQueries.UseLoginLink(context.Background(), expired.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCountLoginLinksSince(t *testing.T) {
+	user := createRandomUser(t)
+	since := time.Now().Add(-time.Minute)
+
+	for range 2 {
+		_, err := testQueries.CreateLoginLink(context.Background(), CreateLoginLinkParams{
+			ID:        uuid.New(),
+			Username:  user.Username,
+			ExpiresAt: time.Now().Add(10 * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+
+	n, err := testQueries.CountLoginLinksSince(context.Background(), CountLoginLinksSinceParams{Username: user.Username, CreatedAt: since})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	n, err = testQueries.CountLoginLinksSince(context.Background(), CountLoginLinksSinceParams{Username: user.Username, CreatedAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
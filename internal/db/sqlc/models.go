@@ -5,6 +5,8 @@
 package sqlc
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +20,39 @@ type Account struct {
 	CreatedAt time.Time
 }
 
+type AccountConsent struct {
+	ID          uuid.UUID
+	Username    string
+	ClientID    string
+	AccountIds  []int64
+	Permissions []string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	RevokedAt   sql.NullTime
+}
+
+type ApiKey struct {
+	ID             int64
+	ServiceAccount string
+	Prefix         string
+	KeyHash        string
+	CreatedAt      time.Time
+	LastUsedAt     sql.NullTime
+	RevokedAt      sql.NullTime
+}
+
+type AuditEvent struct {
+	ID        int64
+	Action    string
+	Actor     string
+	Subject   string
+	ClientIp  string
+	UserAgent string
+	RequestID string
+	Details   json.RawMessage
+	CreatedAt time.Time
+}
+
 type Entry struct {
 	ID        int64
 	AccountID int64
@@ -25,6 +60,106 @@ type Entry struct {
 	CreatedAt time.Time
 }
 
+type KnownDevice struct {
+	ID               int64
+	Username         string
+	UserAgentHash    string
+	IpRange          string
+	UserAgent        string
+	LastIp           string
+	ConfirmedAt      sql.NullTime
+	ConfirmTokenHash sql.NullString
+	ConfirmExpiresAt sql.NullTime
+	CreatedAt        time.Time
+	LastSeenAt       time.Time
+}
+
+type LoginFailure struct {
+	Subject        string
+	FailedAttempts int32
+	Lockouts       int32
+	LockedUntil    sql.NullTime
+	LastFailedAt   time.Time
+}
+
+type LoginLink struct {
+	ID        uuid.UUID
+	Username  string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type OauthAuthorizationCode struct {
+	CodeHash            string
+	ClientID            string
+	Username            string
+	RedirectUri         string
+	Scopes              []string
+	CodeChallenge       string
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+	UsedAt              sql.NullTime
+	RedirectUriSupplied bool
+}
+
+type OauthClient struct {
+	ID           string
+	Name         string
+	SecretHash   sql.NullString
+	RedirectUris []string
+	Scopes       []string
+	GrantTypes   []string
+	CreatedBy    string
+	CreatedAt    time.Time
+	RevokedAt    sql.NullTime
+}
+
+type OauthClientToken struct {
+	ID        uuid.UUID
+	ClientID  string
+	Scopes    []string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type OauthConsent struct {
+	ID        int64
+	Username  string
+	ClientID  string
+	Scopes    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type PasswordReset struct {
+	ID        int64
+	Username  string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type RecoveryCode struct {
+	ID        int64
+	Username  string
+	CodeHash  string
+	CreatedAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type ServiceAccount struct {
+	Name        string
+	Description string
+	Scopes      []string
+	AccountIds  []int64
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
 type Session struct {
 	ID           uuid.UUID
 	Username     string
@@ -34,6 +169,17 @@ type Session struct {
 	IsBlocked    bool
 	ExpiresAt    time.Time
 	CreatedAt    time.Time
+	FamilyID     uuid.UUID
+	ParentID     uuid.NullUUID
+	RotatedAt    sql.NullTime
+	ClientID     sql.NullString
+}
+
+type SpentMfaToken struct {
+	ID        uuid.UUID
+	Username  string
+	SpentAt   time.Time
+	ExpiresAt time.Time
 }
 
 type Transfer struct {
@@ -51,4 +197,55 @@ type User struct {
 	Email             string
 	PasswordChangedAt time.Time
 	CreatedAt         time.Time
+	IsEmailVerified   bool
+	Role              string
+}
+
+type UserTotp struct {
+	Username     string
+	Secret       string
+	ConfirmedAt  sql.NullTime
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+type VerifyEmail struct {
+	ID             int64
+	Username       string
+	Email          string
+	SecretCodeHash string
+	IsUsed         bool
+	CreatedAt      time.Time
+	ExpiredAt      time.Time
+}
+
+type WebauthnCeremony struct {
+	ID          uuid.UUID
+	Kind        string
+	Username    sql.NullString
+	SessionData json.RawMessage
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+type WebauthnCredential struct {
+	ID              int64
+	Username        string
+	Name            string
+	CredentialID    []byte
+	PublicKey       []byte
+	AttestationType string
+	Transports      []string
+	Aaguid          []byte
+	BackupEligible  bool
+	BackupState     bool
+	SignCount       int64
+	CreatedAt       time.Time
+	LastUsedAt      sql.NullTime
+}
+
+type WebauthnUser struct {
+	Username   string
+	UserHandle []byte
+	CreatedAt  time.Time
 }
@@ -0,0 +1,172 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOAuthClient(t *testing.T) *OauthClient {
+	arg := CreateOAuthClientParams{
+		ID:           "cl_" + util.RandomString(12),
+		Name:         util.RandomOwner(),
+		SecretHash:   sql.NullString{String: util.RandomString(64), Valid: true},
+		RedirectUris: []string{"https://partner.example/callback"},
+		Scopes:       []string{"accounts:read", "transfers:write"},
+		GrantTypes:   []string{"authorization_code", "client_credentials"},
+		CreatedBy:    createRandomUser(t).Username,
+	}
+	client, err := testQueries.CreateOAuthClient(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, client.ID)
+	require.Equal(t, arg.RedirectUris, client.RedirectUris)
+	require.Equal(t, arg.Scopes, client.Scopes)
+	require.Equal(t, arg.GrantTypes, client.GrantTypes)
+	require.False(t, client.RevokedAt.Valid)
+	return client
+}
+
+func TestRevokeOAuthClient(t *testing.T) {
+	client := createRandomOAuthClient(t)
+
+	n, err := testQueries.RevokeOAuthClient(context.Background(), client.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	n, err = testQueries.RevokeOAuthClient(context.Background(), client.ID)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	got, err := testQueries.GetOAuthClient(context.Background(), client.ID)
+	require.NoError(t, err)
+	require.True(t, got.RevokedAt.Valid)
+}
+
+func TestGrantOAuthConsent(t *testing.T) {
+	client := createRandomOAuthClient(t)
+	user := createRandomUser(t)
+
+	consent, err := testQueries.GrantOAuthConsent(context.Background(), GrantOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ID,
+		Scopes:   []string{"transfers:write"},
+	})
+	require.NoError(t, err)
+
+	//granting again widens the same consent
+	widened, err := testQueries.GrantOAuthConsent(context.Background(), GrantOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ID,
+		Scopes:   []string{"accounts:read", "transfers:write"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, consent.ID, widened.ID)
+	require.Equal(t, []string{"accounts:read", "transfers:write"}, widened.Scopes)
+
+	consents, err := testQueries.ListOAuthConsents(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, consents, 1)
+
+	n, err := testQueries.RevokeOAuthConsent(context.Background(), RevokeOAuthConsentParams{Username: user.Username, ClientID: client.ID})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	_, err = testQueries.GetOAuthConsent(context.Background(), GetOAuthConsentParams{Username: user.Username, ClientID: client.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	//after a revocation, consenting again starts a new record
+	again, err := testQueries.GrantOAuthConsent(context.Background(), GrantOAuthConsentParams{
+		Username: user.Username,
+		ClientID: client.ID,
+		Scopes:   []string{"accounts:read"},
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, consent.ID, again.ID)
+	require.Equal(t, []string{"accounts:read"}, again.Scopes)
+}
+
+func TestUseOAuthAuthorizationCode(t *testing.T) {
+	client := createRandomOAuthClient(t)
+	user := createRandomUser(t)
+
+	arg := CreateOAuthAuthorizationCodeParams{
+		CodeHash:            util.RandomString(64),
+		ClientID:            client.ID,
+		Username:            user.Username,
+		RedirectUri:         client.RedirectUris[0],
+		Scopes:              []string{"accounts:read"},
+		RedirectUriSupplied: true,
+		CodeChallenge:       util.RandomString(43),
+		ExpiresAt:           time.Now().Add(time.Minute),
+	}
+	require.NoError(t, testQueries.CreateOAuthAuthorizationCode(context.Background(), arg))
+
+	code, err := testQueries.UseOAuthAuthorizationCode(context.Background(), arg.CodeHash)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, code.Username)
+	require.Equal(t, arg.Scopes, code.Scopes)
+	require.True(t, code.RedirectUriSupplied)
+	require.True(t, code.UsedAt.Valid)
+
+	_, err = testQueries.UseOAuthAuthorizationCode(context.Background(), arg.CodeHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	expired := arg
+	expired.CodeHash = util.RandomString(64)
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	require.NoError(t, testQueries.CreateOAuthAuthorizationCode(context.Background(), expired))
+	_, err = testQueries.UseOAuthAuthorizationCode(context.Background(), expired.CodeHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRevokeOAuthClientToken(t *testing.T) {
+	client := createRandomOAuthClient(t)
+	arg := CreateOAuthClientTokenParams{
+		ID:        uuid.New(),
+		ClientID:  client.ID,
+		Scopes:    []string{"accounts:read"},
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	require.NoError(t, testQueries.CreateOAuthClientToken(context.Background(), arg))
+
+	//another client cannot revoke the token
+	other := createRandomOAuthClient(t)
+	require.NoError(t, testQueries.RevokeOAuthClientToken(context.Background(), RevokeOAuthClientTokenParams{ID: arg.ID, ClientID: other.ID}))
+	token, err := testQueries.GetOAuthClientToken(context.Background(), arg.ID)
+	require.NoError(t, err)
+	require.False(t, token.RevokedAt.Valid)
+
+	require.NoError(t, testQueries.RevokeOAuthClientToken(context.Background(), RevokeOAuthClientTokenParams{ID: arg.ID, ClientID: client.ID}))
+	token, err = testQueries.GetOAuthClientToken(context.Background(), arg.ID)
+	require.NoError(t, err)
+	require.True(t, token.RevokedAt.Valid)
+}
+
+func TestBlockUserClientSessions(t *testing.T) {
+	client := createRandomOAuthClient(t)
+	user := createRandomUser(t)
+
+	id := uuid.New()
+	session, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:           id,
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     id,
+		ClientID:     sql.NullString{String: client.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, client.ID, session.ClientID.String)
+
+	err = testQueries.BlockUserClientSessions(context.Background(), BlockUserClientSessionsParams{
+		Username: user.Username,
+		ClientID: session.ClientID,
+	})
+	require.NoError(t, err)
+	got, err := testQueries.GetSession(context.Background(), id)
+	require.NoError(t, err)
+	require.True(t, got.IsBlocked)
+}
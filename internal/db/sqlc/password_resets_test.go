@@ -0,0 +1,82 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+
+	reset, err := testQueries.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		Username:  user.Username,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(15 * time.Minute),
+	})
+	require.NoError(t, err)
+	other, err := testQueries.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		Username:  user.Username,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(15 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	owner, err := testQueries.GetPasswordResetUser(context.Background(), reset.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, owner.Username)
+
+	updated, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:      reset.TokenHash,
+		HashedPassword: "new-hash",
+		Audit: &CreateAuditEventParams{
+			Action:  "user.password_reset",
+			Actor:   user.Username,
+			Subject: "user:" + user.Username,
+			Details: json.RawMessage(`{}`),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "new-hash", updated.HashedPassword)
+	require.False(t, updated.PasswordChangedAt.IsZero())
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor:    sql.NullString{String: user.Username, Valid: true},
+		Action:   sql.NullString{String: "user.password_reset", Valid: true},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	//the used token and every other outstanding token for the user are spent
+	for _, hash := range []string{reset.TokenHash, other.TokenHash} {
+		_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{TokenHash: hash, HashedPassword: "again"})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+	_, err = testQueries.GetPasswordResetUser(context.Background(), reset.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUsePasswordResetExpired(t *testing.T) {
+	user := createRandomUser(t)
+	reset, err := testQueries.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		Username:  user.Username,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.UsePasswordReset(context.Background(), reset.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
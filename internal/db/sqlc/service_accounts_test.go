@@ -0,0 +1,65 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomServiceAccount(t *testing.T) *ServiceAccount {
+	admin := createRandomUser(t)
+	arg := CreateServiceAccountParams{
+		Name:        "svc-" + util.RandomOwner(),
+		Description: "reconciliation",
+		Scopes:      []string{"GET /accounts/:id"},
+		AccountIds:  []int64{1, 2},
+		CreatedBy:   admin.Username,
+	}
+	sa, err := testQueries.CreateServiceAccount(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Scopes, sa.Scopes)
+	require.Equal(t, arg.AccountIds, sa.AccountIds)
+	return sa
+}
+
+func TestRotateAPIKeyTx(t *testing.T) {
+	store := NewStore(testDB)
+	sa := createRandomServiceAccount(t)
+
+	got, err := testQueries.GetServiceAccount(context.Background(), sa.Name)
+	require.NoError(t, err)
+	require.Equal(t, sa, got)
+
+	first, err := testQueries.CreateAPIKey(context.Background(), CreateAPIKeyParams{
+		ServiceAccount: sa.Name,
+		Prefix:         util.RandomString(8),
+		KeyHash:        util.RandomString(64),
+	})
+	require.NoError(t, err)
+	require.NoError(t, testQueries.TouchAPIKey(context.Background(), first.ID))
+
+	second, err := store.RotateAPIKeyTx(context.Background(), RotateAPIKeyTxParams{
+		ServiceAccount: sa.Name,
+		Prefix:         util.RandomString(8),
+		KeyHash:        util.RandomString(64),
+	})
+	require.NoError(t, err)
+
+	keys, err := testQueries.ListAPIKeys(context.Background(), sa.Name)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	old, err := testQueries.GetAPIKeyByPrefix(context.Background(), first.Prefix)
+	require.NoError(t, err)
+	require.True(t, old.RevokedAt.Valid)
+	require.True(t, old.LastUsedAt.Valid)
+
+	n, err := testQueries.RevokeAPIKey(context.Background(), second.Prefix)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	n, err = testQueries.RevokeAPIKey(context.Background(), second.Prefix)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
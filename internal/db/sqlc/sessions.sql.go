@@ -7,6 +7,7 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,10 +21,13 @@ INSERT INTO sessions (
     user_agent,
     client_ip,
     is_blocked,
-    expires_at
+    expires_at,
+    family_id,
+    parent_id,
+    client_id
 )
-VALUES ($1,$2,$3,$4,$5,$6,$7)
-RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, parent_id, rotated_at, client_id
 `
 
 type CreateSessionParams struct {
@@ -34,6 +38,9 @@ type CreateSessionParams struct {
 	ClientIp     string
 	IsBlocked    bool
 	ExpiresAt    time.Time
+	FamilyID     uuid.UUID
+	ParentID     uuid.NullUUID
+	ClientID     sql.NullString
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (*Session, error) {
@@ -45,6 +52,9 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (*
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiresAt,
+		arg.FamilyID,
+		arg.ParentID,
+		arg.ClientID,
 	)
 	var i Session
 	err := row.Scan(
@@ -56,12 +66,16 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (*
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.RotatedAt,
+		&i.ClientID,
 	)
 	return &i, err
 }
 
 const getSession = `-- name: GetSession :one
-SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, parent_id, rotated_at, client_id FROM sessions
 WHERE id = $1 LIMIT 1
 `
 
@@ -77,6 +91,166 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (*Session, error
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.RotatedAt,
+		&i.ClientID,
 	)
 	return &i, err
 }
+
+const markSessionRotated = `-- name: MarkSessionRotated :one
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1 AND rotated_at IS NULL AND is_blocked = false
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, parent_id, rotated_at, client_id
+`
+
+func (q *Queries) MarkSessionRotated(ctx context.Context, id uuid.UUID) (*Session, error) {
+	row := q.db.QueryRowContext(ctx, markSessionRotated, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.FamilyID,
+		&i.ParentID,
+		&i.RotatedAt,
+		&i.ClientID,
+	)
+	return &i, err
+}
+
+const blockSessionFamily = `-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1
+`
+
+func (q *Queries) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, blockSessionFamily, familyID)
+	return err
+}
+
+const listActiveSessions = `-- name: ListActiveSessions :many
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, family_id, parent_id, rotated_at, client_id FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListActiveSessions(ctx context.Context, username string) ([]*Session, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveSessions, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.FamilyID,
+			&i.ParentID,
+			&i.RotatedAt,
+			&i.ClientID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const blockUserSessionFamily = `-- name: BlockUserSessionFamily :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1 AND username = $2
+`
+
+type BlockUserSessionFamilyParams struct {
+	FamilyID uuid.UUID
+	Username string
+}
+
+func (q *Queries) BlockUserSessionFamily(ctx context.Context, arg BlockUserSessionFamilyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, blockUserSessionFamily, arg.FamilyID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const blockOtherSessionFamilies = `-- name: BlockOtherSessionFamilies :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND family_id <> $2
+`
+
+type BlockOtherSessionFamiliesParams struct {
+	Username string
+	FamilyID uuid.UUID
+}
+
+func (q *Queries) BlockOtherSessionFamilies(ctx context.Context, arg BlockOtherSessionFamiliesParams) error {
+	_, err := q.db.ExecContext(ctx, blockOtherSessionFamilies, arg.Username, arg.FamilyID)
+	return err
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, blockUserSessions, username)
+	return err
+}
+
+const blockClientSessions = `-- name: BlockClientSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE client_id = $1
+`
+
+func (q *Queries) BlockClientSessions(ctx context.Context, clientID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, blockClientSessions, clientID)
+	return err
+}
+
+const blockUserClientSessions = `-- name: BlockUserClientSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND client_id = $2
+`
+
+type BlockUserClientSessionsParams struct {
+	Username string
+	ClientID sql.NullString
+}
+
+func (q *Queries) BlockUserClientSessions(ctx context.Context, arg BlockUserClientSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, blockUserClientSessions, arg.Username, arg.ClientID)
+	return err
+}
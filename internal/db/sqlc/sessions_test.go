@@ -0,0 +1,104 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, username string) *Session {
+	id := uuid.New()
+	session, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:           id,
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    "test",
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     id,
+	})
+	require.NoError(t, err)
+	require.Equal(t, id, session.FamilyID)
+	require.False(t, session.ParentID.Valid)
+	require.False(t, session.RotatedAt.Valid)
+	return session
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	first := createRandomSession(t, user.Username)
+
+	next := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    first.UserAgent,
+		ClientIp:     first.ClientIp,
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     first.FamilyID,
+		ParentID:     uuid.NullUUID{UUID: first.ID, Valid: true},
+	}
+	second, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{PreviousID: first.ID, Next: next})
+	require.NoError(t, err)
+	require.Equal(t, first.FamilyID, second.FamilyID)
+	require.Equal(t, first.ID, second.ParentID.UUID)
+
+	rotated, err := testQueries.GetSession(context.Background(), first.ID)
+	require.NoError(t, err)
+	require.True(t, rotated.RotatedAt.Valid)
+
+	//a session can only be rotated once
+	next.ID = uuid.New()
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{PreviousID: first.ID, Next: next})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetSession(context.Background(), next.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = testQueries.BlockSessionFamily(context.Background(), first.FamilyID)
+	require.NoError(t, err)
+	for _, id := range []uuid.UUID{first.ID, second.ID} {
+		session, err := testQueries.GetSession(context.Background(), id)
+		require.NoError(t, err)
+		require.True(t, session.IsBlocked)
+	}
+}
+
+func TestRevokeSessions(t *testing.T) {
+	user := createRandomUser(t)
+	current := createRandomSession(t, user.Username)
+	other := createRandomSession(t, user.Username)
+	stranger := createRandomSession(t, createRandomUser(t).Username)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	//a family can only be revoked by its owner
+	n, err := testQueries.BlockUserSessionFamily(context.Background(), BlockUserSessionFamilyParams{
+		FamilyID: stranger.FamilyID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	err = testQueries.BlockOtherSessionFamilies(context.Background(), BlockOtherSessionFamiliesParams{
+		Username: user.Username,
+		FamilyID: current.FamilyID,
+	})
+	require.NoError(t, err)
+
+	sessions, err = testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, current.ID, sessions[0].ID)
+
+	blocked, err := testQueries.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+}
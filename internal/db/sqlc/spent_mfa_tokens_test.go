@@ -0,0 +1,37 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpendMFAToken(t *testing.T) {
+	user := createRandomUser(t)
+	arg := SpendMFATokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+
+	n, err := testQueries.SpendMFAToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	//a token is spent once
+	n, err = testQueries.SpendMFAToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	//expired tokens can't be verified anymore, so their rows are dropped
+	expired := SpendMFATokenParams{ID: uuid.New(), Username: user.Username, ExpiresAt: time.Now().Add(-time.Minute)}
+	_, err = testQueries.SpendMFAToken(context.Background(), expired)
+	require.NoError(t, err)
+	require.NoError(t, testQueries.DeleteExpiredMFATokens(context.Background()))
+	n, err = testQueries.SpendMFAToken(context.Background(), expired)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/google/uuid"
 )
 
 type SQLStore struct {
@@ -14,15 +18,18 @@ type SQLStore struct {
 func NewStore(db *sql.DB) *SQLStore {
 	return &SQLStore{
 		DB:      db,
-		Queries: New(db),
+		Queries: New(WithTracing(db)),
 	}
 }
-func (s *SQLStore) execTX(ctx context.Context, fn func(*Queries) error) error {
+func (s *SQLStore) execTX(ctx context.Context, fn func(*Queries) error) (err error) {
+	ctx, span := tracer.Start(ctx, "sqlc.execTX")
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	q := New(tx)
+	q := New(WithTracing(tx))
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -33,10 +40,24 @@ func (s *SQLStore) execTX(ctx context.Context, fn func(*Queries) error) error {
 	return tx.Commit()
 }
 
+// AuditTx runs fn and records event in the same transaction, so the record exists exactly when
+// the action took effect. fn may fill in the event, e.g. its subject once a row has an id.
+func (store *SQLStore) AuditTx(ctx context.Context, event CreateAuditEventParams, fn func(q *Queries, event *CreateAuditEventParams) error) error {
+	return store.execTX(ctx, func(q *Queries) error {
+		if err := fn(q, &event); err != nil {
+			return err
+		}
+		_, err := q.CreateAuditEvent(ctx, event)
+		return err
+	})
+}
+
 type TransferTxParams struct {
 	FromAccountID int64
 	ToAccountID   int64
 	Amount        int64
+	// Audit is recorded in the transfer's transaction when set.
+	Audit *CreateAuditEventParams
 }
 
 type TransferTxResult struct {
@@ -55,7 +76,11 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (*T
 	err := store.execTX(ctx, func(q *Queries) error {
 		var err error
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+		})
 		if err != nil {
 			return err
 		}
@@ -86,6 +111,10 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (*T
 		if err != nil {
 			return err
 		}
+		if arg.Audit != nil {
+			_, err = q.CreateAuditEvent(ctx, *arg.Audit)
+			return err
+		}
 		return nil
 	})
 
@@ -117,3 +146,206 @@ func addMoney(
 	}
 	return
 }
+
+type RotateSessionTxParams struct {
+	PreviousID uuid.UUID
+	Next       CreateSessionParams
+}
+
+// RotateSessionTx retires the session PreviousID and creates its successor in one transaction.
+// Marking the previous session fails with sql.ErrNoRows when it was already rotated or blocked,
+// i.e. its refresh token is being reused.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (*Session, error) {
+	var session *Session
+
+	err := store.execTX(ctx, func(q *Queries) error {
+		_, err := q.MarkSessionRotated(ctx, arg.PreviousID)
+		if err != nil {
+			return err
+		}
+
+		session, err = q.CreateSession(ctx, arg.Next)
+		return err
+	})
+	return session, err
+}
+
+type VerifyEmailTxParams struct {
+	EmailID        int64
+	SecretCodeHash string
+}
+
+// VerifyEmailTx consumes a verification code and marks the user's email verified. It fails with
+// sql.ErrNoRows when the code is wrong, used or expired, or the user has changed their email since.
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (*VerifyEmail, error) {
+	var verifyEmail *VerifyEmail
+
+	err := store.execTX(ctx, func(q *Queries) error {
+		var err error
+		verifyEmail, err = q.UseVerifyEmail(ctx, UseVerifyEmailParams{
+			ID:             arg.EmailID,
+			SecretCodeHash: arg.SecretCodeHash,
+		})
+		if err != nil {
+			return err
+		}
+
+		n, err := q.MarkEmailVerified(ctx, MarkEmailVerifiedParams{
+			Username: verifyEmail.Username,
+			Email:    verifyEmail.Email,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	return verifyEmail, err
+}
+
+type ResetPasswordTxParams struct {
+	TokenHash      string
+	HashedPassword string
+	// Audit is recorded in the reset's transaction when set.
+	Audit *CreateAuditEventParams
+}
+
+// ResetPasswordTx consumes a reset token, sets the new password and signs the user out
+// everywhere. It fails with sql.ErrNoRows when the token is unknown, used or expired.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (*User, error) {
+	var user *User
+
+	err := store.execTX(ctx, func(q *Queries) error {
+		reset, err := q.UsePasswordReset(ctx, arg.TokenHash)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UpdateUser(ctx, UpdateUserParams{
+			HashedPassword:    sql.NullString{String: arg.HashedPassword, Valid: true},
+			PasswordChangedAt: sql.NullTime{Time: time.Now(), Valid: true},
+			Username:          reset.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		//any other outstanding link for the account is void too
+		if err := q.ExpireUserPasswordResets(ctx, reset.Username); err != nil {
+			return err
+		}
+		if err := q.BlockUserSessions(ctx, reset.Username); err != nil {
+			return err
+		}
+		if arg.Audit != nil {
+			_, err = q.CreateAuditEvent(ctx, *arg.Audit)
+			return err
+		}
+		return nil
+	})
+	return user, err
+}
+
+type ConfirmTOTPTxParams struct {
+	Username           string
+	Step               int64
+	RecoveryCodeHashes []string
+	// Audit is recorded in the confirmation's transaction when set.
+	Audit *CreateAuditEventParams
+}
+
+// ConfirmTOTPTx turns on a pending authenticator and replaces the user's recovery codes.
+// It fails with sql.ErrNoRows when there is no pending authenticator to confirm.
+func (store *SQLStore) ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) error {
+	return store.execTX(ctx, func(q *Queries) error {
+		n, err := q.ConfirmUserTOTP(ctx, ConfirmUserTOTPParams{
+			Username:     arg.Username,
+			LastUsedStep: arg.Step,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return sql.ErrNoRows
+		}
+
+		if err := q.DeleteRecoveryCodes(ctx, arg.Username); err != nil {
+			return err
+		}
+		for _, hash := range arg.RecoveryCodeHashes {
+			if err := q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username: arg.Username,
+				CodeHash: hash,
+			}); err != nil {
+				return err
+			}
+		}
+		if arg.Audit != nil {
+			_, err = q.CreateAuditEvent(ctx, *arg.Audit)
+			return err
+		}
+		return nil
+	})
+}
+
+type DisableTOTPTxParams struct {
+	Username string
+	// Audit is recorded in the removal's transaction when set.
+	Audit *CreateAuditEventParams
+}
+
+// DisableTOTPTx removes the user's authenticator together with its recovery codes.
+func (store *SQLStore) DisableTOTPTx(ctx context.Context, arg DisableTOTPTxParams) error {
+	return store.execTX(ctx, func(q *Queries) error {
+		if err := q.DeleteRecoveryCodes(ctx, arg.Username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserTOTP(ctx, arg.Username); err != nil {
+			return err
+		}
+		if arg.Audit != nil {
+			_, err := q.CreateAuditEvent(ctx, *arg.Audit)
+			return err
+		}
+		return nil
+	})
+}
+
+type RotateAPIKeyTxParams struct {
+	ServiceAccount string
+	Prefix         string
+	KeyHash        string
+	// Audit is recorded in the rotation's transaction when set.
+	Audit *CreateAuditEventParams
+}
+
+// RotateAPIKeyTx stores a new key for the service account and revokes all of its other keys.
+func (store *SQLStore) RotateAPIKeyTx(ctx context.Context, arg RotateAPIKeyTxParams) (*ApiKey, error) {
+	var key *ApiKey
+	err := store.execTX(ctx, func(q *Queries) error {
+		var err error
+		key, err = q.CreateAPIKey(ctx, CreateAPIKeyParams{
+			ServiceAccount: arg.ServiceAccount,
+			Prefix:         arg.Prefix,
+			KeyHash:        arg.KeyHash,
+		})
+		if err != nil {
+			return err
+		}
+		err = q.RevokeOtherAPIKeys(ctx, RevokeOtherAPIKeysParams{
+			ServiceAccount: arg.ServiceAccount,
+			ID:             key.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if arg.Audit != nil {
+			_, err = q.CreateAuditEvent(ctx, *arg.Audit)
+			return err
+		}
+		return nil
+	})
+	return key, err
+}
@@ -0,0 +1,73 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/0xOnah/bank/internal/db/sqlc")
+
+// tracedDB wraps a DBTX so every generated query runs inside its own span.
+type tracedDB struct {
+	db DBTX
+}
+
+// WithTracing wraps db (a *sql.DB or *sql.Tx) so the queries built on it are traced.
+func WithTracing(db DBTX) DBTX {
+	if _, ok := db.(*tracedDB); ok {
+		return db
+	}
+	return &tracedDB{db: db}
+}
+
+// queryName pulls the sqlc query name out of the "-- name: GetUser :one" header.
+func queryName(query string) string {
+	line, _, _ := strings.Cut(query, "\n")
+	if name, ok := strings.CutPrefix(line, "-- name: "); ok {
+		name, _, _ = strings.Cut(name, " ")
+		return name
+	}
+	return "query"
+}
+
+func (t *tracedDB) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "sqlc."+queryName(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", queryName(query)),
+		),
+	)
+}
+
+func (t *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	result, err := t.db.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (t *tracedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return t.db.PrepareContext(ctx, query)
+}
+
+func (t *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query)
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (t *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := t.start(ctx, query)
+	row := t.db.QueryRowContext(ctx, query, args...)
+	//scan errors (including sql.ErrNoRows) surface to the caller, not here
+	tracing.End(span, row.Err())
+	return row
+}
@@ -0,0 +1,75 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	pending, err := testQueries.CreatePendingTOTP(context.Background(), CreatePendingTOTPParams{
+		Username: user.Username,
+		Secret:   util.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.False(t, pending.ConfirmedAt.Valid)
+
+	//enrolling again before confirming replaces the pending secret
+	pending, err = testQueries.CreatePendingTOTP(context.Background(), CreatePendingTOTPParams{
+		Username: user.Username,
+		Secret:   util.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	codes := []string{util.RandomString(64), util.RandomString(64)}
+	err = store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{
+		Username:           user.Username,
+		Step:               100,
+		RecoveryCodeHashes: codes,
+	})
+	require.NoError(t, err)
+
+	confirmed, err := testQueries.GetUserTOTP(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, pending.Secret, confirmed.Secret)
+	require.True(t, confirmed.ConfirmedAt.Valid)
+	require.Equal(t, int64(100), confirmed.LastUsedStep)
+
+	//a confirmed authenticator is neither replaced nor confirmed twice
+	_, err = testQueries.CreatePendingTOTP(context.Background(), CreatePendingTOTPParams{
+		Username: user.Username,
+		Secret:   util.RandomString(32),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	err = store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{Username: user.Username, Step: 101})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	//steps only move forward
+	n, err := testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, LastUsedStep: 100})
+	require.NoError(t, err)
+	require.Zero(t, n)
+	n, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, LastUsedStep: 101})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	//recovery codes work once
+	n, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, CodeHash: codes[0]})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	n, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, CodeHash: codes[0]})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	require.NoError(t, store.DisableTOTPTx(context.Background(), DisableTOTPTxParams{Username: user.Username}))
+	_, err = testQueries.GetUserTOTP(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	n, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, CodeHash: codes[1]})
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
@@ -18,7 +18,7 @@ INSERT INTO users (
     email
 )
 VALUES ($1, $2, $3, $4)
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role
 `
 
 type CreateUserParams struct {
@@ -43,12 +43,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (*User,
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
 	)
 	return &i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -62,6 +64,8 @@ func (q *Queries) GetUser(ctx context.Context, username string) (*User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
 	)
 	return &i, err
 }
@@ -72,9 +76,12 @@ SET
     full_name = coalesce($1, full_name),
     hashed_password = coalesce($2, hashed_password),
     email = coalesce($3, email),
-    password_changed_at = coalesce($4, password_changed_at)
-WHERE username = $5
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+    password_changed_at = coalesce($4, password_changed_at),
+    role = coalesce($5, role),
+    -- a new address has to be verified again
+    is_email_verified = is_email_verified AND coalesce($3 = email, true)
+WHERE username = $6
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role
 `
 
 type UpdateUserParams struct {
@@ -82,6 +89,7 @@ type UpdateUserParams struct {
 	HashedPassword    sql.NullString
 	Email             sql.NullString
 	PasswordChangedAt sql.NullTime
+	Role              sql.NullString
 	Username          string
 }
 
@@ -91,6 +99,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (*User,
 		arg.HashedPassword,
 		arg.Email,
 		arg.PasswordChangedAt,
+		arg.Role,
 		arg.Username,
 	)
 	var i User
@@ -101,6 +110,69 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (*User,
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
 	)
 	return &i, err
 }
+
+const markEmailVerified = `-- name: MarkEmailVerified :execrows
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1 AND email = $2
+`
+
+type MarkEmailVerifiedParams struct {
+	Username string
+	Email    string
+}
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markEmailVerified, arg.Username, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+	)
+	return &i, err
+}
+
+const rehashPassword = `-- name: RehashPassword :execrows
+UPDATE users
+SET hashed_password = $1
+WHERE username = $2 AND hashed_password = $3
+`
+
+type RehashPasswordParams struct {
+	NewHash  string
+	Username string
+	OldHash  string
+}
+
+// only replaces the hash it was computed from, so a password changed meanwhile is kept
+func (q *Queries) RehashPassword(ctx context.Context, arg RehashPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rehashPassword, arg.NewHash, arg.Username, arg.OldHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -2,8 +2,11 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"testing"
 
+	"github.com/0xOnah/bank/internal/sdk/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,3 +27,69 @@ func TestGetUsers(t *testing.T) {
 	require.True(t, user.PasswordChangedAt.IsZero())
 	require.NotZero(t, user.CreatedAt)
 }
+
+func TestUpdateUserEmailResetsVerification(t *testing.T) {
+	user := createRandomUser(t)
+	_, err := testQueries.MarkEmailVerified(context.Background(), MarkEmailVerifiedParams{Username: user.Username, Email: user.Email})
+	require.NoError(t, err)
+
+	//setting the same address keeps it verified
+	updated, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: user.Email, Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, updated.IsEmailVerified)
+
+	updated, err = testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: fmt.Sprintf("%s@gmail.com", util.RandomString(10)), Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.False(t, updated.IsEmailVerified)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	user := createRandomUser(t)
+	require.Equal(t, "customer", user.Role)
+
+	updated, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Role:     sql.NullString{String: "teller", Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "teller", updated.Role)
+
+	_, err = testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Role:     sql.NullString{String: "root", Valid: true},
+		Username: user.Username,
+	})
+	require.Error(t, err)
+}
+
+func TestRehashPassword(t *testing.T) {
+	user := createRandomUser(t)
+
+	//a stale hash means the password changed meanwhile, nothing is written
+	n, err := testQueries.RehashPassword(context.Background(), RehashPasswordParams{
+		NewHash:  util.RandomString(32),
+		Username: user.Username,
+		OldHash:  util.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	newHash := util.RandomString(32)
+	n, err = testQueries.RehashPassword(context.Background(), RehashPasswordParams{
+		NewHash:  newHash,
+		Username: user.Username,
+		OldHash:  user.HashedPassword,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	updated, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, newHash, updated.HashedPassword)
+	require.Equal(t, user.PasswordChangedAt, updated.PasswordChangedAt)
+}
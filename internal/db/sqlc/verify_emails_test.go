@@ -0,0 +1,44 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.False(t, user.IsEmailVerified)
+
+	verifyEmail, err := testQueries.CreateVerifyEmail(context.Background(), CreateVerifyEmailParams{
+		Username:       user.Username,
+		Email:          user.Email,
+		SecretCodeHash: util.RandomString(64),
+	})
+	require.NoError(t, err)
+	require.False(t, verifyEmail.IsUsed)
+	require.True(t, verifyEmail.ExpiredAt.After(verifyEmail.CreatedAt))
+
+	last, err := testQueries.GetLastVerifyEmail(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, verifyEmail.ID, last.ID)
+
+	_, err = store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{EmailID: verifyEmail.ID, SecretCodeHash: "wrong"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	used, err := store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{EmailID: verifyEmail.ID, SecretCodeHash: verifyEmail.SecretCodeHash})
+	require.NoError(t, err)
+	require.True(t, used.IsUsed)
+
+	verified, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, verified.IsEmailVerified)
+
+	//codes are single use
+	_, err = store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{EmailID: verifyEmail.ID, SecretCodeHash: verifyEmail.SecretCodeHash})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
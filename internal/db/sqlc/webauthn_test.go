@@ -0,0 +1,134 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAuthnCredentials(t *testing.T) {
+	user := createRandomUser(t)
+
+	handle := []byte(util.RandomString(32))
+	saved, err := testQueries.SaveWebAuthnUser(context.Background(), SaveWebAuthnUserParams{
+		Username:   user.Username,
+		UserHandle: handle,
+	})
+	require.NoError(t, err)
+	require.Equal(t, handle, saved.UserHandle)
+
+	//a user keeps the handle they were first given
+	again, err := testQueries.SaveWebAuthnUser(context.Background(), SaveWebAuthnUserParams{
+		Username:   user.Username,
+		UserHandle: []byte(util.RandomString(32)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, handle, again.UserHandle)
+
+	found, err := testQueries.GetWebAuthnUserByHandle(context.Background(), handle)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, found.Username)
+
+	arg := CreateWebAuthnCredentialParams{
+		Username:        user.Username,
+		Name:            "laptop",
+		CredentialID:    []byte(util.RandomString(16)),
+		PublicKey:       []byte(util.RandomString(64)),
+		AttestationType: "none",
+		Transports:      []string{"internal", "hybrid"},
+		Aaguid:          make([]byte, 16),
+		BackupEligible:  true,
+	}
+	credential, err := testQueries.CreateWebAuthnCredential(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.CredentialID, credential.CredentialID)
+	require.Equal(t, arg.Transports, credential.Transports)
+	require.False(t, credential.LastUsedAt.Valid)
+
+	_, err = testQueries.CreateWebAuthnCredential(context.Background(), arg)
+	require.Error(t, err)
+
+	err = testQueries.UseWebAuthnCredential(context.Background(), UseWebAuthnCredentialParams{
+		CredentialID: arg.CredentialID,
+		SignCount:    3,
+		BackupState:  true,
+	})
+	require.NoError(t, err)
+	credentials, err := testQueries.ListWebAuthnCredentials(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	require.EqualValues(t, 3, credentials[0].SignCount)
+	require.True(t, credentials[0].BackupState)
+	require.True(t, credentials[0].LastUsedAt.Valid)
+
+	//only the owner can delete a passkey
+	n, err := testQueries.DeleteWebAuthnCredential(context.Background(), DeleteWebAuthnCredentialParams{ID: credential.ID, Username: createRandomUser(t).Username})
+	require.NoError(t, err)
+	require.Zero(t, n)
+	n, err = testQueries.DeleteWebAuthnCredential(context.Background(), DeleteWebAuthnCredentialParams{ID: credential.ID, Username: user.Username})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+}
+
+func TestTakeWebAuthnCeremony(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := CreateWebAuthnCeremonyParams{
+		ID:          uuid.New(),
+		Kind:        "registration",
+		Username:    sql.NullString{String: user.Username, Valid: true},
+		SessionData: json.RawMessage(`{"challenge":"abc"}`),
+		ExpiresAt:   time.Now().Add(5 * time.Minute),
+	}
+	require.NoError(t, testQueries.CreateWebAuthnCeremony(context.Background(), arg))
+
+	_, err := testQueries.TakeWebAuthnCeremony(context.Background(), TakeWebAuthnCeremonyParams{ID: arg.ID, Kind: "login"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	ceremony, err := testQueries.TakeWebAuthnCeremony(context.Background(), TakeWebAuthnCeremonyParams{ID: arg.ID, Kind: "registration"})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, ceremony.Username.String)
+	require.JSONEq(t, `{"challenge":"abc"}`, string(ceremony.SessionData))
+
+	//a ceremony finishes once
+	_, err = testQueries.TakeWebAuthnCeremony(context.Background(), TakeWebAuthnCeremonyParams{ID: arg.ID, Kind: "registration"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	expired := arg
+	expired.ID = uuid.New()
+	expired.Username = sql.NullString{}
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, testQueries.CreateWebAuthnCeremony(context.Background(), expired))
+	_, err = testQueries.TakeWebAuthnCeremony(context.Background(), TakeWebAuthnCeremonyParams{ID: expired.ID, Kind: "registration"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteExpiredWebAuthnCeremonies(t *testing.T) {
+	live := CreateWebAuthnCeremonyParams{
+		ID:          uuid.New(),
+		Kind:        "login",
+		SessionData: json.RawMessage(`{"challenge":"abc"}`),
+		ExpiresAt:   time.Now().Add(5 * time.Minute),
+	}
+	expired := live
+	expired.ID = uuid.New()
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, testQueries.CreateWebAuthnCeremony(context.Background(), live))
+	require.NoError(t, testQueries.CreateWebAuthnCeremony(context.Background(), expired))
+
+	require.NoError(t, testQueries.DeleteExpiredWebAuthnCeremonies(context.Background()))
+
+	var n int
+	err := testDB.QueryRowContext(context.Background(), "SELECT count(*) FROM webauthn_ceremonies WHERE id = $1", expired.ID).Scan(&n)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	_, err = testQueries.TakeWebAuthnCeremony(context.Background(), TakeWebAuthnCeremonyParams{ID: live.ID, Kind: "login"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What an account consent can let a client read.
+const (
+	AccountPermissionBalances     = "balances"
+	AccountPermissionTransactions = "transactions"
+)
+
+// Account consent statuses, derived from the revocation and expiry times.
+const (
+	AccountConsentValid   = "valid"
+	AccountConsentExpired = "expired"
+	AccountConsentRevoked = "revoked"
+)
+
+// AccountConsent lets an OAuth client read some of a user's accounts until it expires or the
+// user revokes it. The client names the consent on every read.
+type AccountConsent struct {
+	ID          uuid.UUID
+	Username    string
+	ClientID    string
+	AccountIDs  []int64
+	Permissions []string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	RevokedAt   time.Time
+}
+
+func (c *AccountConsent) Status() string {
+	switch {
+	case !c.RevokedAt.IsZero():
+		return AccountConsentRevoked
+	case !c.ExpiresAt.After(time.Now()):
+		return AccountConsentExpired
+	}
+	return AccountConsentValid
+}
+
+func (c *AccountConsent) Covers(accountID int64) bool {
+	return slices.Contains(c.AccountIDs, accountID)
+}
+
+func (c *AccountConsent) Allows(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
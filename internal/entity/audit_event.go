@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// AuditEvent records who did what to which resource, and from where.
+type AuditEvent struct {
+	ID     int64
+	Action string
+	// Actor is the user or service account that acted, or the username tried on a failed login.
+	Actor string
+	// Subject is what was acted on, like "user:hector" or "account:12".
+	Subject   string
+	ClientIP  string
+	UserAgent string
+	RequestID string
+	// Details hold action specific values. Never put secrets or new field values here.
+	Details   map[string]string
+	CreatedAt time.Time
+}
+
+// AuditEventFilter narrows a listing of the audit log; zero fields don't filter.
+type AuditEventFilter struct {
+	Actor   string
+	Subject string
+	Action  string
+	Since   time.Time
+	Until   time.Time
+	// BeforeID pages back from the last event seen.
+	BeforeID int64
+	PageSize int
+}
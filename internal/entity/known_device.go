@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// KnownDevice is a user agent a user has signed in with from an ip range. Only confirmed
+// devices count as recognized.
+type KnownDevice struct {
+	ID            int64
+	Username      string
+	UserAgentHash string
+	IPRange       string
+	UserAgent     string
+	LastIP        string
+	ConfirmedAt   time.Time
+	CreatedAt     time.Time
+	LastSeenAt    time.Time
+}
+
+func (d *KnownDevice) Confirmed() bool {
+	return !d.ConfirmedAt.IsZero()
+}
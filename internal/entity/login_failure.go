@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// LoginFailure counts recent failed logins for a subject, a username or a client IP, and
+// whether it is locked out.
+type LoginFailure struct {
+	Subject        string
+	FailedAttempts int
+	Lockouts       int
+	LockedUntil    time.Time
+	LastFailedAt   time.Time
+}
+
+func (f *LoginFailure) Locked() bool {
+	return time.Now().Before(f.LockedUntil)
+}
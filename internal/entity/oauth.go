@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient is a partner application registered to act for users through OAuth. Public
+// clients, such as mobile apps, cannot keep a secret and have an empty SecretHash.
+type OAuthClient struct {
+	ID           string
+	Name         string
+	SecretHash   string
+	RedirectURIs []string
+	// Scopes and GrantTypes are the most the client may ask for.
+	Scopes     []string
+	GrantTypes []string
+	CreatedBy  string
+	CreatedAt  time.Time
+	RevokedAt  time.Time
+}
+
+func (c *OAuthClient) Public() bool {
+	return c.SecretHash == ""
+}
+
+func (c *OAuthClient) Revoked() bool {
+	return !c.RevokedAt.IsZero()
+}
+
+// OAuthConsent records the scopes a user let a client use on their behalf.
+type OAuthConsent struct {
+	ID        int64
+	Username  string
+	ClientID  string
+	Scopes    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OAuthAuthorizationCode is a code handed to a client after the user consented, to be exchanged
+// once for tokens. CodeChallenge is the PKCE S256 challenge the exchange must answer.
+// RedirectURISupplied says whether the authorization request named RedirectURI, rather than it
+// being the client's only registered uri.
+type OAuthAuthorizationCode struct {
+	CodeHash            string
+	ClientID            string
+	Username            string
+	RedirectURI         string
+	RedirectURISupplied bool
+	Scopes              []string
+	CodeChallenge       string
+	ExpiresAt           time.Time
+}
+
+// OAuthClientToken is an access token a client got for itself with the client credentials grant.
+type OAuthClientToken struct {
+	ID        uuid.UUID
+	ClientID  string
+	Scopes    []string
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+func (t *OAuthClientToken) Revoked() bool {
+	return !t.RevokedAt.IsZero()
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// ServiceAccount is a machine client, such as the reconciliation system. It authenticates with
+// API keys and can only call the methods in Scopes on the accounts in AccountIDs.
+type ServiceAccount struct {
+	Name        string
+	Description string
+	Scopes      []string
+	AccountIDs  []int64
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// APIKey is one credential of a service account. Only a hash of the key is kept; Prefix
+// identifies it in lists and logs.
+type APIKey struct {
+	ID             int64
+	ServiceAccount string
+	Prefix         string
+	KeyHash        string
+	CreatedAt      time.Time
+	LastUsedAt     time.Time
+	RevokedAt      time.Time
+}
+
+func (k *APIKey) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
@@ -14,6 +14,13 @@ type Session struct {
 	ClientIp     string    `json:"client_ip"`
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	// FamilyID is shared by every session rotated from the same login.
+	FamilyID  uuid.UUID `json:"family_id"`
+	ParentID  uuid.UUID `json:"parent_id"`
+	RotatedAt time.Time `json:"rotated_at"`
+	// ClientID is the OAuth client the session was granted to, empty for the user's own logins.
+	ClientID string `json:"client_id"`
 }
 
 func (ses *Session) IsSessionBlocked() bool {
@@ -31,3 +38,8 @@ func (ses *Session) RefreshTokenCheck(token string) bool {
 func (ses *Session) IsSessionExpired() bool {
 	return time.Now().After(ses.ExpiresAt)
 }
+
+// IsRotated reports whether the session's refresh token has been exchanged for a newer one.
+func (ses *Session) IsRotated() bool {
+	return !ses.RotatedAt.IsZero()
+}
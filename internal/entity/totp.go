@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// UserTOTP is a user's authenticator app secret. It only guards logins once ConfirmedAt is set.
+type UserTOTP struct {
+	Username string
+	// Secret is sealed; it is never stored or returned in the clear.
+	Secret       string
+	ConfirmedAt  time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+func (t *UserTOTP) Enabled() bool {
+	return !t.ConfirmedAt.IsZero()
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/0xOnah/bank/internal/sdk/validator"
 )
 
+// DefaultUserRole is the role new users start with, the same as the users.role column default.
+const DefaultUserRole = "customer"
+
 // User represents a user entity in the domain.
 type User struct {
 	Username          string
@@ -19,6 +22,8 @@ type User struct {
 	Email             Email
 	CreatedAt         time.Time
 	PasswordChangedAt time.Time
+	IsEmailVerified   bool
+	Role              string
 }
 
 type Email struct {
@@ -38,6 +43,14 @@ func (e Email) String() string {
 	return e.value
 }
 
+// ValidatePassword checks password against the password rules, reporting problems under key.
+func ValidatePassword(v *validator.Validator, key, password string) {
+	v.Check(password != "", key, "cannot be empty")
+	v.Check(len(password) >= 8, key, "must be at least 8 characters")
+	//argon2id takes any length, this only keeps request sizes sane
+	v.Check(len(password) <= 256, key, "must not exceed 256 characters")
+}
+
 func NewUser(username, password, fullName, email string) (User, error) {
 	v := validator.NewValidator()
 
@@ -45,10 +58,7 @@ func NewUser(username, password, fullName, email string) (User, error) {
 	v.Check(username != "", "username", "cannot be empty")
 	v.Check(len(username) >= 3 && len(username) <= 30, "username", "must be between 3 and 30 characters")
 
-	// Validate password
-	v.Check(password != "", "password", "cannot be empty")
-	v.Check(len(password) >= 8, "password", "must be at least 8 characters")
-	v.Check(len(password) <= 72, "password", "must not exceed 72 characters")
+	ValidatePassword(v, "password", password)
 
 	// Validate fullName
 	v.Check(fullName != "", "full_name", "cannot be empty")
@@ -79,5 +89,6 @@ func NewUser(username, password, fullName, email string) (User, error) {
 		Email:             emailObj,
 		CreatedAt:         createdAt,
 		PasswordChangedAt: pwdChangedAt,
+		Role:              DefaultUserRole,
 	}, nil
 }
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// VerifyEmail is a one-off code emailed to prove a user owns their address. Only the code's
+// hash is kept.
+type VerifyEmail struct {
+	ID             int64
+	Username       string
+	Email          string
+	SecretCodeHash string
+	IsUsed         bool
+	CreatedAt      time.Time
+	ExpiredAt      time.Time
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential is a passkey a user registered. Only its public key is ever stored.
+type WebAuthnCredential struct {
+	ID       int64
+	Username string
+	// Name is a label the user chose, like "work laptop".
+	Name            string
+	CredentialID    []byte
+	PublicKey       []byte
+	AttestationType string
+	Transports      []string
+	AAGUID          []byte
+	// BackupEligible is fixed at registration; BackupState tracks whether the passkey is
+	// currently synced to other devices.
+	BackupEligible bool
+	BackupState    bool
+	SignCount      uint32
+	CreatedAt      time.Time
+	LastUsedAt     time.Time
+}
+
+// WebAuthnCeremony keeps the challenge handed out when a passkey registration or login
+// begins until the authenticator's answer comes back. Username is empty for logins.
+type WebAuthnCeremony struct {
+	ID          uuid.UUID
+	Kind        string
+	Username    string
+	SessionData []byte
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,79 @@
+// Package audit names the actions recorded in the audit log and carries the client a request
+// came from through its context, so events can be recorded far from the transport.
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/google/uuid"
+)
+
+const (
+	ActionLogin                = "user.login"
+	ActionLoginFailed          = "user.login_failed"
+	ActionUserCreate           = "user.create"
+	ActionUserUpdate           = "user.update"
+	ActionPasswordReset        = "user.password_reset"
+	ActionUserUnlock           = "user.unlock"
+	ActionTOTPEnable           = "totp.enable"
+	ActionTOTPDisable          = "totp.disable"
+	ActionDeviceConfirm        = "device.confirm"
+	ActionAccountCreate        = "account.create"
+	ActionTransfer             = "transfer.create"
+	ActionSessionRevoke        = "session.revoke"
+	ActionSessionRevokeOthers  = "session.revoke_others"
+	ActionPasskeyCreate        = "passkey.create"
+	ActionPasskeyDelete        = "passkey.delete"
+	ActionOAuthClientCreate    = "oauth_client.create"
+	ActionOAuthClientRevoke    = "oauth_client.revoke"
+	ActionOAuthConsentGrant    = "oauth_consent.grant"
+	ActionOAuthConsentRevoke   = "oauth_consent.revoke"
+	ActionAccountConsentGrant  = "account_consent.grant"
+	ActionAccountConsentRevoke = "account_consent.revoke"
+	ActionServiceAccountCreate = "service_account.create"
+	ActionAPIKeyRotate         = "api_key.rotate"
+	ActionAPIKeyRevoke         = "api_key.revoke"
+)
+
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type ctxKey struct{}
+
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, ctxKey{}, client)
+}
+
+// ClientFromContext returns the client stored in ctx, or an empty one.
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(ctxKey{}).(Client)
+	return client
+}
+
+// NewEvent starts an event with the client and request id of ctx filled in.
+func NewEvent(ctx context.Context, action, actor, subject string) entity.AuditEvent {
+	client := ClientFromContext(ctx)
+	return entity.AuditEvent{
+		Action:    action,
+		Actor:     actor,
+		Subject:   subject,
+		ClientIP:  client.IP,
+		UserAgent: client.UserAgent,
+		RequestID: requestid.FromContext(ctx),
+	}
+}
+
+func UserSubject(username string) string        { return "user:" + username }
+func AccountSubject(id int64) string            { return fmt.Sprintf("account:%d", id) }
+func SessionSubject(id uuid.UUID) string        { return "session:" + id.String() }
+func PasskeySubject(id int64) string            { return fmt.Sprintf("passkey:%d", id) }
+func OAuthClientSubject(id string) string       { return "oauth_client:" + id }
+func AccountConsentSubject(id uuid.UUID) string { return "account_consent:" + id.String() }
+func DeviceSubject(id int64) string             { return fmt.Sprintf("device:%d", id) }
+func ServiceAccountSubject(name string) string  { return "service_account:" + name }
+func APIKeySubject(prefix string) string        { return "api_key:" + prefix }
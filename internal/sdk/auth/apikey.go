@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key so they can be told apart from tokens and found by secret scanners.
+const APIKeyPrefix = "bk_"
+
+// ServiceAccountPrefix starts the username an API key authenticates as. Usernames can't hold a
+// ":", so a service account never acts as the user it shares a name with.
+const ServiceAccountPrefix = "sa:"
+
+const (
+	apiKeyIDLength     = 8
+	apiKeyCheckTimeout = 2 * time.Second
+)
+
+var (
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	apiKeyIDEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// NewAPIKey returns a key like "bk_k3j9dx2m_<secret>" and its prefix "k3j9dx2m", which is stored
+// in the clear to find the key again. Only HashSecret of the whole key is stored.
+func NewAPIKey() (key, prefix string, err error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = strings.ToLower(apiKeyIDEncoding.EncodeToString(b))
+	secret, err := NewSecret()
+	if err != nil {
+		return "", "", err
+	}
+	return APIKeyPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// ParseAPIKey returns the prefix of a key made by NewAPIKey.
+func ParseAPIKey(key string) (prefix string, ok bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != apiKeyIDLength || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// APIKeyGrant is what a service account's key allows. Scopes are the methods it may call, named
+// like rate limit policies: grpc full method names, or "METHOD /path" for http routes.
+type APIKeyGrant struct {
+	Prefix     string
+	Scopes     []string
+	AccountIDs []int64
+}
+
+func (g *APIKeyGrant) Allows(scope string) bool {
+	return slices.Contains(g.Scopes, scope)
+}
+
+func (g *APIKeyGrant) CanAccessAccount(id int64) bool {
+	return slices.Contains(g.AccountIDs, id)
+}
+
+// AllowsScope reports whether the caller may call the method named scope. Only API keys and
+// OAuth tokens are limited.
+func (p *Payload) AllowsScope(scope string) bool {
+	switch {
+	case p.APIKey != nil:
+		return p.APIKey.Allows(scope)
+	case p.ClientID != "":
+		return oauthAllows(p.OAuthScopes(), scope)
+	}
+	return true
+}
+
+// APIKeyVerifier looks up an API key and returns the payload of its service account.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*Payload, error)
+}
+
+type apiKeyAuthenticator struct {
+	Authenticator
+	keys APIKeyVerifier
+}
+
+// WithAPIKeys wraps a to also accept service account API keys wherever it accepts bearer tokens.
+func WithAPIKeys(a Authenticator, keys APIKeyVerifier) Authenticator {
+	return &apiKeyAuthenticator{Authenticator: a, keys: keys}
+}
+
+func (ak *apiKeyAuthenticator) VerifyToken(token string) (*Payload, error) {
+	return ak.VerifyTokenContext(context.Background(), token)
+}
+
+// VerifyTokenContext looks keys up within ctx, and within apiKeyCheckTimeout at most. Other
+// tokens go to the wrapped authenticator with ctx.
+func (ak *apiKeyAuthenticator) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	if !strings.HasPrefix(token, APIKeyPrefix) {
+		return VerifyTokenContext(ctx, ak.Authenticator, token)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, apiKeyCheckTimeout)
+	defer cancel()
+	payload, err := ak.keys.VerifyAPIKey(ctx, token)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	return payload, nil
+}
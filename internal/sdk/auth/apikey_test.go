@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type stubKeys map[string]*Payload
+
+func (s stubKeys) VerifyAPIKey(ctx context.Context, key string) (*Payload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if p, ok := s[key]; ok {
+		return p, nil
+	}
+	return nil, errors.New("unknown key")
+}
+
+func TestAPIKey(t *testing.T) {
+	key, prefix, err := NewAPIKey()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, APIKeyPrefix+prefix+"_"))
+
+	parsed, ok := ParseAPIKey(key)
+	require.True(t, ok)
+	require.Equal(t, prefix, parsed)
+
+	for _, bad := range []string{"", prefix, "bk_short_secret", APIKeyPrefix + prefix + "_", "xx_" + prefix + "_secret"} {
+		_, ok := ParseAPIKey(bad)
+		require.False(t, ok, bad)
+	}
+}
+
+func TestWithAPIKeys(t *testing.T) {
+	maker, err := NewJWTMaker("123456789123456789123456789123456789")
+	require.NoError(t, err)
+	key, prefix, err := NewAPIKey()
+	require.NoError(t, err)
+	grant := &APIKeyGrant{Prefix: prefix, Scopes: []string{"GET /accounts/:id"}, AccountIDs: []int64{7}}
+	authenticator := WithAPIKeys(maker, stubKeys{key: {Username: "recon", APIKey: grant}})
+
+	payload, err := authenticator.VerifyToken(key)
+	require.NoError(t, err)
+	require.Equal(t, "recon", payload.Username)
+	require.True(t, payload.AllowsScope("GET /accounts/:id"))
+	require.False(t, payload.AllowsScope("POST /transfer"))
+
+	_, err = authenticator.VerifyToken(APIKeyPrefix + "unknown1_secret")
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	//the lookup ends with the request
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = VerifyTokenContext(ctx, authenticator, key)
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	//tokens still go to the wrapped authenticator, and are not limited by scopes
+	token, _, err := maker.GenerateToken("hector", time.Minute)
+	require.NoError(t, err)
+	payload, err = authenticator.VerifyToken(token)
+	require.NoError(t, err)
+	require.True(t, payload.AllowsScope("POST /transfer"))
+
+	//service accounts own nothing and only reach the accounts in their grant
+	sa := &Payload{Username: "hector", APIKey: grant}
+	require.ErrorIs(t, Authorize(sa, "hector", PermReadAnyAccount), ErrForbidden)
+	require.NoError(t, AuthorizeAccount(sa, "anyone", 7, PermReadAnyAccount))
+	require.ErrorIs(t, AuthorizeAccount(sa, "hector", 8, PermReadAnyAccount), ErrForbidden)
+	require.ErrorIs(t, Require(&Payload{Role: RoleAdmin, APIKey: grant}, PermManageUsers), ErrForbidden)
+}
+
+func TestWithAPIKeysPassesContext(t *testing.T) {
+	maker, err := NewJWTMaker("123456789123456789123456789123456789")
+	require.NoError(t, err)
+	checked := WithAPIKeys(WithSessionCheck(maker, sessionCheckerFunc(func(ctx context.Context, _ *Payload) error {
+		return ctx.Err()
+	}), nil), stubKeys{})
+
+	//tokens reach the session check with the caller's context
+	token, _, err := maker.GenerateToken("hector", time.Minute, WithSessionID(uuid.New()))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = VerifyTokenContext(ctx, checked, token)
+	require.NoError(t, err)
+	cancel()
+	_, err = VerifyTokenContext(ctx, checked, token)
+	require.ErrorIs(t, err, ErrSessionRevoked)
+}
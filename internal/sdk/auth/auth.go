@@ -1,10 +1,73 @@
 package auth
 
 import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/0xOnah/bank/internal/config"
 )
 
 type Authenticator interface {
-	GenerateToken(name string, duration time.Duration) (string, *Payload, error)
+	GenerateToken(name string, duration time.Duration, opts ...PayloadOption) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
 }
+
+// ContextVerifier is implemented by authenticators that look tokens up, so the lookup ends with
+// the request that asked for it.
+type ContextVerifier interface {
+	VerifyTokenContext(ctx context.Context, token string) (*Payload, error)
+}
+
+// VerifyTokenContext verifies token with a, passing ctx on when a is a ContextVerifier.
+func VerifyTokenContext(ctx context.Context, a Authenticator, token string) (*Payload, error) {
+	if cv, ok := a.(ContextVerifier); ok {
+		return cv.VerifyTokenContext(ctx, token)
+	}
+	return a.VerifyToken(token)
+}
+
+type TokenType string
+
+const (
+	TokenJWT          TokenType = "jwt"
+	TokenPasetoLocal  TokenType = "paseto-local"
+	TokenPasetoPublic TokenType = "paseto-public"
+	TokenJWTKeyring   TokenType = "jwt-keyring"
+)
+
+// NewAuthenticator returns the token maker selected by TOKEN_TYPE. jwt and paseto-local use
+// TOKEN_SYMMETRIC_KEY; paseto-public uses TOKEN_PRIVATE_KEY, a hex encoded ed25519 seed;
+// jwt-keyring signs with the PEM key in TOKEN_SIGNING_KEY_FILE and still accepts tokens from
+// the comma separated TOKEN_PREVIOUS_KEY_FILES.
+func NewAuthenticator(cfg *config.Config) (Authenticator, error) {
+	switch TokenType(cfg.TOKEN_TYPE) {
+	case TokenJWT, "":
+		return NewJWTMaker(cfg.TOKEN_SYMMETRIC_KEY)
+	case TokenPasetoLocal:
+		return NewPasetoLocalMaker(cfg.TOKEN_SYMMETRIC_KEY)
+	case TokenPasetoPublic:
+		seed, err := hex.DecodeString(cfg.TOKEN_PRIVATE_KEY)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid private key, must be a hex encoded %d byte ed25519 seed", ed25519.SeedSize)
+		}
+		return NewPasetoPublicMaker(ed25519.NewKeyFromSeed(seed))
+	case TokenJWTKeyring:
+		var previous []string
+		for _, file := range strings.Split(cfg.TOKEN_PREVIOUS_KEY_FILES, ",") {
+			if file = strings.TrimSpace(file); file != "" {
+				previous = append(previous, file)
+			}
+		}
+		keyring, err := LoadKeyring(cfg.TOKEN_SIGNING_KEY_FILE, previous...)
+		if err != nil {
+			return nil, fmt.Errorf("load keyring: %w", err)
+		}
+		return NewAsymmetricJWTMaker(keyring)
+	default:
+		return nil, fmt.Errorf("invalid token type %q", cfg.TOKEN_TYPE)
+	}
+}
@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSymmetricKey  = "12345678901234567890123456789012"
+	otherSymmetricKey = "abcdefghijklmnopqrstuvwxyzabcdef"
+)
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func newKeyringMaker(t *testing.T, current crypto.Signer, previous ...crypto.PublicKey) Authenticator {
+	keyring, err := NewKeyring(current, previous...)
+	require.NoError(t, err)
+	return newMaker(t, func() (Authenticator, error) { return NewAsymmetricJWTMaker(keyring) })
+}
+
+func newMaker(t *testing.T, newFn func() (Authenticator, error)) Authenticator {
+	maker, err := newFn()
+	require.NoError(t, err)
+	return maker
+}
+
+// every Authenticator must pass the same suite, so transports can treat them interchangeably
+func TestAuthenticators(t *testing.T) {
+	edKey := newEd25519Key(t)
+	rsaKey := newRSAKey(t)
+
+	makers := []struct {
+		name  string
+		maker Authenticator
+		// other is the same kind of maker with a different key
+		other Authenticator
+	}{
+		{
+			name:  "jwt",
+			maker: newMaker(t, func() (Authenticator, error) { return NewJWTMaker(testSymmetricKey) }),
+			other: newMaker(t, func() (Authenticator, error) { return NewJWTMaker(otherSymmetricKey) }),
+		}, {
+			name:  "paseto local",
+			maker: newMaker(t, func() (Authenticator, error) { return NewPasetoLocalMaker(testSymmetricKey) }),
+			other: newMaker(t, func() (Authenticator, error) { return NewPasetoLocalMaker(otherSymmetricKey) }),
+		}, {
+			name:  "paseto public",
+			maker: newMaker(t, func() (Authenticator, error) { return NewPasetoPublicMaker(edKey) }),
+			other: newMaker(t, func() (Authenticator, error) { return NewPasetoPublicMaker(newEd25519Key(t)) }),
+		}, {
+			name:  "jwt eddsa",
+			maker: newKeyringMaker(t, edKey),
+			other: newKeyringMaker(t, newEd25519Key(t)),
+		}, {
+			name:  "jwt rs256",
+			maker: newKeyringMaker(t, rsaKey),
+			other: newKeyringMaker(t, newRSAKey(t)),
+		},
+	}
+
+	for _, tc := range makers {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("valid token", func(t *testing.T) {
+				sessionID := uuid.New()
+				token, payload, err := tc.maker.GenerateToken("hector", time.Minute, WithSessionID(sessionID))
+				require.NoError(t, err)
+				require.NotEmpty(t, token)
+
+				verified, err := tc.maker.VerifyToken(token)
+				require.NoError(t, err)
+				require.Equal(t, "hector", verified.Username)
+				require.Equal(t, sessionID.String(), verified.SessionID)
+				require.Equal(t, payload.ID, verified.ID)
+				require.WithinDuration(t, payload.IssuedAt.Time, verified.IssuedAt.Time, time.Second)
+				require.WithinDuration(t, payload.ExpiresAt.Time, verified.ExpiresAt.Time, time.Second)
+			})
+
+			t.Run("purpose", func(t *testing.T) {
+				token, _, err := tc.maker.GenerateToken("hector", time.Minute, WithPurpose(PurposeMFA))
+				require.NoError(t, err)
+
+				verified, err := tc.maker.VerifyToken(token)
+				require.NoError(t, err)
+				require.Equal(t, PurposeMFA, verified.Purpose)
+			})
+
+			t.Run("auth time", func(t *testing.T) {
+				authTime := time.Now().Add(-time.Hour)
+				token, _, err := tc.maker.GenerateToken("hector", time.Minute, WithAuthTime(authTime, AssuranceMFA))
+				require.NoError(t, err)
+
+				verified, err := tc.maker.VerifyToken(token)
+				require.NoError(t, err)
+				require.NotNil(t, verified.AuthTime)
+				require.WithinDuration(t, authTime, verified.AuthTime.Time, time.Second)
+				require.Equal(t, AssuranceMFA, verified.Assurance)
+			})
+
+			t.Run("role", func(t *testing.T) {
+				token, _, err := tc.maker.GenerateToken("hector", time.Minute, WithRole(RoleTeller))
+				require.NoError(t, err)
+
+				verified, err := tc.maker.VerifyToken(token)
+				require.NoError(t, err)
+				require.Equal(t, RoleTeller, verified.Role)
+			})
+
+			t.Run("oauth client", func(t *testing.T) {
+				token, _, err := tc.maker.GenerateToken("hector", time.Minute, WithClient("cl_partner", []string{ScopeAccountsRead, ScopeTransfersWrite}))
+				require.NoError(t, err)
+
+				verified, err := tc.maker.VerifyToken(token)
+				require.NoError(t, err)
+				require.Equal(t, "cl_partner", verified.ClientID)
+				require.Equal(t, []string{ScopeAccountsRead, ScopeTransfersWrite}, verified.OAuthScopes())
+			})
+
+			t.Run("expired token", func(t *testing.T) {
+				token, _, err := tc.maker.GenerateToken("hector", -time.Minute)
+				require.NoError(t, err)
+
+				payload, err := tc.maker.VerifyToken(token)
+				require.ErrorIs(t, err, ErrExpired)
+				require.Nil(t, payload)
+			})
+
+			t.Run("tampered token", func(t *testing.T) {
+				token, _, err := tc.maker.GenerateToken("hector", time.Minute)
+				require.NoError(t, err)
+
+				i := len(token) / 2
+				flipped := byte('A')
+				if token[i] == 'A' {
+					flipped = 'B'
+				}
+				payload, err := tc.maker.VerifyToken(token[:i] + string(flipped) + token[i+1:])
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.Nil(t, payload)
+			})
+
+			t.Run("other key", func(t *testing.T) {
+				token, _, err := tc.other.GenerateToken("hector", time.Minute)
+				require.NoError(t, err)
+
+				payload, err := tc.maker.VerifyToken(token)
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.Nil(t, payload)
+			})
+
+			t.Run("other token types", func(t *testing.T) {
+				for _, other := range makers {
+					if other.name == tc.name {
+						continue
+					}
+					token, _, err := other.maker.GenerateToken("hector", time.Minute)
+					require.NoError(t, err)
+
+					payload, err := tc.maker.VerifyToken(token)
+					require.ErrorIs(t, err, ErrInvalidToken)
+					require.Nil(t, payload)
+				}
+			})
+
+			t.Run("garbage", func(t *testing.T) {
+				payload, err := tc.maker.VerifyToken("not-a-token")
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.Nil(t, payload)
+			})
+		})
+	}
+}
+
+func TestJWTRejectsNoneAlgorithm(t *testing.T) {
+	maker := newMaker(t, func() (Authenticator, error) { return NewJWTMaker(testSymmetricKey) })
+
+	payload, err := NewPayload("hector", time.Minute)
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, payload).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	verified, err := maker.VerifyToken(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.Nil(t, verified)
+}
+
+func TestPasetoTokenFormat(t *testing.T) {
+	local := newMaker(t, func() (Authenticator, error) { return NewPasetoLocalMaker(testSymmetricKey) })
+	token, _, err := local.GenerateToken("hector", time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.local."))
+	//the claims are encrypted
+	require.NotContains(t, token, b64.EncodeToString([]byte("hector")))
+
+	public := newMaker(t, func() (Authenticator, error) { return NewPasetoPublicMaker(newEd25519Key(t)) })
+	token, _, err = public.GenerateToken("hector", time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+}
+
+func TestPAE(t *testing.T) {
+	//vectors from the paseto spec
+	require.Equal(t, "\x00\x00\x00\x00\x00\x00\x00\x00", string(pae()))
+	require.Equal(t, "\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00", string(pae([]byte{})))
+	require.Equal(t, "\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test", string(pae([]byte("test"))))
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		cfg     config.Config
+		wantErr bool
+	}{
+		{name: "default jwt", cfg: config.Config{TOKEN_SYMMETRIC_KEY: testSymmetricKey}},
+		{name: "paseto local", cfg: config.Config{TOKEN_TYPE: "paseto-local", TOKEN_SYMMETRIC_KEY: testSymmetricKey}},
+		{name: "paseto local short key", cfg: config.Config{TOKEN_TYPE: "paseto-local", TOKEN_SYMMETRIC_KEY: "short"}, wantErr: true},
+		{name: "paseto public", cfg: config.Config{TOKEN_TYPE: "paseto-public", TOKEN_PRIVATE_KEY: hex.EncodeToString(seed)}},
+		{name: "paseto public bad key", cfg: config.Config{TOKEN_TYPE: "paseto-public", TOKEN_PRIVATE_KEY: "zz"}, wantErr: true},
+		{name: "jwt keyring missing key", cfg: config.Config{TOKEN_TYPE: "jwt-keyring", TOKEN_SIGNING_KEY_FILE: "missing.pem"}, wantErr: true},
+		{name: "unknown type", cfg: config.Config{TOKEN_TYPE: "saml"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewAuthenticator(&tc.cfg)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			token, _, err := maker.GenerateToken("hector", time.Minute)
+			require.NoError(t, err)
+			_, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -27,8 +27,8 @@ func NewJWTMaker(key string) (Authenticator, error) {
 	return &JWTMaker{secretKey: key}, nil
 }
 
-func (jt *JWTMaker) GenerateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+func (jt *JWTMaker) GenerateToken(username string, duration time.Duration, opts ...PayloadOption) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration, opts...)
 	if err != nil {
 		return "", nil, ErrTokenGen
 	}
@@ -54,7 +54,7 @@ func (jt *JWTMaker) VerifyToken(token string) (*Payload, error) {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrExpired
 		}
-		return nil, fmt.Errorf("token validation failed")
+		return nil, ErrInvalidToken
 	}
 	if !parsedToken.Valid {
 		return nil, ErrInvalidToken
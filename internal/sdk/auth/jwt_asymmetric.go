@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AsymmetricJWTMaker signs JWTs with the keyring's current EdDSA or RS256 key and names it
+// in the kid header, so services holding only the JWKS can verify tokens but not mint them.
+type AsymmetricJWTMaker struct {
+	keyring *Keyring
+}
+
+func NewAsymmetricJWTMaker(keyring *Keyring) (Authenticator, error) {
+	if keyring == nil {
+		return nil, errors.New("keyring is required")
+	}
+	return &AsymmetricJWTMaker{keyring: keyring}, nil
+}
+
+func (am *AsymmetricJWTMaker) GenerateToken(username string, duration time.Duration, opts ...PayloadOption) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration, opts...)
+	if err != nil {
+		return "", nil, ErrTokenGen
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(am.keyring.signingAlgorithm()), payload)
+	token.Header["kid"] = am.keyring.signingID
+	tokenString, err := token.SignedString(am.keyring.signingKey)
+	if err != nil {
+		return "", nil, errors.Join(ErrTokenGen, err)
+	}
+	return tokenString, payload, nil
+}
+
+func (am *AsymmetricJWTMaker) VerifyToken(token string) (*Payload, error) {
+	payload := Payload{}
+	parsedToken, err := jwt.ParseWithClaims(token, &payload, func(t *jwt.Token) (interface{}, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing kid header")
+		}
+		//the key, not the token, decides the algorithm
+		return am.keyring.verificationKey(kid, t.Method.Alg())
+	}, jwt.WithValidMethods([]string{AlgEdDSA, AlgRS256}), jwt.WithExpirationRequired())
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpired
+		}
+		return nil, ErrInvalidToken
+	}
+	if !parsedToken.Valid {
+		return nil, ErrInvalidToken
+	}
+	return &payload, nil
+}
+
+func (am *AsymmetricJWTMaker) JWKS() JWKSet {
+	return am.keyring.JWKS()
+}
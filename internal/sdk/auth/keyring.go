@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+)
+
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+
+	minRSAKeyBits = 2048
+)
+
+// JWK is the public half of a signing key as published in the JWKS (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKSProvider is implemented by authenticators whose tokens can be verified with public keys.
+type JWKSProvider interface {
+	JWKS() JWKSet
+}
+
+// JWKSHandler serves the key set at /.well-known/jwks.json.
+func JWKSHandler(provider JWKSProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(provider.JWKS())
+	}
+}
+
+type verificationKey struct {
+	jwk    JWK
+	public crypto.PublicKey
+}
+
+// Keyring holds the key new tokens are signed with and every key tokens are still accepted from.
+// To rotate, sign with a new key and keep the old one as a previous key until the tokens it
+// signed have expired.
+type Keyring struct {
+	signingKey crypto.Signer
+	signingID  string
+	keys       map[string]verificationKey
+	order      []string
+}
+
+// NewKeyring builds a keyring signing with current (an ed25519 or rsa private key) and also
+// verifying with previous (public or private keys). Key ids are RFC 7638 thumbprints.
+func NewKeyring(current crypto.Signer, previous ...crypto.PublicKey) (*Keyring, error) {
+	kr := &Keyring{keys: make(map[string]verificationKey)}
+	id, err := kr.add(current.Public())
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	kr.signingKey = current
+	kr.signingID = id
+
+	for _, key := range previous {
+		if signer, ok := key.(crypto.Signer); ok {
+			key = signer.Public()
+		}
+		if _, err := kr.add(key); err != nil {
+			return nil, fmt.Errorf("previous key: %w", err)
+		}
+	}
+	return kr, nil
+}
+
+func (kr *Keyring) add(public crypto.PublicKey) (string, error) {
+	jwk, err := newJWK(public)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := kr.keys[jwk.KeyID]; !ok {
+		kr.keys[jwk.KeyID] = verificationKey{jwk: jwk, public: public}
+		kr.order = append(kr.order, jwk.KeyID)
+	}
+	return jwk.KeyID, nil
+}
+
+// verificationKey returns the key for kid, provided it is used with alg.
+func (kr *Keyring) verificationKey(kid, alg string) (crypto.PublicKey, error) {
+	key, ok := kr.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if key.jwk.Algorithm != alg {
+		return nil, fmt.Errorf("key %q does not sign %s", kid, alg)
+	}
+	return key.public, nil
+}
+
+func (kr *Keyring) signingAlgorithm() string {
+	return kr.keys[kr.signingID].jwk.Algorithm
+}
+
+// JWKS lists the public keys, current signing key first.
+func (kr *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(kr.order))}
+	for _, id := range kr.order {
+		set.Keys = append(set.Keys, kr.keys[id].jwk)
+	}
+	return set
+}
+
+func newJWK(public crypto.PublicKey) (JWK, error) {
+	var jwk JWK
+	var thumbprint []byte
+	var err error
+
+	switch key := public.(type) {
+	case ed25519.PublicKey:
+		jwk = JWK{KeyType: "OKP", Algorithm: AlgEdDSA, Curve: "Ed25519", X: b64.EncodeToString(key)}
+		//members in lexicographic order, as RFC 7638 requires
+		thumbprint, err = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X})
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return JWK{}, fmt.Errorf("rsa key must be at least %d bits", minRSAKeyBits)
+		}
+		e := big.NewInt(int64(key.E)).Bytes()
+		jwk = JWK{KeyType: "RSA", Algorithm: AlgRS256, N: b64.EncodeToString(key.N.Bytes()), E: b64.EncodeToString(e)}
+		thumbprint, err = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N})
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T, want ed25519 or rsa", public)
+	}
+	if err != nil {
+		return JWK{}, err
+	}
+
+	sum := sha256.Sum256(thumbprint)
+	jwk.KeyID = b64.EncodeToString(sum[:])
+	jwk.Use = "sig"
+	return jwk, nil
+}
+
+// LoadKeyring reads a keyring from PEM files: a PKCS#8 (or PKCS#1 rsa) private key to sign with
+// and any number of previous private or PKIX public keys.
+func LoadKeyring(signingKeyFile string, previousKeyFiles ...string) (*Keyring, error) {
+	key, err := readPEMKey(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: not a private key", signingKeyFile)
+	}
+
+	var previous []crypto.PublicKey
+	for _, file := range previousKeyFiles {
+		key, err := readPEMKey(file)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	return NewKeyring(signer, previous...)
+}
+
+func readPEMKey(file string) (any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no pem block found", file)
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported pem block %q", file, block.Type)
+	}
+}
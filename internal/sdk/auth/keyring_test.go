@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringRotation(t *testing.T) {
+	oldKey := newEd25519Key(t)
+	oldMaker := newKeyringMaker(t, oldKey)
+	oldToken, _, err := oldMaker.GenerateToken("hector", time.Minute)
+	require.NoError(t, err)
+
+	//after rotating to an rsa key, tokens from the old key still verify
+	rotated := newKeyringMaker(t, newRSAKey(t), oldKey.Public())
+	payload, err := rotated.VerifyToken(oldToken)
+	require.NoError(t, err)
+	require.Equal(t, "hector", payload.Username)
+
+	newToken, _, err := rotated.GenerateToken("hector", time.Minute)
+	require.NoError(t, err)
+	token, _, err := jwt.NewParser().ParseUnverified(newToken, &Payload{})
+	require.NoError(t, err)
+	require.Equal(t, AlgRS256, token.Method.Alg())
+
+	jwks := rotated.(JWKSProvider).JWKS()
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, token.Header["kid"], jwks.Keys[0].KeyID)
+	require.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	require.Equal(t, "OKP", jwks.Keys[1].KeyType)
+
+	//once the old key is dropped its tokens are rejected
+	dropped := newKeyringMaker(t, newRSAKey(t))
+	_, err = dropped.VerifyToken(oldToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestKeyringRejectsAlgorithmConfusion(t *testing.T) {
+	key := newEd25519Key(t)
+	keyring, err := NewKeyring(key)
+	require.NoError(t, err)
+	maker, err := NewAsymmetricJWTMaker(keyring)
+	require.NoError(t, err)
+
+	payload, err := NewPayload("hector", time.Minute)
+	require.NoError(t, err)
+
+	//an HMAC token keyed with the published public key must not verify
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	forged.Header["kid"] = keyring.signingID
+	tokenString, err := forged.SignedString([]byte(key.Public().(ed25519.PublicKey)))
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(tokenString)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	//nor a token without a kid
+	unnamed, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, payload).SignedString(key)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(unnamed)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestKeyringRejectsWeakRSAKey(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = NewKeyring(weak)
+	require.Error(t, err)
+}
+
+func TestLoadKeyringAndJWKSHandler(t *testing.T) {
+	dir := t.TempDir()
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+
+	current, err := x509.MarshalPKCS8PrivateKey(newEd25519Key(t))
+	require.NoError(t, err)
+	previous, err := x509.MarshalPKIXPublicKey(newRSAKey(t).Public())
+	require.NoError(t, err)
+
+	keyring, err := LoadKeyring(
+		writePEM("current.pem", "PRIVATE KEY", current),
+		writePEM("previous.pem", "PUBLIC KEY", previous),
+	)
+	require.NoError(t, err)
+
+	_, err = LoadKeyring(writePEM("public.pem", "PUBLIC KEY", previous))
+	require.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	JWKSHandler(keyring).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var set JWKSet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 2)
+	require.Equal(t, AlgEdDSA, set.Keys[0].Algorithm)
+	require.NotEmpty(t, set.Keys[0].X)
+	require.Equal(t, AlgRS256, set.Keys[1].Algorithm)
+	require.NotEmpty(t, set.Keys[1].N)
+}
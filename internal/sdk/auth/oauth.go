@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"slices"
+	"strings"
+)
+
+// Scopes OAuth clients can be granted. Each stands for a fixed set of methods, named like
+// APIKeyGrant scopes, so partners only reach what the user consented to.
+const (
+	ScopeAccountsRead   = "accounts:read"
+	ScopeTransfersWrite = "transfers:write"
+	// ScopeAccountInformation reaches accounts through account consents only, which also
+	// limit which accounts and what about them the client may read.
+	ScopeAccountInformation = "accounts:information"
+)
+
+var oauthScopeMethods = map[string][]string{
+	ScopeAccountsRead:   {"GET /accounts/:id", "GET /accounts"},
+	ScopeTransfersWrite: {"POST /transfer"},
+	ScopeAccountInformation: {
+		"/pb.UserService/GetAccountConsent",
+		"/pb.UserService/ListConsentedAccounts",
+		"/pb.UserService/GetConsentedAccountBalance",
+		"/pb.UserService/ListConsentedAccountTransactions",
+	},
+}
+
+// OAuthClientIDPrefix starts every OAuth client id, so ids never look like usernames.
+const OAuthClientIDPrefix = "cl_"
+
+// NewOAuthClientID returns a random public client id like "cl_k3j9dx2mq4tg7a1b".
+func NewOAuthClientID() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return OAuthClientIDPrefix + strings.ToLower(apiKeyIDEncoding.EncodeToString(b)), nil
+}
+
+// ValidOAuthScope reports whether scope is one OAuth clients can be granted.
+func ValidOAuthScope(scope string) bool {
+	_, ok := oauthScopeMethods[scope]
+	return ok
+}
+
+func oauthAllows(scopes []string, method string) bool {
+	for _, scope := range scopes {
+		if slices.Contains(oauthScopeMethods[scope], method) {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthScopes returns the scopes of a token issued to an OAuth client.
+func (p *Payload) OAuthScopes() []string {
+	return strings.Fields(p.Scope)
+}
+
+// IsClientToken reports whether the token is a client credentials token, which acts for an
+// OAuth client itself rather than for one of our users.
+func (p *Payload) IsClientToken() bool {
+	return p.ClientID != "" && p.Username == ""
+}
+
+// VerifyPKCE checks verifier answers the S256 challenge of RFC 7636.
+func VerifyPKCE(verifier, challenge string) bool {
+	//43 to 128 characters keep the verifier as strong as a random 256 bit value
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
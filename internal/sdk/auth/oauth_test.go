@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthScopes(t *testing.T) {
+	require.True(t, ValidOAuthScope(ScopeAccountsRead))
+	require.False(t, ValidOAuthScope("users:manage"))
+
+	payload := &Payload{Username: "hector", ClientID: "cl_partner", Scope: ScopeAccountsRead}
+	require.True(t, payload.AllowsScope("GET /accounts/:id"))
+	require.True(t, payload.AllowsScope("GET /accounts"))
+	require.False(t, payload.AllowsScope("POST /transfer"))
+	require.False(t, payload.AllowsScope("/pb.UserService/UpdateUser"))
+	require.False(t, payload.IsClientToken())
+
+	//account information clients only reach accounts through consents
+	aggregator := &Payload{ClientID: "cl_aggregator", Scope: ScopeAccountInformation}
+	require.True(t, aggregator.AllowsScope("/pb.UserService/GetConsentedAccountBalance"))
+	require.False(t, aggregator.AllowsScope("GET /accounts/:id"))
+	require.False(t, aggregator.AllowsScope("/pb.UserService/CreateAccountConsent"))
+
+	//a client with no scopes can call nothing
+	require.False(t, (&Payload{ClientID: "cl_partner"}).AllowsScope("GET /accounts"))
+	require.True(t, (&Payload{ClientID: "cl_partner"}).IsClientToken())
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	verifier := strings.Repeat("v", 43)
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	require.True(t, VerifyPKCE(verifier, challenge))
+	require.False(t, VerifyPKCE(strings.Repeat("w", 43), challenge))
+	//the plain method is not supported
+	require.False(t, VerifyPKCE(verifier, verifier))
+	short := "short"
+	sum = sha256.Sum256([]byte(short))
+	require.False(t, VerifyPKCE(short, base64.RawURLEncoding.EncodeToString(sum[:])))
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/chacha20"
+)
+
+// pasetoClaims is the payload as PASETO registered claims, which carry times as RFC 3339 strings.
+type pasetoClaims struct {
+	Username  string     `json:"username"`
+	SessionID string     `json:"sid,omitempty"`
+	Purpose   string     `json:"pur,omitempty"`
+	AuthTime  *time.Time `json:"auth_time,omitempty"`
+	Assurance Assurance  `json:"aal,omitempty"`
+	Role      Role       `json:"role,omitempty"`
+	ClientID  string     `json:"client_id,omitempty"`
+	Scope     string     `json:"scope,omitempty"`
+	ID        string     `json:"jti,omitempty"`
+	IssuedAt  *time.Time `json:"iat,omitempty"`
+	NotBefore *time.Time `json:"nbf,omitempty"`
+	ExpiresAt *time.Time `json:"exp,omitempty"`
+}
+
+func claimTime(d *jwt.NumericDate) *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := d.UTC()
+	return &t
+}
+
+func numericDate(t *time.Time) *jwt.NumericDate {
+	if t == nil {
+		return nil
+	}
+	return jwt.NewNumericDate(*t)
+}
+
+func marshalPasetoClaims(payload *Payload) ([]byte, error) {
+	return json.Marshal(pasetoClaims{
+		Username:  payload.Username,
+		SessionID: payload.SessionID,
+		Purpose:   payload.Purpose,
+		AuthTime:  claimTime(payload.AuthTime),
+		Assurance: payload.Assurance,
+		Role:      payload.Role,
+		ClientID:  payload.ClientID,
+		Scope:     payload.Scope,
+		ID:        payload.ID,
+		IssuedAt:  claimTime(payload.IssuedAt),
+		NotBefore: claimTime(payload.NotBefore),
+		ExpiresAt: claimTime(payload.ExpiresAt),
+	})
+}
+
+// unmarshalPasetoClaims decodes an authenticated message and applies the same time checks as JWTMaker.
+func unmarshalPasetoClaims(message []byte) (*Payload, error) {
+	var claims pasetoClaims
+	if err := json.Unmarshal(message, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+	if !now.Before(*claims.ExpiresAt) {
+		return nil, ErrExpired
+	}
+	if claims.NotBefore != nil && now.Before(*claims.NotBefore) {
+		return nil, ErrInvalidToken
+	}
+	if claims.IssuedAt != nil && now.Before(*claims.IssuedAt) {
+		return nil, ErrInvalidToken
+	}
+
+	return &Payload{
+		Username:  claims.Username,
+		SessionID: claims.SessionID,
+		Purpose:   claims.Purpose,
+		AuthTime:  numericDate(claims.AuthTime),
+		Assurance: claims.Assurance,
+		Role:      claims.Role,
+		ClientID:  claims.ClientID,
+		Scope:     claims.Scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        claims.ID,
+			IssuedAt:  numericDate(claims.IssuedAt),
+			NotBefore: numericDate(claims.NotBefore),
+			ExpiresAt: numericDate(claims.ExpiresAt),
+		},
+	}, nil
+}
+
+// PasetoLocalMaker issues v4.local tokens: encrypted and authenticated with a shared key.
+type PasetoLocalMaker struct {
+	symmetricKey []byte
+}
+
+func NewPasetoLocalMaker(key string) (Authenticator, error) {
+	if len(key) != chacha20.KeySize {
+		return nil, fmt.Errorf("invalid key size, must be exactly %d characters", chacha20.KeySize)
+	}
+	return &PasetoLocalMaker{symmetricKey: []byte(key)}, nil
+}
+
+func (pm *PasetoLocalMaker) GenerateToken(username string, duration time.Duration, opts ...PayloadOption) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration, opts...)
+	if err != nil {
+		return "", nil, ErrTokenGen
+	}
+	message, err := marshalPasetoClaims(payload)
+	if err != nil {
+		return "", nil, errors.Join(ErrTokenGen, err)
+	}
+	token, err := v4Encrypt(pm.symmetricKey, message, nil)
+	if err != nil {
+		return "", nil, errors.Join(ErrTokenGen, err)
+	}
+	return token, payload, nil
+}
+
+func (pm *PasetoLocalMaker) VerifyToken(token string) (*Payload, error) {
+	message, _, err := v4Decrypt(pm.symmetricKey, token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return unmarshalPasetoClaims(message)
+}
+
+// PasetoPublicMaker issues v4.public tokens: signed with Ed25519, so holders of the
+// public key can verify them but not mint them.
+type PasetoPublicMaker struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+func NewPasetoPublicMaker(privateKey ed25519.PrivateKey) (Authenticator, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed25519 private key size, must be %d bytes", ed25519.PrivateKeySize)
+	}
+	return &PasetoPublicMaker{
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+func (pm *PasetoPublicMaker) GenerateToken(username string, duration time.Duration, opts ...PayloadOption) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration, opts...)
+	if err != nil {
+		return "", nil, ErrTokenGen
+	}
+	message, err := marshalPasetoClaims(payload)
+	if err != nil {
+		return "", nil, errors.Join(ErrTokenGen, err)
+	}
+	return v4Sign(pm.privateKey, message, nil), payload, nil
+}
+
+func (pm *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	message, _, err := v4Verify(pm.publicKey, token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return unmarshalPasetoClaims(message)
+}
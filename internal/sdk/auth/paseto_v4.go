@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+)
+
+// PASETO v4 (https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md)
+// built directly on x/crypto. Only the primitives the spec allows are reachable, so there is
+// no algorithm for a token to pick.
+
+const (
+	v4LocalHeader  = "v4.local."
+	v4PublicHeader = "v4.public."
+
+	v4NonceSize = 32
+	v4MacSize   = 32
+)
+
+var errPasetoInvalid = errors.New("invalid paseto token")
+
+var b64 = base64.RawURLEncoding
+
+// pae is the spec's pre-authentication encoding: the piece count followed by every
+// piece, each prefixed by its little endian length.
+func pae(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	le64 := func(n int) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(n)&^(1<<63))
+		buf.Write(b[:])
+	}
+	le64(len(pieces))
+	for _, piece := range pieces {
+		le64(len(piece))
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
+
+func keyedHash(key []byte, size int, msg ...[]byte) []byte {
+	h, err := blake2b.New(size, key)
+	if err != nil {
+		//size and key length are fixed by the callers
+		panic(err)
+	}
+	for _, m := range msg {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// v4LocalKeys derives the encryption key, the XChaCha20 nonce and the authentication key for nonce n.
+func v4LocalKeys(key, n []byte) (ek, n2, ak []byte) {
+	tmp := keyedHash(key, 56, []byte("paseto-encryption-key"), n)
+	ak = keyedHash(key, 32, []byte("paseto-auth-key-for-aead"), n)
+	return tmp[:32], tmp[32:], ak
+}
+
+func xchacha20(key, nonce, in []byte) []byte {
+	cipher, err := chacha20.NewUnauthenticatedCipher(key, nonce)
+	if err != nil {
+		panic(err)
+	}
+	out := make([]byte, len(in))
+	cipher.XORKeyStream(out, in)
+	return out
+}
+
+func v4Encrypt(key, message, footer []byte) (string, error) {
+	n := make([]byte, v4NonceSize)
+	if _, err := rand.Read(n); err != nil {
+		return "", err
+	}
+	return v4EncryptWithNonce(key, n, message, footer), nil
+}
+
+// v4EncryptWithNonce is v4Encrypt with a given nonce, which the spec's test vectors fix.
+func v4EncryptWithNonce(key, n, message, footer []byte) string {
+	ek, n2, ak := v4LocalKeys(key, n)
+	c := xchacha20(ek, n2, message)
+	t := keyedHash(ak, v4MacSize, pae([]byte(v4LocalHeader), n, c, footer, nil))
+
+	body := make([]byte, 0, len(n)+len(c)+len(t))
+	body = append(append(append(body, n...), c...), t...)
+	return encodeToken(v4LocalHeader, body, footer)
+}
+
+func v4Decrypt(key []byte, token string) (message, footer []byte, err error) {
+	body, footer, err := decodeToken(v4LocalHeader, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) < v4NonceSize+v4MacSize {
+		return nil, nil, errPasetoInvalid
+	}
+
+	n := body[:v4NonceSize]
+	c := body[v4NonceSize : len(body)-v4MacSize]
+	t := body[len(body)-v4MacSize:]
+
+	ek, n2, ak := v4LocalKeys(key, n)
+	t2 := keyedHash(ak, v4MacSize, pae([]byte(v4LocalHeader), n, c, footer, nil))
+	if subtle.ConstantTimeCompare(t, t2) != 1 {
+		return nil, nil, errPasetoInvalid
+	}
+	return xchacha20(ek, n2, c), footer, nil
+}
+
+func v4Sign(key ed25519.PrivateKey, message, footer []byte) string {
+	sig := ed25519.Sign(key, pae([]byte(v4PublicHeader), message, footer, nil))
+	body := make([]byte, 0, len(message)+len(sig))
+	body = append(append(body, message...), sig...)
+	return encodeToken(v4PublicHeader, body, footer)
+}
+
+func v4Verify(key ed25519.PublicKey, token string) (message, footer []byte, err error) {
+	body, footer, err := decodeToken(v4PublicHeader, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, nil, errPasetoInvalid
+	}
+
+	message = body[:len(body)-ed25519.SignatureSize]
+	sig := body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(key, pae([]byte(v4PublicHeader), message, footer, nil), sig) {
+		return nil, nil, errPasetoInvalid
+	}
+	return message, footer, nil
+}
+
+func encodeToken(header string, body, footer []byte) string {
+	token := header + b64.EncodeToString(body)
+	if len(footer) > 0 {
+		token += "." + b64.EncodeToString(footer)
+	}
+	return token
+}
+
+func decodeToken(header, token string) (body, footer []byte, err error) {
+	rest, ok := strings.CutPrefix(token, header)
+	if !ok {
+		return nil, nil, errPasetoInvalid
+	}
+
+	encBody, encFooter, hasFooter := strings.Cut(rest, ".")
+	body, err = b64.DecodeString(encBody)
+	if err != nil {
+		return nil, nil, errPasetoInvalid
+	}
+	if hasFooter {
+		footer, err = b64.DecodeString(encFooter)
+		if err != nil {
+			return nil, nil, errPasetoInvalid
+		}
+	}
+	return body, footer, nil
+}
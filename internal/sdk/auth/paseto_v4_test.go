@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Vectors from https://github.com/paseto-standard/test-vectors/blob/master/v4.json. The ones
+// with an implicit assertion are left out, as tokens here never carry one.
+
+const (
+	vectorSecretMessage = `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`
+	vectorHiddenMessage = `{"data":"this is a hidden message","exp":"2022-01-01T00:00:00+00:00"}`
+	vectorSignedMessage = `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+	vectorFooter        = `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestV4LocalVectors(t *testing.T) {
+	key := mustHex(t, "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	zeroNonce := "0000000000000000000000000000000000000000000000000000000000000000"
+	nonce := "26f7553354482a1d91d4784627854b8da6b8042a7966523c2b404e8dbbe7f7f2"
+
+	testCases := []struct {
+		name    string
+		nonce   string
+		payload string
+		footer  string
+		token   string
+	}{
+		{
+			name:    "4-E-1",
+			nonce:   zeroNonce,
+			payload: vectorSecretMessage,
+			token:   "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg",
+		}, {
+			name:    "4-E-2",
+			nonce:   zeroNonce,
+			payload: vectorHiddenMessage,
+			token:   "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvS2csCgglvpk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XIemu9chy3WVKvRBfg6t8wwYHK0ArLxxfZP73W_vfwt5A",
+		}, {
+			name:    "4-E-3",
+			nonce:   nonce,
+			payload: vectorSecretMessage,
+			token:   "v4.local.JvdVM1RIKh2R1HhGJ4VLjaa4BCp5ZlI8K0BOjbvn9_L6qU34Aj806z9BHW68MiMIOL-WkS5pimduKSmcwEtx3ksEnMJnnMvZUScQKTvmZyxuKxT3L9IjiRh_2vdM-ac-tvG3LB6V6O_cKswZ1kK-vBsCO-WG6r5-xhqj0J73IogDuxnNWA",
+		}, {
+			name:    "4-E-4",
+			nonce:   nonce,
+			payload: vectorHiddenMessage,
+			token:   "v4.local.JvdVM1RIKh2R1HhGJ4VLjaa4BCp5ZlI8K0BOjbvn9_L6qU34Aj806z9BHW68MiMIOL-WiiJunGd0KSmcwEtx3ksEnMJnnMvZUScQKTvmZyxuKxT3L9IjiRh_2vdM-ac-tvG3LB7Tel74ti0JFn6skilnLGyub72L5SFRUegCvR2efmjcuQ",
+		}, {
+			name:    "4-E-5",
+			nonce:   nonce,
+			payload: vectorSecretMessage,
+			footer:  vectorFooter,
+			token:   "v4.local.JvdVM1RIKh2R1HhGJ4VLjaa4BCp5ZlI8K0BOjbvn9_L6qU34Aj806z9BHW68MiMIOL-WkS5pimduKSmcwEtx3ksEnMJnnMvZUScQKTvmZyxuKxT3L9IjiRh_2vdM-ac-tvG3LB4SjEkrlyKT9n2hlPhtLNi1CB9fsgS12-7n9paSy5-VNA.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		}, {
+			name:    "4-E-6",
+			nonce:   nonce,
+			payload: vectorHiddenMessage,
+			footer:  vectorFooter,
+			token:   "v4.local.JvdVM1RIKh2R1HhGJ4VLjaa4BCp5ZlI8K0BOjbvn9_L6qU34Aj806z9BHW68MiMIOL-WiiJunGd0KSmcwEtx3ksEnMJnnMvZUScQKTvmZyxuKxT3L9IjiRh_2vdM-ac-tvG3LB5ozczLb9MElasx3uIJ8cWCoKb4fN5i_xeLm5gnDzBytQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token := v4EncryptWithNonce(key, mustHex(t, tc.nonce), []byte(tc.payload), []byte(tc.footer))
+			require.Equal(t, tc.token, token)
+
+			message, footer, err := v4Decrypt(key, tc.token)
+			require.NoError(t, err)
+			require.Equal(t, tc.payload, string(message))
+			require.Equal(t, tc.footer, string(footer))
+		})
+	}
+}
+
+func TestV4PublicVectors(t *testing.T) {
+	secretKey := ed25519.PrivateKey(mustHex(t, "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"))
+	publicKey := ed25519.PublicKey(mustHex(t, "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"))
+	require.Equal(t, publicKey, secretKey.Public())
+
+	testCases := []struct {
+		name   string
+		footer string
+		token  string
+	}{
+		{
+			name:  "4-S-1",
+			token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA",
+		}, {
+			name:   "4-S-2",
+			footer: vectorFooter,
+			token:  "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.token, v4Sign(secretKey, []byte(vectorSignedMessage), []byte(tc.footer)))
+
+			message, footer, err := v4Verify(publicKey, tc.token)
+			require.NoError(t, err)
+			require.Equal(t, vectorSignedMessage, string(message))
+			require.Equal(t, tc.footer, string(footer))
+		})
+	}
+}
+
+func TestV4RejectsTampering(t *testing.T) {
+	key := mustHex(t, "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	publicKey := ed25519.PublicKey(mustHex(t, "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"))
+	local := v4EncryptWithNonce(key, make([]byte, v4NonceSize), []byte(vectorSecretMessage), []byte(vectorFooter))
+	public := "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"
+	//a footer the token was not made with, for both purposes
+	otherFooter := "." + b64.EncodeToString([]byte(`{"kid":"other"}`))
+	cut := func(token string) string { return token[:len(token)-len(b64.EncodeToString([]byte(vectorFooter)))-1] }
+
+	_, _, err := v4Decrypt(key, cut(local)+otherFooter)
+	require.ErrorIs(t, err, errPasetoInvalid)
+	_, _, err = v4Decrypt(key, public)
+	require.ErrorIs(t, err, errPasetoInvalid)
+	_, _, err = v4Decrypt(key, "v4.local.AAAA")
+	require.ErrorIs(t, err, errPasetoInvalid)
+
+	_, _, err = v4Verify(publicKey, cut(public)+otherFooter)
+	require.ErrorIs(t, err, errPasetoInvalid)
+	_, _, err = v4Verify(publicKey, local)
+	require.ErrorIs(t, err, errPasetoInvalid)
+	_, _, err = v4Verify(publicKey, "v4.public.!!")
+	require.ErrorIs(t, err, errPasetoInvalid)
+}
@@ -1,20 +1,109 @@
 package auth
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Argon2Params are the argon2id cost settings. They are encoded in every hash, so hashes made
+// with older settings still verify and can be spotted by PasswordNeedsRehash.
+type Argon2Params struct {
+	// Memory is in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follow the OWASP recommendation for argon2id.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+const argon2idPrefix = "$argon2id$"
+
+var (
+	errInvalidHash         = errors.New("invalid argon2id hash")
+	errIncompatibleVersion = errors.New("incompatible argon2 version")
+	argon2Encoding         = base64.RawStdEncoding
+)
+
+// HashPassword hashes password with argon2id, encoded like
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
 func HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), 12)
-	if err != nil {
+	p := DefaultArgon2Params
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", fmt.Errorf("failed to hash password")
 	}
-	return string(hash), nil
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		p.Memory, p.Iterations, p.Parallelism, argon2Encoding.EncodeToString(salt), argon2Encoding.EncodeToString(key)), nil
 }
 
+// ComparePassword checks password against an argon2id hash, or a bcrypt hash from before argon2id.
 func ComparePassword(hash []byte, password string) bool {
-	err := bcrypt.CompareHashAndPassword(hash, []byte(password))
-	return err == nil
+	if !bytes.HasPrefix(hash, []byte(argon2idPrefix)) {
+		return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+	}
+	p, salt, key, err := decodeArgon2Hash(string(hash))
+	if err != nil {
+		return false
+	}
+	other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+// PasswordNeedsRehash reports whether hash was made with another algorithm or older settings
+// than HashPassword uses now. Check it after a successful ComparePassword, while the password
+// is at hand.
+func PasswordNeedsRehash(hash []byte) bool {
+	p, _, _, err := decodeArgon2Hash(string(hash))
+	return err != nil || p != DefaultArgon2Params
+}
+
+func decodeArgon2Hash(hash string) (p Argon2Params, salt, key []byte, err error) {
+	rest, ok := strings.CutPrefix(hash, argon2idPrefix)
+	if !ok {
+		return p, nil, nil, errInvalidHash
+	}
+	parts := strings.Split(rest, "$")
+	if len(parts) != 4 {
+		return p, nil, nil, errInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[0], "v=%d", &version); err != nil {
+		return p, nil, nil, errInvalidHash
+	}
+	if version != argon2.Version {
+		return p, nil, nil, errIncompatibleVersion
+	}
+	if _, err := fmt.Sscanf(parts[1], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, errInvalidHash
+	}
+	if salt, err = argon2Encoding.DecodeString(parts[2]); err != nil {
+		return p, nil, nil, errInvalidHash
+	}
+	if key, err = argon2Encoding.DecodeString(parts[3]); err != nil {
+		return p, nil, nil, errInvalidHash
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+	if p.Iterations == 0 || p.Parallelism == 0 || p.KeyLength == 0 {
+		return p, nil, nil, errInvalidHash
+	}
+	return p, salt, key, nil
 }
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/0xOnah/bank/internal/sdk/validator"
+)
+
+// PasswordPolicy is what a new password must satisfy. A nil policy uses DefaultPasswordPolicy.
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	// MinCharClasses is how many of lowercase, uppercase, digits and symbols must be mixed.
+	MinCharClasses int
+	// Breached rejects passwords seen in data breaches. Optional.
+	Breached *BreachedPasswords
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8, MaxLength: 256}
+
+// minPersonalLength keeps short usernames from ruling out common substrings.
+const minPersonalLength = 3
+
+// Check reports every rule password breaks to v under key. personal holds the username, email
+// and the like, which the password must not contain. The error is only for a failed breach lookup.
+func (p *PasswordPolicy) Check(v *validator.Validator, key, password string, personal ...string) error {
+	if p == nil {
+		p = &DefaultPasswordPolicy
+	}
+	if password == "" {
+		v.Add(key, "cannot be empty")
+		return nil
+	}
+	length := len([]rune(password))
+	v.Check(length >= p.MinLength, key, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	v.Check(p.MaxLength <= 0 || length <= p.MaxLength, key, fmt.Sprintf("must not exceed %d characters", p.MaxLength))
+	v.Check(charClasses(password) >= p.MinCharClasses, key,
+		fmt.Sprintf("must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.MinCharClasses))
+
+	lower := strings.ToLower(password)
+	for _, info := range personal {
+		info = strings.ToLower(info)
+		if local, _, ok := strings.Cut(info, "@"); ok {
+			info = local
+		}
+		if len(info) >= minPersonalLength && strings.Contains(lower, info) {
+			v.Add(key, "must not contain your username or email")
+		}
+	}
+
+	if p.Breached == nil || !v.Valid() {
+		return nil
+	}
+	breached, err := p.Breached.Contains(password)
+	if err != nil {
+		return err
+	}
+	v.Check(!breached, key, "has appeared in a data breach, choose another")
+	return nil
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// BreachedPasswords looks passwords up in a local copy of a breach corpus kept in the
+// k-anonymity range format of Have I Been Pwned: one file per 5 character prefix of the
+// uppercase hex SHA-1, named like "5BAA6.txt", holding "SUFFIX:COUNT" lines for the remaining
+// 35 characters. Only the one file for the password's prefix is read per lookup. Prefixes
+// without a file are treated as clean, so a partial corpus works.
+type BreachedPasswords struct {
+	fsys fs.FS
+}
+
+func NewBreachedPasswords(fsys fs.FS) *BreachedPasswords {
+	return &BreachedPasswords{fsys: fsys}
+}
+
+func (b *BreachedPasswords) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	f, err := b.fsys.Open(prefix + ".txt")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("open breached password range %s: %w", prefix, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entrySuffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(entrySuffix, suffix) {
+			continue
+		}
+		//padded responses carry made up suffixes with a count of 0
+		n, err := strconv.Atoi(count)
+		return err != nil || n > 0, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("read breached password range %s: %w", prefix, err)
+	}
+	return false, nil
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/stretchr/testify/require"
+)
+
+// sha1("password123") is CBFDAC6008F9CAB4083784CBD1874F76618D2A97
+var breachCorpus = fstest.MapFS{
+	"CBFDA.txt": {Data: []byte("0000000000000000000000000000000000A:0\r\nC6008F9CAB4083784CBD1874F76618D2A97:251682\r\n")},
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := &PasswordPolicy{
+		MinLength:      10,
+		MaxLength:      64,
+		MinCharClasses: 3,
+		Breached:       NewBreachedPasswords(breachCorpus),
+	}
+
+	testCases := []struct {
+		name     string
+		policy   *PasswordPolicy
+		password string
+		valid    bool
+	}{
+		{"OK", policy, "Correct-Horse-7", true},
+		{"Empty", policy, "", false},
+		{"Too short", policy, "Ab1!", false},
+		{"Too long", policy, "Aa1!" + strings.Repeat("x", 64), false},
+		{"Too few classes", policy, "correcthorsebattery", false},
+		{"Contains username", policy, "xHector-2024!", false},
+		{"Contains email", policy, "King.Hector@99", false},
+		{"Breached", &PasswordPolicy{MinLength: 8, Breached: policy.Breached}, "password123", false},
+		{"Nil policy is the default", nil, "secret12345", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := validator.NewValidator()
+			require.NoError(t, tc.policy.Check(v, "password", tc.password, "hector", "king.hector@gmail.com"))
+			require.Equal(t, tc.valid, v.Valid(), v.ErrVal)
+		})
+	}
+}
+
+func TestBreachedPasswords(t *testing.T) {
+	breached := NewBreachedPasswords(breachCorpus)
+
+	found, err := breached.Contains("password123")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	found, err = breached.Contains("Correct-Horse-7")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	//no file for the prefix
+	found, err = NewBreachedPasswords(fstest.MapFS{}).Contains("password123")
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -1,9 +1,11 @@
 package auth
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const password = "secret"
@@ -27,3 +29,28 @@ func TestHashPassword(t *testing.T) {
 
 	require.NotEqual(t, hash, hash1)
 }
+
+func TestBcryptPasswordStillVerifies(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	require.True(t, ComparePassword(legacy, password))
+	require.False(t, ComparePassword(legacy, "wrong"))
+	require.True(t, PasswordNeedsRehash(legacy))
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	hash, err := HashPassword(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$"))
+	require.False(t, PasswordNeedsRehash([]byte(hash)))
+
+	//hashes keep their own parameters, so they verify after the defaults change
+	old := DefaultArgon2Params
+	DefaultArgon2Params.Iterations = 4
+	defer func() { DefaultArgon2Params = old }()
+	require.True(t, ComparePassword([]byte(hash), password))
+	require.True(t, PasswordNeedsRehash([]byte(hash)))
+
+	require.False(t, ComparePassword([]byte("$argon2id$v=19$m=65536,t=0,p=2$c2FsdA$a2V5"), password))
+}
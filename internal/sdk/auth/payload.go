@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,10 +10,93 @@ import (
 
 type Payload struct {
 	Username string `json:"username"`
+	// SessionID names the login session an access token belongs to, so revoking the
+	// session revokes its access tokens. Refresh tokens leave it empty.
+	SessionID string `json:"sid,omitempty"`
+	// Purpose marks tokens that only grant one step of a flow, such as PurposeMFA.
+	// Access and refresh tokens leave it empty.
+	Purpose string `json:"pur,omitempty"`
+	// AuthTime is when the user last proved who they are, and Assurance how. Renewed tokens keep
+	// the values from login; only re-authenticating moves AuthTime forward.
+	AuthTime  *jwt.NumericDate `json:"auth_time,omitempty"`
+	Assurance Assurance        `json:"aal,omitempty"`
+	// Role is the user's role when the token was issued. Renewals read it from the user again.
+	Role Role `json:"role,omitempty"`
+	// ClientID is set on tokens issued to an OAuth client, which can only call the methods its
+	// space separated Scope covers. Client credentials tokens act for the client itself and have
+	// no Username.
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	// APIKey is set instead of a session when a service account authenticated with an API key.
+	// It is never part of a token.
+	APIKey *APIKeyGrant `json:"-"`
 	jwt.RegisteredClaims
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// PurposeMFA tokens prove the password was checked and can only be exchanged for a session
+// by completing the second factor.
+const PurposeMFA = "mfa"
+
+// MFATokenUsername returns who token was issued to when it is a valid MFA token, or "".
+func MFATokenUsername(a Authenticator, token string) string {
+	if a == nil || token == "" {
+		return ""
+	}
+	payload, err := a.VerifyToken(token)
+	if err != nil || payload.Purpose != PurposeMFA {
+		return ""
+	}
+	return payload.Username
+}
+
+// PurposeLoginLink tokens are emailed in a login link and can be exchanged for a session once.
+const PurposeLoginLink = "login_link"
+
+type PayloadOption func(*Payload)
+
+func WithPurpose(purpose string) PayloadOption {
+	return func(p *Payload) {
+		p.Purpose = purpose
+	}
+}
+
+func WithSessionID(id uuid.UUID) PayloadOption {
+	return func(p *Payload) {
+		p.SessionID = id.String()
+	}
+}
+
+func WithRole(role Role) PayloadOption {
+	return func(p *Payload) {
+		p.Role = role
+	}
+}
+
+func WithAuthTime(t time.Time, assurance Assurance) PayloadOption {
+	return func(p *Payload) {
+		p.AuthTime = jwt.NewNumericDate(t)
+		p.Assurance = assurance
+	}
+}
+
+// WithClient marks a token as issued to an OAuth client for scopes. OAuth tokens never carry a
+// role, so they only reach the user's own resources.
+func WithClient(clientID string, scopes []string) PayloadOption {
+	return func(p *Payload) {
+		p.ClientID = clientID
+		p.Scope = strings.Join(scopes, " ")
+	}
+}
+
+// WithAuthenticationOf copies the auth time and assurance of another token, as renewals do.
+func WithAuthenticationOf(other *Payload) PayloadOption {
+	return func(p *Payload) {
+		p.AuthTime = other.AuthTime
+		p.Assurance = other.Assurance
+	}
+}
+
+func NewPayload(username string, duration time.Duration, opts ...PayloadOption) (*Payload, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -26,5 +110,8 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 			ID:        id.String(),
 		},
 	}
+	for _, opt := range opts {
+		opt(payload)
+	}
 	return payload, nil
 }
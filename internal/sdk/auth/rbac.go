@@ -0,0 +1,82 @@
+package auth
+
+import "errors"
+
+// Role is the user's position at the bank. It is stored with the user and carried in tokens.
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleTeller   Role = "teller"
+	RoleAdmin    Role = "admin"
+)
+
+// Permission lets a role act on resources it does not own.
+type Permission string
+
+const (
+	PermReadAnyAccount        Permission = "accounts:read_any"
+	PermTransferAnyAccount    Permission = "accounts:transfer_any"
+	PermManageUsers           Permission = "users:manage"
+	PermManageServiceAccounts Permission = "service_accounts:manage"
+	PermReadAuditLog          Permission = "audit:read"
+	PermManageOAuthClients    Permission = "oauth_clients:manage"
+)
+
+var ErrForbidden = errors.New("permission denied")
+
+// rolePermissions lists what each role may do beyond its own resources. Customers get nothing extra.
+var rolePermissions = map[Role][]Permission{
+	RoleTeller: {PermReadAnyAccount},
+	RoleAdmin:  {PermReadAnyAccount, PermTransferAnyAccount, PermManageUsers, PermManageServiceAccounts, PermReadAuditLog, PermManageOAuthClients},
+}
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleCustomer, RoleTeller, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (r Role) Has(perm Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize lets payload act on a resource owned by owner: its own resources always, anyone
+// else's only if its role grants perm. Tokens without a role are treated as customers.
+// Service accounts own nothing; they only reach accounts through AuthorizeAccount.
+func Authorize(payload *Payload, owner string, perm Permission) error {
+	if payload == nil || payload.APIKey != nil {
+		return ErrForbidden
+	}
+	if payload.Username == owner || payload.Role.Has(perm) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// Require checks payload's role grants perm, for actions that don't act on a resource of its own.
+func Require(payload *Payload, perm Permission) error {
+	if payload == nil || payload.APIKey != nil || !payload.Role.Has(perm) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeAccount is Authorize for a bank account, which service accounts may act on when it
+// is in their grant.
+func AuthorizeAccount(payload *Payload, owner string, accountID int64, perm Permission) error {
+	if payload != nil && payload.APIKey != nil {
+		if payload.APIKey.CanAccessAccount(accountID) {
+			return nil
+		}
+		return ErrForbidden
+	}
+	return Authorize(payload, owner, perm)
+}
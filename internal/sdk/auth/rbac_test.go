@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	customer := &Payload{Username: "hector", Role: RoleCustomer}
+	teller := &Payload{Username: "tina", Role: RoleTeller}
+	admin := &Payload{Username: "ada", Role: RoleAdmin}
+
+	//everyone can act on their own resources
+	require.NoError(t, Authorize(customer, "hector", PermReadAnyAccount))
+	require.NoError(t, Authorize(&Payload{Username: "hector"}, "hector", PermManageUsers))
+
+	require.ErrorIs(t, Authorize(customer, "tina", PermReadAnyAccount), ErrForbidden)
+	require.ErrorIs(t, Authorize(&Payload{Username: "hector"}, "tina", PermReadAnyAccount), ErrForbidden)
+	require.NoError(t, Authorize(teller, "hector", PermReadAnyAccount))
+	require.ErrorIs(t, Authorize(teller, "hector", PermManageUsers), ErrForbidden)
+	require.ErrorIs(t, Authorize(teller, "hector", PermTransferAnyAccount), ErrForbidden)
+	require.NoError(t, Authorize(admin, "hector", PermManageUsers))
+	require.ErrorIs(t, Authorize(nil, "hector", PermReadAnyAccount), ErrForbidden)
+
+	require.True(t, RoleTeller.Valid())
+	require.False(t, Role("root").Valid())
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrSessionRevoked = errors.New("session revoked")
+
+const sessionCheckTimeout = 2 * time.Second
+
+// SessionChecker reports whether the login session an access token was issued for is still active.
+type SessionChecker interface {
+	CheckSession(ctx context.Context, payload *Payload) error
+}
+
+// ClientChecker reports whether a client credentials token is still good: its OAuth client
+// is registered and the token was not revoked.
+type ClientChecker interface {
+	CheckClientToken(ctx context.Context, payload *Payload) error
+}
+
+type sessionAuthenticator struct {
+	Authenticator
+	sessions SessionChecker
+	clients  ClientChecker
+}
+
+// WithSessionCheck wraps a to reject access tokens whose session has been revoked, even before
+// they expire. Tokens without a session id, such as refresh tokens, and single-purpose tokens
+// are rejected too, so the wrapped authenticator is for access tokens only. Client credentials
+// tokens have no session and are checked with clients instead; a nil clients rejects them.
+func WithSessionCheck(a Authenticator, sessions SessionChecker, clients ClientChecker) Authenticator {
+	return &sessionAuthenticator{Authenticator: a, sessions: sessions, clients: clients}
+}
+
+func (sa *sessionAuthenticator) VerifyToken(token string) (*Payload, error) {
+	return sa.VerifyTokenContext(context.Background(), token)
+}
+
+// VerifyTokenContext checks the session within ctx, and within sessionCheckTimeout at most.
+func (sa *sessionAuthenticator) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	payload, err := VerifyTokenContext(ctx, sa.Authenticator, token)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sessionCheckTimeout)
+	defer cancel()
+	if payload.IsClientToken() {
+		if sa.clients == nil {
+			return nil, ErrInvalidToken
+		}
+		if err := sa.clients.CheckClientToken(ctx, payload); err != nil {
+			return nil, ErrSessionRevoked
+		}
+		return payload, nil
+	}
+	if payload.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+	if err := sa.sessions.CheckSession(ctx, payload); err != nil {
+		return nil, ErrSessionRevoked
+	}
+	return payload, nil
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionCheckerFunc func(ctx context.Context, payload *Payload) error
+
+func (f sessionCheckerFunc) CheckSession(ctx context.Context, payload *Payload) error {
+	return f(ctx, payload)
+}
+
+type clientCheckerFunc func(ctx context.Context, payload *Payload) error
+
+func (f clientCheckerFunc) CheckClientToken(ctx context.Context, payload *Payload) error {
+	return f(ctx, payload)
+}
+
+func TestWithSessionCheck(t *testing.T) {
+	maker := newMaker(t, func() (Authenticator, error) { return NewJWTMaker(testSymmetricKey) })
+	active := uuid.New()
+	checked := WithSessionCheck(maker, sessionCheckerFunc(func(_ context.Context, payload *Payload) error {
+		if payload.SessionID != active.String() {
+			return errors.New("session is blocked")
+		}
+		return nil
+	}), nil)
+
+	token, _, err := checked.GenerateToken("hector", time.Minute, WithSessionID(active))
+	require.NoError(t, err)
+	payload, err := checked.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, active.String(), payload.SessionID)
+
+	token, _, err = checked.GenerateToken("hector", time.Minute, WithSessionID(uuid.New()))
+	require.NoError(t, err)
+	payload, err = checked.VerifyToken(token)
+	require.ErrorIs(t, err, ErrSessionRevoked)
+	require.Nil(t, payload)
+
+	//refresh tokens carry no session id and cannot be used as access tokens
+	token, _, err = checked.GenerateToken("hector", time.Minute)
+	require.NoError(t, err)
+	payload, err = checked.VerifyToken(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.Nil(t, payload)
+
+	//nor can single-purpose tokens, even with a live session id
+	token, _, err = checked.GenerateToken("hector", time.Minute, WithSessionID(active), WithPurpose(PurposeMFA))
+	require.NoError(t, err)
+	_, err = checked.VerifyToken(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	token, _, err = checked.GenerateToken("hector", -time.Minute, WithSessionID(active))
+	require.NoError(t, err)
+	_, err = checked.VerifyToken(token)
+	require.ErrorIs(t, err, ErrExpired)
+}
+
+func TestWithSessionCheckClientTokens(t *testing.T) {
+	maker := newMaker(t, func() (Authenticator, error) { return NewJWTMaker(testSymmetricKey) })
+	noSessions := sessionCheckerFunc(func(context.Context, *Payload) error {
+		return errors.New("no session expected")
+	})
+	checked := WithSessionCheck(maker, noSessions, clientCheckerFunc(func(_ context.Context, payload *Payload) error {
+		if payload.ClientID != "cl_partner" {
+			return errors.New("client revoked")
+		}
+		return nil
+	}))
+
+	token, _, err := checked.GenerateToken("", time.Minute, WithClient("cl_partner", []string{ScopeAccountsRead}))
+	require.NoError(t, err)
+	payload, err := checked.VerifyToken(token)
+	require.NoError(t, err)
+	require.True(t, payload.IsClientToken())
+
+	token, _, err = checked.GenerateToken("", time.Minute, WithClient("cl_revoked", []string{ScopeAccountsRead}))
+	require.NoError(t, err)
+	_, err = checked.VerifyToken(token)
+	require.ErrorIs(t, err, ErrSessionRevoked)
+
+	//tokens a client got for a user still need their session
+	token, _, err = checked.GenerateToken("hector", time.Minute, WithSessionID(uuid.New()), WithClient("cl_partner", []string{ScopeAccountsRead}))
+	require.NoError(t, err)
+	_, err = checked.VerifyToken(token)
+	require.ErrorIs(t, err, ErrSessionRevoked)
+
+	//without a client checker there is nothing to accept client tokens with
+	token, _, err = maker.GenerateToken("", time.Minute, WithClient("cl_partner", []string{ScopeAccountsRead}))
+	require.NoError(t, err)
+	_, err = WithSessionCheck(maker, noSessions, nil).VerifyToken(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestWithSessionCheckContext(t *testing.T) {
+	maker := newMaker(t, func() (Authenticator, error) { return NewJWTMaker(testSymmetricKey) })
+	type key struct{}
+	checked := WithSessionCheck(maker, sessionCheckerFunc(func(ctx context.Context, _ *Payload) error {
+		if ctx.Value(key{}) == nil {
+			return errors.New("not the request context")
+		}
+		return ctx.Err()
+	}), nil)
+
+	token, _, err := maker.GenerateToken("hector", time.Minute, WithSessionID(uuid.New()))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, true))
+	_, err = VerifyTokenContext(ctx, checked, token)
+	require.NoError(t, err)
+
+	//the lookup ends with the request
+	cancel()
+	_, err = VerifyTokenContext(ctx, checked, token)
+	require.ErrorIs(t, err, ErrSessionRevoked)
+}
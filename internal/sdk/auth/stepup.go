@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Assurance is how strongly the user proved who they are when a token's session was authenticated.
+type Assurance int
+
+const (
+	AssuranceNone Assurance = iota
+	AssurancePassword
+	AssuranceMFA
+)
+
+func (a Assurance) String() string {
+	return "aal" + strconv.Itoa(int(a))
+}
+
+// Operations that can demand step-up authentication.
+const (
+	OpHighValueTransfer = "high_value_transfer"
+	OpChangeEmail       = "change_email"
+	OpChangePassword    = "change_password"
+	OpShareAccounts     = "share_accounts"
+	OpRegisterPasskey   = "register_passkey"
+	OpAuthorizeClient   = "authorize_client"
+)
+
+// StepUpErrorCode is the RFC 9470 error telling clients to re-authenticate the user and retry.
+const StepUpErrorCode = "insufficient_user_authentication"
+
+var ErrStepUpRequired = errors.New("step-up authentication required")
+
+// Requirement is how recent and how strong a token's authentication must be for an operation.
+type Requirement struct {
+	MaxAge    time.Duration
+	Assurance Assurance
+}
+
+// Check returns a *StepUpError unless payload authenticated at r.Assurance or better within r.MaxAge.
+func (r Requirement) Check(payload *Payload) error {
+	if payload.Assurance < r.Assurance || payload.AuthTime == nil || time.Since(payload.AuthTime.Time) > r.MaxAge {
+		return &StepUpError{Requirement: r}
+	}
+	return nil
+}
+
+// StepUpPolicy maps operations to the authentication they need. Operations not listed need none.
+type StepUpPolicy map[string]Requirement
+
+func (p StepUpPolicy) Check(op string, payload *Payload) error {
+	r, ok := p[op]
+	if !ok {
+		return nil
+	}
+	return r.Check(payload)
+}
+
+// StepUpError reports the requirement a token failed; it matches ErrStepUpRequired.
+type StepUpError struct {
+	Requirement Requirement
+}
+
+func (e *StepUpError) Error() string {
+	return ErrStepUpRequired.Error()
+}
+
+func (e *StepUpError) Is(target error) bool {
+	return target == ErrStepUpRequired
+}
+
+func (e *StepUpError) maxAgeSeconds() int {
+	return int(e.Requirement.MaxAge / time.Second)
+}
+
+// WWWAuthenticate is the RFC 9470 challenge for http responses.
+func (e *StepUpError) WWWAuthenticate() string {
+	return fmt.Sprintf(`Bearer error=%q, error_description="re-authenticate and retry", max_age=%d, acr_values=%q`,
+		StepUpErrorCode, e.maxAgeSeconds(), e.Requirement.Assurance.String())
+}
+
+// Status is the Unauthenticated status returned to grpc callers, with an ErrorInfo detail
+// carrying the requirement.
+func (e *StepUpError) Status() *status.Status {
+	st := status.New(codes.Unauthenticated, "recent authentication required, re-authenticate and retry")
+	withInfo, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: "INSUFFICIENT_USER_AUTHENTICATION",
+		Domain: "bank",
+		Metadata: map[string]string{
+			"max_age":    strconv.Itoa(e.maxAgeSeconds()),
+			"acr_values": e.Requirement.Assurance.String(),
+		},
+	})
+	if err != nil {
+		return st
+	}
+	return withInfo
+}
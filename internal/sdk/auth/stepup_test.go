@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestStepUpPolicy(t *testing.T) {
+	policy := StepUpPolicy{
+		OpChangeEmail:       {MaxAge: 5 * time.Minute, Assurance: AssurancePassword},
+		OpHighValueTransfer: {MaxAge: 5 * time.Minute, Assurance: AssuranceMFA},
+	}
+	authenticatedAt := func(ago time.Duration, assurance Assurance) *Payload {
+		return &Payload{AuthTime: jwt.NewNumericDate(time.Now().Add(-ago)), Assurance: assurance}
+	}
+
+	require.NoError(t, policy.Check(OpChangeEmail, authenticatedAt(time.Minute, AssurancePassword)))
+	require.NoError(t, policy.Check(OpHighValueTransfer, authenticatedAt(time.Minute, AssuranceMFA)))
+	//operations without a requirement pass even without an auth time
+	require.NoError(t, policy.Check("unlisted", &Payload{}))
+
+	require.ErrorIs(t, policy.Check(OpChangeEmail, authenticatedAt(time.Hour, AssuranceMFA)), ErrStepUpRequired)
+	require.ErrorIs(t, policy.Check(OpChangeEmail, &Payload{Assurance: AssuranceMFA}), ErrStepUpRequired)
+	err := policy.Check(OpHighValueTransfer, authenticatedAt(time.Minute, AssurancePassword))
+	require.ErrorIs(t, err, ErrStepUpRequired)
+
+	stepUp, ok := err.(*StepUpError)
+	require.True(t, ok)
+	require.Equal(t, `Bearer error="insufficient_user_authentication", error_description="re-authenticate and retry", max_age=300, acr_values="aal2"`,
+		stepUp.WWWAuthenticate())
+
+	st := stepUp.Status()
+	require.Equal(t, codes.Unauthenticated, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, "300", info.Metadata["max_age"])
+	require.Equal(t, "aal2", info.Metadata["acr_values"])
+}
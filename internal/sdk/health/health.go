@@ -0,0 +1,173 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// Check probes a single dependency and returns an error when it cannot be used.
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status   Status `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker runs the registered dependency checks and mirrors the result
+// into the standard grpc.health.v1 service.
+type Checker struct {
+	mu       sync.RWMutex
+	checks   map[string]Check
+	services []string
+	timeout  time.Duration
+	server   *health.Server
+	logger   *zerolog.Logger
+}
+
+// NewChecker creates a checker that reports the given grpc service names
+// (the empty name is always reported as the overall server health).
+func NewChecker(logger *zerolog.Logger, services ...string) *Checker {
+	return &Checker{
+		checks:   make(map[string]Check),
+		services: append([]string{""}, services...),
+		timeout:  defaultTimeout,
+		server:   health.NewServer(),
+		logger:   logger,
+	}
+}
+
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// GRPCServer returns the grpc.health.v1 implementation to register on a grpc server.
+func (c *Checker) GRPCServer() healthpb.HealthServer {
+	return c.server
+}
+
+// Run executes every check concurrently, each bounded by the checker timeout.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(checks))}
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			result := CheckResult{Status: StatusUp}
+			if err := check(ctx); err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+			result.Duration = time.Since(start).String()
+
+			mu.Lock()
+			report.Checks[name] = result
+			if result.Status == StatusDown {
+				report.Status = StatusDown
+			}
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
+
+// Watch runs the checks every interval and updates the grpc serving status until ctx is done.
+func (c *Checker) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.update(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			c.server.Shutdown()
+			return
+		case <-ticker.C:
+			c.update(ctx)
+		}
+	}
+}
+
+func (c *Checker) update(ctx context.Context) {
+	report := c.Run(ctx)
+	status := healthpb.HealthCheckResponse_SERVING
+	if report.Status == StatusDown {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+		for name, result := range report.Checks {
+			if result.Status == StatusDown {
+				c.logger.Warn().
+					Str("check", name).
+					Str("error", result.Error).
+					Msg("health check failed")
+			}
+		}
+	}
+	for _, svc := range c.services {
+		c.server.SetServingStatus(svc, status)
+	}
+}
+
+// LivenessHandler reports that the process is up without probing dependencies.
+func (c *Checker) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusUp})
+	}
+}
+
+// ReadinessHandler probes every dependency and answers 503 when any of them is down.
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		code := http.StatusOK
+		if report.Status == StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+		writeReport(w, code, report)
+	}
+}
+
+func writeReport(w http.ResponseWriter, code int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestReadiness(t *testing.T) {
+	testCases := []struct {
+		name       string
+		checks     map[string]Check
+		statusCode int
+		status     Status
+	}{
+		{
+			name: "OK: all dependencies up",
+			checks: map[string]Check{
+				"postgres": func(ctx context.Context) error { return nil },
+				"redis":    func(ctx context.Context) error { return nil },
+			},
+			statusCode: http.StatusOK,
+			status:     StatusUp,
+		}, {
+			name: "Down: one dependency failing",
+			checks: map[string]Check{
+				"postgres": func(ctx context.Context) error { return nil },
+				"redis":    func(ctx context.Context) error { return errors.New("connection refused") },
+			},
+			statusCode: http.StatusServiceUnavailable,
+			status:     StatusDown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			checker := NewChecker(&logger)
+			for name, check := range tc.checks {
+				checker.Register(name, check)
+			}
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			checker.ReadinessHandler().ServeHTTP(rec, req)
+			require.Equal(t, tc.statusCode, rec.Code)
+
+			var report Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			require.Equal(t, tc.status, report.Status)
+			require.Len(t, report.Checks, len(tc.checks))
+		})
+	}
+}
+
+func TestLivenessIgnoresDependencies(t *testing.T) {
+	logger := zerolog.Nop()
+	checker := NewChecker(&logger)
+	checker.Register("postgres", func(ctx context.Context) error { return errors.New("down") })
+
+	rec := httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestGRPCStatus(t *testing.T) {
+	logger := zerolog.Nop()
+	checker := NewChecker(&logger, "pb.UserService")
+	failing := errors.New("down")
+	checker.Register("postgres", func(ctx context.Context) error { return failing })
+
+	checker.update(context.Background())
+	resp, err := checker.GRPCServer().Check(context.Background(), &healthpb.HealthCheckRequest{Service: "pb.UserService"})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	failing = nil
+	checker.Register("postgres", func(ctx context.Context) error { return failing })
+	checker.update(context.Background())
+	resp, err = checker.GRPCServer().Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
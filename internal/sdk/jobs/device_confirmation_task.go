@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TypeDeviceConfirmation = "task:device_confirmation"
+
+type DeviceConfirmationPayload struct {
+	Username string
+	// DeviceID is the unconfirmed known device; the worker issues the token for it so it never
+	// sits in the queue.
+	DeviceID  int64
+	Time      time.Time
+	ClientIP  string
+	UserAgent string
+	Metadata  map[string]string `json:",omitempty"`
+}
+
+func TaskDeviceConfirmation(arg *DeviceConfirmationPayload) (*asynq.Task, error) {
+	payload, err := json.Marshal(arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshall payload %w", err)
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueCritical),
+	}
+	return asynq.NewTask(TypeDeviceConfirmation, payload, opts...), nil
+}
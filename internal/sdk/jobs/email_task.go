@@ -12,10 +12,12 @@ const TypeEmailVerify = "task:verify_email"
 
 type VerifyEmailPayload struct {
 	Username string
+	// Metadata carries the enqueuing request's trace context and request id to the worker.
+	Metadata map[string]string `json:",omitempty"`
 }
 
-func TaskVerifyEmail(username string) (*asynq.Task, error) {
-	payload, err := json.Marshal(VerifyEmailPayload{Username: username})
+func TaskVerifyEmail(arg *VerifyEmailPayload) (*asynq.Task, error) {
+	payload, err := json.Marshal(arg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshall payload %w", err)
 	}
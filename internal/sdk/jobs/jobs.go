@@ -4,12 +4,21 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type TaskDistributor interface {
 	JobVerifyEmail(context.Context, *VerifyEmailPayload) error
+	JobPasswordReset(context.Context, *PasswordResetPayload) error
+	JobSecurityAlert(context.Context, *SecurityAlertPayload) error
+	JobDeviceConfirmation(context.Context, *DeviceConfirmationPayload) error
+	JobLoginLink(context.Context, *LoginLinkPayload) error
+	Ping() error
 }
 
 type TaskQueue struct {
@@ -22,11 +31,24 @@ func NewTaskQueue(redisOpt asynq.RedisClientOpt, logger *zerolog.Logger) TaskDis
 	return &TaskQueue{client: redisClient, logger: logger}
 }
 
+// Ping checks that the redis broker is reachable.
+func (jd *TaskQueue) Ping() error {
+	return jd.client.Ping()
+}
+
 // taskcreation and distribution
-func (jd *TaskQueue) JobVerifyEmail(ctx context.Context, payload *VerifyEmailPayload) error {
-	taskJob, err := TaskVerifyEmail(payload.Username)
+func (jd *TaskQueue) JobVerifyEmail(ctx context.Context, payload *VerifyEmailPayload) (err error) {
+	ctx, span := tracer.Start(ctx, "enqueue "+TypeEmailVerify,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.destination.name", QueueCritical)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	log := logger.FromCTX(ctx, jd.logger)
+	payload.Metadata = newMetadata(ctx)
+	taskJob, err := TaskVerifyEmail(payload)
 	if err != nil {
-		jd.logger.Error().
+		log.Error().
 			Err(err).
 			Str("username", payload.Username).
 			Str("task_type", TypeEmailVerify).
@@ -34,17 +56,16 @@ func (jd *TaskQueue) JobVerifyEmail(ctx context.Context, payload *VerifyEmailPay
 		return fmt.Errorf("create email verification task: %w", err)
 	}
 
-	info, err := jd.client.Enqueue(taskJob)
+	info, err := jd.client.EnqueueContext(ctx, taskJob)
 	if err != nil {
-		jd.logger.Error().
+		log.Error().
 			Err(err).
-			Str("queue", info.Queue).
 			Str("username", payload.Username).
 			Str("task_type", TypeEmailVerify).
 			Msg("failed to enqueue email verification task")
 		return fmt.Errorf("enqueue email verification task: %w", err)
 	}
-	jd.logger.Info().
+	log.Info().
 		Str("username", payload.Username).
 		Str("task_type", TypeEmailVerify).
 		Str("queue", info.Queue).
@@ -52,3 +73,145 @@ func (jd *TaskQueue) JobVerifyEmail(ctx context.Context, payload *VerifyEmailPay
 		Msg("succesfylly enqueued email verification task")
 	return nil
 }
+
+func (jd *TaskQueue) JobPasswordReset(ctx context.Context, payload *PasswordResetPayload) (err error) {
+	ctx, span := tracer.Start(ctx, "enqueue "+TypePasswordReset,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.destination.name", QueueCritical)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	log := logger.FromCTX(ctx, jd.logger)
+	payload.Metadata = newMetadata(ctx)
+	taskJob, err := TaskPasswordReset(payload)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("task_type", TypePasswordReset).
+			Msg("failed to create password reset task")
+		return fmt.Errorf("create password reset task: %w", err)
+	}
+
+	info, err := jd.client.EnqueueContext(ctx, taskJob)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("task_type", TypePasswordReset).
+			Msg("failed to enqueue password reset task")
+		return fmt.Errorf("enqueue password reset task: %w", err)
+	}
+	log.Info().
+		Str("task_type", TypePasswordReset).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued password reset task")
+	return nil
+}
+
+func (jd *TaskQueue) JobSecurityAlert(ctx context.Context, payload *SecurityAlertPayload) (err error) {
+	ctx, span := tracer.Start(ctx, "enqueue "+TypeSecurityAlert,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.destination.name", QueueCritical)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	log := logger.FromCTX(ctx, jd.logger)
+	payload.Metadata = newMetadata(ctx)
+	taskJob, err := TaskSecurityAlert(payload)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("username", payload.Username).
+			Str("task_type", TypeSecurityAlert).
+			Msg("failed to create security alert task")
+		return fmt.Errorf("create security alert task: %w", err)
+	}
+
+	info, err := jd.client.EnqueueContext(ctx, taskJob)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("username", payload.Username).
+			Str("task_type", TypeSecurityAlert).
+			Msg("failed to enqueue security alert task")
+		return fmt.Errorf("enqueue security alert task: %w", err)
+	}
+	log.Info().
+		Str("username", payload.Username).
+		Str("task_type", TypeSecurityAlert).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued security alert task")
+	return nil
+}
+
+func (jd *TaskQueue) JobDeviceConfirmation(ctx context.Context, payload *DeviceConfirmationPayload) (err error) {
+	ctx, span := tracer.Start(ctx, "enqueue "+TypeDeviceConfirmation,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.destination.name", QueueCritical)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	log := logger.FromCTX(ctx, jd.logger)
+	payload.Metadata = newMetadata(ctx)
+	taskJob, err := TaskDeviceConfirmation(payload)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("username", payload.Username).
+			Str("task_type", TypeDeviceConfirmation).
+			Msg("failed to create device confirmation task")
+		return fmt.Errorf("create device confirmation task: %w", err)
+	}
+
+	info, err := jd.client.EnqueueContext(ctx, taskJob)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("username", payload.Username).
+			Str("task_type", TypeDeviceConfirmation).
+			Msg("failed to enqueue device confirmation task")
+		return fmt.Errorf("enqueue device confirmation task: %w", err)
+	}
+	log.Info().
+		Str("username", payload.Username).
+		Str("task_type", TypeDeviceConfirmation).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued device confirmation task")
+	return nil
+}
+
+func (jd *TaskQueue) JobLoginLink(ctx context.Context, payload *LoginLinkPayload) (err error) {
+	ctx, span := tracer.Start(ctx, "enqueue "+TypeLoginLink,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.destination.name", QueueCritical)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	log := logger.FromCTX(ctx, jd.logger)
+	payload.Metadata = newMetadata(ctx)
+	taskJob, err := TaskLoginLink(payload)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("task_type", TypeLoginLink).
+			Msg("failed to create login link task")
+		return fmt.Errorf("create login link task: %w", err)
+	}
+
+	info, err := jd.client.EnqueueContext(ctx, taskJob)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("task_type", TypeLoginLink).
+			Msg("failed to enqueue login link task")
+		return fmt.Errorf("enqueue login link task: %w", err)
+	}
+	log.Info().
+		Str("task_type", TypeLoginLink).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued login link task")
+	return nil
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+const TypeLoginLink = "task:login_link"
+
+type LoginLinkPayload struct {
+	// Email is as entered by the requester; the worker looks the account up so the request
+	// itself never reveals whether it exists.
+	Email    string
+	Metadata map[string]string `json:",omitempty"`
+}
+
+func TaskLoginLink(arg *LoginLinkPayload) (*asynq.Task, error) {
+	payload, err := json.Marshal(arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshall payload %w", err)
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueCritical),
+	}
+	return asynq.NewTask(TypeLoginLink, payload, opts...), nil
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/0xOnah/bank/internal/sdk/jobs")
+
+const requestIDKey = "request_id"
+
+// newMetadata captures the request scoped values of ctx (the trace context and
+// request id) so they can travel with a task payload to the worker.
+func newMetadata(ctx context.Context) map[string]string {
+	md := make(map[string]string)
+	tracing.Inject(ctx, md)
+	if id := requestid.FromContext(ctx); id != "" {
+		md[requestIDKey] = id
+	}
+	return md
+}
+
+// contextFromMetadata restores the values captured by newMetadata into ctx.
+func contextFromMetadata(ctx context.Context, md map[string]string) context.Context {
+	ctx = tracing.Extract(ctx, md)
+	if id := md[requestIDKey]; requestid.Valid(id) {
+		ctx = requestid.NewContext(ctx, id)
+	}
+	return ctx
+}
+
+// taskLogger tags log with the request id restored into ctx, if any.
+func taskLogger(ctx context.Context, log *zerolog.Logger) *zerolog.Logger {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return log
+	}
+	l := log.With().Str("request_id", id).Logger()
+	return &l
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/mailer"
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type userStoreStub struct{}
+
+func (userStoreStub) GetUser(ctx context.Context, username string) (*entity.User, error) {
+	email, err := entity.NewEmail(username + "@example.com")
+	if err != nil {
+		return nil, err
+	}
+	return &entity.User{Username: username, Email: email}, nil
+}
+
+func (userStoreStub) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	username, domain, _ := strings.Cut(email, "@")
+	if domain != "example.com" {
+		return nil, repo.ErrUserNotFound
+	}
+	return userStoreStub{}.GetUser(ctx, username)
+}
+
+type verifyEmailStoreStub struct {
+	secretCodeHash string
+}
+
+func (s *verifyEmailStoreStub) CreateVerifyEmail(ctx context.Context, username, email, secretCodeHash string) (*entity.VerifyEmail, error) {
+	s.secretCodeHash = secretCodeHash
+	return &entity.VerifyEmail{ID: 1, Username: username, Email: email, SecretCodeHash: secretCodeHash}, nil
+}
+
+func TestVerifyEmailJoinsEnqueuingTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(nil, sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	ctx := requestid.NewContext(context.Background(), "req-123")
+	ctx, parent := provider.Tracer("test").Start(ctx, "CreateUser")
+	payload := &VerifyEmailPayload{Username: "hector", Metadata: newMetadata(ctx)}
+	parent.End()
+
+	task, err := TaskVerifyEmail(payload)
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs)
+	outbox := mailer.NewMemoryMailer("bank <no-reply@example.com>")
+	worker := &WorkerService{
+		userStore:        userStoreStub{},
+		verifyEmailStore: &verifyEmailStoreStub{},
+		mailer:           outbox,
+		baseURL:          "https://bank.example.com",
+		logger:           &logger,
+	}
+	require.NoError(t, worker.JobSendVerifyEmail(context.Background(), task))
+	msg, ok := outbox.Last()
+	require.True(t, ok)
+	require.Equal(t, []string{"hector@example.com"}, msg.To)
+	require.Contains(t, msg.Text, "https://bank.example.com/v1/verify_email?email_id=1&secret_code=")
+
+	var found bool
+	for _, span := range exporter.GetSpans() {
+		if span.Name != "JobSendVerifyEmail" {
+			continue
+		}
+		found = true
+		require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	}
+	require.True(t, found, "worker span not recorded")
+	require.Contains(t, logs.String(), `"request_id":"req-123"`)
+}
@@ -5,11 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/0xOnah/bank/internal/db/repo"
 	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/mailer"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -20,19 +29,77 @@ const (
 type TaskProcessor interface {
 	Start() error
 	JobSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	JobSendPasswordReset(ctx context.Context, task *asynq.Task) error
+	JobSendSecurityAlert(ctx context.Context, task *asynq.Task) error
+	JobSendDeviceConfirmation(ctx context.Context, task *asynq.Task) error
+	JobSendLoginLink(ctx context.Context, task *asynq.Task) error
 }
 
 type UserStore interface {
 	GetUser(ctx context.Context, username string) (*entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
 }
 
+type VerifyEmailStore interface {
+	CreateVerifyEmail(ctx context.Context, username, email, secretCodeHash string) (*entity.VerifyEmail, error)
+}
+
+type PasswordResetStore interface {
+	CreatePasswordReset(ctx context.Context, username, tokenHash string, expiresAt time.Time) error
+}
+
+type DeviceStore interface {
+	SetDeviceConfirmation(ctx context.Context, id int64, tokenHash string, expiresAt time.Time) error
+}
+
+type LoginLinkStore interface {
+	CreateLoginLink(ctx context.Context, id uuid.UUID, username string, expiresAt time.Time) error
+	CountLoginLinksSince(ctx context.Context, username string, since time.Time) (int64, error)
+}
+
+// passwordResetTTL is how long an emailed reset link works.
+const passwordResetTTL = 15 * time.Minute
+
+// deviceConfirmationTTL is how long an emailed device confirmation link works.
+const deviceConfirmationTTL = 30 * time.Minute
+
+// loginLinkTTL is how long an emailed login link works.
+const loginLinkTTL = 10 * time.Minute
+
+// At most loginLinkLimit links are emailed to an account per loginLinkWindow, however many ips
+// ask for them.
+const (
+	loginLinkLimit  = 3
+	loginLinkWindow = time.Hour
+)
+
 type WorkerService struct {
-	server    *asynq.Server
-	userStore UserStore
-	logger    *zerolog.Logger
+	server           *asynq.Server
+	userStore        UserStore
+	verifyEmailStore VerifyEmailStore
+	resetStore       PasswordResetStore
+	deviceStore      DeviceStore
+	loginLinkStore   LoginLinkStore
+	// tokens signs login links
+	tokens auth.Authenticator
+	mailer mailer.Mailer
+	// baseURL is where links in emails point, e.g. https://bank.example.com
+	baseURL string
+	logger  *zerolog.Logger
 }
 
-func NewWorkerService(redisOpt asynq.RedisClientOpt, usStore UserStore, logger *zerolog.Logger) TaskProcessor {
+func NewWorkerService(
+	redisOpt asynq.RedisClientOpt,
+	usStore UserStore,
+	veStore VerifyEmailStore,
+	prStore PasswordResetStore,
+	devStore DeviceStore,
+	linkStore LoginLinkStore,
+	tokens auth.Authenticator,
+	mail mailer.Mailer,
+	baseURL string,
+	logger *zerolog.Logger,
+) TaskProcessor {
 	server := asynq.NewServer(
 		redisOpt,
 		asynq.Config{
@@ -42,12 +109,23 @@ func NewWorkerService(redisOpt asynq.RedisClientOpt, usStore UserStore, logger *
 			},
 		},
 	)
-	return &WorkerService{server: server, userStore: usStore, logger: logger}
+	return &WorkerService{
+		server:           server,
+		userStore:        usStore,
+		verifyEmailStore: veStore,
+		resetStore:       prStore,
+		deviceStore:      devStore,
+		loginLinkStore:   linkStore,
+		tokens:           tokens,
+		mailer:           mail,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+		logger:           logger,
+	}
 }
 
-func (rt *WorkerService) JobSendVerifyEmail(ctx context.Context, t *asynq.Task) error {
+func (rt *WorkerService) JobSendVerifyEmail(ctx context.Context, t *asynq.Task) (err error) {
 	var payload VerifyEmailPayload
-	err := json.Unmarshal(t.Payload(), &payload)
+	err = json.Unmarshal(t.Payload(), &payload)
 	if err != nil {
 		rt.logger.Error().
 			Err(err).
@@ -56,34 +134,334 @@ func (rt *WorkerService) JobSendVerifyEmail(ctx context.Context, t *asynq.Task)
 		return fmt.Errorf("bad payload: %w", asynq.SkipRetry)
 	}
 
+	ctx = contextFromMetadata(ctx, payload.Metadata)
+	log := taskLogger(ctx, rt.logger)
+	ctx, span := tracer.Start(ctx, "JobSendVerifyEmail", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer func() { tracing.End(span, err) }()
+
 	user, err := rt.userStore.GetUser(ctx, payload.Username)
 	if err != nil {
-		if errors.Is(err, repo.ErrUserNotExist) {
-			rt.logger.Warn().
+		if errors.Is(err, repo.ErrUserNotFound) {
+			log.Warn().
 				Err(err).
 				Str("username", payload.Username).
 				Msg("JobSendVerifyEmail: user does not exist")
 			return fmt.Errorf("user not found: %w", asynq.SkipRetry)
 		}
-		rt.logger.Error().
+		log.Error().
 			Err(err).Str("username", payload.Username).
 			Msg("failed to get user")
-		return fmt.Errorf("get user: %w, %w", err, asynq.SkipRetry)
+		return fmt.Errorf("get user: %w", err)
+	}
+	if user.IsEmailVerified {
+		log.Info().Str("username", user.Username).Msg("JobSendVerifyEmail: email already verified")
+		return nil
+	}
+
+	secretCode, err := auth.NewSecret()
+	if err != nil {
+		return fmt.Errorf("generate secret code: %w", err)
+	}
+	verifyEmail, err := rt.verifyEmailStore.CreateVerifyEmail(ctx, user.Username, user.Email.String(), auth.HashSecret(secretCode))
+	if err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).
+			Msg("failed to create verify email")
+		return fmt.Errorf("create verify email: %w", err)
 	}
 
-	//Todo: send emai to user
+	link := fmt.Sprintf("%s/v1/verify_email?%s", rt.baseURL, url.Values{
+		"email_id":    {strconv.FormatInt(verifyEmail.ID, 10)},
+		"secret_code": {secretCode},
+	}.Encode())
+	msg, err := mailer.Render(mailer.TemplateVerifyEmail, user.Email.String(), mailer.VerifyEmailData{
+		Name:      user.FullName,
+		Link:      link,
+		ExpiresAt: verifyEmail.ExpiredAt,
+	})
+	if err != nil {
+		return fmt.Errorf("render verification email: %w", asynq.SkipRetry)
+	}
+	if err = rt.mailer.Send(ctx, msg); err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).
+			Msg("failed to send verification email")
+		return fmt.Errorf("send verification email: %w", err)
+	}
 
-	_ = user
-	rt.logger.Info().
+	log.Info().
 		Str("type", t.Type()).
 		Str("to", user.Email.String()).
+		Int64("email_id", verifyEmail.ID).
 		Msg("JobSendVerifyEmail: successfully sent verification email")
 	return nil
 }
 
+func (rt *WorkerService) JobSendPasswordReset(ctx context.Context, t *asynq.Task) (err error) {
+	var payload PasswordResetPayload
+	err = json.Unmarshal(t.Payload(), &payload)
+	if err != nil {
+		rt.logger.Error().
+			Err(err).
+			Msg("JobSendPasswordReset: failed to unmarshal payload")
+		return fmt.Errorf("bad payload: %w", asynq.SkipRetry)
+	}
+
+	ctx = contextFromMetadata(ctx, payload.Metadata)
+	log := taskLogger(ctx, rt.logger)
+	ctx, span := tracer.Start(ctx, "JobSendPasswordReset", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer func() { tracing.End(span, err) }()
+
+	email, err := entity.NewEmail(payload.Email)
+	if err != nil {
+		log.Info().Msg("JobSendPasswordReset: invalid email, nothing sent")
+		return nil
+	}
+	user, err := rt.userStore.GetUserByEmail(ctx, email.String())
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			//nothing to do, and the requester is never told
+			log.Info().Msg("JobSendPasswordReset: no account for email, nothing sent")
+			return nil
+		}
+		log.Error().Err(err).Msg("failed to get user by email")
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	token, err := auth.NewSecret()
+	if err != nil {
+		return fmt.Errorf("generate reset token: %w", err)
+	}
+	expiresAt := time.Now().Add(passwordResetTTL)
+	if err = rt.resetStore.CreatePasswordReset(ctx, user.Username, auth.HashSecret(token), expiresAt); err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).
+			Msg("failed to create password reset")
+		return fmt.Errorf("create password reset: %w", err)
+	}
+
+	msg, err := mailer.Render(mailer.TemplatePasswordReset, user.Email.String(), mailer.PasswordResetData{
+		Name:      user.FullName,
+		Link:      fmt.Sprintf("%s/reset_password?%s", rt.baseURL, url.Values{"token": {token}}.Encode()),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("render password reset email: %w", asynq.SkipRetry)
+	}
+	if err = rt.mailer.Send(ctx, msg); err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).
+			Msg("failed to send password reset email")
+		return fmt.Errorf("send password reset email: %w", err)
+	}
+
+	log.Info().
+		Str("type", t.Type()).
+		Str("username", user.Username).
+		Msg("JobSendPasswordReset: successfully sent password reset email")
+	return nil
+}
+
+func (rt *WorkerService) JobSendSecurityAlert(ctx context.Context, t *asynq.Task) (err error) {
+	var payload SecurityAlertPayload
+	err = json.Unmarshal(t.Payload(), &payload)
+	if err != nil {
+		rt.logger.Error().
+			Err(err).
+			Msg("JobSendSecurityAlert: failed to unmarshal payload")
+		return fmt.Errorf("bad payload: %w", asynq.SkipRetry)
+	}
+
+	ctx = contextFromMetadata(ctx, payload.Metadata)
+	log := taskLogger(ctx, rt.logger)
+	ctx, span := tracer.Start(ctx, "JobSendSecurityAlert", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := rt.userStore.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			log.Warn().
+				Str("username", payload.Username).
+				Msg("JobSendSecurityAlert: user does not exist")
+			return fmt.Errorf("user not found: %w", asynq.SkipRetry)
+		}
+		log.Error().Err(err).Str("username", payload.Username).Msg("failed to get user")
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	msg, err := mailer.Render(mailer.TemplateSecurityAlert, user.Email.String(), mailer.SecurityAlertData{
+		Name:      user.FullName,
+		Event:     payload.Event,
+		Time:      payload.Time,
+		ClientIP:  payload.ClientIP,
+		UserAgent: payload.UserAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("render security alert email: %w", asynq.SkipRetry)
+	}
+	if err = rt.mailer.Send(ctx, msg); err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).
+			Msg("failed to send security alert email")
+		return fmt.Errorf("send security alert email: %w", err)
+	}
+
+	log.Info().
+		Str("type", t.Type()).
+		Str("username", user.Username).
+		Msg("JobSendSecurityAlert: successfully sent security alert email")
+	return nil
+}
+
+func (rt *WorkerService) JobSendDeviceConfirmation(ctx context.Context, t *asynq.Task) (err error) {
+	var payload DeviceConfirmationPayload
+	err = json.Unmarshal(t.Payload(), &payload)
+	if err != nil {
+		rt.logger.Error().
+			Err(err).
+			Msg("JobSendDeviceConfirmation: failed to unmarshal payload")
+		return fmt.Errorf("bad payload: %w", asynq.SkipRetry)
+	}
+
+	ctx = contextFromMetadata(ctx, payload.Metadata)
+	log := taskLogger(ctx, rt.logger)
+	ctx, span := tracer.Start(ctx, "JobSendDeviceConfirmation", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := rt.userStore.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			log.Warn().
+				Str("username", payload.Username).
+				Msg("JobSendDeviceConfirmation: user does not exist")
+			return fmt.Errorf("user not found: %w", asynq.SkipRetry)
+		}
+		log.Error().Err(err).Str("username", payload.Username).Msg("failed to get user")
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	token, err := auth.NewSecret()
+	if err != nil {
+		return fmt.Errorf("generate device confirmation token: %w", err)
+	}
+	expiresAt := time.Now().Add(deviceConfirmationTTL)
+	if err = rt.deviceStore.SetDeviceConfirmation(ctx, payload.DeviceID, auth.HashSecret(token), expiresAt); err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).Int64("device_id", payload.DeviceID).
+			Msg("failed to store device confirmation")
+		return fmt.Errorf("store device confirmation: %w", err)
+	}
+
+	msg, err := mailer.Render(mailer.TemplateConfirmDevice, user.Email.String(), mailer.ConfirmDeviceData{
+		Name:      user.FullName,
+		Link:      fmt.Sprintf("%s/v1/devices/confirm?%s", rt.baseURL, url.Values{"token": {token}}.Encode()),
+		Time:      payload.Time,
+		ClientIP:  payload.ClientIP,
+		UserAgent: payload.UserAgent,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("render device confirmation email: %w", asynq.SkipRetry)
+	}
+	if err = rt.mailer.Send(ctx, msg); err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).
+			Msg("failed to send device confirmation email")
+		return fmt.Errorf("send device confirmation email: %w", err)
+	}
+
+	log.Info().
+		Str("type", t.Type()).
+		Str("username", user.Username).
+		Int64("device_id", payload.DeviceID).
+		Msg("JobSendDeviceConfirmation: successfully sent device confirmation email")
+	return nil
+}
+
+func (rt *WorkerService) JobSendLoginLink(ctx context.Context, t *asynq.Task) (err error) {
+	var payload LoginLinkPayload
+	err = json.Unmarshal(t.Payload(), &payload)
+	if err != nil {
+		rt.logger.Error().
+			Err(err).
+			Msg("JobSendLoginLink: failed to unmarshal payload")
+		return fmt.Errorf("bad payload: %w", asynq.SkipRetry)
+	}
+
+	ctx = contextFromMetadata(ctx, payload.Metadata)
+	log := taskLogger(ctx, rt.logger)
+	ctx, span := tracer.Start(ctx, "JobSendLoginLink", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer func() { tracing.End(span, err) }()
+
+	email, err := entity.NewEmail(payload.Email)
+	if err != nil {
+		log.Info().Msg("JobSendLoginLink: invalid email, nothing sent")
+		return nil
+	}
+	user, err := rt.userStore.GetUserByEmail(ctx, email.String())
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			//nothing to do, and the requester is never told
+			log.Info().Msg("JobSendLoginLink: no account for email, nothing sent")
+			return nil
+		}
+		log.Error().Err(err).Msg("failed to get user by email")
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	sent, err := rt.loginLinkStore.CountLoginLinksSince(ctx, user.Username, time.Now().Add(-loginLinkWindow))
+	if err != nil {
+		log.Error().Err(err).Str("username", user.Username).Msg("failed to count recent login links")
+		return fmt.Errorf("count login links: %w", err)
+	}
+	if sent >= loginLinkLimit {
+		//the requester is never told, like for unknown addresses
+		log.Warn().Str("username", user.Username).Msg("JobSendLoginLink: too many recent login links, nothing sent")
+		return nil
+	}
+
+	token, linkPayload, err := rt.tokens.GenerateToken(user.Username, loginLinkTTL, auth.WithPurpose(auth.PurposeLoginLink))
+	if err != nil {
+		return fmt.Errorf("sign login link: %w", err)
+	}
+	//the row is what makes the signed token single use
+	expiresAt := linkPayload.ExpiresAt.Time
+	if err = rt.loginLinkStore.CreateLoginLink(ctx, uuid.MustParse(linkPayload.ID), user.Username, expiresAt); err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).
+			Msg("failed to create login link")
+		return fmt.Errorf("create login link: %w", err)
+	}
+
+	msg, err := mailer.Render(mailer.TemplateLoginLink, user.Email.String(), mailer.LoginLinkData{
+		Name:      user.FullName,
+		Link:      fmt.Sprintf("%s/login_link?%s", rt.baseURL, url.Values{"token": {token}}.Encode()),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("render login link email: %w", asynq.SkipRetry)
+	}
+	if err = rt.mailer.Send(ctx, msg); err != nil {
+		log.Error().
+			Err(err).Str("username", user.Username).
+			Msg("failed to send login link email")
+		return fmt.Errorf("send login link email: %w", err)
+	}
+
+	log.Info().
+		Str("type", t.Type()).
+		Str("username", user.Username).
+		Msg("JobSendLoginLink: successfully sent login link email")
+	return nil
+}
+
 func (rt *WorkerService) Start() error {
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(TypeEmailVerify, rt.JobSendVerifyEmail)
+	mux.HandleFunc(TypePasswordReset, rt.JobSendPasswordReset)
+	mux.HandleFunc(TypeSecurityAlert, rt.JobSendSecurityAlert)
+	mux.HandleFunc(TypeDeviceConfirmation, rt.JobSendDeviceConfirmation)
+	mux.HandleFunc(TypeLoginLink, rt.JobSendLoginLink)
 
 	return rt.server.Run(mux)
 }
@@ -0,0 +1,225 @@
+package jobs
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/mailer"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type resetStoreStub struct {
+	username, tokenHash string
+}
+
+func (s *resetStoreStub) CreatePasswordReset(ctx context.Context, username, tokenHash string, expiresAt time.Time) error {
+	s.username, s.tokenHash = username, tokenHash
+	return nil
+}
+
+type deviceStoreStub struct {
+	id        int64
+	tokenHash string
+}
+
+func (s *deviceStoreStub) SetDeviceConfirmation(ctx context.Context, id int64, tokenHash string, expiresAt time.Time) error {
+	s.id, s.tokenHash = id, tokenHash
+	return nil
+}
+
+type loginLinkStoreStub struct {
+	id       uuid.UUID
+	username string
+	created  int64
+}
+
+func (s *loginLinkStoreStub) CreateLoginLink(ctx context.Context, id uuid.UUID, username string, expiresAt time.Time) error {
+	s.id, s.username = id, username
+	s.created++
+	return nil
+}
+
+func (s *loginLinkStoreStub) CountLoginLinksSince(ctx context.Context, username string, since time.Time) (int64, error) {
+	return s.created, nil
+}
+
+func TestJobSendVerifyEmail(t *testing.T) {
+	logger := zerolog.Nop()
+	outbox := mailer.NewMemoryMailer("bank <no-reply@example.com>")
+	store := &verifyEmailStoreStub{}
+	worker := &WorkerService{
+		userStore:        userStoreStub{},
+		verifyEmailStore: store,
+		mailer:           outbox,
+		baseURL:          "https://bank.example.com",
+		logger:           &logger,
+	}
+
+	task, err := TaskVerifyEmail(&VerifyEmailPayload{Username: "hector"})
+	require.NoError(t, err)
+	require.NoError(t, worker.JobSendVerifyEmail(context.Background(), task))
+
+	//only the hash of the emailed code is stored
+	msg, ok := outbox.Last()
+	require.True(t, ok)
+	_, rawLink, ok := strings.Cut(msg.Text, "https://bank.example.com/v1/verify_email?")
+	require.True(t, ok)
+	query, err := url.ParseQuery(strings.Fields(rawLink)[0])
+	require.NoError(t, err)
+	code := query.Get("secret_code")
+	require.NotEmpty(t, code)
+	require.NotEqual(t, code, store.secretCodeHash)
+	require.Equal(t, auth.HashSecret(code), store.secretCodeHash)
+}
+
+func TestJobSendPasswordReset(t *testing.T) {
+	logger := zerolog.Nop()
+	outbox := mailer.NewMemoryMailer("bank <no-reply@example.com>")
+	store := &resetStoreStub{}
+	worker := &WorkerService{
+		userStore:  userStoreStub{},
+		resetStore: store,
+		mailer:     outbox,
+		baseURL:    "https://bank.example.com",
+		logger:     &logger,
+	}
+
+	//unknown addresses are dropped quietly
+	task, err := TaskPasswordReset(&PasswordResetPayload{Email: "nobody@elsewhere.com"})
+	require.NoError(t, err)
+	require.NoError(t, worker.JobSendPasswordReset(context.Background(), task))
+	require.Empty(t, outbox.Messages())
+	require.Empty(t, store.tokenHash)
+
+	task, err = TaskPasswordReset(&PasswordResetPayload{Email: "Hector@Example.com"})
+	require.NoError(t, err)
+	require.NoError(t, worker.JobSendPasswordReset(context.Background(), task))
+
+	msg, ok := outbox.Last()
+	require.True(t, ok)
+	require.Equal(t, []string{"hector@example.com"}, msg.To)
+	require.Equal(t, "hector", store.username)
+
+	//only the hash of the emailed token is stored
+	_, rawLink, ok := strings.Cut(msg.Text, "https://bank.example.com/reset_password?")
+	require.True(t, ok)
+	query, err := url.ParseQuery(strings.Fields(rawLink)[0])
+	require.NoError(t, err)
+	token := query.Get("token")
+	require.NotEmpty(t, token)
+	require.NotEqual(t, token, store.tokenHash)
+	require.Equal(t, auth.HashSecret(token), store.tokenHash)
+}
+
+func TestJobSendSecurityAlert(t *testing.T) {
+	logger := zerolog.Nop()
+	outbox := mailer.NewMemoryMailer("bank <no-reply@example.com>")
+	worker := &WorkerService{userStore: userStoreStub{}, mailer: outbox, logger: &logger}
+
+	task, err := TaskSecurityAlert(&SecurityAlertPayload{
+		Username: "hector",
+		Event:    "Account locked",
+		Time:     time.Now(),
+		ClientIP: "203.0.113.7",
+	})
+	require.NoError(t, err)
+	require.NoError(t, worker.JobSendSecurityAlert(context.Background(), task))
+
+	msg, ok := outbox.Last()
+	require.True(t, ok)
+	require.Equal(t, []string{"hector@example.com"}, msg.To)
+	require.Contains(t, msg.Subject, "Account locked")
+	require.Contains(t, msg.Text, "203.0.113.7")
+}
+
+func TestJobSendDeviceConfirmation(t *testing.T) {
+	logger := zerolog.Nop()
+	outbox := mailer.NewMemoryMailer("bank <no-reply@example.com>")
+	store := &deviceStoreStub{}
+	worker := &WorkerService{
+		userStore:   userStoreStub{},
+		deviceStore: store,
+		mailer:      outbox,
+		baseURL:     "https://bank.example.com",
+		logger:      &logger,
+	}
+
+	task, err := TaskDeviceConfirmation(&DeviceConfirmationPayload{
+		Username:  "hector",
+		DeviceID:  42,
+		Time:      time.Now(),
+		ClientIP:  "203.0.113.7",
+		UserAgent: "Mozilla/5.0",
+	})
+	require.NoError(t, err)
+	require.NoError(t, worker.JobSendDeviceConfirmation(context.Background(), task))
+
+	msg, ok := outbox.Last()
+	require.True(t, ok)
+	require.Equal(t, []string{"hector@example.com"}, msg.To)
+	require.Contains(t, msg.Text, "203.0.113.7")
+	require.EqualValues(t, 42, store.id)
+
+	//only the hash of the emailed token is stored
+	_, rawLink, ok := strings.Cut(msg.Text, "https://bank.example.com/v1/devices/confirm?")
+	require.True(t, ok)
+	query, err := url.ParseQuery(strings.Fields(rawLink)[0])
+	require.NoError(t, err)
+	token := query.Get("token")
+	require.NotEmpty(t, token)
+	require.Equal(t, auth.HashSecret(token), store.tokenHash)
+}
+
+func TestJobSendLoginLink(t *testing.T) {
+	logger := zerolog.Nop()
+	outbox := mailer.NewMemoryMailer("bank <no-reply@example.com>")
+	store := &loginLinkStoreStub{}
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	worker := &WorkerService{
+		userStore:      userStoreStub{},
+		loginLinkStore: store,
+		tokens:         maker,
+		mailer:         outbox,
+		baseURL:        "https://bank.example.com",
+		logger:         &logger,
+	}
+
+	//unknown addresses are dropped quietly
+	task, err := TaskLoginLink(&LoginLinkPayload{Email: "nobody@elsewhere.com"})
+	require.NoError(t, err)
+	require.NoError(t, worker.JobSendLoginLink(context.Background(), task))
+	require.Empty(t, outbox.Messages())
+
+	task, err = TaskLoginLink(&LoginLinkPayload{Email: "hector@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, worker.JobSendLoginLink(context.Background(), task))
+
+	msg, ok := outbox.Last()
+	require.True(t, ok)
+	require.Equal(t, []string{"hector@example.com"}, msg.To)
+
+	//the link carries a signed token whose id is recorded for single use
+	_, rawLink, ok := strings.Cut(msg.Text, "https://bank.example.com/login_link?")
+	require.True(t, ok)
+	query, err := url.ParseQuery(strings.Fields(rawLink)[0])
+	require.NoError(t, err)
+	payload, err := maker.VerifyToken(query.Get("token"))
+	require.NoError(t, err)
+	require.Equal(t, auth.PurposeLoginLink, payload.Purpose)
+	require.Equal(t, "hector", payload.Username)
+	require.Equal(t, "hector", store.username)
+	require.Equal(t, payload.ID, store.id.String())
+
+	//requests from any number of ips can't flood one inbox
+	for range loginLinkLimit {
+		require.NoError(t, worker.JobSendLoginLink(context.Background(), task))
+	}
+	require.Len(t, outbox.Messages(), loginLinkLimit)
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TypeSecurityAlert = "task:security_alert"
+
+type SecurityAlertPayload struct {
+	Username string
+	// Event says what happened, e.g. "Account locked after repeated failed sign-ins"
+	Event     string
+	Time      time.Time
+	ClientIP  string
+	UserAgent string
+	Metadata  map[string]string `json:",omitempty"`
+}
+
+func TaskSecurityAlert(arg *SecurityAlertPayload) (*asynq.Task, error) {
+	payload, err := json.Marshal(arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshall payload %w", err)
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueCritical),
+	}
+	return asynq.NewTask(TypeSecurityAlert, payload, opts...), nil
+}
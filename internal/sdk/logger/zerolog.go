@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -89,11 +90,19 @@ func InitLogger(cfg *config.Config) (*zerolog.Logger, error) {
 	return logger, nil
 }
 
-// func WithCTXLogger(ctx context.Context, logger *zerolog.Logger) *zerolog.Logger {
-// 	return zerolog.Ctx(context.WithValue(ctx, CtxKey, logger))
-// }
+// WithCTXLogger stores a request scoped logger in ctx.
+func WithCTXLogger(ctx context.Context, logger *zerolog.Logger) context.Context {
+	return context.WithValue(ctx, CtxKey, logger)
+}
+
+// FromCTX returns the logger stored by WithCTXLogger, or fallback when ctx has none.
+func FromCTX(ctx context.Context, fallback *zerolog.Logger) *zerolog.Logger {
+	if logger, ok := ctx.Value(CtxKey).(*zerolog.Logger); ok {
+		return logger
+	}
+	return fallback
+}
 
-// func FromLoggerCTX(key string)
 func ServiceLogger(log *zerolog.Logger, svcName string) *zerolog.Logger {
 	svclog := log.With().Str("service", svcName).Logger()
 	return &svclog
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer delivers into a maildir, so local runs can read mail with any maildir client
+// or just cat the files in new/.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir == "" {
+		dir = "mail"
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, fmt.Errorf("create maildir: %w", err)
+		}
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (fm *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, err := encode(fm.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	//written to tmp and renamed into new, so readers never see partial messages
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d.%s.bank", now.UnixNano(), hex.EncodeToString(b))
+	tmp := filepath.Join(fm.dir, "tmp", name)
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(fm.dir, "new", name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("deliver message: %w", err)
+	}
+	return nil
+}
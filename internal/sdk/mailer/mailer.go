@@ -0,0 +1,141 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+)
+
+type Driver string
+
+const (
+	DriverSMTP   Driver = "smtp"
+	DriverFile   Driver = "file"
+	DriverMemory Driver = "memory"
+)
+
+// Message is an email with a plain text body and an optional html alternative.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers messages. Implementations set the From address themselves.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by MAIL_DRIVER: smtp, file (a maildir under MAIL_DIR) or
+// memory, which keeps messages in process for tests and local runs.
+func New(cfg *config.Config) (Mailer, error) {
+	if _, err := mail.ParseAddress(cfg.MAIL_FROM); err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM %q: %w", cfg.MAIL_FROM, err)
+	}
+	switch Driver(cfg.MAIL_DRIVER) {
+	case DriverSMTP:
+		return NewSMTPMailer(SMTPConfig{
+			Host:     cfg.SMTP_HOST,
+			Port:     cfg.SMTP_PORT,
+			Username: cfg.SMTP_USERNAME,
+			Password: cfg.SMTP_PASSWORD,
+			TLS:      TLSMode(cfg.SMTP_TLS),
+			From:     cfg.MAIL_FROM,
+		})
+	case DriverFile, "":
+		return NewFileMailer(cfg.MAIL_DIR, cfg.MAIL_FROM)
+	case DriverMemory:
+		return NewMemoryMailer(cfg.MAIL_FROM), nil
+	default:
+		return nil, fmt.Errorf("invalid mail driver %q", cfg.MAIL_DRIVER)
+	}
+}
+
+// encode renders msg as an RFC 5322 message, multipart/alternative when it has an html body.
+func encode(from string, msg Message, now time.Time) ([]byte, error) {
+	if len(msg.To) == 0 {
+		return nil, fmt.Errorf("message has no recipients")
+	}
+	for _, to := range msg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", from)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", `text/plain; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		return buf.Bytes(), writeQuotedPrintable(&buf, msg.Text)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{`text/plain; charset="utf-8"`, msg.Text},
+		{`text/html; charset="utf-8"`, msg.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	header("Content-Type", fmt.Sprintf(`multipart/alternative; boundary="%s"`, mw.Boundary()))
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = d
+		}
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
@@ -0,0 +1,234 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testFrom = "Bank <no-reply@bank.example.com>"
+
+func TestRender(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg, err := Render(TemplateVerifyEmail, "hector@example.com", VerifyEmailData{
+		Name:      "<b>hector</b>",
+		Link:      "https://bank.example.com/v1/verify_email?email_id=1&secret_code=abc",
+		ExpiresAt: expires,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"hector@example.com"}, msg.To)
+	require.Equal(t, "Verify your email address", msg.Subject)
+	require.Contains(t, msg.Text, "Hello <b>hector</b>,")
+	require.Contains(t, msg.Text, "email_id=1&secret_code=abc")
+	require.Contains(t, msg.Text, "Wed, 02 Jan 2030 03:04:05 UTC")
+	//the html part escapes user data
+	require.Contains(t, msg.HTML, "Hello &lt;b&gt;hector&lt;/b&gt;,")
+	require.Contains(t, msg.HTML, "email_id=1&amp;secret_code=abc")
+
+	for _, tc := range []struct {
+		name Template
+		data any
+	}{
+		{TemplatePasswordReset, PasswordResetData{Name: "hector", Link: "https://bank.example.com/reset", ExpiresAt: expires}},
+		{TemplateSecurityAlert, SecurityAlertData{Name: "hector", Event: "New sign-in", Time: expires, ClientIP: "203.0.113.7"}},
+		{TemplateConfirmDevice, ConfirmDeviceData{Name: "hector", Link: "https://bank.example.com/confirm", Time: expires, ExpiresAt: expires}},
+		{TemplateLoginLink, LoginLinkData{Name: "hector", Link: "https://bank.example.com/login_link", ExpiresAt: expires}},
+	} {
+		msg, err := Render(tc.name, "hector@example.com", tc.data)
+		require.NoError(t, err)
+		require.NotEmpty(t, msg.Subject)
+		require.NotEmpty(t, msg.Text)
+		require.NotEmpty(t, msg.HTML)
+	}
+
+	_, err = Render("missing", "hector@example.com", nil)
+	require.Error(t, err)
+}
+
+func testMessage() Message {
+	return Message{
+		To:      []string{"hector@example.com"},
+		Subject: "Héllo",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	}
+}
+
+// readParts parses an encoded message and returns its decoded parts by content type.
+func readParts(t *testing.T, r io.Reader) (*mail.Message, map[string]string) {
+	msg, err := mail.ReadMessage(r)
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		contentType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		parts[contentType] = string(body)
+	}
+	return msg, parts
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	fm, err := NewFileMailer(dir, testFrom)
+	require.NoError(t, err)
+	require.NoError(t, fm.Send(context.Background(), testMessage()))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	tmp, err := os.ReadDir(filepath.Join(dir, "tmp"))
+	require.NoError(t, err)
+	require.Empty(t, tmp)
+
+	f, err := os.Open(filepath.Join(dir, "new", entries[0].Name()))
+	require.NoError(t, err)
+	defer f.Close()
+
+	msg, parts := readParts(t, f)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, "Héllo", subject)
+	require.Equal(t, testFrom, msg.Header.Get("From"))
+	require.Equal(t, "hector@example.com", msg.Header.Get("To"))
+	require.True(t, strings.HasSuffix(msg.Header.Get("Message-ID"), "@bank.example.com>"))
+	require.Equal(t, "plain body", parts["text/plain"])
+	require.Equal(t, "<p>html body</p>", parts["text/html"])
+}
+
+func TestMemoryMailer(t *testing.T) {
+	mm := NewMemoryMailer(testFrom)
+	_, ok := mm.Last()
+	require.False(t, ok)
+
+	require.NoError(t, mm.Send(context.Background(), testMessage()))
+	last, ok := mm.Last()
+	require.True(t, ok)
+	require.Equal(t, testMessage(), last)
+	require.Len(t, mm.Messages(), 1)
+
+	require.Error(t, mm.Send(context.Background(), Message{Subject: "nobody"}))
+	require.Error(t, mm.Send(context.Background(), Message{To: []string{"not an address"}}))
+	require.Len(t, mm.Messages(), 1)
+}
+
+// fakeSMTPServer accepts one plain text session and returns the DATA it received.
+func fakeSMTPServer(t *testing.T, extensions ...string) (addr string, data <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.Fields(line)[0])
+			switch cmd {
+			case "EHLO":
+				reply("250-localhost")
+				for _, ext := range extensions {
+					reply("250-" + ext)
+				}
+				reply("250 8BITMIME")
+			case "DATA":
+				reply("354 go ahead")
+				var body strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					body.WriteString(line)
+				}
+				received <- body.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func newTestSMTPMailer(t *testing.T, addr string, mode TLSMode) *SMTPMailer {
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	sm, err := NewSMTPMailer(SMTPConfig{Host: host, Port: p, TLS: mode, From: testFrom})
+	require.NoError(t, err)
+	return sm
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, data := fakeSMTPServer(t)
+	sm := newTestSMTPMailer(t, addr, TLSNone)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, sm.Send(ctx, testMessage()))
+
+	_, parts := readParts(t, strings.NewReader(<-data))
+	require.Equal(t, "plain body", parts["text/plain"])
+}
+
+func TestSMTPMailerRequiresStartTLS(t *testing.T) {
+	addr, _ := fakeSMTPServer(t)
+	sm := newTestSMTPMailer(t, addr, TLSStartTLS)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := sm.Send(ctx, testMessage())
+	require.ErrorContains(t, err, "does not support STARTTLS")
+}
+
+func TestNewSMTPMailerConfig(t *testing.T) {
+	_, err := NewSMTPMailer(SMTPConfig{From: testFrom})
+	require.Error(t, err)
+	_, err = NewSMTPMailer(SMTPConfig{Host: "smtp.example.com", TLS: "ssl", From: testFrom})
+	require.Error(t, err)
+
+	sm, err := NewSMTPMailer(SMTPConfig{Host: "smtp.example.com", From: testFrom})
+	require.NoError(t, err)
+	require.Equal(t, TLSStartTLS, sm.cfg.TLS)
+	require.Equal(t, "smtp.example.com:587", sm.addr)
+}
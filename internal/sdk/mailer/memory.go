@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryMailer keeps sent messages in memory for tests to inspect.
+type MemoryMailer struct {
+	from     string
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer(from string) *MemoryMailer {
+	return &MemoryMailer{from: from}
+}
+
+func (mm *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	//encoded only to fail on the same bad input the other drivers reject
+	if _, err := encode(mm.from, msg, time.Now()); err != nil {
+		return err
+	}
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.messages = append(mm.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (mm *MemoryMailer) Messages() []Message {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return append([]Message(nil), mm.messages...)
+}
+
+// Last returns the most recently sent message.
+func (mm *MemoryMailer) Last() (Message, bool) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	if len(mm.messages) == 0 {
+		return Message{}, false
+	}
+	return mm.messages[len(mm.messages)-1], true
+}
@@ -0,0 +1,131 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+type TLSMode string
+
+const (
+	// TLSNone sends in plain text; only meant for local relays.
+	TLSNone TLSMode = "none"
+	// TLSStartTLS upgrades the connection with STARTTLS and fails if the server doesn't offer it.
+	TLSStartTLS TLSMode = "starttls"
+	// TLSImplicit connects over TLS from the start, usually on port 465.
+	TLSImplicit TLSMode = "tls"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLS      TLSMode
+	From     string
+}
+
+type SMTPMailer struct {
+	cfg       SMTPConfig
+	addr      string
+	tlsConfig *tls.Config
+}
+
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	switch cfg.TLS {
+	case "":
+		cfg.TLS = TLSStartTLS
+	case TLSNone, TLSStartTLS, TLSImplicit:
+	default:
+		return nil, fmt.Errorf("invalid smtp tls mode %q", cfg.TLS)
+	}
+	return &SMTPMailer{
+		cfg:       cfg,
+		addr:      net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		tlsConfig: &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12},
+	}, nil
+}
+
+func (sm *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := encode(sm.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(sm.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+
+	conn, err := sm.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("smtp dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, sm.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if sm.cfg.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", sm.addr)
+		}
+		if err := client.StartTLS(sm.tlsConfig); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if sm.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", sm.cfg.Username, sm.cfg.Password, sm.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	for _, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+		if err := client.Rcpt(addr.Address); err != nil {
+			return fmt.Errorf("smtp rcpt to: %w", err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return client.Quit()
+}
+
+func (sm *SMTPMailer) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if sm.cfg.TLS == TLSImplicit {
+		return (&tls.Dialer{NetDialer: dialer, Config: sm.tlsConfig}).DialContext(ctx, "tcp", sm.addr)
+	}
+	return dialer.DialContext(ctx, "tcp", sm.addr)
+}
@@ -0,0 +1,106 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+type Template string
+
+const (
+	TemplateVerifyEmail   Template = "verify_email"
+	TemplatePasswordReset Template = "password_reset"
+	TemplateSecurityAlert Template = "security_alert"
+	TemplateConfirmDevice Template = "confirm_device"
+	TemplateLoginLink     Template = "login_link"
+)
+
+// VerifyEmailData fills TemplateVerifyEmail.
+type VerifyEmailData struct {
+	Name      string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// PasswordResetData fills TemplatePasswordReset.
+type PasswordResetData struct {
+	Name      string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// SecurityAlertData fills TemplateSecurityAlert.
+type SecurityAlertData struct {
+	Name      string
+	Event     string
+	Time      time.Time
+	ClientIP  string
+	UserAgent string
+}
+
+// ConfirmDeviceData fills TemplateConfirmDevice.
+type ConfirmDeviceData struct {
+	Name      string
+	Link      string
+	Time      time.Time
+	ClientIP  string
+	UserAgent string
+	ExpiresAt time.Time
+}
+
+// LoginLinkData fills TemplateLoginLink.
+type LoginLinkData struct {
+	Name      string
+	Link      string
+	ExpiresAt time.Time
+}
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templates are parsed once; each text template defines "subject" and "body".
+var templates = func() map[Template]emailTemplate {
+	funcs := map[string]any{"formatTime": func(t time.Time) string { return t.UTC().Format(time.RFC1123) }}
+	set := make(map[Template]emailTemplate)
+	for _, name := range []Template{TemplateVerifyEmail, TemplatePasswordReset, TemplateSecurityAlert, TemplateConfirmDevice, TemplateLoginLink} {
+		set[name] = emailTemplate{
+			text: texttemplate.Must(texttemplate.New("").Funcs(funcs).ParseFS(templateFS, fmt.Sprintf("templates/%s.txt.tmpl", name))),
+			html: htmltemplate.Must(htmltemplate.New("").Funcs(funcs).ParseFS(templateFS, fmt.Sprintf("templates/%s.html.tmpl", name))),
+		}
+	}
+	return set
+}()
+
+// Render builds the message for template name addressed to to.
+func Render(name Template, to string, data any) (Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, fmt.Sprintf("%s.html.tmpl", name), data); err != nil {
+		return Message{}, fmt.Errorf("render %s html: %w", name, err)
+	}
+	return Message{
+		To:      []string{to},
+		Subject: subject.String(),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
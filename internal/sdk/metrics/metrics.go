@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+)
+
+const namespace = "bank"
+
+// Metrics owns the prometheus registry and the collectors shared by the transports and services.
+// A nil *Metrics is valid and records nothing, which keeps tests free of metric setup.
+type Metrics struct {
+	registry       *prometheus.Registry
+	grpcRequests   *prometheus.CounterVec
+	grpcDuration   *prometheus.HistogramVec
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	transfers      *prometheus.CounterVec
+	transferVolume *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "requests_total",
+			Help:      "Total unary grpc requests by method and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of unary grpc requests by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total http requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of http requests by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		transfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfers_created_total",
+			Help:      "Total transfers created by currency.",
+		}, []string{"currency"}),
+		transferVolume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfer_volume_total",
+			Help:      "Sum of transferred amounts in minor units by currency.",
+		}, []string{"currency"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.grpcRequests,
+		m.grpcDuration,
+		m.httpRequests,
+		m.httpDuration,
+		m.transfers,
+		m.transferVolume,
+	)
+	return m
+}
+
+// Register adds extra collectors such as the db pool and job queue collectors.
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewDBStatsCollector exports the sql.DBStats connection pool gauges.
+func NewDBStatsCollector(db *sql.DB) prometheus.Collector {
+	return collectors.NewDBStatsCollector(db, "postgres")
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveGRPC(method string, code codes.Code, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.grpcRequests.WithLabelValues(method, code.String()).Inc()
+	m.grpcDuration.WithLabelValues(method, code.String()).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveHTTP(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordTransfer(currency string, amount int64) {
+	if m == nil {
+		return
+	}
+	m.transfers.WithLabelValues(currency).Inc()
+	m.transferVolume.WithLabelValues(currency).Add(float64(amount))
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestNilMetricsIsNoop(t *testing.T) {
+	var m *Metrics
+	require.NotPanics(t, func() {
+		m.ObserveGRPC("/pb.UserService/LoginUser", codes.OK, time.Millisecond)
+		m.ObserveHTTP(http.MethodGet, "/v1/login_user", http.StatusOK, time.Millisecond)
+		m.RecordTransfer("USD", 100)
+	})
+}
+
+func TestRecordTransfer(t *testing.T) {
+	m := New()
+	m.RecordTransfer("USD", 100)
+	m.RecordTransfer("USD", 50)
+	m.RecordTransfer("EUR", 10)
+
+	require.Equal(t, 2.0, testutil.ToFloat64(m.transfers.WithLabelValues("USD")))
+	require.Equal(t, 150.0, testutil.ToFloat64(m.transferVolume.WithLabelValues("USD")))
+	require.Equal(t, 10.0, testutil.ToFloat64(m.transferVolume.WithLabelValues("EUR")))
+}
+
+func TestObserveRequests(t *testing.T) {
+	m := New()
+	m.ObserveGRPC("/pb.UserService/LoginUser", codes.Unauthenticated, time.Millisecond)
+	m.ObserveHTTP(http.MethodPost, "/v1/login_user", http.StatusOK, time.Millisecond)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.grpcRequests.WithLabelValues("/pb.UserService/LoginUser", "Unauthenticated")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodPost, "/v1/login_user", "200")))
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "bank_grpc_request_duration_seconds")
+}
+
+type fakeInspector struct {
+	err   error
+	infos map[string]*asynq.QueueInfo
+}
+
+func (f fakeInspector) Queues() ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var queues []string
+	for name := range f.infos {
+		queues = append(queues, name)
+	}
+	return queues, nil
+}
+
+func (f fakeInspector) GetQueueInfo(queue string) (*asynq.QueueInfo, error) {
+	return f.infos[queue], nil
+}
+
+func TestQueueCollector(t *testing.T) {
+	collector := NewQueueCollector(fakeInspector{infos: map[string]*asynq.QueueInfo{
+		"critical": {Queue: "critical", Size: 3, Pending: 2, Active: 1, ProcessedTotal: 10, FailedTotal: 1},
+	}})
+
+	expected := `
+# HELP bank_jobs_failed_total Total tasks that failed while being processed from the queue.
+# TYPE bank_jobs_failed_total counter
+bank_jobs_failed_total{queue="critical"} 1
+# HELP bank_jobs_queue_size Number of tasks in the queue across all states.
+# TYPE bank_jobs_queue_size gauge
+bank_jobs_queue_size{queue="critical"} 3
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "bank_jobs_failed_total", "bank_jobs_queue_size")
+	require.NoError(t, err)
+
+	broken := NewQueueCollector(fakeInspector{err: errors.New("redis down")})
+	err = testutil.CollectAndCompare(broken, strings.NewReader(`
+# HELP bank_jobs_scrape_error 1 if the last scrape of the job queues failed.
+# TYPE bank_jobs_scrape_error gauge
+bank_jobs_scrape_error 1
+`), "bank_jobs_scrape_error")
+	require.NoError(t, err)
+}
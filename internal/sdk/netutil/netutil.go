@@ -1,9 +1,9 @@
 package netutil
 
-//for getting the actual ip either form x-forwarded or x-realip headers falls back to remoteip
+//for getting the actual client ip, from x-forwarded-for only behind trusted proxies
 import (
+	"fmt"
 	"net"
-	"net/http"
 	"strings"
 )
 
@@ -42,28 +42,102 @@ func mustCIDR(cidr string) *net.IPNet {
 	return block
 }
 
-// GetClientIP extracts the best IP address from headers or RemoteAddr
-func GetClientIP(r *http.Request) string {
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		ips := strings.Split(xff, ",")
-		for _, ip := range ips {
-			ip = strings.TrimSpace(ip)
-			if ip != "" && !IsPrivate(ip) {
-				return ip
+// Proxies are the networks of the reverse proxies in front of the servers. Only addresses they
+// add to X-Forwarded-For are believed.
+type Proxies []*net.IPNet
+
+// ParseProxies reads a comma separated list of CIDRs or single addresses. An empty list trusts
+// no proxy.
+func ParseProxies(list string) (Proxies, error) {
+	var proxies Proxies
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", entry)
+			}
+			bits := 128
+			if v4 := ip.To4(); v4 != nil {
+				ip, bits = v4, 32
 			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
 		}
+		_, block, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy network %q: %w", entry, err)
+		}
+		proxies = append(proxies, block)
 	}
+	return proxies, nil
+}
 
-	xrip := strings.TrimSpace(r.Header.Get("X-Real-IP"))
-	if xrip != "" && !IsPrivate(xrip) {
-		return xrip
+func (p Proxies) trusts(ip net.IP) bool {
+	for _, block := range p {
+		if block.Contains(ip) {
+			return true
+		}
 	}
+	return false
+}
 
-	ip, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
-	if err == nil && !IsPrivate(ip) {
-		return ip
+// ClientIP returns the address of the client behind a connection from remoteAddr carrying the
+// forwardedFor X-Forwarded-For values. The header is read right to left only while each hop
+// came from a trusted proxy, so a client can't pick its address by sending the header itself.
+// Ports are dropped. It returns "" when remoteAddr doesn't parse.
+func (p Proxies) ClientIP(remoteAddr string, forwardedFor []string) string {
+	client := parseHost(remoteAddr)
+	if client == nil {
+		return ""
+	}
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && p.trusts(client); i-- {
+		hop := parseHost(hops[i])
+		if hop == nil {
+			break
+		}
+		client = hop
 	}
+	return client.String()
+}
+
+// Host returns the address of addr without its port, or "" when it doesn't parse.
+func Host(addr string) string {
+	ip := parseHost(addr)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
 
-	return ""
+func parseHost(addr string) net.IP {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// IPRange returns the network ip belongs to, the /24 for IPv4 and the /48 for IPv6, so addresses
+// handed out by the same provider compare equal. ip may carry a port. It returns "" when ip
+// doesn't parse.
+func IPRange(ip string) string {
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	parsedIP := net.ParseIP(strings.TrimSpace(ip))
+	if parsedIP == nil {
+		return ""
+	}
+	if v4 := parsedIP.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsedIP.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
 }
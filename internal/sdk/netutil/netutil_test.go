@@ -0,0 +1,153 @@
+package netutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProxies(t *testing.T) {
+	testCases := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Empty", list: "", want: nil},
+		{name: "Networks", list: "10.0.0.0/8, fd00::/8", want: []string{"10.0.0.0/8", "fd00::/8"}},
+		{name: "Single addresses", list: "10.1.2.3,::1", want: []string{"10.1.2.3/32", "::1/128"}},
+		{name: "Blank entries", list: " ,10.0.0.0/8,, ", want: []string{"10.0.0.0/8"}},
+		{name: "Bad address", list: "10.0.0.0/8,proxy.internal", wantErr: true},
+		{name: "Bad network", list: "10.0.0.0/33", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proxies, err := ParseProxies(tc.list)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var got []string
+			for _, block := range proxies {
+				got = append(got, block.String())
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseProxies("10.0.0.0/8,fd00::/8")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		proxies      Proxies
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+	}{
+		{
+			name:       "Direct",
+			proxies:    proxies,
+			remoteAddr: "203.0.113.7:51234",
+			want:       "203.0.113.7",
+		}, {
+			//without a trusted proxy in front the header is the client's own say
+			name:         "Spoofed header",
+			proxies:      proxies,
+			remoteAddr:   "203.0.113.7:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "203.0.113.7",
+		}, {
+			name:         "No proxies trusted",
+			proxies:      nil,
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "10.0.0.2",
+		}, {
+			name:         "Trusted proxy",
+			proxies:      proxies,
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"203.0.113.7"},
+			want:         "203.0.113.7",
+		}, {
+			//hops prepended by the client are left of the first untrusted one and are ignored
+			name:         "Several trusted hops",
+			proxies:      proxies,
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7", "10.0.0.3"},
+			want:         "203.0.113.7",
+		}, {
+			name:         "Every hop trusted",
+			proxies:      proxies,
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"10.0.0.4, 10.0.0.3"},
+			want:         "10.0.0.4",
+		}, {
+			name:         "Malformed hop",
+			proxies:      proxies,
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"203.0.113.7, unknown, 10.0.0.3"},
+			want:         "10.0.0.3",
+		}, {
+			name:         "Hops with ports",
+			proxies:      proxies,
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"203.0.113.7:51234, 10.0.0.3:8080"},
+			want:         "203.0.113.7",
+		}, {
+			name:         "IPv6 hops",
+			proxies:      proxies,
+			remoteAddr:   "[fd00::2]:443",
+			forwardedFor: []string{"[2001:db8::7]:51234"},
+			want:         "2001:db8::7",
+		}, {
+			name:         "IPv4-mapped IPv6",
+			proxies:      proxies,
+			remoteAddr:   "[::ffff:10.0.0.2]:443",
+			forwardedFor: []string{"::ffff:203.0.113.7"},
+			want:         "203.0.113.7",
+		}, {
+			name:       "Unparsable remote address",
+			proxies:    proxies,
+			remoteAddr: "pipe",
+			want:       "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.proxies.ClientIP(tc.remoteAddr, tc.forwardedFor))
+		})
+	}
+}
+
+func TestIPRange(t *testing.T) {
+	testCases := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{name: "IPv4", ip: "203.0.113.7", want: "203.0.113.0/24"},
+		{name: "IPv4 with port", ip: "203.0.113.7:51234", want: "203.0.113.0/24"},
+		{name: "IPv4-mapped IPv6", ip: "::ffff:203.0.113.7", want: "203.0.113.0/24"},
+		{name: "IPv6", ip: "2001:db8:1:2:3::7", want: "2001:db8:1::/48"},
+		{name: "IPv6 with port", ip: "[2001:db8:1:2:3::7]:443", want: "2001:db8:1::/48"},
+		{name: "Invalid", ip: "localhost", want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, IPRange(tc.ip))
+		})
+	}
+
+	//neighbours share a range and the next network over doesn't
+	require.Equal(t, IPRange("203.0.113.7"), IPRange("203.0.113.250"))
+	require.NotEqual(t, IPRange("203.0.113.7"), IPRange("203.0.114.7"))
+	require.Equal(t, IPRange("2001:db8:1:2::1"), IPRange("2001:db8:1:ffff::1"))
+	require.NotEqual(t, IPRange("2001:db8:1::1"), IPRange("2001:db8:2::1"))
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	"github.com/0xOnah/bank/internal/sdk/netutil"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type Store string
+
+const (
+	StoreMemory Store = "memory"
+	StoreRedis  Store = "redis"
+)
+
+// Rule is a token bucket: Burst requests at once, refilled at Rate tokens per second.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute allows n requests a minute, all of which may arrive at once.
+func PerMinute(n int) Rule {
+	return Rule{Rate: float64(n) / 60, Burst: n}
+}
+
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// RetryAfterSeconds rounds RetryAfter up to whole seconds, as the Retry-After header expects.
+func (r Result) RetryAfterSeconds() int {
+	return int(math.Ceil(r.RetryAfter.Seconds()))
+}
+
+// Status is the ResourceExhausted status returned to throttled callers, with a RetryInfo detail.
+func (r Result) Status() *status.Status {
+	st := status.New(codes.ResourceExhausted, "too many requests, retry later")
+	withRetry, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(r.RetryAfter)})
+	if err != nil {
+		return st
+	}
+	return withRetry
+}
+
+// Limiter takes a token from the bucket identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// NewLimiter returns the limiter for the store selected in config. The redis store shares
+// buckets between instances; the memory store is per process.
+func NewLimiter(cfg *config.Config, client redis.Scripter) (Limiter, error) {
+	switch Store(cfg.RATE_LIMIT_STORE) {
+	case StoreMemory, "":
+		return NewMemoryLimiter(), nil
+	case StoreRedis:
+		return NewRedisLimiter(client), nil
+	default:
+		return nil, fmt.Errorf("invalid rate limit store %q", cfg.RATE_LIMIT_STORE)
+	}
+}
+
+// Subject is who a bucket is kept for, e.g. a client ip or a username.
+type Subject struct {
+	Kind  string
+	Value string
+}
+
+// IP keys the bucket by the client's network rather than its address, as one IPv6 client
+// usually holds a whole /64.
+func IP(ip string) Subject {
+	return Subject{Kind: "ip", Value: netutil.IPRange(ip)}
+}
+
+func User(username string) Subject {
+	return Subject{Kind: "user", Value: username}
+}
+
+// Operation is a limited action. Its buckets are keyed by Name, so the grpc method and the
+// routes performing it draw from the same budget.
+type Operation struct {
+	Name string
+	Rule Rule
+}
+
+// Policy maps a grpc full method or "METHOD /route" to the operation it performs.
+// Methods and routes missing from the policy, or with a zero rule, are not limited.
+type Policy map[string]Operation
+
+// Enforcer applies a Policy using a Limiter. A nil Enforcer allows everything.
+type Enforcer struct {
+	limiter Limiter
+	policy  Policy
+}
+
+func NewEnforcer(limiter Limiter, policy Policy) *Enforcer {
+	return &Enforcer{limiter: limiter, policy: policy}
+}
+
+// Allow takes a token for the operation behind op from the bucket of every subject; the
+// request is rejected when any of them is empty, so a user is throttled across ips and an ip
+// across users.
+func (e *Enforcer) Allow(ctx context.Context, op string, subjects ...Subject) (Result, error) {
+	if e == nil {
+		return Result{Allowed: true}, nil
+	}
+	operation, ok := e.policy[op]
+	rule := operation.Rule
+	if !ok || rule.Rate <= 0 || rule.Burst <= 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	result := Result{Allowed: true, Remaining: rule.Burst}
+	for _, subject := range subjects {
+		if subject.Value == "" {
+			continue
+		}
+		res, err := e.limiter.Allow(ctx, key(operation.Name, subject), rule)
+		if err != nil {
+			return Result{}, fmt.Errorf("rate limit %s: %w", operation.Name, err)
+		}
+		if !res.Allowed {
+			return res, nil
+		}
+		result.Remaining = min(result.Remaining, res.Remaining)
+	}
+	return result, nil
+}
+
+func key(op string, subject Subject) string {
+	return "ratelimit:" + op + ":" + subject.Kind + ":" + subject.Value
+}
@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestLimiter() (*MemoryLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	limiter := NewMemoryLimiter()
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestMemoryLimiterTokenBucket(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	rule := PerMinute(3)
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		res, err := limiter.Allow(ctx, "k", rule)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, i, res.Remaining)
+	}
+
+	res, err := limiter.Allow(ctx, "k", rule)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 20*time.Second, res.RetryAfter)
+	require.Equal(t, 20, res.RetryAfterSeconds())
+
+	//other keys have their own bucket
+	res, err = limiter.Allow(ctx, "other", rule)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	clock.now = clock.now.Add(20 * time.Second)
+	res, err = limiter.Allow(ctx, "k", rule)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+}
+
+func TestMemoryLimiterSweepsFullBuckets(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	_, err := limiter.Allow(context.Background(), "k", PerMinute(3))
+	require.NoError(t, err)
+
+	clock.now = clock.now.Add(2 * sweepInterval)
+	_, err = limiter.Allow(context.Background(), "other", PerMinute(3))
+	require.NoError(t, err)
+	require.NotContains(t, limiter.buckets, "k")
+}
+
+func TestEnforcer(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	enforcer := NewEnforcer(limiter, Policy{
+		"login":    {Name: "login", Rule: PerMinute(2)},
+		"disabled": {Name: "disabled", Rule: PerMinute(0)},
+	})
+	ctx := context.Background()
+
+	//a user is throttled even when the guesses come from different ips
+	for _, ip := range []string{"10.0.0.1", "10.0.1.2"} {
+		res, err := enforcer.Allow(ctx, "login", IP(ip), User("hector"))
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+	res, err := enforcer.Allow(ctx, "login", IP("10.0.2.3"), User("hector"))
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	st := res.Status()
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 1)
+	require.IsType(t, &errdetails.RetryInfo{}, st.Details()[0])
+
+	for _, op := range []string{"unlisted", "disabled"} {
+		for range 5 {
+			res, err = enforcer.Allow(ctx, op, IP("10.0.0.1"))
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+		}
+	}
+
+	var nilEnforcer *Enforcer
+	res, err = nilEnforcer.Allow(ctx, "login", IP("10.0.0.1"))
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+}
+
+func TestEnforcerSharesOperations(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	login := Operation{Name: "login", Rule: PerMinute(2)}
+	enforcer := NewEnforcer(limiter, Policy{
+		"/pb.UserService/LoginUser": login,
+		"POST /v1/login_user":       login,
+		"POST /v1/login_user/mfa":   {Name: "verify_mfa", Rule: PerMinute(2)},
+	})
+	ctx := context.Background()
+
+	//the grpc method and its gateway route draw from one budget
+	for _, op := range []string{"/pb.UserService/LoginUser", "POST /v1/login_user"} {
+		res, err := enforcer.Allow(ctx, op, User("hector"))
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+	res, err := enforcer.Allow(ctx, "/pb.UserService/LoginUser", User("hector"))
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	//other operations keep their own
+	res, err = enforcer.Allow(ctx, "POST /v1/login_user/mfa", User("hector"))
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+}
+
+func TestIP(t *testing.T) {
+	testCases := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{"IPv4", "203.0.113.7", "203.0.113.0/24"},
+		{"IPv6", "2001:db8:1:2::7", "2001:db8:1::/48"},
+		{"With port", "203.0.113.7:4000", "203.0.113.0/24"},
+		{"Invalid", "not-an-ip", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, Subject{Kind: "ip", Value: tc.want}, IP(tc.ip))
+		})
+	}
+
+	//an IPv6 client rotating addresses inside its network stays in one bucket
+	limiter, _ := newTestLimiter()
+	enforcer := NewEnforcer(limiter, Policy{"login": {Name: "login", Rule: PerMinute(1)}})
+	res, err := enforcer.Allow(context.Background(), "login", IP("2001:db8:1:2::1"))
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	res, err = enforcer.Allow(context.Background(), "login", IP("2001:db8:1:ffff::2"))
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucket refills and takes from the bucket atomically, using the redis clock so
+// every instance agrees on the time. Fractions are returned as strings because redis
+// truncates lua numbers to integers.
+var tokenBucket = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = (1 - tokens) / rate
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000))
+return {allowed, tostring(tokens), tostring(retry)}
+`)
+
+// RedisLimiter keeps buckets in redis so every instance enforces the same limits.
+type RedisLimiter struct {
+	client redis.Scripter
+}
+
+func NewRedisLimiter(client redis.Scripter) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+func (rl *RedisLimiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	values, err := tokenBucket.Run(ctx, rl.client, []string{key}, rule.Rate, rule.Burst).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("run token bucket script: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected token bucket reply %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	tokens, err := parseFloat(values[1])
+	if err != nil {
+		return Result{}, err
+	}
+	retry, err := parseFloat(values[2])
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    allowed == 1,
+		Remaining:  int(tokens),
+		RetryAfter: time.Duration(retry * float64(time.Second)),
+	}, nil
+}
+
+func parseFloat(v any) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected token bucket value %v", v)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse token bucket value: %w", err)
+	}
+	return f, nil
+}
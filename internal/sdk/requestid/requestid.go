@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header is the http header a request id is accepted from and echoed in.
+	Header = "X-Request-ID"
+	// MetadataKey is the grpc metadata key a request id is accepted from and echoed in.
+	MetadataKey = "x-request-id"
+
+	maxLength = 128
+)
+
+type ctxKey struct{}
+
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid rejects ids a client could use to forge or flood log lines.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// FromIncoming returns the client supplied id when it is valid, otherwise a fresh one.
+func FromIncoming(id string) string {
+	if Valid(id) {
+		return id
+	}
+	return New()
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
@@ -0,0 +1,58 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const sealKeySize = 32
+
+var ErrUnseal = errors.New("cannot decrypt totp secret")
+
+// Sealer encrypts secrets with AES-256-GCM before they are stored, so a database dump alone is
+// not enough to generate codes.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+func NewSealer(key string) (*Sealer, error) {
+	if len(key) != sealKeySize {
+		return nil, fmt.Errorf("invalid key size, must be exactly %d characters", sealKeySize)
+	}
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal encrypts secret, binding it to username so a sealed secret cannot be moved to another user.
+func (s *Sealer) Seal(username, secret string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(secret), []byte(username))
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *Sealer) Open(username, sealed string) (string, error) {
+	b, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil || len(b) < s.aead.NonceSize() {
+		return "", ErrUnseal
+	}
+	nonce, ciphertext := b[:s.aead.NonceSize()], b[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, []byte(username))
+	if err != nil {
+		return "", ErrUnseal
+	}
+	return string(secret), nil
+}
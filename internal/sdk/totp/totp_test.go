@@ -0,0 +1,94 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeRFC6238(t *testing.T) {
+	//appendix B test vectors for SHA1, truncated to six digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tc := range cases {
+		got, err := Code(secret, time.Unix(tc.unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, got, "t=%d", tc.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+
+	current, err := Code(secret, now)
+	require.NoError(t, err)
+	step, ok := Validate(secret, current, now, 0)
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	//replaying the same step is refused
+	_, ok = Validate(secret, current, now, step)
+	require.False(t, ok)
+
+	//one step of drift either way is accepted, two is not
+	previous, err := Code(secret, now.Add(-Period))
+	require.NoError(t, err)
+	_, ok = Validate(secret, previous, now, 0)
+	require.True(t, ok)
+	stale, err := Code(secret, now.Add(-2*Period))
+	require.NoError(t, err)
+	if stale != current && stale != previous {
+		_, ok = Validate(secret, stale, now, 0)
+		require.False(t, ok)
+	}
+
+	_, ok = Validate(secret, "12345", now, 0)
+	require.False(t, ok)
+	_, ok = Validate("not base32!", current, now, 0)
+	require.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Bank", "hector", "JBSWY3DPEHPK3PXP")
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Bank:hector", u.Path)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	require.Equal(t, "Bank", u.Query().Get("issuer"))
+}
+
+func TestSealer(t *testing.T) {
+	_, err := NewSealer("short")
+	require.Error(t, err)
+
+	s, err := NewSealer("01234567890123456789012345678901")
+	require.NoError(t, err)
+	sealed, err := s.Seal("hector", "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	require.NotContains(t, sealed, "JBSWY3DPEHPK3PXP")
+
+	secret, err := s.Open("hector", sealed)
+	require.NoError(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", secret)
+
+	//sealed secrets are bound to their user
+	_, err = s.Open("achilles", sealed)
+	require.ErrorIs(t, err, ErrUnseal)
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/0xOnah/bank/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ServiceName = "bank"
+
+type Exporter string
+
+const (
+	ExporterNone   Exporter = "none"
+	ExporterStdout Exporter = "stdout"
+	ExporterOTLP   Exporter = "otlp"
+)
+
+// ShutdownFunc flushes pending spans and stops the tracer provider.
+type ShutdownFunc func(context.Context) error
+
+// InitTracer installs the global tracer provider and propagator for the exporter selected in config.
+// With no exporter configured spans are still created (so trace ids propagate) but never exported.
+func InitTracer(ctx context.Context, cfg *config.Config) (ShutdownFunc, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch Exporter(cfg.TRACING_EXPORTER) {
+	case ExporterNone, "":
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+		if cfg.TRACING_OTLP_ENDPOINT != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.TRACING_OTLP_ENDPOINT))
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter %q", cfg.TRACING_EXPORTER)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s exporter: %w", cfg.TRACING_EXPORTER, err)
+	}
+
+	ratio := cfg.TRACING_SAMPLE_RATIO
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	provider := NewProvider(exporter, sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))))
+	return provider.Shutdown, nil
+}
+
+// NewProvider builds a tracer provider batching to exporter and installs it globally.
+// Tests pass a nil exporter with sdktrace.WithSyncer(tracetest.NewInMemoryExporter())
+// to assert on recorded spans synchronously.
+func NewProvider(exporter sdktrace.SpanExporter, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(semconv.ServiceName(ServiceName))
+	opts = append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider
+}
+
+// End records err on the span, if any, and ends it. Use it with a named error return:
+//
+//	ctx, span := tracer.Start(ctx, "name")
+//	defer func() { tracing.End(span, err) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject writes the trace context of ctx into carrier so it survives a hop through the job queue.
+func Inject(ctx context.Context, carrier map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(carrier))
+}
+
+// Extract returns ctx with the remote trace context found in carrier.
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
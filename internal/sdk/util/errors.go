@@ -1,9 +1,18 @@
 package util
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
 
-func ErrorResponse(err error) gin.H {
-	return gin.H{"error": map[string]any{
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+func ErrorResponse(ctx context.Context, err error) gin.H {
+	body := map[string]any{
 		"message": err.Error(),
-	}}
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		body["request_id"] = id
+	}
+	return gin.H{"error": body}
 }
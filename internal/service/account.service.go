@@ -7,12 +7,15 @@ import (
 
 	"github.com/0xOnah/bank/internal/db/repo"
 	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
 	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
 )
 
 type AccountRepository interface {
 	AddAccountBalance(ctx context.Context, arg entity.AddAccountBalanceInput) (*entity.Account, error)
-	CreateAccount(ctx context.Context, arg entity.CreateAccountInput) (*entity.Account, error)
+	CreateAccount(ctx context.Context, arg entity.CreateAccountInput, event entity.AuditEvent) (*entity.Account, error)
 	DeleteAccount(ctx context.Context, id int64) error
 	GetAccountByID(ctx context.Context, id int64) (*entity.Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (*entity.Account, error)
@@ -28,8 +31,14 @@ func NewAccountService(repo AccountRepository) *AccountService {
 	return &AccountService{accountRepo: repo}
 }
 
-func (a *AccountService) CreateAccount(ctx context.Context, arg entity.CreateAccountInput) (*entity.Account, error) {
-	account, err := a.accountRepo.CreateAccount(ctx, arg)
+func (a *AccountService) CreateAccount(ctx context.Context, arg entity.CreateAccountInput) (_ *entity.Account, err error) {
+	ctx, span := tracer.Start(ctx, "AccountService.CreateAccount")
+	defer func() { tracing.End(span, err) }()
+
+	//the subject is filled in with the new account's id
+	event := audit.NewEvent(ctx, audit.ActionAccountCreate, arg.Owner, "")
+	event.Details = map[string]string{"currency": arg.Currency}
+	account, err := a.accountRepo.CreateAccount(ctx, arg, event)
 	if err != nil {
 		switch {
 		case errors.Is(err, repo.ErrDuplicateAccountCurrency):
@@ -43,7 +52,11 @@ func (a *AccountService) CreateAccount(ctx context.Context, arg entity.CreateAcc
 	return account, nil
 }
 
-func (a *AccountService) GetAccountByID(ctx context.Context, username string, id int64) (*entity.Account, error) {
+// GetAccountByID returns an account owned by the caller, or any account for staff who may read them.
+func (a *AccountService) GetAccountByID(ctx context.Context, payload *auth.Payload, id int64) (_ *entity.Account, err error) {
+	ctx, span := tracer.Start(ctx, "AccountService.GetAccountByID")
+	defer func() { tracing.End(span, err) }()
+
 	account, err := a.accountRepo.GetAccountByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, repo.ErrRecordNotFound) {
@@ -51,13 +64,20 @@ func (a *AccountService) GetAccountByID(ctx context.Context, username string, id
 		}
 		return nil, errorutil.NewAppError(errorutil.ErrUnknown, "failed to retrieve account due to an unexpected issue", err) // `err` here is the original repo error
 	}
-	if account.Owner != username {
-		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "cannot not retrieve data for this account", nil)
+	if err := auth.AuthorizeAccount(payload, account.Owner, account.ID, auth.PermReadAnyAccount); err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "cannot not retrieve data for this account", err)
 	}
 	return account, nil
 }
 
-func (a *AccountService) ListAccount(ctx context.Context, arg entity.ListAccountInput) ([]*entity.Account, error) {
+// ListAccount lists arg.User's accounts, which must be the caller's own unless they may read any account.
+func (a *AccountService) ListAccount(ctx context.Context, payload *auth.Payload, arg entity.ListAccountInput) (_ []*entity.Account, err error) {
+	ctx, span := tracer.Start(ctx, "AccountService.ListAccount")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(payload, arg.User, auth.PermReadAnyAccount); err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "cannot list accounts of this user", err)
+	}
 	accounts, err := a.accountRepo.ListAccount(ctx, arg)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxAccountConsentDuration is the longest a user may let a client read their accounts
+	// before consenting again, as PSD2 requires.
+	maxAccountConsentDuration   = 90 * 24 * time.Hour
+	defaultTransactionPageSize  = 50
+	maxTransactionPageSize      = 200
+	maxTransactionPage          = 10000
+	maxAccountConsentAccountIDs = 50
+)
+
+var (
+	errConsentInactive  = errors.New("account consent expired or revoked")
+	errConsentNotClient = errors.New("account consent belongs to another client or user")
+	errConsentDenied    = errors.New("account consent does not allow this read")
+)
+
+type AccountConsentRepository interface {
+	CreateAccountConsent(ctx context.Context, arg entity.AccountConsent, event entity.AuditEvent) (*entity.AccountConsent, error)
+	GetAccountConsent(ctx context.Context, id uuid.UUID) (*entity.AccountConsent, error)
+	ListAccountConsents(ctx context.Context, username string) ([]*entity.AccountConsent, error)
+	RevokeAccountConsent(ctx context.Context, id uuid.UUID, username string, event entity.AuditEvent) error
+}
+
+// AccountConsentService is the account information api for aggregators. A user consents to an
+// OAuth client reading chosen accounts for a limited time, and every read the client makes is
+// checked against that consent as it is now, so expiry and revocation apply at once.
+type AccountConsentService struct {
+	consents AccountConsentRepository
+	accounts AccountRepository
+	entries  EntryRepository
+	clients  OAuthRepository
+}
+
+func NewAccountConsentService(cr AccountConsentRepository, ar AccountRepository, er EntryRepository, or OAuthRepository) *AccountConsentService {
+	return &AccountConsentService{consents: cr, accounts: ar, entries: er, clients: or}
+}
+
+type CreateAccountConsentInput struct {
+	ClientID    string
+	AccountIDs  []int64
+	Permissions []string
+	ExpiresAt   time.Time
+}
+
+// CreateAccountConsent lets a client read the user's own accounts in arg until arg.ExpiresAt.
+func (ac *AccountConsentService) CreateAccountConsent(ctx context.Context, payload *auth.Payload, arg CreateAccountConsentInput) (_ *entity.AccountConsent, err error) {
+	ctx, span := tracer.Start(ctx, "AccountConsentService.CreateAccountConsent")
+	defer func() { tracing.End(span, err) }()
+
+	if !userLogin(payload) {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "only you can share your accounts", errNotUserLogin)
+	}
+	accountIDs := slices.Clone(arg.AccountIDs)
+	slices.Sort(accountIDs)
+	accountIDs = slices.Compact(accountIDs)
+	permissions := slices.Clone(arg.Permissions)
+	slices.Sort(permissions)
+	permissions = slices.Compact(permissions)
+
+	v := validator.NewValidator()
+	v.Check(arg.ClientID != "", "client_id", "cannot be empty")
+	v.Check(len(accountIDs) > 0 && len(accountIDs) <= maxAccountConsentAccountIDs, "account_ids", "must list 1 to 50 accounts")
+	v.Check(len(permissions) > 0, "permissions", "must grant at least one permission")
+	for _, permission := range permissions {
+		v.Check(permission == entity.AccountPermissionBalances || permission == entity.AccountPermissionTransactions, "permissions", "unknown permission "+permission)
+	}
+	v.Check(arg.ExpiresAt.After(time.Now()), "expires_at", "must be in the future")
+	v.Check(arg.ExpiresAt.Before(time.Now().Add(maxAccountConsentDuration)), "expires_at", "must be within 90 days")
+	if !v.Valid() {
+		return nil, v
+	}
+
+	client, err := ac.clients.GetOAuthClient(ctx, arg.ClientID)
+	if err != nil {
+		if errors.Is(err, repo.ErrOAuthClientNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "unknown client", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if client.Revoked() || !slices.Contains(client.Scopes, auth.ScopeAccountInformation) {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "client cannot read account information", errInvalidClient)
+	}
+	for _, id := range accountIDs {
+		account, err := ac.accounts.GetAccountByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repo.ErrRecordNotFound) {
+				return nil, errorutil.NewAppError(errorutil.ErrNotFound, fmt.Sprintf("account %d not found", id), err)
+			}
+			return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+		}
+		//not even staff may share accounts that are not theirs
+		if account.Owner != payload.Username {
+			return nil, errorutil.NewAppError(errorutil.ErrForbidden, fmt.Sprintf("account %d is not yours to share", id), auth.ErrForbidden)
+		}
+	}
+
+	id := uuid.New()
+	ids := make([]string, 0, len(accountIDs))
+	for _, accountID := range accountIDs {
+		ids = append(ids, strconv.FormatInt(accountID, 10))
+	}
+	event := audit.NewEvent(ctx, audit.ActionAccountConsentGrant, payload.Username, audit.AccountConsentSubject(id))
+	event.Details = map[string]string{
+		"client_id":   client.ID,
+		"account_ids": strings.Join(ids, ","),
+		"permissions": strings.Join(permissions, ","),
+		"expires_at":  arg.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	consent, err := ac.consents.CreateAccountConsent(ctx, entity.AccountConsent{
+		ID:          id,
+		Username:    payload.Username,
+		ClientID:    client.ID,
+		AccountIDs:  accountIDs,
+		Permissions: permissions,
+		ExpiresAt:   arg.ExpiresAt,
+	}, event)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return consent, nil
+}
+
+// ListAccountConsents returns every consent the user gave, newest first, including expired and
+// revoked ones.
+func (ac *AccountConsentService) ListAccountConsents(ctx context.Context, payload *auth.Payload) (_ []*entity.AccountConsent, err error) {
+	ctx, span := tracer.Start(ctx, "AccountConsentService.ListAccountConsents")
+	defer func() { tracing.End(span, err) }()
+
+	if !userLogin(payload) {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "only you can see who you share your accounts with", errNotUserLogin)
+	}
+	consents, err := ac.consents.ListAccountConsents(ctx, payload.Username)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return consents, nil
+}
+
+// RevokeAccountConsent stops the client reading the consent's accounts from its next request.
+func (ac *AccountConsentService) RevokeAccountConsent(ctx context.Context, payload *auth.Payload, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "AccountConsentService.RevokeAccountConsent")
+	defer func() { tracing.End(span, err) }()
+
+	if !userLogin(payload) {
+		return errorutil.NewAppError(errorutil.ErrForbidden, "only you can stop sharing your accounts", errNotUserLogin)
+	}
+	event := audit.NewEvent(ctx, audit.ActionAccountConsentRevoke, payload.Username, audit.AccountConsentSubject(id))
+	if err := ac.consents.RevokeAccountConsent(ctx, id, payload.Username, event); err != nil {
+		if errors.Is(err, repo.ErrAccountConsentNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "account consent not found or already revoked", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// GetAccountConsent returns a consent to the user who gave it or the client it was given to,
+// whatever its status, so clients can tell when to ask again.
+func (ac *AccountConsentService) GetAccountConsent(ctx context.Context, payload *auth.Payload, id uuid.UUID) (_ *entity.AccountConsent, err error) {
+	ctx, span := tracer.Start(ctx, "AccountConsentService.GetAccountConsent")
+	defer func() { tracing.End(span, err) }()
+
+	consent, err := ac.consents.GetAccountConsent(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrAccountConsentNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrNotFound, "account consent not found", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	owner := userLogin(payload) && payload.Username == consent.Username
+	if !owner && !consentClient(payload, consent) {
+		return nil, errorutil.NewAppError(errorutil.ErrNotFound, "account consent not found", errConsentNotClient)
+	}
+	return consent, nil
+}
+
+// ListConsentedAccounts returns the accounts a valid consent covers.
+func (ac *AccountConsentService) ListConsentedAccounts(ctx context.Context, payload *auth.Payload, consentID uuid.UUID) (_ []*entity.Account, err error) {
+	ctx, span := tracer.Start(ctx, "AccountConsentService.ListConsentedAccounts")
+	defer func() { tracing.End(span, err) }()
+
+	consent, err := ac.validConsent(ctx, payload, consentID)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]*entity.Account, 0, len(consent.AccountIDs))
+	for _, id := range consent.AccountIDs {
+		account, err := ac.accounts.GetAccountByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repo.ErrRecordNotFound) {
+				continue
+			}
+			return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+		}
+		if account.Owner == consent.Username {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+// GetConsentedAccountBalance returns an account, with its balance, if a valid consent covers it
+// and grants the balances permission.
+func (ac *AccountConsentService) GetConsentedAccountBalance(ctx context.Context, payload *auth.Payload, consentID uuid.UUID, accountID int64) (_ *entity.Account, err error) {
+	ctx, span := tracer.Start(ctx, "AccountConsentService.GetConsentedAccountBalance")
+	defer func() { tracing.End(span, err) }()
+
+	return ac.consentedAccount(ctx, payload, consentID, accountID, entity.AccountPermissionBalances)
+}
+
+type ListConsentedTransactionsInput struct {
+	ConsentID uuid.UUID
+	AccountID int64
+	// PageID starts at 1, which is also the default; PageSize defaults to 50.
+	PageID   int32
+	PageSize int32
+}
+
+// ListConsentedTransactions returns an account's entries if a valid consent covers it and grants
+// the transactions permission.
+func (ac *AccountConsentService) ListConsentedTransactions(ctx context.Context, payload *auth.Payload, arg ListConsentedTransactionsInput) (_ []entity.Entry, err error) {
+	ctx, span := tracer.Start(ctx, "AccountConsentService.ListConsentedTransactions")
+	defer func() { tracing.End(span, err) }()
+
+	v := validator.NewValidator()
+	v.Check(arg.PageID >= 0 && arg.PageID <= maxTransactionPage, "page_id", "must be between 0 and 10000")
+	v.Check(arg.PageSize >= 0 && arg.PageSize <= maxTransactionPageSize, "page_size", "must be between 0 and 200")
+	if !v.Valid() {
+		return nil, v
+	}
+	arg.PageID = max(arg.PageID, 1)
+	if arg.PageSize == 0 {
+		arg.PageSize = defaultTransactionPageSize
+	}
+
+	account, err := ac.consentedAccount(ctx, payload, arg.ConsentID, arg.AccountID, entity.AccountPermissionTransactions)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ac.entries.ListEntries(ctx, entity.ListEntriesInput{
+		AccountID: account.ID,
+		Limit:     arg.PageSize,
+		Offset:    (arg.PageID - 1) * arg.PageSize,
+	})
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return entries, nil
+}
+
+// consentedAccount checks the consent lets the caller read permission about the account, and
+// returns the account.
+func (ac *AccountConsentService) consentedAccount(ctx context.Context, payload *auth.Payload, consentID uuid.UUID, accountID int64, permission string) (*entity.Account, error) {
+	consent, err := ac.validConsent(ctx, payload, consentID)
+	if err != nil {
+		return nil, err
+	}
+	if !consent.Allows(permission) {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "account consent does not grant "+permission, errConsentDenied)
+	}
+	if !consent.Covers(accountID) {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, fmt.Sprintf("account consent does not cover account %d", accountID), errConsentDenied)
+	}
+	account, err := ac.accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, repo.ErrRecordNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrNotFound, fmt.Sprintf("account %d not found", accountID), err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if account.Owner != consent.Username {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, fmt.Sprintf("account consent does not cover account %d", accountID), errConsentDenied)
+	}
+	return account, nil
+}
+
+// validConsent loads the consent afresh and checks it was given to the calling client and has
+// neither expired nor been revoked.
+func (ac *AccountConsentService) validConsent(ctx context.Context, payload *auth.Payload, consentID uuid.UUID) (*entity.AccountConsent, error) {
+	consent, err := ac.consents.GetAccountConsent(ctx, consentID)
+	if err != nil {
+		if errors.Is(err, repo.ErrAccountConsentNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrNotFound, "account consent not found", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	//another client must not learn the consent exists
+	if !consentClient(payload, consent) {
+		return nil, errorutil.NewAppError(errorutil.ErrNotFound, "account consent not found", errConsentNotClient)
+	}
+	if status := consent.Status(); status != entity.AccountConsentValid {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "account consent is "+status, errConsentInactive)
+	}
+	return consent, nil
+}
+
+// consentClient reports whether payload is the client the consent was given to, acting for
+// itself or for the user who gave it.
+func consentClient(payload *auth.Payload, consent *entity.AccountConsent) bool {
+	if payload == nil || payload.ClientID == "" || payload.ClientID != consent.ClientID {
+		return false
+	}
+	return payload.Username == "" || payload.Username == consent.Username
+}
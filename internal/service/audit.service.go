@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+// AuditRepository reads the audit log. Events for writes are recorded by the repository doing
+// the write, in the same transaction; RecordAuditEvent is only for actions that write nothing else.
+type AuditRepository interface {
+	RecordAuditEvent(ctx context.Context, event entity.AuditEvent) error
+	ListAuditEvents(ctx context.Context, filter entity.AuditEventFilter) ([]*entity.AuditEvent, error)
+}
+
+type AuditService struct {
+	repo AuditRepository
+}
+
+func NewAuditService(repo AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// ListAuditEvents returns the newest events matching filter first. Pass the id of the last event
+// of a page as BeforeID to get the next one. Admins only.
+func (as *AuditService) ListAuditEvents(ctx context.Context, payload *auth.Payload, filter entity.AuditEventFilter) (_ []*entity.AuditEvent, err error) {
+	ctx, span := tracer.Start(ctx, "AuditService.ListAuditEvents")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Require(payload, auth.PermReadAuditLog); err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "you cannot read the audit log", err)
+	}
+	v := validator.NewValidator()
+	v.Check(filter.PageSize >= 0 && filter.PageSize <= maxAuditPageSize, "page_size", "must be between 0 and 200")
+	v.Check(filter.BeforeID >= 0, "before_id", "cannot be negative")
+	v.Check(filter.Since.IsZero() || filter.Until.IsZero() || filter.Since.Before(filter.Until), "since", "must be before until")
+	if !v.Valid() {
+		return nil, v
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = defaultAuditPageSize
+	}
+
+	events, err := as.repo.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/netutil"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/rs/zerolog"
+)
+
+// what the user is told in the security alert email
+const (
+	newDeviceAlertEvent  = "New sign-in from an unrecognized device"
+	newNetworkAlertEvent = "Sign-in from a new location on a recognized device"
+)
+
+type KnownDeviceRepository interface {
+	ListKnownDevices(ctx context.Context, username string) ([]*entity.KnownDevice, error)
+	SaveKnownDevice(ctx context.Context, arg entity.KnownDevice, confirm bool) (*entity.KnownDevice, error)
+	ConfirmKnownDevice(ctx context.Context, tokenHash string, event entity.AuditEvent) (*entity.KnownDevice, error)
+}
+
+// DeviceAlertQueue emails users about sign-ins from devices they haven't used before.
+type DeviceAlertQueue interface {
+	SecurityAlertQueue
+	JobDeviceConfirmation(ctx context.Context, payload *jobs.DeviceConfirmationPayload) error
+}
+
+// DeviceDetector remembers the devices, a user agent from an ip range, each user signs in from
+// and flags sign-ins from anything else. The first device a user signs in with is trusted as
+// there is nothing to compare it against. A nil DeviceDetector recognizes every device.
+type DeviceDetector struct {
+	repo   KnownDeviceRepository
+	alerts DeviceAlertQueue
+	// requireConfirmation holds back the tokens for an unrecognized device until the user
+	// approves it from an emailed link.
+	requireConfirmation bool
+	logger              *zerolog.Logger
+}
+
+// NewDeviceDetector returns a detector that emails an alert for each unrecognized device, or a
+// confirmation link when requireConfirmation is set. Without alerts nobody is emailed and no
+// confirmation is asked for; new devices are only logged.
+func NewDeviceDetector(repo KnownDeviceRepository, alerts DeviceAlertQueue, requireConfirmation bool, log *zerolog.Logger) *DeviceDetector {
+	return &DeviceDetector{
+		repo:                repo,
+		alerts:              alerts,
+		requireConfirmation: requireConfirmation && alerts != nil,
+		logger:              logger.ServiceLogger(log, "device_detector"),
+	}
+}
+
+// recognize records the device a user just signed in from and reports whether tokens may be
+// issued for it. It is false only while an unrecognized device waits for confirmation.
+func (d *DeviceDetector) recognize(ctx context.Context, username, clientIP, userAgent string) (bool, error) {
+	if d == nil {
+		return true, nil
+	}
+	device := entity.KnownDevice{
+		Username:      username,
+		UserAgentHash: auth.HashSecret(userAgent),
+		IPRange:       netutil.IPRange(clientIP),
+		UserAgent:     userAgent,
+		LastIP:        clientIP,
+	}
+	known, err := d.repo.ListKnownDevices(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	var confirmed int
+	var sameAgent, sameDevice bool
+	for _, k := range known {
+		if !k.Confirmed() {
+			continue
+		}
+		confirmed++
+		if k.UserAgentHash == device.UserAgentHash {
+			sameAgent = true
+			sameDevice = sameDevice || k.IPRange == device.IPRange
+		}
+	}
+
+	recognized := sameDevice || confirmed == 0
+	saved, err := d.repo.SaveKnownDevice(ctx, device, recognized || !d.requireConfirmation)
+	if err != nil {
+		return false, err
+	}
+	if recognized {
+		return true, nil
+	}
+
+	logger.FromCTX(ctx, d.logger).Warn().
+		Str("security_event", "new_device").
+		Str("username", username).
+		Str("client_ip", clientIP).
+		Str("user_agent", userAgent).
+		Bool("known_user_agent", sameAgent).
+		Bool("confirmation_required", d.requireConfirmation).
+		Msg("sign-in from an unrecognized device")
+	if d.alerts == nil {
+		return true, nil
+	}
+
+	now := time.Now()
+	if d.requireConfirmation {
+		err = d.alerts.JobDeviceConfirmation(ctx, &jobs.DeviceConfirmationPayload{
+			Username:  username,
+			DeviceID:  saved.ID,
+			Time:      now,
+			ClientIP:  clientIP,
+			UserAgent: userAgent,
+		})
+		//without the email the user has no way to confirm, so the login can't go on
+		return false, err
+	}
+
+	event := newDeviceAlertEvent
+	if sameAgent {
+		event = newNetworkAlertEvent
+	}
+	err = d.alerts.JobSecurityAlert(ctx, &jobs.SecurityAlertPayload{
+		Username:  username,
+		Event:     event,
+		Time:      now,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		logger.FromCTX(ctx, d.logger).Error().Err(err).Str("username", username).Msg("failed to queue new device alert")
+	}
+	return true, nil
+}
+
+// confirm approves the device the token was emailed for.
+func (d *DeviceDetector) confirm(ctx context.Context, token string) (*entity.KnownDevice, error) {
+	if d == nil {
+		return nil, repo.ErrInvalidDeviceToken
+	}
+	//the repository fills in whose device it was
+	event := audit.NewEvent(ctx, audit.ActionDeviceConfirm, "", "")
+	return d.repo.ConfirmKnownDevice(ctx, auth.HashSecret(token), event)
+}
+
+// ConfirmDevice approves the device a confirmation link was emailed for, so signing in from it
+// again goes through.
+func (us *userService) ConfirmDevice(ctx context.Context, token string) (err error) {
+	ctx, span := tracer.Start(ctx, "userService.ConfirmDevice")
+	defer func() { tracing.End(span, err) }()
+
+	v := validator.NewValidator()
+	v.Check(token != "", "token", "cannot be empty")
+	if !v.Valid() {
+		return v
+	}
+	device, err := us.devices.confirm(ctx, token)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidDeviceToken) {
+			return errorutil.NewAppError(errorutil.ErrBadRequest, "invalid or expired confirmation link", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	logger.FromCTX(ctx, us.logger).Info().
+		Str("security_event", "device_confirmed").
+		Str("username", device.Username).
+		Int64("device_id", device.ID).
+		Msg("new device confirmed")
+	return nil
+}
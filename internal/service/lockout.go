@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/netutil"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/rs/zerolog"
+)
+
+var errLoginLocked = errors.New("login locked after repeated failures")
+
+// lockoutAlertEvent is what the user is told in the security alert email.
+const lockoutAlertEvent = "Sign-in locked after repeated failed attempts"
+
+type LoginFailureRepository interface {
+	ListLoginLocks(ctx context.Context, subjects []string) ([]*entity.LoginFailure, error)
+	RecordLoginFailure(ctx context.Context, subject string, windowStart time.Time) (*entity.LoginFailure, error)
+	LockLoginSubject(ctx context.Context, subject string, until time.Time) (*entity.LoginFailure, error)
+	ClearLoginFailures(ctx context.Context, subject string) error
+	UnlockLoginSubject(ctx context.Context, subject string, event entity.AuditEvent) error
+}
+
+// SecurityAlertQueue emails users about activity on their account.
+type SecurityAlertQueue interface {
+	JobSecurityAlert(ctx context.Context, payload *jobs.SecurityAlertPayload) error
+}
+
+// LockoutPolicy says when repeated failed logins lock a username or client ip out. A zero
+// threshold never locks that subject.
+type LockoutPolicy struct {
+	Threshold   int
+	IPThreshold int
+	Window      time.Duration
+	// Duration is how long the first lock lasts; each lock after it doubles, up to MaxDuration.
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// LoginLockout counts failed logins per username and per client ip and locks them out for an
+// escalating period. Usernames are tracked whether or not they exist, so a lockout says nothing
+// about which accounts are real. A nil LoginLockout never locks anyone out.
+type LoginLockout struct {
+	repo   LoginFailureRepository
+	alerts SecurityAlertQueue
+	policy LockoutPolicy
+	logger *zerolog.Logger
+}
+
+func NewLoginLockout(repo LoginFailureRepository, alerts SecurityAlertQueue, policy LockoutPolicy, log *zerolog.Logger) *LoginLockout {
+	return &LoginLockout{
+		repo:   repo,
+		alerts: alerts,
+		policy: policy,
+		logger: logger.ServiceLogger(log, "login_lockout"),
+	}
+}
+
+func userSubject(username string) string { return "user:" + username }
+
+// ipSubject groups failures by the network of ip, so changing ports or addresses within it
+// doesn't reset the count. It returns "" when ip doesn't parse.
+func ipSubject(ip string) string {
+	network := netutil.IPRange(ip)
+	if network == "" {
+		return ""
+	}
+	return "ip:" + network
+}
+
+// check returns errLoginLocked if the username or client ip is locked out.
+func (l *LoginLockout) check(ctx context.Context, username, clientIP string) error {
+	if l == nil {
+		return nil
+	}
+	subjects := []string{userSubject(username)}
+	if subject := ipSubject(clientIP); subject != "" {
+		subjects = append(subjects, subject)
+	}
+	locks, err := l.repo.ListLoginLocks(ctx, subjects)
+	if err != nil {
+		return err
+	}
+	if len(locks) > 0 {
+		return errLoginLocked
+	}
+	return nil
+}
+
+// failed records a failed login, locking the username or ip once it passes its threshold.
+// The user is only emailed when the account exists. Failures here are logged, not returned,
+// so the caller's response is the same either way.
+func (l *LoginLockout) failed(ctx context.Context, arg Logininput, userExists bool) {
+	if l == nil {
+		return
+	}
+	now := time.Now()
+	if l.record(ctx, userSubject(arg.Username), l.policy.Threshold, now) && userExists && l.alerts != nil {
+		err := l.alerts.JobSecurityAlert(ctx, &jobs.SecurityAlertPayload{
+			Username:  arg.Username,
+			Event:     lockoutAlertEvent,
+			Time:      now,
+			ClientIP:  arg.ClientIP,
+			UserAgent: arg.UserAgent,
+		})
+		if err != nil {
+			logger.FromCTX(ctx, l.logger).Error().Err(err).Str("username", arg.Username).Msg("failed to queue lockout alert")
+		}
+	}
+	if subject := ipSubject(arg.ClientIP); subject != "" {
+		l.record(ctx, subject, l.policy.IPThreshold, now)
+	}
+}
+
+// record counts a failure for subject and reports whether it locked it.
+func (l *LoginLockout) record(ctx context.Context, subject string, threshold int, now time.Time) bool {
+	log := logger.FromCTX(ctx, l.logger)
+	failure, err := l.repo.RecordLoginFailure(ctx, subject, now.Add(-l.policy.Window))
+	if err != nil {
+		log.Error().Err(err).Str("subject", subject).Msg("failed to record login failure")
+		return false
+	}
+	if threshold <= 0 || failure.FailedAttempts < threshold {
+		return false
+	}
+
+	until := now.Add(l.lockDuration(failure.Lockouts))
+	if _, err := l.repo.LockLoginSubject(ctx, subject, until); err != nil {
+		log.Error().Err(err).Str("subject", subject).Msg("failed to lock login subject")
+		return false
+	}
+	log.Warn().
+		Str("security_event", "login_lockout").
+		Str("subject", subject).
+		Int("lockouts", failure.Lockouts+1).
+		Time("locked_until", until).
+		Msg("locked out after repeated failed logins")
+	return true
+}
+
+// lockDuration doubles the base duration for every earlier lockout.
+func (l *LoginLockout) lockDuration(previous int) time.Duration {
+	d := l.policy.Duration
+	for i := 0; i < previous && d < l.policy.MaxDuration; i++ {
+		d *= 2
+	}
+	return min(d, l.policy.MaxDuration)
+}
+
+// succeeded forgets the username's failures after a correct password.
+func (l *LoginLockout) succeeded(ctx context.Context, username string) {
+	if l == nil {
+		return
+	}
+	if err := l.repo.ClearLoginFailures(ctx, userSubject(username)); err != nil {
+		logger.FromCTX(ctx, l.logger).Error().Err(err).Str("username", username).Msg("failed to clear login failures")
+	}
+}
+
+// unlock lifts any lock on the username and resets its escalation, recording event.
+func (l *LoginLockout) unlock(ctx context.Context, username string, event entity.AuditEvent) error {
+	if l == nil {
+		return nil
+	}
+	return l.repo.UnlockLoginSubject(ctx, userSubject(username), event)
+}
+
+// UnlockUser lifts a login lockout on username before it runs out. Admins only.
+func (us *userService) UnlockUser(ctx context.Context, payload *auth.Payload, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "userService.UnlockUser")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Require(payload, auth.PermManageUsers); err != nil {
+		return errorutil.NewAppError(errorutil.ErrForbidden, "you cannot manage users", err)
+	}
+	if _, err := us.UserRepo.GetUser(ctx, username); err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "user not found", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	event := audit.NewEvent(ctx, audit.ActionUserUnlock, payload.Username, audit.UserSubject(username))
+	if err := us.lockout.unlock(ctx, username, event); err != nil {
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
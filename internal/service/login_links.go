@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+)
+
+var errNotLoginLinkToken = errors.New("token is not a login link")
+
+type LoginLinkRepository interface {
+	UseLoginLink(ctx context.Context, id uuid.UUID) (string, error)
+}
+
+type LoginLinkQueue interface {
+	JobLoginLink(ctx context.Context, payload *jobs.LoginLinkPayload) error
+}
+
+// LoginLinks lets users sign in with a link emailed to them instead of their password. The
+// worker signs each link and records its id; spending that record makes the link single use.
+// A nil LoginLinks turns the feature off.
+type LoginLinks struct {
+	repo  LoginLinkRepository
+	tasks LoginLinkQueue
+}
+
+func NewLoginLinks(repo LoginLinkRepository, tasks LoginLinkQueue) *LoginLinks {
+	return &LoginLinks{repo: repo, tasks: tasks}
+}
+
+// RequestLoginLink queues a login link for email. Only the worker checks whether the address
+// belongs to anyone, when it builds the link, so the reply can't be used to find accounts.
+func (us *userService) RequestLoginLink(ctx context.Context, email string) (err error) {
+	ctx, span := tracer.Start(ctx, "userService.RequestLoginLink")
+	defer func() { tracing.End(span, err) }()
+
+	if us.links == nil {
+		return errorutil.NewAppError(errorutil.ErrBadRequest, "login links are not available", nil)
+	}
+	if !validator.EmailCheck(email) {
+		return errorutil.NewAppError(errorutil.ErrBadRequest, "invalid email", nil)
+	}
+	if err := us.links.tasks.JobLoginLink(ctx, &jobs.LoginLinkPayload{Email: email}); err != nil {
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+type ConsumeLoginLinkInput struct {
+	Token     string
+	ClientIP  string
+	UserAgent string
+}
+
+// ConsumeLoginLink spends an emailed login link and signs the user in from the device that
+// opened it, exactly as a password login would, second factor and lockout included.
+func (us *userService) ConsumeLoginLink(ctx context.Context, arg ConsumeLoginLinkInput) (_ *AuthResult, err error) {
+	ctx, span := tracer.Start(ctx, "userService.ConsumeLoginLink")
+	defer func() { tracing.End(span, err) }()
+
+	v := validator.NewValidator()
+	v.Check(arg.Token != "", "token", "cannot be empty")
+	if !v.Valid() {
+		return nil, v
+	}
+	if us.links == nil {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired login link", nil)
+	}
+
+	payload, err := us.token.VerifyToken(arg.Token)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired login link", err)
+	}
+	if payload.Purpose != auth.PurposeLoginLink {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired login link", errNotLoginLinkToken)
+	}
+	linkID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired login link", err)
+	}
+
+	//checked before the link is spent, so a locked user can still use it once the lock lifts
+	if err := us.checkLockout(ctx, payload.Username, arg.ClientIP, arg.UserAgent); err != nil {
+		return nil, err
+	}
+
+	username, err := us.links.repo.UseLoginLink(ctx, linkID)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidLoginLink) {
+			us.lockout.failed(ctx, Logininput{Username: payload.Username, ClientIP: arg.ClientIP, UserAgent: arg.UserAgent}, true)
+			us.loginFailed(ctx, payload.Username, arg.ClientIP, arg.UserAgent, "used_login_link")
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired login link", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if username != payload.Username {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired login link", errNotLoginLinkToken)
+	}
+
+	user, err := us.UserRepo.GetUser(ctx, username)
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired login link", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return us.completeLogin(ctx, user, arg.ClientIP, arg.UserAgent)
+}
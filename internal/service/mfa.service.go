@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/totp"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	errMFAUnavailable  = errors.New("no totp encryption key configured")
+	errInvalidMFACode  = errors.New("invalid totp or recovery code")
+	errMFANotEnabled   = errors.New("two-factor authentication is not enabled")
+	recoveryCodeFormat = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+type MFARepository interface {
+	CreatePendingTOTP(ctx context.Context, username, secret string) (*entity.UserTOTP, error)
+	GetUserTOTP(ctx context.Context, username string) (*entity.UserTOTP, error)
+	ConfirmTOTP(ctx context.Context, username string, step int64, recoveryCodeHashes []string, event entity.AuditEvent) error
+	UseTOTPStep(ctx context.Context, username string, step int64) error
+	UseRecoveryCode(ctx context.Context, username, codeHash string) error
+	DisableTOTP(ctx context.Context, username string, event entity.AuditEvent) error
+}
+
+type MFAService struct {
+	repo   MFARepository
+	sealer *totp.Sealer
+	issuer string
+}
+
+// NewMFAService returns the TOTP service. Without a sealer no new authenticators can be enrolled.
+func NewMFAService(mr MFARepository, sealer *totp.Sealer, issuer string) *MFAService {
+	return &MFAService{repo: mr, sealer: sealer, issuer: issuer}
+}
+
+// TOTPEnrollment is shown to the user once, usually as a QR code of ProvisioningURI.
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// EnrollTOTP starts authenticator setup. Logins are unaffected until ConfirmTOTP proves the
+// authenticator works; enrolling again before that replaces the pending secret.
+func (ms *MFAService) EnrollTOTP(ctx context.Context, payload *auth.Payload) (_ *TOTPEnrollment, err error) {
+	ctx, span := tracer.Start(ctx, "MFAService.EnrollTOTP")
+	defer func() { tracing.End(span, err) }()
+
+	if ms.sealer == nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "two-factor authentication is unavailable", errMFAUnavailable)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	sealed, err := ms.sealer.Seal(payload.Username, secret)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if _, err := ms.repo.CreatePendingTOTP(ctx, payload.Username, sealed); err != nil {
+		if errors.Is(err, repo.ErrTOTPAlreadyEnabled) {
+			return nil, errorutil.NewAppError(errorutil.ErrConflict, "two-factor authentication is already enabled", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	return &TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(ms.issuer, payload.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user enters a code from the newly
+// enrolled authenticator, and returns fresh recovery codes. They are only ever shown here.
+func (ms *MFAService) ConfirmTOTP(ctx context.Context, payload *auth.Payload, code string) (_ []string, err error) {
+	ctx, span := tracer.Start(ctx, "MFAService.ConfirmTOTP")
+	defer func() { tracing.End(span, err) }()
+
+	pending, err := ms.repo.GetUserTOTP(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, repo.ErrTOTPNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "no authenticator enrollment in progress", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if pending.Enabled() {
+		return nil, errorutil.NewAppError(errorutil.ErrConflict, "two-factor authentication is already enabled", repo.ErrTOTPAlreadyEnabled)
+	}
+
+	step, err := ms.checkTOTP(pending, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	event := audit.NewEvent(ctx, audit.ActionTOTPEnable, payload.Username, audit.UserSubject(payload.Username))
+	if err := ms.repo.ConfirmTOTP(ctx, payload.Username, step, hashes, event); err != nil {
+		if errors.Is(err, repo.ErrTOTPNotFound) {
+			//confirmed or re-enrolled concurrently
+			return nil, errorutil.NewAppError(errorutil.ErrConflict, "authenticator enrollment changed, try again", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It takes a current TOTP or recovery code so
+// a stolen access token alone cannot remove the second factor.
+func (ms *MFAService) DisableTOTP(ctx context.Context, payload *auth.Payload, code string) (err error) {
+	ctx, span := tracer.Start(ctx, "MFAService.DisableTOTP")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ms.VerifyCode(ctx, payload.Username, code); err != nil {
+		return err
+	}
+	event := audit.NewEvent(ctx, audit.ActionTOTPDisable, payload.Username, audit.UserSubject(payload.Username))
+	if err := ms.repo.DisableTOTP(ctx, payload.Username, event); err != nil {
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// MFAEnabled reports whether logins for username need a second factor.
+func (ms *MFAService) MFAEnabled(ctx context.Context, username string) (bool, error) {
+	t, err := ms.repo.GetUserTOTP(ctx, username)
+	if err != nil {
+		if errors.Is(err, repo.ErrTOTPNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return t.Enabled(), nil
+}
+
+// VerifyCode checks a TOTP code, or failing the six digit format a recovery code, against the
+// user's enabled authenticator. Either kind of code works only once.
+func (ms *MFAService) VerifyCode(ctx context.Context, username, code string) (err error) {
+	ctx, span := tracer.Start(ctx, "MFAService.VerifyCode")
+	defer func() { tracing.End(span, err) }()
+
+	t, err := ms.repo.GetUserTOTP(ctx, username)
+	if err != nil {
+		if errors.Is(err, repo.ErrTOTPNotFound) {
+			return errorutil.NewAppError(errorutil.ErrBadRequest, "two-factor authentication is not enabled", errMFANotEnabled)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if !t.Enabled() {
+		return errorutil.NewAppError(errorutil.ErrBadRequest, "two-factor authentication is not enabled", errMFANotEnabled)
+	}
+
+	code = strings.TrimSpace(code)
+	if !isTOTPCode(code) {
+		err := ms.repo.UseRecoveryCode(ctx, username, auth.HashSecret(normalizeRecoveryCode(code)))
+		if err != nil {
+			if errors.Is(err, repo.ErrInvalidRecoveryCode) {
+				return errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid authentication code", errInvalidMFACode)
+			}
+			return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+		}
+		return nil
+	}
+
+	step, err := ms.checkTOTP(t, code)
+	if err != nil {
+		return err
+	}
+	if err := ms.repo.UseTOTPStep(ctx, username, step); err != nil {
+		if errors.Is(err, repo.ErrTOTPCodeReused) {
+			return errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid authentication code", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// checkTOTP validates code against t's secret and returns the time step it belongs to.
+func (ms *MFAService) checkTOTP(t *entity.UserTOTP, code string) (int64, error) {
+	if ms.sealer == nil {
+		return 0, errorutil.NewAppError(errorutil.ErrInternal, "two-factor authentication is unavailable", errMFAUnavailable)
+	}
+	secret, err := ms.sealer.Open(t.Username, t.Secret)
+	if err != nil {
+		return 0, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	step, ok := totp.Validate(secret, strings.TrimSpace(code), time.Now(), t.LastUsedStep)
+	if !ok {
+		return 0, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid authentication code", errInvalidMFACode)
+	}
+	return step, nil
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCodes returns codes formatted for the user, like "k3j9d-x2m4q", and their hashes.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for range recoveryCodeCount {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeFormat.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, auth.HashSecret(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,712 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Grant types of the token endpoint. Clients registered for the authorization code grant may
+// also use refresh tokens.
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+)
+
+// oauthCodeDuration is how long a client has to exchange an authorization code.
+const oauthCodeDuration = time.Minute
+
+var (
+	errInvalidClient      = errors.New("unknown, revoked or unauthenticated oauth client")
+	errCodeMismatch       = errors.New("authorization code was issued for another client or redirect uri")
+	errPKCEMismatch       = errors.New("code verifier does not answer the code challenge")
+	errClientTokenRevoked = errors.New("client token revoked")
+	errNotUserLogin       = errors.New("only a user's own login can manage oauth consent")
+)
+
+type OAuthRepository interface {
+	CreateOAuthClient(ctx context.Context, arg entity.OAuthClient, event entity.AuditEvent) (*entity.OAuthClient, error)
+	GetOAuthClient(ctx context.Context, id string) (*entity.OAuthClient, error)
+	RevokeOAuthClient(ctx context.Context, id string, event entity.AuditEvent) error
+	GrantOAuthConsent(ctx context.Context, code entity.OAuthAuthorizationCode, event entity.AuditEvent) (*entity.OAuthConsent, error)
+	GetOAuthConsent(ctx context.Context, username, clientID string) (*entity.OAuthConsent, error)
+	ListOAuthConsents(ctx context.Context, username string) ([]*entity.OAuthConsent, error)
+	RevokeOAuthConsent(ctx context.Context, username, clientID string, event entity.AuditEvent) error
+	UseOAuthAuthorizationCode(ctx context.Context, codeHash string) (*entity.OAuthAuthorizationCode, error)
+	CreateOAuthClientToken(ctx context.Context, arg entity.OAuthClientToken) error
+	GetOAuthClientToken(ctx context.Context, id uuid.UUID) (*entity.OAuthClientToken, error)
+	RevokeOAuthClientToken(ctx context.Context, id uuid.UUID, clientID string) error
+}
+
+// OAuthService lets partner applications act for users without their password. Users consent
+// to a client's scopes and the client exchanges the resulting code for a session of its own,
+// kept in the sessions table like any login; clients can also get tokens for themselves.
+type OAuthService struct {
+	repo     OAuthRepository
+	sessions SessionRepository
+	token    auth.Authenticator
+	config   config.Config
+	logger   *zerolog.Logger
+}
+
+func NewOAuthService(or OAuthRepository, sr SessionRepository, token auth.Authenticator, config config.Config, log *zerolog.Logger) *OAuthService {
+	return &OAuthService{repo: or, sessions: sr, token: token, config: config, logger: log}
+}
+
+type CreateOAuthClientInput struct {
+	Name         string
+	RedirectURIs []string
+	Scopes       []string
+	GrantTypes   []string
+	// Public clients, such as mobile apps, get no secret and can only use the authorization code grant.
+	Public bool
+}
+
+// RegisteredOAuthClient is a client as just registered. The partner has to copy Secret now: only
+// its hash is kept, so it can't be shown again. Public clients get none.
+type RegisteredOAuthClient struct {
+	Secret string
+	*entity.OAuthClient
+}
+
+// CreateOAuthClient registers a partner application. Admins only.
+func (oa *OAuthService) CreateOAuthClient(ctx context.Context, payload *auth.Payload, arg CreateOAuthClientInput) (_ *RegisteredOAuthClient, err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.CreateOAuthClient")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Require(payload, auth.PermManageOAuthClients); err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "you cannot manage oauth clients", err)
+	}
+	v := validator.NewValidator()
+	v.Check(arg.Name != "" && len(arg.Name) <= 100, "name", "must be 1 to 100 characters")
+	v.Check(len(arg.Scopes) > 0, "scopes", "must grant at least one scope")
+	for _, scope := range arg.Scopes {
+		v.Check(auth.ValidOAuthScope(scope), "scopes", "unknown scope "+scope)
+	}
+	v.Check(len(arg.GrantTypes) > 0, "grant_types", "must allow at least one grant type")
+	for _, grant := range arg.GrantTypes {
+		v.Check(grant == GrantAuthorizationCode || grant == GrantClientCredentials, "grant_types", "unsupported grant type "+grant)
+	}
+	v.Check(!arg.Public || !slices.Contains(arg.GrantTypes, GrantClientCredentials), "grant_types", "public clients cannot use client credentials")
+	if slices.Contains(arg.GrantTypes, GrantAuthorizationCode) {
+		v.Check(len(arg.RedirectURIs) > 0, "redirect_uris", "must list at least one uri for the authorization code grant")
+	}
+	for _, uri := range arg.RedirectURIs {
+		v.Check(validRedirectURI(uri), "redirect_uris", "must be absolute, without a fragment, and only use http for loopback addresses")
+	}
+	if !v.Valid() {
+		return nil, v
+	}
+
+	id, err := auth.NewOAuthClientID()
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	var secret, secretHash string
+	if !arg.Public {
+		secret, err = auth.NewSecret()
+		if err != nil {
+			return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+		}
+		secretHash = auth.HashSecret(secret)
+	}
+
+	event := audit.NewEvent(ctx, audit.ActionOAuthClientCreate, payload.Username, audit.OAuthClientSubject(id))
+	event.Details = map[string]string{"name": arg.Name}
+	client, err := oa.repo.CreateOAuthClient(ctx, entity.OAuthClient{
+		ID:           id,
+		Name:         arg.Name,
+		SecretHash:   secretHash,
+		RedirectURIs: arg.RedirectURIs,
+		Scopes:       arg.Scopes,
+		GrantTypes:   arg.GrantTypes,
+		CreatedBy:    payload.Username,
+	}, event)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return &RegisteredOAuthClient{Secret: secret, OAuthClient: client}, nil
+}
+
+// RevokeOAuthClient stops a client from getting tokens and ends every session users granted it.
+func (oa *OAuthService) RevokeOAuthClient(ctx context.Context, payload *auth.Payload, clientID string) (err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.RevokeOAuthClient")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Require(payload, auth.PermManageOAuthClients); err != nil {
+		return errorutil.NewAppError(errorutil.ErrForbidden, "you cannot manage oauth clients", err)
+	}
+	event := audit.NewEvent(ctx, audit.ActionOAuthClientRevoke, payload.Username, audit.OAuthClientSubject(clientID))
+	if err := oa.repo.RevokeOAuthClient(ctx, clientID, event); err != nil {
+		if errors.Is(err, repo.ErrOAuthClientNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "oauth client not found or already revoked", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+type AuthorizeOAuthClientInput struct {
+	ClientID    string
+	RedirectURI string
+	Scopes      []string
+	State       string
+	// CodeChallenge is the PKCE challenge; only the S256 method is accepted.
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+type OAuthAuthorization struct {
+	Code string
+	// RedirectURI is where to send the user back to, with the code and state added.
+	RedirectURI string
+}
+
+// AuthorizeOAuthClient records the signed-in user's consent to a client's scopes and issues the
+// authorization code the client exchanges for tokens.
+func (oa *OAuthService) AuthorizeOAuthClient(ctx context.Context, payload *auth.Payload, arg AuthorizeOAuthClientInput) (_ *OAuthAuthorization, err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.AuthorizeOAuthClient")
+	defer func() { tracing.End(span, err) }()
+
+	if !userLogin(payload) {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "only you can authorize applications", errNotUserLogin)
+	}
+	v := validator.NewValidator()
+	v.Check(arg.ClientID != "", "client_id", "cannot be empty")
+	v.Check(len(arg.Scopes) > 0, "scope", "must ask for at least one scope")
+	v.Check(arg.CodeChallengeMethod == "S256", "code_challenge_method", "must be S256")
+	v.Check(len(arg.CodeChallenge) == 43, "code_challenge", "must be a base64url encoded sha256 hash")
+	if !v.Valid() {
+		return nil, v
+	}
+
+	client, err := oa.repo.GetOAuthClient(ctx, arg.ClientID)
+	if err != nil {
+		if errors.Is(err, repo.ErrOAuthClientNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "unknown client", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if client.Revoked() || !slices.Contains(client.GrantTypes, GrantAuthorizationCode) {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "unknown client", errInvalidClient)
+	}
+	redirectURI := arg.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	//never redirect anywhere the client did not register, or the code could be sent to an attacker
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "redirect uri is not registered for this client", nil)
+	}
+	for _, scope := range arg.Scopes {
+		if !slices.Contains(client.Scopes, scope) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "client may not ask for scope "+scope, nil)
+		}
+	}
+
+	code, err := auth.NewSecret()
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	event := audit.NewEvent(ctx, audit.ActionOAuthConsentGrant, payload.Username, audit.OAuthClientSubject(client.ID))
+	event.Details = map[string]string{"scope": strings.Join(arg.Scopes, " ")}
+	_, err = oa.repo.GrantOAuthConsent(ctx, entity.OAuthAuthorizationCode{
+		CodeHash:            auth.HashSecret(code),
+		ClientID:            client.ID,
+		Username:            payload.Username,
+		RedirectURI:         redirectURI,
+		RedirectURISupplied: arg.RedirectURI != "",
+		Scopes:              arg.Scopes,
+		CodeChallenge:       arg.CodeChallenge,
+		ExpiresAt:           time.Now().Add(oauthCodeDuration),
+	}, event)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	back, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	query := back.Query()
+	query.Set("code", code)
+	if arg.State != "" {
+		query.Set("state", arg.State)
+	}
+	back.RawQuery = query.Encode()
+	return &OAuthAuthorization{Code: code, RedirectURI: back.String()}, nil
+}
+
+type OAuthTokenInput struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	// Code, RedirectURI and CodeVerifier are for the authorization code grant.
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	// Scopes narrows what the new tokens allow; empty keeps everything the grant allows.
+	Scopes    []string
+	ClientIP  string
+	UserAgent string
+}
+
+type OAuthToken struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	// RefreshToken is empty for client credentials grants.
+	RefreshToken string
+	Scopes       []string
+}
+
+// Token is the token endpoint: it authenticates the client and issues tokens for the grant.
+func (oa *OAuthService) Token(ctx context.Context, arg OAuthTokenInput) (_ *OAuthToken, err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.Token")
+	defer func() { tracing.End(span, err) }()
+
+	client, err := oa.authenticateClient(ctx, arg.ClientID, arg.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	switch arg.GrantType {
+	case GrantAuthorizationCode:
+		return oa.exchangeCode(ctx, client, arg)
+	case GrantRefreshToken:
+		return oa.refresh(ctx, client, arg)
+	case GrantClientCredentials:
+		return oa.clientCredentials(ctx, client, arg)
+	}
+	return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "unsupported grant type", nil)
+}
+
+func (oa *OAuthService) exchangeCode(ctx context.Context, client *entity.OAuthClient, arg OAuthTokenInput) (*OAuthToken, error) {
+	if !slices.Contains(client.GrantTypes, GrantAuthorizationCode) {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "client may not use this grant type", nil)
+	}
+	code, err := oa.repo.UseOAuthAuthorizationCode(ctx, auth.HashSecret(arg.Code))
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidAuthorizationCode) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid or expired authorization code", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	//RFC 6749 4.1.3: the redirect uri must be repeated only if the authorization request named it,
+	//but one that is sent must match
+	if code.ClientID != client.ID || (code.RedirectURISupplied || arg.RedirectURI != "") && code.RedirectURI != arg.RedirectURI {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid or expired authorization code", errCodeMismatch)
+	}
+	if !auth.VerifyPKCE(arg.CodeVerifier, code.CodeChallenge) {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid or expired authorization code", errPKCEMismatch)
+	}
+	//the user may have withdrawn consent in the minute the code was valid
+	if _, err := oa.repo.GetOAuthConsent(ctx, code.Username, client.ID); err != nil {
+		if errors.Is(err, repo.ErrOAuthConsentNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid or expired authorization code", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	grant := auth.WithClient(client.ID, code.Scopes)
+	refreshToken, refreshPayload, err := oa.token.GenerateToken(code.Username, oa.config.REFRESH_TOKEN_DURATION, grant)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	sessionID := uuid.MustParse(refreshPayload.ID)
+	accessToken, accessPayload, err := oa.token.GenerateToken(code.Username, oa.config.ACCESS_TOKEN_DURATATION, auth.WithSessionID(sessionID), grant)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	event := audit.NewEvent(ctx, audit.ActionLogin, code.Username, audit.SessionSubject(sessionID))
+	event.ClientIP, event.UserAgent = arg.ClientIP, arg.UserAgent
+	event.Details = map[string]string{"client_id": client.ID, "scope": strings.Join(code.Scopes, " ")}
+	_, err = oa.sessions.CreateSession(ctx, entity.Session{
+		ID:           sessionID,
+		Username:     code.Username,
+		RefreshToken: refreshToken,
+		ClientIp:     arg.ClientIP,
+		UserAgent:    arg.UserAgent,
+		ExpiresAt:    refreshPayload.ExpiresAt.Time,
+		ClientID:     client.ID,
+	}, event)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return &OAuthToken{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiresAt.Time,
+		RefreshToken:         refreshToken,
+		Scopes:               code.Scopes,
+	}, nil
+}
+
+// refresh rotates a client's session the way RenewAccessToken rotates a user's, reuse detection included.
+func (oa *OAuthService) refresh(ctx context.Context, client *entity.OAuthClient, arg OAuthTokenInput) (*OAuthToken, error) {
+	if !slices.Contains(client.GrantTypes, GrantAuthorizationCode) {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "client may not use this grant type", nil)
+	}
+	refreshPayload, err := oa.token.VerifyToken(arg.RefreshToken)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid refresh token", err)
+	}
+	if refreshPayload.Purpose != "" || refreshPayload.SessionID != "" || refreshPayload.ClientID != client.ID {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid refresh token", errNotRefreshToken)
+	}
+	scopes := refreshPayload.OAuthScopes()
+	if len(arg.Scopes) > 0 {
+		for _, scope := range arg.Scopes {
+			if !slices.Contains(scopes, scope) {
+				return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "refresh token does not grant scope "+scope, nil)
+			}
+		}
+		scopes = arg.Scopes
+	}
+	sessionID, err := uuid.Parse(refreshPayload.ID)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid refresh token", err)
+	}
+
+	session, err := oa.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrSessionNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid refresh token", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	switch {
+	case session.IsSessionBlocked():
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid refresh token", errSessionBlocked)
+	case session.ClientID != client.ID, session.UsernameCheck(refreshPayload.Username), session.RefreshTokenCheck(arg.RefreshToken):
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid refresh token", errSessionMismatch)
+	case session.IsSessionExpired():
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid refresh token", errSessionExpired)
+	case session.IsRotated():
+		return nil, oa.refreshTokenReused(ctx, session)
+	}
+
+	grant := auth.WithClient(client.ID, scopes)
+	newRefreshToken, newRefreshPayload, err := oa.token.GenerateToken(session.Username, oa.config.REFRESH_TOKEN_DURATION, grant)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	next, err := oa.sessions.RotateSession(ctx, session.ID, entity.Session{
+		ID:           uuid.MustParse(newRefreshPayload.ID),
+		Username:     session.Username,
+		RefreshToken: newRefreshToken,
+		UserAgent:    session.UserAgent,
+		ClientIp:     session.ClientIp,
+		ExpiresAt:    newRefreshPayload.ExpiresAt.Time,
+		FamilyID:     session.FamilyID,
+		ParentID:     session.ID,
+		ClientID:     client.ID,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrSessionReused) {
+			return nil, oa.refreshTokenReused(ctx, session)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	accessToken, accessPayload, err := oa.token.GenerateToken(session.Username, oa.config.ACCESS_TOKEN_DURATATION, auth.WithSessionID(next.ID), grant)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return &OAuthToken{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiresAt.Time,
+		RefreshToken:         newRefreshToken,
+		Scopes:               scopes,
+	}, nil
+}
+
+func (oa *OAuthService) refreshTokenReused(ctx context.Context, session *entity.Session) error {
+	log := logger.FromCTX(ctx, oa.logger)
+	if err := oa.sessions.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		log.Error().Err(err).Str("family_id", session.FamilyID.String()).Msg("failed to block session family")
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	log.Warn().
+		Str("security_event", "refresh_token_reuse").
+		Str("username", session.Username).
+		Str("client_id", session.ClientID).
+		Str("family_id", session.FamilyID.String()).
+		Msg("rotated oauth refresh token presented again, blocked session family")
+	return errorutil.NewAppError(errorutil.ErrBadRequest, "invalid refresh token", errRefreshTokenReused)
+}
+
+func (oa *OAuthService) clientCredentials(ctx context.Context, client *entity.OAuthClient, arg OAuthTokenInput) (*OAuthToken, error) {
+	if client.Public() || !slices.Contains(client.GrantTypes, GrantClientCredentials) {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "client may not use this grant type", nil)
+	}
+	scopes := client.Scopes
+	if len(arg.Scopes) > 0 {
+		for _, scope := range arg.Scopes {
+			if !slices.Contains(client.Scopes, scope) {
+				return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "client may not ask for scope "+scope, nil)
+			}
+		}
+		scopes = arg.Scopes
+	}
+
+	accessToken, accessPayload, err := oa.token.GenerateToken("", oa.config.ACCESS_TOKEN_DURATATION, auth.WithClient(client.ID, scopes))
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	err = oa.repo.CreateOAuthClientToken(ctx, entity.OAuthClientToken{
+		ID:        uuid.MustParse(accessPayload.ID),
+		ClientID:  client.ID,
+		Scopes:    scopes,
+		ExpiresAt: accessPayload.ExpiresAt.Time,
+	})
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return &OAuthToken{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiresAt.Time,
+		Scopes:               scopes,
+	}, nil
+}
+
+type OAuthTokenRequest struct {
+	ClientID     string
+	ClientSecret string
+	Token        string
+}
+
+// RevokeToken revokes one of the calling client's tokens, as in RFC 7009. Revoking a refresh or
+// access token a user granted ends that whole session. Tokens that are invalid, or belong to
+// another client, are ignored so callers cannot probe them.
+func (oa *OAuthService) RevokeToken(ctx context.Context, arg OAuthTokenRequest) (err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.RevokeToken")
+	defer func() { tracing.End(span, err) }()
+
+	client, err := oa.authenticateClient(ctx, arg.ClientID, arg.ClientSecret)
+	if err != nil {
+		return err
+	}
+	payload, err := oa.token.VerifyToken(arg.Token)
+	if err != nil || payload.ClientID != client.ID {
+		return nil
+	}
+
+	if payload.IsClientToken() {
+		id, err := uuid.Parse(payload.ID)
+		if err != nil {
+			return nil
+		}
+		if err := oa.repo.RevokeOAuthClientToken(ctx, id, client.ID); err != nil {
+			return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+		}
+		return nil
+	}
+	session, err := oa.tokenSession(ctx, payload)
+	if err != nil {
+		if errors.Is(err, repo.ErrSessionNotFound) {
+			return nil
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if session == nil || session.ClientID != client.ID {
+		return nil
+	}
+	if err := oa.sessions.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// TokenIntrospection describes a token as in RFC 7662. Only Active is set for inactive tokens.
+type TokenIntrospection struct {
+	Active    bool
+	ClientID  string
+	Username  string
+	Scopes    []string
+	TokenType string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// IntrospectToken reports whether one of the calling client's tokens is still active, and what it
+// allows. Another client's tokens are always reported inactive.
+func (oa *OAuthService) IntrospectToken(ctx context.Context, arg OAuthTokenRequest) (_ *TokenIntrospection, err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.IntrospectToken")
+	defer func() { tracing.End(span, err) }()
+
+	client, err := oa.authenticateClient(ctx, arg.ClientID, arg.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	inactive := &TokenIntrospection{}
+	payload, err := oa.token.VerifyToken(arg.Token)
+	if err != nil || payload.ClientID != client.ID || payload.Purpose != "" {
+		return inactive, nil
+	}
+
+	tokenType := "access_token"
+	if payload.IsClientToken() {
+		if err := oa.CheckClientToken(ctx, payload); err != nil {
+			return inactive, nil
+		}
+	} else {
+		session, err := oa.tokenSession(ctx, payload)
+		if err != nil {
+			if errors.Is(err, repo.ErrSessionNotFound) {
+				return inactive, nil
+			}
+			return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+		}
+		if session == nil || session.IsSessionBlocked() || session.IsSessionExpired() || session.ClientID != client.ID || session.UsernameCheck(payload.Username) {
+			return inactive, nil
+		}
+		if payload.SessionID == "" {
+			tokenType = "refresh_token"
+			if session.IsRotated() || session.RefreshTokenCheck(arg.Token) {
+				return inactive, nil
+			}
+		}
+	}
+	return &TokenIntrospection{
+		Active:    true,
+		ClientID:  payload.ClientID,
+		Username:  payload.Username,
+		Scopes:    payload.OAuthScopes(),
+		TokenType: tokenType,
+		IssuedAt:  payload.IssuedAt.Time,
+		ExpiresAt: payload.ExpiresAt.Time,
+	}, nil
+}
+
+// tokenSession returns the session a user's access or refresh token belongs to, or nil for
+// tokens that name none.
+func (oa *OAuthService) tokenSession(ctx context.Context, payload *auth.Payload) (*entity.Session, error) {
+	//access tokens name their session; a refresh token's own id is its session's
+	id := payload.SessionID
+	if id == "" {
+		id = payload.ID
+	}
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, nil
+	}
+	return oa.sessions.GetSession(ctx, sessionID)
+}
+
+// CheckClientToken implements auth.ClientChecker.
+func (oa *OAuthService) CheckClientToken(ctx context.Context, payload *auth.Payload) (err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.CheckClientToken")
+	defer func() { tracing.End(span, err) }()
+
+	id, err := uuid.Parse(payload.ID)
+	if err != nil {
+		return err
+	}
+	token, err := oa.repo.GetOAuthClientToken(ctx, id)
+	if err != nil {
+		return err
+	}
+	if token.Revoked() || token.ClientID != payload.ClientID {
+		return errClientTokenRevoked
+	}
+	client, err := oa.repo.GetOAuthClient(ctx, token.ClientID)
+	if err != nil {
+		return err
+	}
+	if client.Revoked() {
+		return errInvalidClient
+	}
+	return nil
+}
+
+// authenticateClient checks a client's credentials. Public clients have no secret to send.
+func (oa *OAuthService) authenticateClient(ctx context.Context, clientID, secret string) (*entity.OAuthClient, error) {
+	client, err := oa.repo.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repo.ErrOAuthClientNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid client", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if client.Public() {
+		if secret != "" {
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid client", errInvalidClient)
+		}
+	} else if subtle.ConstantTimeCompare([]byte(auth.HashSecret(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid client", errInvalidClient)
+	}
+	if client.Revoked() {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid client", errInvalidClient)
+	}
+	return client, nil
+}
+
+func (oa *OAuthService) ListOAuthConsents(ctx context.Context, payload *auth.Payload) (_ []*entity.OAuthConsent, err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.ListOAuthConsents")
+	defer func() { tracing.End(span, err) }()
+
+	if !userLogin(payload) {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "only you can see the applications you authorized", errNotUserLogin)
+	}
+	consents, err := oa.repo.ListOAuthConsents(ctx, payload.Username)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return consents, nil
+}
+
+// RevokeOAuthConsent withdraws the user's consent to a client, ending the sessions it had for them
+// and the account consents the user gave it.
+func (oa *OAuthService) RevokeOAuthConsent(ctx context.Context, payload *auth.Payload, clientID string) (err error) {
+	ctx, span := tracer.Start(ctx, "OAuthService.RevokeOAuthConsent")
+	defer func() { tracing.End(span, err) }()
+
+	if !userLogin(payload) {
+		return errorutil.NewAppError(errorutil.ErrForbidden, "only you can revoke the applications you authorized", errNotUserLogin)
+	}
+	event := audit.NewEvent(ctx, audit.ActionOAuthConsentRevoke, payload.Username, audit.OAuthClientSubject(clientID))
+	if err := oa.repo.RevokeOAuthConsent(ctx, payload.Username, clientID, event); err != nil {
+		if errors.Is(err, repo.ErrOAuthConsentNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "you have not authorized this application", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// userLogin reports whether payload is a user acting for themselves, not a service account or
+// an application acting for them.
+func userLogin(payload *auth.Payload) bool {
+	return payload != nil && payload.Username != "" && payload.APIKey == nil && payload.ClientID == ""
+}
+
+// validRedirectURI accepts absolute uris without a fragment, as RFC 6749 requires. Plain http is
+// only allowed back to the device itself, for native apps.
+func validRedirectURI(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Fragment != "" {
+		return false
+	}
+	if u.Scheme == "http" {
+		host := u.Hostname()
+		return host == "localhost" || host == "127.0.0.1" || host == "::1"
+	}
+	return true
+}
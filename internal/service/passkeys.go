@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+const (
+	passkeyRegistration = "registration"
+	passkeyLogin        = "login"
+	// passkeyCeremonyTimeout is how long the browser has to answer after a ceremony begins.
+	passkeyCeremonyTimeout = 5 * time.Minute
+	passkeyNameMaxLength   = 64
+)
+
+var errPasskeyCloned = errors.New("passkey signature counter did not increase")
+
+type PasskeyRepository interface {
+	SaveWebAuthnUser(ctx context.Context, username string, handle []byte) ([]byte, error)
+	GetWebAuthnUsername(ctx context.Context, handle []byte) (string, error)
+	ListWebAuthnCredentials(ctx context.Context, username string) ([]*entity.WebAuthnCredential, error)
+	CreateWebAuthnCredential(ctx context.Context, arg entity.WebAuthnCredential, event entity.AuditEvent) (*entity.WebAuthnCredential, error)
+	UseWebAuthnCredential(ctx context.Context, credentialID []byte, signCount uint32, backupState bool) error
+	DeleteWebAuthnCredential(ctx context.Context, username string, id int64, event entity.AuditEvent) error
+	CreateWebAuthnCeremony(ctx context.Context, arg entity.WebAuthnCeremony) error
+	TakeWebAuthnCeremony(ctx context.Context, id uuid.UUID, kind string) (*entity.WebAuthnCeremony, error)
+}
+
+// RelyingParty is who passkeys are registered with. ID is the domain they are bound to; the
+// browser only offers them on Origins, which default to https on that domain.
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+// Passkeys lets users sign in with WebAuthn passkeys instead of a password. Only discoverable
+// credentials that verify the user, with a PIN or biometric, are accepted, so a passkey login
+// proves two factors and skips the TOTP prompt. A nil Passkeys turns the feature off.
+type Passkeys struct {
+	webauthn *webauthn.WebAuthn
+	repo     PasskeyRepository
+}
+
+func NewPasskeys(repo PasskeyRepository, rp RelyingParty) (*Passkeys, error) {
+	origins := rp.Origins
+	if len(origins) == 0 {
+		origins = []string{"https://" + rp.ID}
+	}
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTimeout, TimeoutUVD: passkeyCeremonyTimeout}
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          rp.ID,
+		RPDisplayName: rp.Name,
+		RPOrigins:     origins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Passkeys{webauthn: wa, repo: repo}, nil
+}
+
+// passkeyUser is a user as the webauthn library sees them.
+type passkeyUser struct {
+	handle      []byte
+	name        string
+	displayName string
+	credentials []webauthn.Credential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte                         { return u.handle }
+func (u *passkeyUser) WebAuthnName() string                       { return u.name }
+func (u *passkeyUser) WebAuthnDisplayName() string                { return u.displayName }
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+func toWebAuthnCredential(c *entity.WebAuthnCredential) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+	for _, t := range c.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(t))
+	}
+	return webauthn.Credential{
+		ID:              c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    c.AAGUID,
+			SignCount: c.SignCount,
+		},
+	}
+}
+
+// user loads a user's passkeys, giving them a user handle first if they have none yet.
+func (pk *Passkeys) user(ctx context.Context, user *entity.User) (*passkeyUser, error) {
+	handle := make([]byte, 32)
+	if _, err := rand.Read(handle); err != nil {
+		return nil, err
+	}
+	handle, err := pk.repo.SaveWebAuthnUser(ctx, user.Username, handle)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := pk.repo.ListWebAuthnCredentials(ctx, user.Username)
+	if err != nil {
+		return nil, err
+	}
+	pu := &passkeyUser{handle: handle, name: user.Username, displayName: user.FullName}
+	for _, c := range credentials {
+		pu.credentials = append(pu.credentials, toWebAuthnCredential(c))
+	}
+	return pu, nil
+}
+
+// userByHandle loads the passkeys of the user an authenticator identified by its user handle.
+func (pk *Passkeys) userByHandle(ctx context.Context, handle []byte) (*passkeyUser, error) {
+	username, err := pk.repo.GetWebAuthnUsername(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := pk.repo.ListWebAuthnCredentials(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	pu := &passkeyUser{handle: handle, name: username, displayName: username}
+	for _, c := range credentials {
+		pu.credentials = append(pu.credentials, toWebAuthnCredential(c))
+	}
+	return pu, nil
+}
+
+// PasskeyCeremony is what the browser needs to create or use a passkey: Options go to
+// navigator.credentials.create or get, and its answer comes back along with ID.
+type PasskeyCeremony struct {
+	ID        uuid.UUID
+	Options   json.RawMessage
+	ExpiresAt time.Time
+}
+
+func (pk *Passkeys) begin(ctx context.Context, kind, username string, options any, session *webauthn.SessionData) (*PasskeyCeremony, error) {
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	ceremony := entity.WebAuthnCeremony{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Username:    username,
+		SessionData: sessionJSON,
+		ExpiresAt:   session.Expires,
+	}
+	if err := pk.repo.CreateWebAuthnCeremony(ctx, ceremony); err != nil {
+		return nil, err
+	}
+	return &PasskeyCeremony{ID: ceremony.ID, Options: optionsJSON, ExpiresAt: ceremony.ExpiresAt}, nil
+}
+
+// finish ends a ceremony, returning the challenge it was begun with. Registrations only finish
+// for the user who began them.
+func (pk *Passkeys) finish(ctx context.Context, id uuid.UUID, kind, username string) (*webauthn.SessionData, error) {
+	ceremony, err := pk.repo.TakeWebAuthnCeremony(ctx, id, kind)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.Username != username {
+		return nil, repo.ErrInvalidWebAuthnCeremony
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal(ceremony.SessionData, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// BeginPasskeyRegistration starts adding a passkey to the signed-in user's account.
+func (us *userService) BeginPasskeyRegistration(ctx context.Context, payload *auth.Payload) (_ *PasskeyCeremony, err error) {
+	ctx, span := tracer.Start(ctx, "userService.BeginPasskeyRegistration")
+	defer func() { tracing.End(span, err) }()
+
+	if us.passkeys == nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "passkeys are not available", nil)
+	}
+	user, err := us.UserRepo.GetUser(ctx, payload.Username)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	pu, err := us.passkeys.user(ctx, user)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	//the browser refuses authenticators that already hold one of the user's passkeys
+	exclusions := webauthn.Credentials(pu.credentials).CredentialDescriptors()
+	creation, session, err := us.passkeys.webauthn.BeginRegistration(pu, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	ceremony, err := us.passkeys.begin(ctx, passkeyRegistration, user.Username, creation, session)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return ceremony, nil
+}
+
+type FinishPasskeyRegistrationInput struct {
+	CeremonyID string
+	Name       string
+	// Credential is the PublicKeyCredential from navigator.credentials.create, as JSON.
+	Credential string
+}
+
+// FinishPasskeyRegistration verifies the new passkey's attestation and stores it.
+func (us *userService) FinishPasskeyRegistration(ctx context.Context, payload *auth.Payload, arg FinishPasskeyRegistrationInput) (_ *entity.WebAuthnCredential, err error) {
+	ctx, span := tracer.Start(ctx, "userService.FinishPasskeyRegistration")
+	defer func() { tracing.End(span, err) }()
+
+	ceremonyID, parseErr := uuid.Parse(arg.CeremonyID)
+	v := validator.NewValidator()
+	v.Check(parseErr == nil, "ceremony_id", "must be a valid id")
+	v.Check(arg.Credential != "", "credential", "cannot be empty")
+	v.Check(len(arg.Name) <= passkeyNameMaxLength, "name", "must not be more than 64 characters")
+	if !v.Valid() {
+		return nil, v
+	}
+	if us.passkeys == nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "passkeys are not available", nil)
+	}
+
+	session, err := us.passkeys.finish(ctx, ceremonyID, passkeyRegistration, payload.Username)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidWebAuthnCeremony) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "passkey registration expired, start again", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes([]byte(arg.Credential))
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid passkey credential", err)
+	}
+
+	user, err := us.UserRepo.GetUser(ctx, payload.Username)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	pu, err := us.passkeys.user(ctx, user)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	credential, err := us.passkeys.webauthn.CreateCredential(pu, *session, parsed)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "passkey could not be verified", err)
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, t := range credential.Transport {
+		transports = append(transports, string(t))
+	}
+	event := audit.NewEvent(ctx, audit.ActionPasskeyCreate, payload.Username, audit.UserSubject(payload.Username))
+	stored, err := us.passkeys.repo.CreateWebAuthnCredential(ctx, entity.WebAuthnCredential{
+		Username:        payload.Username,
+		Name:            arg.Name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          credential.Authenticator.AAGUID,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		SignCount:       credential.Authenticator.SignCount,
+	}, event)
+	if err != nil {
+		if errors.Is(err, repo.ErrDuplicateWebAuthnCredential) {
+			return nil, errorutil.NewAppError(errorutil.ErrConflict, "passkey is already registered", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return stored, nil
+}
+
+func (us *userService) ListPasskeys(ctx context.Context, payload *auth.Payload) (_ []*entity.WebAuthnCredential, err error) {
+	ctx, span := tracer.Start(ctx, "userService.ListPasskeys")
+	defer func() { tracing.End(span, err) }()
+
+	if us.passkeys == nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "passkeys are not available", nil)
+	}
+	credentials, err := us.passkeys.repo.ListWebAuthnCredentials(ctx, payload.Username)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return credentials, nil
+}
+
+func (us *userService) DeletePasskey(ctx context.Context, payload *auth.Payload, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "userService.DeletePasskey")
+	defer func() { tracing.End(span, err) }()
+
+	if us.passkeys == nil {
+		return errorutil.NewAppError(errorutil.ErrBadRequest, "passkeys are not available", nil)
+	}
+	event := audit.NewEvent(ctx, audit.ActionPasskeyDelete, payload.Username, audit.PasskeySubject(id))
+	if err := us.passkeys.repo.DeleteWebAuthnCredential(ctx, payload.Username, id, event); err != nil {
+		if errors.Is(err, repo.ErrWebAuthnCredentialNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "passkey not found", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// BeginPasskeyLogin starts a login without a username: the browser lets the user pick one of
+// their passkeys for this site, and the passkey says whose it is.
+func (us *userService) BeginPasskeyLogin(ctx context.Context) (_ *PasskeyCeremony, err error) {
+	ctx, span := tracer.Start(ctx, "userService.BeginPasskeyLogin")
+	defer func() { tracing.End(span, err) }()
+
+	if us.passkeys == nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "passkeys are not available", nil)
+	}
+	assertion, session, err := us.passkeys.webauthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	ceremony, err := us.passkeys.begin(ctx, passkeyLogin, "", assertion, session)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return ceremony, nil
+}
+
+type FinishPasskeyLoginInput struct {
+	CeremonyID string
+	// Credential is the PublicKeyCredential from navigator.credentials.get, as JSON.
+	Credential string
+	ClientIP   string
+	UserAgent  string
+}
+
+// FinishPasskeyLogin verifies the passkey's signature over the login challenge and starts a
+// session at multi-factor assurance.
+func (us *userService) FinishPasskeyLogin(ctx context.Context, arg FinishPasskeyLoginInput) (_ *AuthResult, err error) {
+	ctx, span := tracer.Start(ctx, "userService.FinishPasskeyLogin")
+	defer func() { tracing.End(span, err) }()
+
+	ceremonyID, parseErr := uuid.Parse(arg.CeremonyID)
+	v := validator.NewValidator()
+	v.Check(parseErr == nil, "ceremony_id", "must be a valid id")
+	v.Check(arg.Credential != "", "credential", "cannot be empty")
+	if !v.Valid() {
+		return nil, v
+	}
+	if us.passkeys == nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "passkeys are not available", nil)
+	}
+
+	session, err := us.passkeys.finish(ctx, ceremonyID, passkeyLogin, "")
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidWebAuthnCeremony) {
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "passkey login expired, start again", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes([]byte(arg.Credential))
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid passkey credential", err)
+	}
+
+	var username string
+	var lookupErr error
+	_, credential, err := us.passkeys.webauthn.ValidatePasskeyLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		pu, err := us.passkeys.userByHandle(ctx, userHandle)
+		if err != nil {
+			lookupErr = err
+			return nil, err
+		}
+		username = pu.name
+		return pu, nil
+	}, *session, parsed)
+	if lookupErr != nil && !errors.Is(lookupErr, repo.ErrWebAuthnUserNotFound) {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", lookupErr)
+	}
+	if err != nil {
+		if username != "" {
+			us.loginFailed(ctx, username, arg.ClientIP, arg.UserAgent, "wrong_passkey")
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "passkey could not be verified", err)
+	}
+	if credential.Authenticator.CloneWarning {
+		us.loginFailed(ctx, username, arg.ClientIP, arg.UserAgent, "cloned_passkey")
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "passkey could not be verified", errPasskeyCloned)
+	}
+
+	if err := us.passkeys.repo.UseWebAuthnCredential(ctx, credential.ID, credential.Authenticator.SignCount, credential.Flags.BackupState); err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	user, err := us.UserRepo.GetUser(ctx, username)
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "passkey could not be verified", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return us.startSession(ctx, user, arg.ClientIP, arg.UserAgent, auth.AssuranceMFA)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+)
+
+type PasswordResetRepository interface {
+	GetPasswordResetUser(ctx context.Context, tokenHash string) (*entity.User, error)
+	ResetPassword(ctx context.Context, tokenHash, hashedPassword string, event entity.AuditEvent) (*entity.User, error)
+}
+
+type PasswordResetQueue interface {
+	JobPasswordReset(ctx context.Context, payload *jobs.PasswordResetPayload) error
+}
+
+type PasswordResetService struct {
+	repo      PasswordResetRepository
+	tasks     PasswordResetQueue
+	passwords *auth.PasswordPolicy
+}
+
+func NewPasswordResetService(pr PasswordResetRepository, tasks PasswordResetQueue, passwords *auth.PasswordPolicy) *PasswordResetService {
+	return &PasswordResetService{repo: pr, tasks: tasks, passwords: passwords}
+}
+
+// RequestPasswordReset queues a reset email for the account registered with email, if any.
+// The account lookup happens in the worker, so the outcome is the same whether or not it exists.
+func (ps *PasswordResetService) RequestPasswordReset(ctx context.Context, email string) (err error) {
+	ctx, span := tracer.Start(ctx, "PasswordResetService.RequestPasswordReset")
+	defer func() { tracing.End(span, err) }()
+
+	if !validator.EmailCheck(email) {
+		return errorutil.NewAppError(errorutil.ErrBadRequest, "invalid email", nil)
+	}
+	if err := ps.tasks.JobPasswordReset(ctx, &jobs.PasswordResetPayload{Email: email}); err != nil {
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using an emailed reset token and signs the user out of
+// every session.
+func (ps *PasswordResetService) ResetPassword(ctx context.Context, token, newPassword string) (_ *entity.User, err error) {
+	ctx, span := tracer.Start(ctx, "PasswordResetService.ResetPassword")
+	defer func() { tracing.End(span, err) }()
+
+	v := validator.NewValidator()
+	v.Check(token != "", "token", "cannot be empty")
+	if !v.Valid() {
+		return nil, v
+	}
+
+	//the policy needs to know whose password it is
+	owner, err := ps.repo.GetPasswordResetUser(ctx, auth.HashSecret(token))
+	if err != nil {
+		return nil, invalidResetToken(err)
+	}
+	if err := ps.passwords.Check(v, "new_password", newPassword, owner.Username, owner.Email.String()); err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if !v.Valid() {
+		return nil, v
+	}
+
+	hashed, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	event := audit.NewEvent(ctx, audit.ActionPasswordReset, owner.Username, audit.UserSubject(owner.Username))
+	user, err := ps.repo.ResetPassword(ctx, auth.HashSecret(token), hashed, event)
+	if err != nil {
+		return nil, invalidResetToken(err)
+	}
+	return user, nil
+}
+
+func invalidResetToken(err error) error {
+	if errors.Is(err, repo.ErrInvalidResetToken) {
+		return errorutil.NewAppError(errorutil.ErrBadRequest, "invalid or expired reset token", err)
+	}
+	return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+}
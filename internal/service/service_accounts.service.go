@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"regexp"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+)
+
+var (
+	errAPIKeyRevoked   = errors.New("api key revoked")
+	serviceAccountName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{2,39}$`)
+)
+
+type ServiceAccountRepository interface {
+	CreateServiceAccount(ctx context.Context, arg entity.ServiceAccount, prefix, keyHash string, event entity.AuditEvent) (*entity.ServiceAccount, *entity.APIKey, error)
+	GetServiceAccount(ctx context.Context, name string) (*entity.ServiceAccount, error)
+	RotateAPIKey(ctx context.Context, serviceAccount, prefix, keyHash string, event entity.AuditEvent) (*entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	ListAPIKeys(ctx context.Context, serviceAccount string) ([]*entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, prefix string, event entity.AuditEvent) error
+	TouchAPIKey(ctx context.Context, id int64) error
+}
+
+type ServiceAccountService struct {
+	repo ServiceAccountRepository
+}
+
+func NewServiceAccountService(sr ServiceAccountRepository) *ServiceAccountService {
+	return &ServiceAccountService{repo: sr}
+}
+
+type CreateServiceAccountInput struct {
+	Name        string
+	Description string
+	Scopes      []string
+	AccountIDs  []int64
+}
+
+// IssuedAPIKey holds a new key in the clear. It is only ever returned here, never stored.
+type IssuedAPIKey struct {
+	Key string
+	*entity.APIKey
+}
+
+// CreateServiceAccount registers a machine client and issues its first API key. Admins only.
+func (ss *ServiceAccountService) CreateServiceAccount(ctx context.Context, payload *auth.Payload, arg CreateServiceAccountInput) (_ *entity.ServiceAccount, _ *IssuedAPIKey, err error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.CreateServiceAccount")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Require(payload, auth.PermManageServiceAccounts); err != nil {
+		return nil, nil, errorutil.NewAppError(errorutil.ErrForbidden, "you cannot manage service accounts", err)
+	}
+	v := validator.NewValidator()
+	v.Check(serviceAccountName.MatchString(arg.Name), "name", "must be 3 to 40 lowercase letters, digits or dashes")
+	v.Check(len(arg.Scopes) > 0, "scopes", "must grant at least one method")
+	for _, id := range arg.AccountIDs {
+		v.Check(id > 0, "account_ids", "must be positive")
+	}
+	if !v.Valid() {
+		return nil, nil, v
+	}
+
+	key, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	event := audit.NewEvent(ctx, audit.ActionServiceAccountCreate, payload.Username, audit.ServiceAccountSubject(arg.Name))
+	event.Details = map[string]string{"prefix": prefix}
+	sa, stored, err := ss.repo.CreateServiceAccount(ctx, entity.ServiceAccount{
+		Name:        arg.Name,
+		Description: arg.Description,
+		Scopes:      arg.Scopes,
+		AccountIDs:  arg.AccountIDs,
+		CreatedBy:   payload.Username,
+	}, prefix, auth.HashSecret(key), event)
+	if err != nil {
+		if errors.Is(err, repo.ErrDuplicateServiceAccount) {
+			return nil, nil, errorutil.NewAppError(errorutil.ErrConflict, "a service account with this name already exists", err)
+		}
+		return nil, nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return sa, &IssuedAPIKey{Key: key, APIKey: stored}, nil
+}
+
+// RotateAPIKey issues a new key for the service account and revokes all its others.
+func (ss *ServiceAccountService) RotateAPIKey(ctx context.Context, payload *auth.Payload, name string) (_ *IssuedAPIKey, err error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.RotateAPIKey")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ss.manage(ctx, payload, name); err != nil {
+		return nil, err
+	}
+	key, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	event := audit.NewEvent(ctx, audit.ActionAPIKeyRotate, payload.Username, audit.ServiceAccountSubject(name))
+	event.Details = map[string]string{"prefix": prefix}
+	stored, err := ss.repo.RotateAPIKey(ctx, name, prefix, auth.HashSecret(key), event)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return &IssuedAPIKey{Key: key, APIKey: stored}, nil
+}
+
+func (ss *ServiceAccountService) ListAPIKeys(ctx context.Context, payload *auth.Payload, name string) (_ []*entity.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.ListAPIKeys")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ss.manage(ctx, payload, name); err != nil {
+		return nil, err
+	}
+	keys, err := ss.repo.ListAPIKeys(ctx, name)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops the key with prefix from authenticating, immediately.
+func (ss *ServiceAccountService) RevokeAPIKey(ctx context.Context, payload *auth.Payload, prefix string) (err error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.RevokeAPIKey")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Require(payload, auth.PermManageServiceAccounts); err != nil {
+		return errorutil.NewAppError(errorutil.ErrForbidden, "you cannot manage service accounts", err)
+	}
+	event := audit.NewEvent(ctx, audit.ActionAPIKeyRevoke, payload.Username, audit.APIKeySubject(prefix))
+	if err := ss.repo.RevokeAPIKey(ctx, prefix, event); err != nil {
+		if errors.Is(err, repo.ErrAPIKeyNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "api key not found or already revoked", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// manage checks payload may manage service accounts and that name exists.
+func (ss *ServiceAccountService) manage(ctx context.Context, payload *auth.Payload, name string) error {
+	if err := auth.Require(payload, auth.PermManageServiceAccounts); err != nil {
+		return errorutil.NewAppError(errorutil.ErrForbidden, "you cannot manage service accounts", err)
+	}
+	if _, err := ss.repo.GetServiceAccount(ctx, name); err != nil {
+		if errors.Is(err, repo.ErrServiceAccountNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "service account not found", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// VerifyAPIKey implements auth.APIKeyVerifier: it returns a payload for the key's service account
+// carrying its grant, and records when the key was last used.
+func (ss *ServiceAccountService) VerifyAPIKey(ctx context.Context, key string) (_ *auth.Payload, err error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.VerifyAPIKey")
+	defer func() { tracing.End(span, err) }()
+
+	prefix, ok := auth.ParseAPIKey(key)
+	if !ok {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	stored, err := ss.repo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(auth.HashSecret(key)), []byte(stored.KeyHash)) != 1 {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if stored.Revoked() {
+		return nil, errAPIKeyRevoked
+	}
+	sa, err := ss.repo.GetServiceAccount(ctx, stored.ServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	//last use is informational, a failed write shouldn't fail the request
+	_ = ss.repo.TouchAPIKey(ctx, stored.ID)
+
+	return &auth.Payload{
+		Username: auth.ServiceAccountPrefix + sa.Name,
+		APIKey: &auth.APIKeyGrant{
+			Prefix:     stored.Prefix,
+			Scopes:     sa.Scopes,
+			AccountIDs: sa.AccountIDs,
+		},
+	}, nil
+}
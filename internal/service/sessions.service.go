@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+)
+
+var errNoSessionID = errors.New("access token has no session id")
+
+// ActiveSession is one signed-in device. Its ID is the session family id, which stays the same
+// across refresh token rotations and is what RevokeSession takes.
+type ActiveSession struct {
+	ID           uuid.UUID
+	UserAgent    string
+	ClientIP     string
+	LastActiveAt time.Time
+	ExpiresAt    time.Time
+	Current      bool
+	// ClientID is set for sessions an OAuth client holds on the user's behalf.
+	ClientID string
+}
+
+// CheckSession implements auth.SessionChecker: it fails when the session an access token
+// was issued for has been revoked, blocked or has expired.
+func (us *userService) CheckSession(ctx context.Context, payload *auth.Payload) (err error) {
+	ctx, span := tracer.Start(ctx, "userService.CheckSession")
+	defer func() { tracing.End(span, err) }()
+
+	session, err := us.tokenSession(ctx, payload)
+	if err != nil {
+		return err
+	}
+	switch {
+	case session.IsSessionBlocked():
+		return errSessionBlocked
+	case session.UsernameCheck(payload.Username), session.ClientID != payload.ClientID:
+		return errSessionMismatch
+	case session.IsSessionExpired():
+		return errSessionExpired
+	}
+	return nil
+}
+
+func (us *userService) tokenSession(ctx context.Context, payload *auth.Payload) (*entity.Session, error) {
+	if payload.SessionID == "" {
+		return nil, errNoSessionID
+	}
+	id, err := uuid.Parse(payload.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return us.SessionRepo.GetSession(ctx, id)
+}
+
+// currentFamily returns the session family of the caller's access token.
+func (us *userService) currentFamily(ctx context.Context, payload *auth.Payload) (uuid.UUID, error) {
+	session, err := us.tokenSession(ctx, payload)
+	if err != nil {
+		if errors.Is(err, errNoSessionID) || errors.Is(err, repo.ErrSessionNotFound) {
+			return uuid.Nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid session", err)
+		}
+		return uuid.Nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return session.FamilyID, nil
+}
+
+// ListSessions returns the caller's signed-in devices, most recently active first.
+func (us *userService) ListSessions(ctx context.Context, payload *auth.Payload) (_ []ActiveSession, err error) {
+	ctx, span := tracer.Start(ctx, "userService.ListSessions")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := us.currentFamily(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := us.SessionRepo.ListActiveSessions(ctx, payload.Username)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	result := make([]ActiveSession, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, ActiveSession{
+			ID:           s.FamilyID,
+			UserAgent:    s.UserAgent,
+			ClientIP:     s.ClientIp,
+			LastActiveAt: s.CreatedAt,
+			ExpiresAt:    s.ExpiresAt,
+			Current:      s.FamilyID == current,
+			ClientID:     s.ClientID,
+		})
+	}
+	return result, nil
+}
+
+// RevokeSession signs one of the caller's devices out. Its refresh token stops working and
+// access tokens issued to it are rejected from the next request on.
+func (us *userService) RevokeSession(ctx context.Context, payload *auth.Payload, sessionID uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "userService.RevokeSession")
+	defer func() { tracing.End(span, err) }()
+
+	event := audit.NewEvent(ctx, audit.ActionSessionRevoke, payload.Username, audit.SessionSubject(sessionID))
+	err = us.SessionRepo.BlockUserSessionFamily(ctx, payload.Username, sessionID, event)
+	if err != nil {
+		if errors.Is(err, repo.ErrSessionNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "session not found", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs out every device of the caller except the one making the request.
+func (us *userService) RevokeOtherSessions(ctx context.Context, payload *auth.Payload) (err error) {
+	ctx, span := tracer.Start(ctx, "userService.RevokeOtherSessions")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := us.currentFamily(ctx, payload)
+	if err != nil {
+		return err
+	}
+	event := audit.NewEvent(ctx, audit.ActionSessionRevokeOthers, payload.Username, audit.UserSubject(payload.Username))
+	event.Details = map[string]string{"kept_session": current.String()}
+	err = us.SessionRepo.BlockOtherSessionFamilies(ctx, payload.Username, current, event)
+	if err != nil {
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
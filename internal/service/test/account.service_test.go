@@ -11,14 +11,12 @@ import (
 	"testing"
 	"time"
 
-	"github.com/0xOnah/bank/internal/config"
 	mockdb "github.com/0xOnah/bank/internal/db/mock"
 	"github.com/0xOnah/bank/internal/db/repo"
 	"github.com/0xOnah/bank/internal/entity"
 	"github.com/0xOnah/bank/internal/sdk/auth"
 	"github.com/0xOnah/bank/internal/sdk/util"
 	"github.com/0xOnah/bank/internal/service"
-	httptransport "github.com/0xOnah/bank/internal/transport/http"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -120,19 +118,10 @@ func TestGetAccountByID(t *testing.T) {
 			defer ctrl.Finish()
 
 			accountRepo := mockdb.NewMockAccountRepository(ctrl)
-			transferRepo := mockdb.NewMockTransferRepository(ctrl)
-			UserRepo := mockdb.NewMockUserRepository(ctrl)
-			sessionRepo := mockdb.NewMockSessionRepository(ctrl)
-			accountSvc := service.NewAccountService(accountRepo)
-			accountHandler := httptransport.NewAccountHandler(accountSvc, token)
-
-			transferSvc := service.NewTransferService(transferRepo, accountRepo)
-			transfHand := httptransport.NewTranserHandler(transferSvc, token)
-
-			usrSvc := service.NewUserService(UserRepo, token, config.Config{}, sessionRepo)
-			userHand := httptransport.NewUserHandler(usrSvc, token)
-
-			router := httptransport.NewRouter(accountHandler, transfHand, userHand)
+			router := newTestRouter(ctrl, testRouter{
+				Users:    service.UserServiceDeps{Token: token},
+				Accounts: accountRepo,
+			})
 
 			value.buildStubs(accountRepo)
 
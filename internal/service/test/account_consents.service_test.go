@@ -0,0 +1,591 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const aggregatorID = "cl_aggregator"
+
+func TestCreateAccountConsent(t *testing.T) {
+	owner := &auth.Payload{Username: "hector", SessionID: uuid.NewString(), Role: auth.RoleCustomer}
+	aggregator := &entity.OAuthClient{ID: aggregatorID, Scopes: []string{auth.ScopeAccountInformation}}
+	expiresAt := time.Now().Add(30 * 24 * time.Hour)
+	valid := service.CreateAccountConsentInput{
+		ClientID:    aggregatorID,
+		AccountIDs:  []int64{2, 1, 2},
+		Permissions: []string{entity.AccountPermissionTransactions, entity.AccountPermissionBalances},
+		ExpiresAt:   expiresAt,
+	}
+	//requests refused up front never reach the repositories
+	untouched := func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, clients *mockdb.MockOAuthRepository) {
+		clients.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+		accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+		consents.EXPECT().CreateAccountConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	}
+	invalid := func(field string) func(t *testing.T, _ *entity.AccountConsent, err error) {
+		return func(t *testing.T, _ *entity.AccountConsent, err error) {
+			var v *validator.Validator
+			require.ErrorAs(t, err, &v)
+			require.Contains(t, v.ErrVal, field)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		payload       *auth.Payload
+		change        func(arg *service.CreateAccountConsentInput)
+		buildStubs    func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, clients *mockdb.MockOAuthRepository)
+		checkResponse func(t *testing.T, consent *entity.AccountConsent, err error)
+	}{
+		{
+			name:    "OK",
+			payload: owner,
+			change:  func(*service.CreateAccountConsentInput) {},
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, clients *mockdb.MockOAuthRepository) {
+				clients.EXPECT().GetOAuthClient(gomock.Any(), aggregatorID).Times(1).Return(aggregator, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(&entity.Account{ID: 1, Owner: "hector"}, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(2)).Times(1).Return(&entity.Account{ID: 2, Owner: "hector"}, nil)
+				consents.EXPECT().CreateAccountConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, c entity.AccountConsent, event entity.AuditEvent) (*entity.AccountConsent, error) {
+						require.Equal(t, audit.ActionAccountConsentGrant, event.Action)
+						require.Equal(t, audit.AccountConsentSubject(c.ID), event.Subject)
+						require.Equal(t, "1,2", event.Details["account_ids"])
+						return &c, nil
+					})
+			},
+			checkResponse: func(t *testing.T, consent *entity.AccountConsent, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []int64{1, 2}, consent.AccountIDs)
+				require.Equal(t, []string{entity.AccountPermissionBalances, entity.AccountPermissionTransactions}, consent.Permissions)
+				require.Equal(t, "hector", consent.Username)
+				require.Equal(t, entity.AccountConsentValid, consent.Status())
+			},
+		}, {
+			name:    "Someone else's account",
+			payload: owner,
+			change:  func(arg *service.CreateAccountConsentInput) { arg.AccountIDs = []int64{1, 3} },
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, clients *mockdb.MockOAuthRepository) {
+				clients.EXPECT().GetOAuthClient(gomock.Any(), aggregatorID).Times(1).Return(aggregator, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(&entity.Account{ID: 1, Owner: "hector"}, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(3)).Times(1).Return(&entity.Account{ID: 3, Owner: "achilles"}, nil)
+				consents.EXPECT().CreateAccountConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *entity.AccountConsent, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:    "Staff sharing a customer's account",
+			payload: &auth.Payload{Username: "root", Role: auth.RoleAdmin},
+			change:  func(arg *service.CreateAccountConsentInput) { arg.AccountIDs = []int64{1} },
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, clients *mockdb.MockOAuthRepository) {
+				clients.EXPECT().GetOAuthClient(gomock.Any(), aggregatorID).Times(1).Return(aggregator, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(&entity.Account{ID: 1, Owner: "hector"}, nil)
+				consents.EXPECT().CreateAccountConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *entity.AccountConsent, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:    "Unknown account",
+			payload: owner,
+			change:  func(arg *service.CreateAccountConsentInput) { arg.AccountIDs = []int64{99} },
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, clients *mockdb.MockOAuthRepository) {
+				clients.EXPECT().GetOAuthClient(gomock.Any(), aggregatorID).Times(1).Return(aggregator, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(99)).Times(1).Return(nil, dbrepo.ErrRecordNotFound)
+				consents.EXPECT().CreateAccountConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *entity.AccountConsent, err error) {
+				requireAppError(t, err, errorutil.ErrNotFound)
+			},
+		}, {
+			name:    "Unknown client",
+			payload: owner,
+			change:  func(arg *service.CreateAccountConsentInput) { arg.ClientID = "cl_nobody" },
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, clients *mockdb.MockOAuthRepository) {
+				clients.EXPECT().GetOAuthClient(gomock.Any(), "cl_nobody").Times(1).Return(nil, dbrepo.ErrOAuthClientNotFound)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+				consents.EXPECT().CreateAccountConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *entity.AccountConsent, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:    "Client without account information",
+			payload: owner,
+			change:  func(arg *service.CreateAccountConsentInput) { arg.ClientID = "cl_payments" },
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, clients *mockdb.MockOAuthRepository) {
+				clients.EXPECT().GetOAuthClient(gomock.Any(), "cl_payments").Times(1).
+					Return(&entity.OAuthClient{ID: "cl_payments", Scopes: []string{auth.ScopeTransfersWrite}}, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+				consents.EXPECT().CreateAccountConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *entity.AccountConsent, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:       "By the client",
+			payload:    &auth.Payload{Username: "hector", ClientID: aggregatorID, Scope: auth.ScopeAccountInformation},
+			change:     func(*service.CreateAccountConsentInput) {},
+			buildStubs: untouched,
+			checkResponse: func(t *testing.T, _ *entity.AccountConsent, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:          "Unknown permission",
+			payload:       owner,
+			change:        func(arg *service.CreateAccountConsentInput) { arg.Permissions = []string{"payments"} },
+			buildStubs:    untouched,
+			checkResponse: invalid("permissions"),
+		}, {
+			name:          "No accounts",
+			payload:       owner,
+			change:        func(arg *service.CreateAccountConsentInput) { arg.AccountIDs = nil },
+			buildStubs:    untouched,
+			checkResponse: invalid("account_ids"),
+		}, {
+			name:          "Longer than 90 days",
+			payload:       owner,
+			change:        func(arg *service.CreateAccountConsentInput) { arg.ExpiresAt = time.Now().Add(91 * 24 * time.Hour) },
+			buildStubs:    untouched,
+			checkResponse: invalid("expires_at"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			consents := mockdb.NewMockAccountConsentRepository(ctrl)
+			accounts := mockdb.NewMockAccountRepository(ctrl)
+			clients := mockdb.NewMockOAuthRepository(ctrl)
+			tc.buildStubs(consents, accounts, clients)
+
+			arg := valid
+			tc.change(&arg)
+			svc := service.NewAccountConsentService(consents, accounts, mockdb.NewMockEntryRepository(ctrl), clients)
+			consent, err := svc.CreateAccountConsent(context.Background(), tc.payload, arg)
+			tc.checkResponse(t, consent, err)
+		})
+	}
+}
+
+func TestGetConsentedAccountBalance(t *testing.T) {
+	aggregator := &auth.Payload{ClientID: aggregatorID, Scope: auth.ScopeAccountInformation}
+	consent := entity.AccountConsent{
+		ID:          uuid.New(),
+		Username:    "hector",
+		ClientID:    aggregatorID,
+		AccountIDs:  []int64{1, 3},
+		Permissions: []string{entity.AccountPermissionBalances},
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name       string
+		payload    *auth.Payload
+		accountID  int64
+		buildStubs func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository)
+		checkErr   func(t *testing.T, account *entity.Account, err error)
+	}{
+		{
+			name:      "OK",
+			payload:   aggregator,
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(&entity.Account{ID: 1, Owner: "hector", Balance: 500}, nil)
+			},
+			checkErr: func(t *testing.T, account *entity.Account, err error) {
+				require.NoError(t, err)
+				require.EqualValues(t, 500, account.Balance)
+			},
+		}, {
+			//a token the user granted the same client works too
+			name:      "Delegated by the user",
+			payload:   &auth.Payload{Username: "hector", ClientID: aggregatorID, Scope: auth.ScopeAccountInformation},
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(&entity.Account{ID: 1, Owner: "hector"}, nil)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				require.NoError(t, err)
+			},
+		}, {
+			name:      "Account not covered",
+			payload:   aggregator,
+			accountID: 2,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			//the account changed hands after the consent was given
+			name:      "Another user's account",
+			payload:   aggregator,
+			accountID: 3,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(3)).Times(1).Return(&entity.Account{ID: 3, Owner: "achilles"}, nil)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:      "Another client",
+			payload:   &auth.Payload{ClientID: "cl_other", Scope: auth.ScopeAccountInformation},
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrNotFound)
+			},
+		}, {
+			name:      "Another user's delegation",
+			payload:   &auth.Payload{Username: "achilles", ClientID: aggregatorID, Scope: auth.ScopeAccountInformation},
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrNotFound)
+			},
+		}, {
+			name:      "The user themselves",
+			payload:   &auth.Payload{Username: "hector", SessionID: uuid.NewString(), Role: auth.RoleCustomer},
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrNotFound)
+			},
+		}, {
+			//the consent is checked again on every read, so expiry applies at once
+			name:      "Expired",
+			payload:   aggregator,
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				expired := consent
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&expired, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:      "Revoked",
+			payload:   aggregator,
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				revoked := consent
+				revoked.RevokedAt = time.Now()
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&revoked, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:      "Balances not granted",
+			payload:   aggregator,
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				transactions := consent
+				transactions.Permissions = []string{entity.AccountPermissionTransactions}
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&transactions, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:      "Unknown consent",
+			payload:   aggregator,
+			accountID: 1,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(nil, dbrepo.ErrAccountConsentNotFound)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.Account, err error) {
+				requireAppError(t, err, errorutil.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			consents := mockdb.NewMockAccountConsentRepository(ctrl)
+			accounts := mockdb.NewMockAccountRepository(ctrl)
+			tc.buildStubs(consents, accounts)
+
+			svc := service.NewAccountConsentService(consents, accounts, mockdb.NewMockEntryRepository(ctrl), mockdb.NewMockOAuthRepository(ctrl))
+			account, err := svc.GetConsentedAccountBalance(context.Background(), tc.payload, consent.ID, tc.accountID)
+			tc.checkErr(t, account, err)
+		})
+	}
+}
+
+func TestListConsentedAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	consent := entity.AccountConsent{
+		ID:          uuid.New(),
+		Username:    "hector",
+		ClientID:    aggregatorID,
+		AccountIDs:  []int64{1, 2, 3},
+		Permissions: []string{entity.AccountPermissionBalances},
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	consents := mockdb.NewMockAccountConsentRepository(ctrl)
+	accounts := mockdb.NewMockAccountRepository(ctrl)
+	svc := service.NewAccountConsentService(consents, accounts, mockdb.NewMockEntryRepository(ctrl), mockdb.NewMockOAuthRepository(ctrl))
+
+	//closed and transferred accounts drop out of the list
+	consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+	accounts.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(&entity.Account{ID: 1, Owner: "hector"}, nil)
+	accounts.EXPECT().GetAccountByID(gomock.Any(), int64(2)).Times(1).Return(nil, dbrepo.ErrRecordNotFound)
+	accounts.EXPECT().GetAccountByID(gomock.Any(), int64(3)).Times(1).Return(&entity.Account{ID: 3, Owner: "achilles"}, nil)
+	got, err := svc.ListConsentedAccounts(context.Background(), &auth.Payload{ClientID: aggregatorID}, consent.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.EqualValues(t, 1, got[0].ID)
+}
+
+func TestListConsentedTransactions(t *testing.T) {
+	aggregator := &auth.Payload{ClientID: aggregatorID, Scope: auth.ScopeAccountInformation}
+	consent := entity.AccountConsent{
+		ID:          uuid.New(),
+		Username:    "hector",
+		ClientID:    aggregatorID,
+		AccountIDs:  []int64{1},
+		Permissions: []string{entity.AccountPermissionTransactions},
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	entries := []entity.Entry{{ID: 7, AccountID: 1, Amount: -20}, {ID: 9, AccountID: 1, Amount: 45}}
+
+	testCases := []struct {
+		name       string
+		arg        service.ListConsentedTransactionsInput
+		buildStubs func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, entryRepo *mockdb.MockEntryRepository)
+		checkErr   func(t *testing.T, got []entity.Entry, err error)
+	}{
+		{
+			name: "First page",
+			arg:  service.ListConsentedTransactionsInput{ConsentID: consent.ID, AccountID: 1},
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, entryRepo *mockdb.MockEntryRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(&entity.Account{ID: 1, Owner: "hector"}, nil)
+				entryRepo.EXPECT().ListEntries(gomock.Any(), entity.ListEntriesInput{AccountID: 1, Limit: 50, Offset: 0}).Times(1).Return(entries, nil)
+			},
+			checkErr: func(t *testing.T, got []entity.Entry, err error) {
+				require.NoError(t, err)
+				require.Equal(t, entries, got)
+			},
+		}, {
+			name: "Later page",
+			arg:  service.ListConsentedTransactionsInput{ConsentID: consent.ID, AccountID: 1, PageID: 3, PageSize: 10},
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, entryRepo *mockdb.MockEntryRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&consent, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(&entity.Account{ID: 1, Owner: "hector"}, nil)
+				entryRepo.EXPECT().ListEntries(gomock.Any(), entity.ListEntriesInput{AccountID: 1, Limit: 10, Offset: 20}).Times(1).Return([]entity.Entry{}, nil)
+			},
+			checkErr: func(t *testing.T, got []entity.Entry, err error) {
+				require.NoError(t, err)
+				require.Empty(t, got)
+			},
+		}, {
+			name: "Transactions not granted",
+			arg:  service.ListConsentedTransactionsInput{ConsentID: consent.ID, AccountID: 1},
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, accounts *mockdb.MockAccountRepository, entryRepo *mockdb.MockEntryRepository) {
+				balances := consent
+				balances.Permissions = []string{entity.AccountPermissionBalances}
+				consents.EXPECT().GetAccountConsent(gomock.Any(), consent.ID).Times(1).Return(&balances, nil)
+				accounts.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+				entryRepo.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ []entity.Entry, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name: "Page too large",
+			arg:  service.ListConsentedTransactionsInput{ConsentID: consent.ID, AccountID: 1, PageSize: 500},
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, _ *mockdb.MockAccountRepository, entryRepo *mockdb.MockEntryRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), gomock.Any()).Times(0)
+				entryRepo.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ []entity.Entry, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "page_size")
+			},
+		}, {
+			//the offset must fit in an int32
+			name: "Page too far",
+			arg:  service.ListConsentedTransactionsInput{ConsentID: consent.ID, AccountID: 1, PageID: 1 << 30, PageSize: 200},
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository, _ *mockdb.MockAccountRepository, entryRepo *mockdb.MockEntryRepository) {
+				consents.EXPECT().GetAccountConsent(gomock.Any(), gomock.Any()).Times(0)
+				entryRepo.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ []entity.Entry, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "page_id")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			consents := mockdb.NewMockAccountConsentRepository(ctrl)
+			accounts := mockdb.NewMockAccountRepository(ctrl)
+			entryRepo := mockdb.NewMockEntryRepository(ctrl)
+			tc.buildStubs(consents, accounts, entryRepo)
+
+			svc := service.NewAccountConsentService(consents, accounts, entryRepo, mockdb.NewMockOAuthRepository(ctrl))
+			got, err := svc.ListConsentedTransactions(context.Background(), aggregator, tc.arg)
+			tc.checkErr(t, got, err)
+		})
+	}
+}
+
+func TestRevokeAccountConsent(t *testing.T) {
+	owner := &auth.Payload{Username: "hector", SessionID: uuid.NewString(), Role: auth.RoleCustomer}
+	consentID := uuid.New()
+
+	testCases := []struct {
+		name       string
+		payload    *auth.Payload
+		buildStubs func(consents *mockdb.MockAccountConsentRepository)
+		checkErr   func(t *testing.T, err error)
+	}{
+		{
+			name:    "OK",
+			payload: owner,
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository) {
+				consents.EXPECT().RevokeAccountConsent(gomock.Any(), consentID, "hector", gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, _ string, event entity.AuditEvent) error {
+						require.Equal(t, audit.ActionAccountConsentRevoke, event.Action)
+						require.Equal(t, audit.AccountConsentSubject(consentID), event.Subject)
+						return nil
+					})
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		}, {
+			//the repository only revokes the caller's own, unrevoked consents
+			name:    "Already revoked or another user's",
+			payload: &auth.Payload{Username: "achilles", Role: auth.RoleCustomer},
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository) {
+				consents.EXPECT().RevokeAccountConsent(gomock.Any(), consentID, "achilles", gomock.Any()).Times(1).Return(dbrepo.ErrAccountConsentNotFound)
+			},
+			checkErr: func(t *testing.T, err error) {
+				requireAppError(t, err, errorutil.ErrNotFound)
+			},
+		}, {
+			name:    "By the client",
+			payload: &auth.Payload{ClientID: aggregatorID, Scope: auth.ScopeAccountInformation},
+			buildStubs: func(consents *mockdb.MockAccountConsentRepository) {
+				consents.EXPECT().RevokeAccountConsent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			consents := mockdb.NewMockAccountConsentRepository(ctrl)
+			tc.buildStubs(consents)
+
+			svc := service.NewAccountConsentService(consents, mockdb.NewMockAccountRepository(ctrl), mockdb.NewMockEntryRepository(ctrl), mockdb.NewMockOAuthRepository(ctrl))
+			tc.checkErr(t, svc.RevokeAccountConsent(context.Background(), tc.payload, consentID))
+		})
+	}
+}
+
+func TestGetAccountConsent(t *testing.T) {
+	owner := &auth.Payload{Username: "hector", SessionID: uuid.NewString(), Role: auth.RoleCustomer}
+	revoked := entity.AccountConsent{ID: uuid.New(), Username: "hector", ClientID: aggregatorID, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: time.Now()}
+
+	//both sides can still see the consent and why it stopped working
+	testCases := []struct {
+		name    string
+		payload *auth.Payload
+		visible bool
+	}{
+		{name: "Owner", payload: owner, visible: true},
+		{name: "Client", payload: &auth.Payload{ClientID: aggregatorID, Scope: auth.ScopeAccountInformation}, visible: true},
+		{name: "Another user", payload: &auth.Payload{Username: "achilles", Role: auth.RoleCustomer}},
+		{name: "Another client", payload: &auth.Payload{ClientID: "cl_other", Scope: auth.ScopeAccountInformation}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			consents := mockdb.NewMockAccountConsentRepository(ctrl)
+			consents.EXPECT().GetAccountConsent(gomock.Any(), revoked.ID).Times(1).Return(&revoked, nil)
+
+			svc := service.NewAccountConsentService(consents, mockdb.NewMockAccountRepository(ctrl), mockdb.NewMockEntryRepository(ctrl), mockdb.NewMockOAuthRepository(ctrl))
+			got, err := svc.GetAccountConsent(context.Background(), tc.payload, revoked.ID)
+			if !tc.visible {
+				requireAppError(t, err, errorutil.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, entity.AccountConsentRevoked, got.Status())
+		})
+	}
+
+	t.Run("List", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		consents := mockdb.NewMockAccountConsentRepository(ctrl)
+		consents.EXPECT().ListAccountConsents(gomock.Any(), "hector").Times(1).Return([]*entity.AccountConsent{&revoked}, nil)
+
+		svc := service.NewAccountConsentService(consents, mockdb.NewMockAccountRepository(ctrl), mockdb.NewMockEntryRepository(ctrl), mockdb.NewMockOAuthRepository(ctrl))
+		got, err := svc.ListAccountConsents(context.Background(), owner)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		_, err = svc.ListAccountConsents(context.Background(), &auth.Payload{ClientID: aggregatorID})
+		requireAppError(t, err, errorutil.ErrForbidden)
+	})
+}
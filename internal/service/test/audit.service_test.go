@@ -0,0 +1,184 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListAuditEvents(t *testing.T) {
+	admin := &auth.Payload{Username: "ada", Role: auth.RoleAdmin}
+	teller := &auth.Payload{Username: "tom", Role: auth.RoleTeller}
+	now := time.Now()
+
+	testCases := []struct {
+		name       string
+		payload    *auth.Payload
+		filter     entity.AuditEventFilter
+		buildStubs func(repo *mockdb.MockAuditRepository)
+		check      func(t *testing.T, events []*entity.AuditEvent, err error)
+	}{
+		{
+			name:    "OK",
+			payload: admin,
+			filter:  entity.AuditEventFilter{Actor: "hector", BeforeID: 100},
+			buildStubs: func(repo *mockdb.MockAuditRepository) {
+				repo.EXPECT().ListAuditEvents(gomock.Any(), entity.AuditEventFilter{Actor: "hector", BeforeID: 100, PageSize: 50}).Times(1).
+					Return([]*entity.AuditEvent{{ID: 99, Action: audit.ActionLogin, Actor: "hector"}}, nil)
+			},
+			check: func(t *testing.T, events []*entity.AuditEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, events, 1)
+			},
+		}, {
+			name:    "Not an admin",
+			payload: teller,
+			buildStubs: func(repo *mockdb.MockAuditRepository) {
+				repo.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, events []*entity.AuditEvent, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:    "Invalid filter",
+			payload: admin,
+			filter:  entity.AuditEventFilter{PageSize: 1000, Since: now, Until: now.Add(-time.Hour)},
+			buildStubs: func(repo *mockdb.MockAuditRepository) {
+				repo.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, events []*entity.AuditEvent, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "page_size")
+				require.Contains(t, v.ErrVal, "since")
+			},
+		}, {
+			name:    "Internal error",
+			payload: admin,
+			buildStubs: func(repo *mockdb.MockAuditRepository) {
+				repo.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, events []*entity.AuditEvent, err error) {
+				requireAppError(t, err, errorutil.ErrInternal)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockAuditRepository(ctrl)
+			tc.buildStubs(repo)
+			events, err := service.NewAuditService(repo).ListAuditEvents(context.Background(), tc.payload, tc.filter)
+			tc.check(t, events, err)
+		})
+	}
+}
+
+func TestLoginAudit(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	cfg := config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour}
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+	ctx := audit.WithClient(context.Background(), audit.Client{IP: "198.51.100.1", UserAgent: "curl"})
+
+	t.Run("Success is recorded with the session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mockdb.NewMockUserRepository(ctrl)
+		sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+		mfa := mockdb.NewMockMFAVerifier(ctrl)
+		auditRepo := mockdb.NewMockAuditRepository(ctrl)
+		userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+		mfa.EXPECT().MFAEnabled(gomock.Any(), "hector").Times(1).Return(false, nil)
+		auditRepo.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(0)
+		sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, s entity.Session, event entity.AuditEvent) (*entity.Session, error) {
+				require.Equal(t, audit.ActionLogin, event.Action)
+				require.Equal(t, "hector", event.Actor)
+				require.Equal(t, audit.SessionSubject(s.ID), event.Subject)
+				//the login input wins over the transport's view of the client
+				require.Equal(t, "203.0.113.7", event.ClientIP)
+				require.Equal(t, "Firefox", event.UserAgent)
+				require.Equal(t, auth.AssurancePassword.String(), event.Details["assurance"])
+				return &s, nil
+			})
+
+		usrSvc := service.NewUserService(service.UserServiceDeps{
+			Users:    userRepo,
+			Token:    maker,
+			Config:   cfg,
+			Sessions: sessionRepo,
+			MFA:      mfa,
+			Audit:    auditRepo,
+			Logger:   &nopLogger,
+		})
+		_, err := usrSvc.Login(ctx, service.Logininput{Username: "hector", Password: "secret12345", ClientIP: "203.0.113.7", UserAgent: "Firefox"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Wrong password is recorded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mockdb.NewMockUserRepository(ctrl)
+		auditRepo := mockdb.NewMockAuditRepository(ctrl)
+		userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+		auditRepo.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, event entity.AuditEvent) error {
+				require.Equal(t, audit.ActionLoginFailed, event.Action)
+				require.Equal(t, audit.UserSubject("hector"), event.Subject)
+				require.Equal(t, "wrong_password", event.Details["reason"])
+				return nil
+			})
+
+		usrSvc := service.NewUserService(service.UserServiceDeps{
+			Users:    userRepo,
+			Token:    maker,
+			Config:   cfg,
+			Sessions: mockdb.NewMockSessionRepository(ctrl),
+			Audit:    auditRepo,
+			Logger:   &nopLogger,
+		})
+		_, err := usrSvc.Login(ctx, service.Logininput{Username: "hector", Password: "wrong-password"})
+		requireAppError(t, err, errorutil.ErrUnauthorized)
+	})
+
+	t.Run("Failing to record doesn't change the answer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mockdb.NewMockUserRepository(ctrl)
+		auditRepo := mockdb.NewMockAuditRepository(ctrl)
+		userRepo.EXPECT().GetUser(gomock.Any(), "nobody").Times(1).Return(nil, dbrepo.ErrUserNotFound)
+		auditRepo.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+
+		usrSvc := service.NewUserService(service.UserServiceDeps{
+			Users:    userRepo,
+			Token:    maker,
+			Config:   cfg,
+			Sessions: mockdb.NewMockSessionRepository(ctrl),
+			Audit:    auditRepo,
+			Logger:   &nopLogger,
+		})
+		_, err := usrSvc.Login(ctx, service.Logininput{Username: "nobody", Password: "secret12345"})
+		requireAppError(t, err, errorutil.ErrUnauthorized)
+	})
+}
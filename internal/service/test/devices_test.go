@@ -0,0 +1,228 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLoginNewDevice(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+	cfg := config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour}
+	input := service.Logininput{Username: "hector", Password: "secret12345", ClientIP: "203.0.113.7", UserAgent: "Firefox"}
+
+	confirmed := func(userAgent, ipRange string) *entity.KnownDevice {
+		return &entity.KnownDevice{
+			Username:      "hector",
+			UserAgentHash: auth.HashSecret(userAgent),
+			IPRange:       ipRange,
+			ConfirmedAt:   time.Now().Add(-time.Hour),
+		}
+	}
+	saved := func(confirm bool) func(context.Context, entity.KnownDevice, bool) (*entity.KnownDevice, error) {
+		return func(_ context.Context, arg entity.KnownDevice, c bool) (*entity.KnownDevice, error) {
+			require.Equal(t, confirm, c)
+			require.Equal(t, "203.0.113.0/24", arg.IPRange)
+			require.Equal(t, "203.0.113.7", arg.LastIP)
+			arg.ID = 7
+			return &arg, nil
+		}
+	}
+	alertEvent := func(event string) func(context.Context, *jobs.SecurityAlertPayload) error {
+		return func(_ context.Context, payload *jobs.SecurityAlertPayload) error {
+			require.Equal(t, "hector", payload.Username)
+			require.Equal(t, event, payload.Event)
+			require.Equal(t, "Firefox", payload.UserAgent)
+			return nil
+		}
+	}
+
+	testCases := []struct {
+		name                string
+		requireConfirmation bool
+		buildStubs          func(devices *mockdb.MockKnownDeviceRepository, alerts *mockdb.MockDeviceAlertQueue)
+		check               func(t *testing.T, result *service.AuthResult, err error)
+	}{
+		{
+			name: "First device is trusted",
+			buildStubs: func(devices *mockdb.MockKnownDeviceRepository, alerts *mockdb.MockDeviceAlertQueue) {
+				devices.EXPECT().ListKnownDevices(gomock.Any(), "hector").Times(1).Return(nil, nil)
+				devices.EXPECT().SaveKnownDevice(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(saved(true))
+				alerts.EXPECT().JobSecurityAlert(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, result.AccessToken)
+			},
+		}, {
+			name: "Known device",
+			buildStubs: func(devices *mockdb.MockKnownDeviceRepository, alerts *mockdb.MockDeviceAlertQueue) {
+				devices.EXPECT().ListKnownDevices(gomock.Any(), "hector").Times(1).
+					Return([]*entity.KnownDevice{confirmed("Safari", "198.51.100.0/24"), confirmed("Firefox", "203.0.113.0/24")}, nil)
+				devices.EXPECT().SaveKnownDevice(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(saved(true))
+				alerts.EXPECT().JobSecurityAlert(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, result.AccessToken)
+			},
+		}, {
+			name: "New device alerts",
+			buildStubs: func(devices *mockdb.MockKnownDeviceRepository, alerts *mockdb.MockDeviceAlertQueue) {
+				devices.EXPECT().ListKnownDevices(gomock.Any(), "hector").Times(1).
+					Return([]*entity.KnownDevice{confirmed("Safari", "203.0.113.0/24")}, nil)
+				devices.EXPECT().SaveKnownDevice(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(saved(true))
+				alerts.EXPECT().JobSecurityAlert(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(alertEvent("New sign-in from an unrecognized device"))
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, result.AccessToken)
+			},
+		}, {
+			name: "Known user agent from a new network alerts",
+			buildStubs: func(devices *mockdb.MockKnownDeviceRepository, alerts *mockdb.MockDeviceAlertQueue) {
+				devices.EXPECT().ListKnownDevices(gomock.Any(), "hector").Times(1).
+					Return([]*entity.KnownDevice{confirmed("Firefox", "198.51.100.0/24")}, nil)
+				devices.EXPECT().SaveKnownDevice(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(saved(true))
+				alerts.EXPECT().JobSecurityAlert(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(alertEvent("Sign-in from a new location on a recognized device"))
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, result.AccessToken)
+			},
+		}, {
+			name:                "Unconfirmed devices don't count",
+			requireConfirmation: true,
+			buildStubs: func(devices *mockdb.MockKnownDeviceRepository, alerts *mockdb.MockDeviceAlertQueue) {
+				pending := confirmed("Firefox", "203.0.113.0/24")
+				pending.ConfirmedAt = time.Time{}
+				devices.EXPECT().ListKnownDevices(gomock.Any(), "hector").Times(1).
+					Return([]*entity.KnownDevice{confirmed("Safari", "198.51.100.0/24"), pending}, nil)
+				devices.EXPECT().SaveKnownDevice(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(saved(false))
+				alerts.EXPECT().JobDeviceConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.True(t, result.DeviceConfirmationRequired)
+			},
+		}, {
+			name:                "New device needs confirmation",
+			requireConfirmation: true,
+			buildStubs: func(devices *mockdb.MockKnownDeviceRepository, alerts *mockdb.MockDeviceAlertQueue) {
+				devices.EXPECT().ListKnownDevices(gomock.Any(), "hector").Times(1).
+					Return([]*entity.KnownDevice{confirmed("Safari", "198.51.100.0/24")}, nil)
+				devices.EXPECT().SaveKnownDevice(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(saved(false))
+				alerts.EXPECT().JobSecurityAlert(gomock.Any(), gomock.Any()).Times(0)
+				alerts.EXPECT().JobDeviceConfirmation(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, payload *jobs.DeviceConfirmationPayload) error {
+						require.Equal(t, "hector", payload.Username)
+						require.EqualValues(t, 7, payload.DeviceID)
+						require.Equal(t, "203.0.113.7", payload.ClientIP)
+						return nil
+					})
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.True(t, result.DeviceConfirmationRequired)
+				//no session until the device is confirmed
+				require.Empty(t, result.AccessToken)
+				require.Empty(t, result.RefreshToken)
+			},
+		}, {
+			name:                "Confirmation email not queued",
+			requireConfirmation: true,
+			buildStubs: func(devices *mockdb.MockKnownDeviceRepository, alerts *mockdb.MockDeviceAlertQueue) {
+				devices.EXPECT().ListKnownDevices(gomock.Any(), "hector").Times(1).
+					Return([]*entity.KnownDevice{confirmed("Safari", "198.51.100.0/24")}, nil)
+				devices.EXPECT().SaveKnownDevice(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(saved(false))
+				alerts.EXPECT().JobDeviceConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(context.DeadlineExceeded)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrInternal)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mockdb.NewMockUserRepository(ctrl)
+			sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+			mfa := mockdb.NewMockMFAVerifier(ctrl)
+			devices := mockdb.NewMockKnownDeviceRepository(ctrl)
+			alerts := mockdb.NewMockDeviceAlertQueue(ctrl)
+			tc.buildStubs(devices, alerts)
+			userRepo.EXPECT().GetUser(gomock.Any(), "hector").AnyTimes().Return(&user, nil)
+			mfa.EXPECT().MFAEnabled(gomock.Any(), gomock.Any()).AnyTimes().Return(false, nil)
+			sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+				DoAndReturn(func(_ context.Context, s entity.Session, _ entity.AuditEvent) (*entity.Session, error) {
+					return &s, nil
+				})
+
+			detector := service.NewDeviceDetector(devices, alerts, tc.requireConfirmation, &nopLogger)
+			usrSvc := service.NewUserService(service.UserServiceDeps{
+				Users:    userRepo,
+				Token:    maker,
+				Config:   cfg,
+				Sessions: sessionRepo,
+				MFA:      mfa,
+				Devices:  detector,
+				Logger:   &nopLogger,
+			})
+			result, err := usrSvc.Login(context.Background(), input)
+			tc.check(t, result, err)
+		})
+	}
+}
+
+func TestConfirmDevice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	devices := mockdb.NewMockKnownDeviceRepository(ctrl)
+	detector := service.NewDeviceDetector(devices, mockdb.NewMockDeviceAlertQueue(ctrl), true, &nopLogger)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    mockdb.NewMockUserRepository(ctrl),
+		Token:    maker,
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		Devices:  detector,
+		Logger:   &nopLogger,
+	})
+
+	var v *validator.Validator
+	require.ErrorAs(t, usrSvc.ConfirmDevice(context.Background(), ""), &v)
+	require.Contains(t, v.ErrVal, "token")
+
+	devices.EXPECT().ConfirmKnownDevice(gomock.Any(), auth.HashSecret("used-token"), gomock.Any()).Times(1).Return(nil, dbrepo.ErrInvalidDeviceToken)
+	requireAppError(t, usrSvc.ConfirmDevice(context.Background(), "used-token"), errorutil.ErrBadRequest)
+
+	//only the hash of the emailed token is looked up
+	devices.EXPECT().ConfirmKnownDevice(gomock.Any(), auth.HashSecret("good-token"), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ string, event entity.AuditEvent) (*entity.KnownDevice, error) {
+			require.Equal(t, audit.ActionDeviceConfirm, event.Action)
+			return &entity.KnownDevice{ID: 7, Username: "hector", ConfirmedAt: time.Now()}, nil
+		})
+	require.NoError(t, usrSvc.ConfirmDevice(context.Background(), "good-token"))
+}
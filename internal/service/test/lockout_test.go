@@ -0,0 +1,382 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var lockoutPolicy = service.LockoutPolicy{
+	Threshold:   3,
+	IPThreshold: 10,
+	Window:      15 * time.Minute,
+	Duration:    time.Minute,
+	MaxDuration: 10 * time.Minute,
+}
+
+func TestLoginLockout(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+	cfg := config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour}
+	login := func(username, password string) service.Logininput {
+		return service.Logininput{Username: username, Password: password, ClientIP: "203.0.113.7", UserAgent: "curl"}
+	}
+	failure := func(subject string, attempts, lockouts int) *entity.LoginFailure {
+		return &entity.LoginFailure{Subject: subject, FailedAttempts: attempts, Lockouts: lockouts}
+	}
+
+	testCases := []struct {
+		name       string
+		input      service.Logininput
+		buildStubs func(userRepo *mockdb.MockUserRepository, failures *mockdb.MockLoginFailureRepository, alerts *mockdb.MockSecurityAlertQueue)
+		check      func(t *testing.T, result *service.AuthResult, err error)
+	}{
+		{
+			name:  "Wrong password",
+			input: login("hector", "wrong-password"),
+			buildStubs: func(userRepo *mockdb.MockUserRepository, failures *mockdb.MockLoginFailureRepository, alerts *mockdb.MockSecurityAlertQueue) {
+				failures.EXPECT().ListLoginLocks(gomock.Any(), []string{"user:hector", "ip:203.0.113.0/24"}).Times(1).Return(nil, nil)
+				userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), "user:hector", gomock.Any()).Times(1).Return(failure("user:hector", 1, 0), nil)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), "ip:203.0.113.0/24", gomock.Any()).Times(1).Return(failure("ip:203.0.113.0/24", 1, 0), nil)
+				failures.EXPECT().LockLoginSubject(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+				require.Equal(t, "invalid username or password", err.(*errorutil.AppError).Message)
+			},
+		}, {
+			name:  "Unknown username",
+			input: login("nobody", "wrong-password"),
+			buildStubs: func(userRepo *mockdb.MockUserRepository, failures *mockdb.MockLoginFailureRepository, alerts *mockdb.MockSecurityAlertQueue) {
+				failures.EXPECT().ListLoginLocks(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+				userRepo.EXPECT().GetUser(gomock.Any(), "nobody").Times(1).Return(nil, dbrepo.ErrUserNotFound)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), "user:nobody", gomock.Any()).Times(1).Return(failure("user:nobody", 1, 0), nil)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), "ip:203.0.113.0/24", gomock.Any()).Times(1).Return(failure("ip:203.0.113.0/24", 1, 0), nil)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				//indistinguishable from a wrong password
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+				require.Equal(t, "invalid username or password", err.(*errorutil.AppError).Message)
+			},
+		}, {
+			name:  "Threshold locks and alerts",
+			input: login("hector", "wrong-password"),
+			buildStubs: func(userRepo *mockdb.MockUserRepository, failures *mockdb.MockLoginFailureRepository, alerts *mockdb.MockSecurityAlertQueue) {
+				failures.EXPECT().ListLoginLocks(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+				userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), "user:hector", gomock.Any()).Times(1).Return(failure("user:hector", 3, 2), nil)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), "ip:203.0.113.0/24", gomock.Any()).Times(1).Return(failure("ip:203.0.113.0/24", 3, 0), nil)
+				//third lockout lasts four times the first
+				failures.EXPECT().LockLoginSubject(gomock.Any(), "user:hector", gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, subject string, until time.Time) (*entity.LoginFailure, error) {
+						require.WithinDuration(t, time.Now().Add(4*time.Minute), until, 5*time.Second)
+						return failure(subject, 0, 3), nil
+					})
+				alerts.EXPECT().JobSecurityAlert(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, payload *jobs.SecurityAlertPayload) error {
+						require.Equal(t, "hector", payload.Username)
+						require.Equal(t, "203.0.113.7", payload.ClientIP)
+						return nil
+					})
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name:  "Unknown username locks without alert",
+			input: login("nobody", "wrong-password"),
+			buildStubs: func(userRepo *mockdb.MockUserRepository, failures *mockdb.MockLoginFailureRepository, alerts *mockdb.MockSecurityAlertQueue) {
+				failures.EXPECT().ListLoginLocks(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+				userRepo.EXPECT().GetUser(gomock.Any(), "nobody").Times(1).Return(nil, dbrepo.ErrUserNotFound)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), "user:nobody", gomock.Any()).Times(1).Return(failure("user:nobody", 3, 9), nil)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), "ip:203.0.113.0/24", gomock.Any()).Times(1).Return(failure("ip:203.0.113.0/24", 4, 0), nil)
+				//escalation stops at the max
+				failures.EXPECT().LockLoginSubject(gomock.Any(), "user:nobody", gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, subject string, until time.Time) (*entity.LoginFailure, error) {
+						require.WithinDuration(t, time.Now().Add(10*time.Minute), until, 5*time.Second)
+						return failure(subject, 0, 10), nil
+					})
+				alerts.EXPECT().JobSecurityAlert(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name:  "Locked",
+			input: login("hector", "secret12345"),
+			buildStubs: func(userRepo *mockdb.MockUserRepository, failures *mockdb.MockLoginFailureRepository, alerts *mockdb.MockSecurityAlertQueue) {
+				failures.EXPECT().ListLoginLocks(gomock.Any(), gomock.Any()).Times(1).
+					Return([]*entity.LoginFailure{{Subject: "user:hector", LockedUntil: time.Now().Add(time.Minute)}}, nil)
+				//even the right password is refused, and the user is never looked up
+				userRepo.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				failures.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrTooManyRequests)
+			},
+		}, {
+			name:  "Correct password clears failures",
+			input: login("hector", "secret12345"),
+			buildStubs: func(userRepo *mockdb.MockUserRepository, failures *mockdb.MockLoginFailureRepository, alerts *mockdb.MockSecurityAlertQueue) {
+				failures.EXPECT().ListLoginLocks(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+				userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+				failures.EXPECT().ClearLoginFailures(gomock.Any(), "user:hector").Times(1).Return(nil)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, result.AccessToken)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mockdb.NewMockUserRepository(ctrl)
+			sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+			mfa := mockdb.NewMockMFAVerifier(ctrl)
+			failures := mockdb.NewMockLoginFailureRepository(ctrl)
+			alerts := mockdb.NewMockSecurityAlertQueue(ctrl)
+			tc.buildStubs(userRepo, failures, alerts)
+			mfa.EXPECT().MFAEnabled(gomock.Any(), gomock.Any()).AnyTimes().Return(false, nil)
+			sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+				DoAndReturn(func(_ context.Context, s entity.Session, _ entity.AuditEvent) (*entity.Session, error) {
+					return &s, nil
+				})
+
+			lockout := service.NewLoginLockout(failures, alerts, lockoutPolicy, &nopLogger)
+			usrSvc := service.NewUserService(service.UserServiceDeps{
+				Users:    userRepo,
+				Token:    maker,
+				Config:   cfg,
+				Sessions: sessionRepo,
+				MFA:      mfa,
+				Lockout:  lockout,
+				Logger:   &nopLogger,
+			})
+			result, err := usrSvc.Login(context.Background(), tc.input)
+			tc.check(t, result, err)
+		})
+	}
+}
+
+func TestLoginLockoutIgnoresClientPort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+
+	userRepo := mockdb.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().GetUser(gomock.Any(), "hector").AnyTimes().Return(&user, nil)
+	failures := mockdb.NewMockLoginFailureRepository(ctrl)
+	failures.EXPECT().ListLoginLocks(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, nil)
+	counts := map[string]int{}
+	failures.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, subject string, _ time.Time) (*entity.LoginFailure, error) {
+			counts[subject]++
+			return &entity.LoginFailure{Subject: subject, FailedAttempts: counts[subject]}, nil
+		})
+
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    userRepo,
+		Token:    maker,
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		Lockout:  service.NewLoginLockout(failures, nil, lockoutPolicy, &nopLogger),
+		Logger:   &nopLogger,
+	})
+	for _, addr := range []string{"203.0.113.7:50001", "203.0.113.7:50002"} {
+		_, err := usrSvc.Login(context.Background(), service.Logininput{Username: "hector", Password: "wrong-password", ClientIP: addr})
+		requireAppError(t, err, errorutil.ErrUnauthorized)
+	}
+
+	//a new source port is the same client, so both attempts share one counter
+	require.Equal(t, map[string]int{"user:hector": 2, "ip:203.0.113.0/24": 2}, counts)
+}
+
+func TestSecondFactorLockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+
+	userRepo := mockdb.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().GetUser(gomock.Any(), "hector").AnyTimes().Return(&user, nil)
+	mfa := mockdb.NewMockMFAVerifier(ctrl)
+	mfa.EXPECT().VerifyCode(gomock.Any(), "hector", gomock.Any()).AnyTimes().
+		Return(errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid authentication code", nil))
+	failures := mockdb.NewMockLoginFailureRepository(ctrl)
+	counts := map[string]int{}
+	failures.EXPECT().ListLoginLocks(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, subjects []string) ([]*entity.LoginFailure, error) {
+			var locks []*entity.LoginFailure
+			for _, subject := range subjects {
+				if counts[subject] >= lockoutPolicy.Threshold {
+					locks = append(locks, &entity.LoginFailure{Subject: subject, LockedUntil: time.Now().Add(time.Minute)})
+				}
+			}
+			return locks, nil
+		})
+	failures.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, subject string, _ time.Time) (*entity.LoginFailure, error) {
+			counts[subject]++
+			return &entity.LoginFailure{Subject: subject, FailedAttempts: counts[subject]}, nil
+		})
+	failures.EXPECT().LockLoginSubject(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(&entity.LoginFailure{}, nil)
+	auditRepo := mockdb.NewMockAuditRepository(ctrl)
+	var reasons []string
+	auditRepo.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(5).
+		DoAndReturn(func(_ context.Context, event entity.AuditEvent) error {
+			require.Equal(t, audit.ActionLoginFailed, event.Action)
+			reasons = append(reasons, event.Details["reason"])
+			return nil
+		})
+
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    userRepo,
+		Token:    maker,
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		MFA:      mfa,
+		Lockout:  service.NewLoginLockout(failures, nil, lockoutPolicy, &nopLogger),
+		Audit:    auditRepo,
+		Logger:   &nopLogger,
+	})
+	mfaToken, _, err := maker.GenerateToken("hector", time.Minute, auth.WithPurpose(auth.PurposeMFA))
+	require.NoError(t, err)
+	signedIn := &auth.Payload{Username: "hector", SessionID: uuid.NewString(), Role: auth.RoleCustomer}
+	ctx := audit.WithClient(context.Background(), audit.Client{IP: "203.0.113.7", UserAgent: "Firefox"})
+
+	//wrong codes and re-authentication answers count against the user like wrong passwords
+	_, err = usrSvc.VerifyMFA(context.Background(), service.VerifyMFAInput{MFAToken: mfaToken, Code: "000000"})
+	requireAppError(t, err, errorutil.ErrUnauthorized)
+	_, err = usrSvc.Reauthenticate(ctx, signedIn, service.ReauthenticateInput{Code: "000000"})
+	requireAppError(t, err, errorutil.ErrUnauthorized)
+	_, err = usrSvc.Reauthenticate(ctx, signedIn, service.ReauthenticateInput{Password: "wrong-password"})
+	requireAppError(t, err, errorutil.ErrUnauthorized)
+	require.Equal(t, lockoutPolicy.Threshold, counts["user:hector"])
+	//re-authentication also counts against the address it came from
+	require.Equal(t, 2, counts["ip:203.0.113.0/24"])
+
+	//once locked, even the right answer is refused
+	_, err = usrSvc.VerifyMFA(context.Background(), service.VerifyMFAInput{MFAToken: mfaToken, Code: "123456"})
+	requireAppError(t, err, errorutil.ErrTooManyRequests)
+	_, err = usrSvc.Reauthenticate(ctx, signedIn, service.ReauthenticateInput{Password: "secret12345"})
+	requireAppError(t, err, errorutil.ErrTooManyRequests)
+	require.Equal(t, []string{"wrong_mfa_code", "wrong_mfa_code", "wrong_password", "locked", "locked"}, reasons)
+}
+
+func TestUnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+
+	userRepo := mockdb.NewMockUserRepository(ctrl)
+	failures := mockdb.NewMockLoginFailureRepository(ctrl)
+	lockout := service.NewLoginLockout(failures, nil, lockoutPolicy, &nopLogger)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    userRepo,
+		Token:    maker,
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		Lockout:  lockout,
+		Logger:   &nopLogger,
+	})
+
+	customer := &auth.Payload{Username: "hector", Role: auth.RoleCustomer}
+	admin := &auth.Payload{Username: "root", Role: auth.RoleAdmin}
+
+	failures.EXPECT().UnlockLoginSubject(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	requireAppError(t, usrSvc.UnlockUser(context.Background(), customer, "hector"), errorutil.ErrForbidden)
+
+	userRepo.EXPECT().GetUser(gomock.Any(), "nobody").Times(1).Return(nil, dbrepo.ErrUserNotFound)
+	requireAppError(t, usrSvc.UnlockUser(context.Background(), admin, "nobody"), errorutil.ErrNotFound)
+
+	userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+	failures.EXPECT().UnlockLoginSubject(gomock.Any(), "user:hector", gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ string, event entity.AuditEvent) error {
+			require.Equal(t, audit.ActionUserUnlock, event.Action)
+			require.Equal(t, "root", event.Actor)
+			require.Equal(t, audit.UserSubject("hector"), event.Subject)
+			return nil
+		})
+	require.NoError(t, usrSvc.UnlockUser(context.Background(), admin, "hector"))
+}
+
+func TestLoginLinkLockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	linkToken, linkPayload, err := maker.GenerateToken("hector", 10*time.Minute, auth.WithPurpose(auth.PurposeLoginLink))
+	require.NoError(t, err)
+	linkID := uuid.MustParse(linkPayload.ID)
+
+	failures := mockdb.NewMockLoginFailureRepository(ctrl)
+	counts := map[string]int{}
+	failures.EXPECT().ListLoginLocks(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, subjects []string) ([]*entity.LoginFailure, error) {
+			var locks []*entity.LoginFailure
+			for _, subject := range subjects {
+				if counts[subject] >= lockoutPolicy.Threshold {
+					locks = append(locks, &entity.LoginFailure{Subject: subject, LockedUntil: time.Now().Add(time.Minute)})
+				}
+			}
+			return locks, nil
+		})
+	failures.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, subject string, _ time.Time) (*entity.LoginFailure, error) {
+			counts[subject]++
+			return &entity.LoginFailure{Subject: subject, FailedAttempts: counts[subject]}, nil
+		})
+	failures.EXPECT().LockLoginSubject(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(&entity.LoginFailure{}, nil)
+
+	links := mockdb.NewMockLoginLinkRepository(ctrl)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    mockdb.NewMockUserRepository(ctrl),
+		Token:    maker,
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		Lockout:  service.NewLoginLockout(failures, nil, lockoutPolicy, &nopLogger),
+		Links:    service.NewLoginLinks(links, mockdb.NewMockLoginLinkQueue(ctrl)),
+		Logger:   &nopLogger,
+	})
+	consume := func() error {
+		_, err := usrSvc.ConsumeLoginLink(context.Background(), service.ConsumeLoginLinkInput{Token: linkToken, ClientIP: "203.0.113.7"})
+		return err
+	}
+
+	//replaying a spent link counts against the user
+	links.EXPECT().UseLoginLink(gomock.Any(), linkID).Times(lockoutPolicy.Threshold).Return("", dbrepo.ErrInvalidLoginLink)
+	for range lockoutPolicy.Threshold {
+		requireAppError(t, consume(), errorutil.ErrUnauthorized)
+	}
+
+	//a locked user can't sign in with a link either, and the link isn't spent
+	requireAppError(t, consume(), errorutil.ErrTooManyRequests)
+}
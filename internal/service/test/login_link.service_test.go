@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestLoginLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	tasks := mockdb.NewMockLoginLinkQueue(ctrl)
+	links := service.NewLoginLinks(mockdb.NewMockLoginLinkRepository(ctrl), tasks)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    mockdb.NewMockUserRepository(ctrl),
+		Token:    maker,
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		Links:    links,
+		Logger:   &nopLogger,
+	})
+
+	tasks.EXPECT().JobLoginLink(gomock.Any(), gomock.Any()).Times(0)
+	requireAppError(t, usrSvc.RequestLoginLink(context.Background(), "not-an-email"), errorutil.ErrBadRequest)
+
+	//the account is looked up by the worker, so unknown addresses are queued too
+	tasks.EXPECT().JobLoginLink(gomock.Any(), &jobs.LoginLinkPayload{Email: "nobody@example.com"}).Times(1).Return(nil)
+	require.NoError(t, usrSvc.RequestLoginLink(context.Background(), "nobody@example.com"))
+
+	tasks.EXPECT().JobLoginLink(gomock.Any(), gomock.Any()).Times(1).Return(context.DeadlineExceeded)
+	requireAppError(t, usrSvc.RequestLoginLink(context.Background(), "hector@example.com"), errorutil.ErrInternal)
+
+	disabled := service.NewUserService(service.UserServiceDeps{
+		Users:    mockdb.NewMockUserRepository(ctrl),
+		Token:    maker,
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		Logger:   &nopLogger,
+	})
+	requireAppError(t, disabled.RequestLoginLink(context.Background(), "hector@example.com"), errorutil.ErrBadRequest)
+}
+
+func TestConsumeLoginLink(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	cfg := config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour}
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+
+	linkToken, linkPayload, err := maker.GenerateToken("hector", 10*time.Minute, auth.WithPurpose(auth.PurposeLoginLink))
+	require.NoError(t, err)
+	linkID := uuid.MustParse(linkPayload.ID)
+	accessToken, _, err := maker.GenerateToken("hector", time.Minute, auth.WithSessionID(uuid.New()))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		token      string
+		buildStubs func(links *mockdb.MockLoginLinkRepository, userRepo *mockdb.MockUserRepository, sessionRepo *mockdb.MockSessionRepository, mfa *mockdb.MockMFAVerifier)
+		check      func(t *testing.T, result *service.AuthResult, err error)
+	}{
+		{
+			name:  "OK",
+			token: linkToken,
+			buildStubs: func(links *mockdb.MockLoginLinkRepository, userRepo *mockdb.MockUserRepository, sessionRepo *mockdb.MockSessionRepository, mfa *mockdb.MockMFAVerifier) {
+				links.EXPECT().UseLoginLink(gomock.Any(), linkID).Times(1).Return("hector", nil)
+				userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+				mfa.EXPECT().MFAEnabled(gomock.Any(), "hector").Times(1).Return(false, nil)
+				sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, s entity.Session, _ entity.AuditEvent) (*entity.Session, error) {
+						//the session belongs to the device that opened the link
+						require.Equal(t, "203.0.113.7", s.ClientIp)
+						require.Equal(t, "Firefox", s.UserAgent)
+						return &s, nil
+					})
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, result.AccessToken)
+				require.NotEmpty(t, result.RefreshToken)
+			},
+		}, {
+			name:  "Second factor still required",
+			token: linkToken,
+			buildStubs: func(links *mockdb.MockLoginLinkRepository, userRepo *mockdb.MockUserRepository, sessionRepo *mockdb.MockSessionRepository, mfa *mockdb.MockMFAVerifier) {
+				links.EXPECT().UseLoginLink(gomock.Any(), linkID).Times(1).Return("hector", nil)
+				userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+				mfa.EXPECT().MFAEnabled(gomock.Any(), "hector").Times(1).Return(true, nil)
+				sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.True(t, result.MFARequired)
+				require.Empty(t, result.AccessToken)
+			},
+		}, {
+			name:  "Used or expired link",
+			token: linkToken,
+			buildStubs: func(links *mockdb.MockLoginLinkRepository, userRepo *mockdb.MockUserRepository, sessionRepo *mockdb.MockSessionRepository, mfa *mockdb.MockMFAVerifier) {
+				links.EXPECT().UseLoginLink(gomock.Any(), linkID).Times(1).Return("", dbrepo.ErrInvalidLoginLink)
+				sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name:  "Not a login link",
+			token: accessToken,
+			buildStubs: func(links *mockdb.MockLoginLinkRepository, userRepo *mockdb.MockUserRepository, sessionRepo *mockdb.MockSessionRepository, mfa *mockdb.MockMFAVerifier) {
+				links.EXPECT().UseLoginLink(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name:  "Tampered token",
+			token: linkToken + "x",
+			buildStubs: func(links *mockdb.MockLoginLinkRepository, userRepo *mockdb.MockUserRepository, sessionRepo *mockdb.MockSessionRepository, mfa *mockdb.MockMFAVerifier) {
+				links.EXPECT().UseLoginLink(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name:  "Internal error",
+			token: linkToken,
+			buildStubs: func(links *mockdb.MockLoginLinkRepository, userRepo *mockdb.MockUserRepository, sessionRepo *mockdb.MockSessionRepository, mfa *mockdb.MockMFAVerifier) {
+				links.EXPECT().UseLoginLink(gomock.Any(), linkID).Times(1).Return("", sql.ErrConnDone)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrInternal)
+			},
+		}, {
+			name: "No token",
+			buildStubs: func(links *mockdb.MockLoginLinkRepository, userRepo *mockdb.MockUserRepository, sessionRepo *mockdb.MockSessionRepository, mfa *mockdb.MockMFAVerifier) {
+				links.EXPECT().UseLoginLink(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "token")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			links := mockdb.NewMockLoginLinkRepository(ctrl)
+			userRepo := mockdb.NewMockUserRepository(ctrl)
+			sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+			mfa := mockdb.NewMockMFAVerifier(ctrl)
+			tc.buildStubs(links, userRepo, sessionRepo, mfa)
+
+			loginLinks := service.NewLoginLinks(links, mockdb.NewMockLoginLinkQueue(ctrl))
+			usrSvc := service.NewUserService(service.UserServiceDeps{
+				Users:    userRepo,
+				Token:    maker,
+				Config:   cfg,
+				Sessions: sessionRepo,
+				MFA:      mfa,
+				Links:    loginLinks,
+				Logger:   &nopLogger,
+			})
+			result, err := usrSvc.ConsumeLoginLink(context.Background(), service.ConsumeLoginLinkInput{
+				Token:     tc.token,
+				ClientIP:  "203.0.113.7",
+				UserAgent: "Firefox",
+			})
+			tc.check(t, result, err)
+		})
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
 )
 
+var nopLogger = zerolog.Nop()
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode) //global testmodem
 	os.Exit(m.Run())
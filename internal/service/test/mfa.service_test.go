@@ -0,0 +1,293 @@
+package service_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/totp"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const totpKey = "01234567890123456789012345678901"
+
+func requireAppError(t *testing.T, err error, code errorutil.ErrorKind) {
+	t.Helper()
+	var appErr *errorutil.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, code, appErr.Code)
+}
+
+// sealedTOTP returns a stored authenticator for hector and its clear secret.
+func sealedTOTP(t *testing.T, sealer *totp.Sealer, confirmed bool) (*entity.UserTOTP, string) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	sealed, err := sealer.Seal("hector", secret)
+	require.NoError(t, err)
+	stored := &entity.UserTOTP{Username: "hector", Secret: sealed}
+	if confirmed {
+		stored.ConfirmedAt = time.Now()
+	}
+	return stored, secret
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sealer, err := totp.NewSealer(totpKey)
+	require.NoError(t, err)
+	repo := mockdb.NewMockMFARepository(ctrl)
+	svc := service.NewMFAService(repo, sealer, "Bank")
+	payload := &auth.Payload{Username: "hector"}
+
+	var stored string
+	repo.EXPECT().CreatePendingTOTP(gomock.Any(), "hector", gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, username, secret string) (*entity.UserTOTP, error) {
+			stored = secret
+			return &entity.UserTOTP{Username: username, Secret: secret}, nil
+		})
+	enrollment, err := svc.EnrollTOTP(context.Background(), payload)
+	require.NoError(t, err)
+
+	//the secret is sealed at rest
+	require.NotEqual(t, enrollment.Secret, stored)
+	opened, err := sealer.Open("hector", stored)
+	require.NoError(t, err)
+	require.Equal(t, enrollment.Secret, opened)
+
+	uri, err := url.Parse(enrollment.ProvisioningURI)
+	require.NoError(t, err)
+	require.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+	require.Equal(t, "/Bank:hector", uri.Path)
+
+	repo.EXPECT().CreatePendingTOTP(gomock.Any(), "hector", gomock.Any()).Times(1).Return(nil, dbrepo.ErrTOTPAlreadyEnabled)
+	_, err = svc.EnrollTOTP(context.Background(), payload)
+	requireAppError(t, err, errorutil.ErrConflict)
+
+	_, err = service.NewMFAService(repo, nil, "Bank").EnrollTOTP(context.Background(), payload)
+	requireAppError(t, err, errorutil.ErrInternal)
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	sealer, err := totp.NewSealer(totpKey)
+	require.NoError(t, err)
+	payload := &auth.Payload{Username: "hector"}
+
+	t.Run("OK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockMFARepository(ctrl)
+		svc := service.NewMFAService(repo, sealer, "Bank")
+
+		pending, secret := sealedTOTP(t, sealer, false)
+		now := time.Now()
+		code, err := totp.Code(secret, now)
+		require.NoError(t, err)
+
+		var hashes []string
+		repo.EXPECT().GetUserTOTP(gomock.Any(), "hector").Times(1).Return(pending, nil)
+		repo.EXPECT().ConfirmTOTP(gomock.Any(), "hector", totp.Step(now), gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, _ string, _ int64, h []string, event entity.AuditEvent) error {
+				require.Equal(t, audit.ActionTOTPEnable, event.Action)
+				hashes = h
+				return nil
+			})
+		recoveryCodes, err := svc.ConfirmTOTP(context.Background(), payload, code)
+		require.NoError(t, err)
+		require.Len(t, recoveryCodes, 10)
+		require.Len(t, hashes, 10)
+		require.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, recoveryCodes[0])
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockMFARepository(ctrl)
+		svc := service.NewMFAService(repo, sealer, "Bank")
+
+		pending, _ := sealedTOTP(t, sealer, false)
+		repo.EXPECT().GetUserTOTP(gomock.Any(), "hector").Times(1).Return(pending, nil)
+		repo.EXPECT().ConfirmTOTP(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		_, err := svc.ConfirmTOTP(context.Background(), payload, "000000x")
+		requireAppError(t, err, errorutil.ErrUnauthorized)
+	})
+
+	t.Run("Already enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockMFARepository(ctrl)
+		svc := service.NewMFAService(repo, sealer, "Bank")
+
+		enabled, _ := sealedTOTP(t, sealer, true)
+		repo.EXPECT().GetUserTOTP(gomock.Any(), "hector").Times(1).Return(enabled, nil)
+		_, err := svc.ConfirmTOTP(context.Background(), payload, "123456")
+		requireAppError(t, err, errorutil.ErrConflict)
+	})
+}
+
+func TestVerifyMFACode(t *testing.T) {
+	sealer, err := totp.NewSealer(totpKey)
+	require.NoError(t, err)
+
+	t.Run("TOTP code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockMFARepository(ctrl)
+		svc := service.NewMFAService(repo, sealer, "Bank")
+
+		enabled, secret := sealedTOTP(t, sealer, true)
+		now := time.Now()
+		code, err := totp.Code(secret, now)
+		require.NoError(t, err)
+
+		repo.EXPECT().GetUserTOTP(gomock.Any(), "hector").Times(2).Return(enabled, nil)
+		repo.EXPECT().UseTOTPStep(gomock.Any(), "hector", totp.Step(now)).Times(1).Return(nil)
+		require.NoError(t, svc.VerifyCode(context.Background(), "hector", code))
+
+		//another request already spent this step
+		repo.EXPECT().UseTOTPStep(gomock.Any(), "hector", gomock.Any()).Times(1).Return(dbrepo.ErrTOTPCodeReused)
+		requireAppError(t, svc.VerifyCode(context.Background(), "hector", code), errorutil.ErrUnauthorized)
+	})
+
+	t.Run("Recovery code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockMFARepository(ctrl)
+		svc := service.NewMFAService(repo, sealer, "Bank")
+
+		enabled, _ := sealedTOTP(t, sealer, true)
+		repo.EXPECT().GetUserTOTP(gomock.Any(), "hector").Times(2).Return(enabled, nil)
+		repo.EXPECT().UseRecoveryCode(gomock.Any(), "hector", auth.HashSecret("abcdefghij")).Times(1).Return(nil)
+		require.NoError(t, svc.VerifyCode(context.Background(), "hector", " ABCDE-fghij "))
+
+		repo.EXPECT().UseRecoveryCode(gomock.Any(), "hector", gomock.Any()).Times(1).Return(dbrepo.ErrInvalidRecoveryCode)
+		requireAppError(t, svc.VerifyCode(context.Background(), "hector", "abcde-fghij"), errorutil.ErrUnauthorized)
+	})
+
+	t.Run("Not enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockMFARepository(ctrl)
+		svc := service.NewMFAService(repo, sealer, "Bank")
+
+		pending, _ := sealedTOTP(t, sealer, false)
+		repo.EXPECT().GetUserTOTP(gomock.Any(), "hector").Times(1).Return(pending, nil)
+		repo.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		requireAppError(t, svc.VerifyCode(context.Background(), "hector", "123456"), errorutil.ErrBadRequest)
+	})
+}
+
+func TestDisableTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sealer, err := totp.NewSealer(totpKey)
+	require.NoError(t, err)
+	repo := mockdb.NewMockMFARepository(ctrl)
+	svc := service.NewMFAService(repo, sealer, "Bank")
+	payload := &auth.Payload{Username: "hector", Role: auth.RoleCustomer}
+
+	enabled, _ := sealedTOTP(t, sealer, true)
+	repo.EXPECT().GetUserTOTP(gomock.Any(), "hector").Times(2).Return(enabled, nil)
+	repo.EXPECT().UseRecoveryCode(gomock.Any(), "hector", gomock.Any()).Times(1).Return(dbrepo.ErrInvalidRecoveryCode)
+	repo.EXPECT().DisableTOTP(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	requireAppError(t, svc.DisableTOTP(context.Background(), payload, "abcde-fghij"), errorutil.ErrUnauthorized)
+
+	repo.EXPECT().UseRecoveryCode(gomock.Any(), "hector", gomock.Any()).Times(1).Return(nil)
+	repo.EXPECT().DisableTOTP(gomock.Any(), "hector", gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ string, event entity.AuditEvent) error {
+			require.Equal(t, audit.ActionTOTPDisable, event.Action)
+			require.Equal(t, audit.UserSubject("hector"), event.Subject)
+			return nil
+		})
+	require.NoError(t, svc.DisableTOTP(context.Background(), payload, "abcde-fghij"))
+}
+
+func TestLoginWithMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+
+	userRepo := mockdb.NewMockUserRepository(ctrl)
+	sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+	mfa := mockdb.NewMockMFAVerifier(ctrl)
+	cfg := config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour}
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    userRepo,
+		Token:    maker,
+		Config:   cfg,
+		Sessions: sessionRepo,
+		MFA:      mfa,
+		Logger:   &nopLogger,
+	})
+
+	//the password alone yields an mfa token, not a session
+	userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+	mfa.EXPECT().MFAEnabled(gomock.Any(), "hector").Times(1).Return(true, nil)
+	sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	challenge, err := usrSvc.Login(context.Background(), service.Logininput{Username: "hector", Password: "secret12345"})
+	require.NoError(t, err)
+	require.True(t, challenge.MFARequired)
+	require.NotEmpty(t, challenge.MFAToken)
+	require.Empty(t, challenge.AccessToken)
+	require.Empty(t, challenge.RefreshToken)
+
+	//the mfa token is not an access or refresh token
+	_, err = auth.WithSessionCheck(maker, usrSvc, nil).VerifyToken(challenge.MFAToken)
+	require.ErrorIs(t, err, auth.ErrInvalidToken)
+	_, err = usrSvc.RenewAccessToken(context.Background(), challenge.MFAToken)
+	requireAppError(t, err, errorutil.ErrUnauthorized)
+
+	//a wrong code creates no session
+	mfa.EXPECT().VerifyCode(gomock.Any(), "hector", "000000").Times(1).
+		Return(errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid authentication code", nil))
+	_, err = usrSvc.VerifyMFA(context.Background(), service.VerifyMFAInput{MFAToken: challenge.MFAToken, Code: "000000"})
+	requireAppError(t, err, errorutil.ErrUnauthorized)
+
+	spent := map[uuid.UUID]bool{}
+	sessionRepo.EXPECT().SpendMFAToken(gomock.Any(), gomock.Any(), "hector", gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, id uuid.UUID, _ string, _ time.Time) error {
+			if spent[id] {
+				return dbrepo.ErrMFATokenSpent
+			}
+			spent[id] = true
+			return nil
+		})
+	mfa.EXPECT().VerifyCode(gomock.Any(), "hector", "123456").Times(2).Return(nil)
+	userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+	sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, s entity.Session, _ entity.AuditEvent) (*entity.Session, error) {
+			return &s, nil
+		})
+	result, err := usrSvc.VerifyMFA(context.Background(), service.VerifyMFAInput{MFAToken: challenge.MFAToken, Code: "123456"})
+	require.NoError(t, err)
+	require.False(t, result.MFARequired)
+	require.NotEmpty(t, result.AccessToken)
+	require.NotEmpty(t, result.RefreshToken)
+	access, err := maker.VerifyToken(result.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, auth.RoleCustomer, access.Role)
+
+	//a used mfa token can't start a second session, even with a valid code
+	_, err = usrSvc.VerifyMFA(context.Background(), service.VerifyMFAInput{MFAToken: challenge.MFAToken, Code: "123456"})
+	requireAppError(t, err, errorutil.ErrUnauthorized)
+
+	//only mfa tokens can be exchanged
+	_, err = usrSvc.VerifyMFA(context.Background(), service.VerifyMFAInput{MFAToken: result.AccessToken, Code: "123456"})
+	requireAppError(t, err, errorutil.ErrUnauthorized)
+}
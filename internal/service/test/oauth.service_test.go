@@ -0,0 +1,1149 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const partnerCallback = "https://partner.example/callback"
+
+var oauthConfig = config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour}
+
+// oauthClient returns a registered partner and, unless it is public, its secret.
+func oauthClient(t *testing.T, public bool, grants ...string) (*entity.OAuthClient, string) {
+	id, err := auth.NewOAuthClientID()
+	require.NoError(t, err)
+	client := &entity.OAuthClient{
+		ID:           id,
+		Name:         "Budget App",
+		RedirectURIs: []string{partnerCallback},
+		Scopes:       []string{auth.ScopeAccountsRead, auth.ScopeTransfersWrite},
+		GrantTypes:   grants,
+	}
+	if public {
+		return client, ""
+	}
+	secret, err := auth.NewSecret()
+	require.NoError(t, err)
+	client.SecretHash = auth.HashSecret(secret)
+	return client, secret
+}
+
+// pkce returns a code verifier and its S256 challenge.
+func pkce() (verifier, challenge string) {
+	verifier = strings.Repeat("v", 20) + uuid.NewString()
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestCreateOAuthClient(t *testing.T) {
+	admin := &auth.Payload{Username: "root", Role: auth.RoleAdmin}
+	valid := service.CreateOAuthClientInput{
+		Name:         "Budget App",
+		RedirectURIs: []string{partnerCallback},
+		Scopes:       []string{auth.ScopeAccountsRead},
+		GrantTypes:   []string{service.GrantAuthorizationCode, service.GrantClientCredentials},
+	}
+
+	testCases := []struct {
+		name          string
+		payload       *auth.Payload
+		change        func(arg *service.CreateOAuthClientInput)
+		buildStubs    func(repo *mockdb.MockOAuthRepository)
+		checkResponse func(t *testing.T, client *service.RegisteredOAuthClient, err error)
+	}{
+		{
+			name:    "Confidential",
+			payload: admin,
+			change:  func(*service.CreateOAuthClientInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, c entity.OAuthClient, event entity.AuditEvent) (*entity.OAuthClient, error) {
+						require.Equal(t, "root", c.CreatedBy)
+						require.Equal(t, audit.ActionOAuthClientCreate, event.Action)
+						require.Equal(t, audit.OAuthClientSubject(c.ID), event.Subject)
+						return &c, nil
+					})
+			},
+			checkResponse: func(t *testing.T, client *service.RegisteredOAuthClient, err error) {
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(client.ID, auth.OAuthClientIDPrefix))
+				//only the hash is stored
+				require.NotEmpty(t, client.Secret)
+				require.Equal(t, auth.HashSecret(client.Secret), client.SecretHash)
+			},
+		}, {
+			name:    "Public loopback",
+			payload: admin,
+			change: func(arg *service.CreateOAuthClientInput) {
+				//loopback redirects are how native apps receive codes
+				arg.RedirectURIs = []string{"http://127.0.0.1:8765/callback"}
+				arg.GrantTypes = []string{service.GrantAuthorizationCode}
+				arg.Public = true
+			},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, c entity.OAuthClient, _ entity.AuditEvent) (*entity.OAuthClient, error) {
+						return &c, nil
+					})
+			},
+			checkResponse: func(t *testing.T, client *service.RegisteredOAuthClient, err error) {
+				require.NoError(t, err)
+				require.Empty(t, client.Secret)
+				require.True(t, client.Public())
+			},
+		}, {
+			name:    "Not an admin",
+			payload: &auth.Payload{Username: "hector", Role: auth.RoleCustomer},
+			change:  func(*service.CreateOAuthClientInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.RegisteredOAuthClient, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:    "Unknown scope",
+			payload: admin,
+			change:  func(arg *service.CreateOAuthClientInput) { arg.Scopes = []string{"users:manage"} },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.RegisteredOAuthClient, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "scopes")
+			},
+		}, {
+			name:    "Public client credentials",
+			payload: admin,
+			change:  func(arg *service.CreateOAuthClientInput) { arg.Public = true },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.RegisteredOAuthClient, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "grant_types")
+			},
+		}, {
+			name:    "Plain http redirect",
+			payload: admin,
+			change:  func(arg *service.CreateOAuthClientInput) { arg.RedirectURIs = []string{"http://partner.example/cb"} },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.RegisteredOAuthClient, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "redirect_uris")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			tc.buildStubs(repo)
+
+			arg := valid
+			tc.change(&arg)
+			svc := service.NewOAuthService(repo, mockdb.NewMockSessionRepository(ctrl), nil, oauthConfig, &nopLogger)
+			client, err := svc.CreateOAuthClient(context.Background(), tc.payload, arg)
+			tc.checkResponse(t, client, err)
+		})
+	}
+}
+
+func TestRevokeOAuthClient(t *testing.T) {
+	admin := &auth.Payload{Username: "root", Role: auth.RoleAdmin}
+
+	testCases := []struct {
+		name       string
+		payload    *auth.Payload
+		buildStubs func(repo *mockdb.MockOAuthRepository)
+		checkErr   func(t *testing.T, err error)
+	}{
+		{
+			name:    "OK",
+			payload: admin,
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				//the repository blocks the sessions users granted the client in the same transaction
+				repo.EXPECT().RevokeOAuthClient(gomock.Any(), "cl_partner", gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, _ string, event entity.AuditEvent) error {
+						require.Equal(t, audit.ActionOAuthClientRevoke, event.Action)
+						require.Equal(t, "root", event.Actor)
+						return nil
+					})
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		}, {
+			name:    "Already revoked",
+			payload: admin,
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().RevokeOAuthClient(gomock.Any(), "cl_partner", gomock.Any()).Times(1).Return(dbrepo.ErrOAuthClientNotFound)
+			},
+			checkErr: func(t *testing.T, err error) {
+				requireAppError(t, err, errorutil.ErrNotFound)
+			},
+		}, {
+			name:    "Not an admin",
+			payload: &auth.Payload{Username: "hector", Role: auth.RoleCustomer},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().RevokeOAuthClient(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			tc.buildStubs(repo)
+
+			svc := service.NewOAuthService(repo, mockdb.NewMockSessionRepository(ctrl), nil, oauthConfig, &nopLogger)
+			tc.checkErr(t, svc.RevokeOAuthClient(context.Background(), tc.payload, "cl_partner"))
+		})
+	}
+}
+
+func TestAuthorizeOAuthClient(t *testing.T) {
+	client, _ := oauthClient(t, false, service.GrantAuthorizationCode)
+	machine, _ := oauthClient(t, false, service.GrantClientCredentials)
+	revoked, _ := oauthClient(t, false, service.GrantAuthorizationCode)
+	revoked.RevokedAt = time.Now()
+	customer := &auth.Payload{Username: "hector", SessionID: uuid.NewString(), Role: auth.RoleCustomer}
+	_, challenge := pkce()
+	valid := service.AuthorizeOAuthClientInput{
+		ClientID:            client.ID,
+		Scopes:              []string{auth.ScopeAccountsRead},
+		State:               "xyz",
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: "S256",
+	}
+
+	testCases := []struct {
+		name          string
+		payload       *auth.Payload
+		change        func(arg *service.AuthorizeOAuthClientInput)
+		buildStubs    func(repo *mockdb.MockOAuthRepository)
+		checkResponse func(t *testing.T, authorization *service.OAuthAuthorization, err error)
+	}{
+		{
+			name:    "OK",
+			payload: customer,
+			change:  func(*service.AuthorizeOAuthClientInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, code entity.OAuthAuthorizationCode, event entity.AuditEvent) (*entity.OAuthConsent, error) {
+						require.Equal(t, "hector", code.Username)
+						require.Equal(t, client.ID, code.ClientID)
+						//the client's only uri is used, but the exchange need not repeat it
+						require.Equal(t, partnerCallback, code.RedirectURI)
+						require.False(t, code.RedirectURISupplied)
+						require.Equal(t, challenge, code.CodeChallenge)
+						require.Equal(t, audit.ActionOAuthConsentGrant, event.Action)
+						return &entity.OAuthConsent{Username: code.Username, ClientID: code.ClientID, Scopes: code.Scopes}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, authorization *service.OAuthAuthorization, err error) {
+				require.NoError(t, err)
+				back, err := url.Parse(authorization.RedirectURI)
+				require.NoError(t, err)
+				require.Equal(t, authorization.Code, back.Query().Get("code"))
+				require.Equal(t, "xyz", back.Query().Get("state"))
+			},
+		}, {
+			name:    "Redirect uri named",
+			payload: customer,
+			change:  func(arg *service.AuthorizeOAuthClientInput) { arg.RedirectURI = partnerCallback },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, code entity.OAuthAuthorizationCode, _ entity.AuditEvent) (*entity.OAuthConsent, error) {
+						require.True(t, code.RedirectURISupplied)
+						return &entity.OAuthConsent{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				require.NoError(t, err)
+			},
+		}, {
+			name:    "Unregistered redirect",
+			payload: customer,
+			change:  func(arg *service.AuthorizeOAuthClientInput) { arg.RedirectURI = "https://evil.example/cb" },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:    "Scope not allowed",
+			payload: customer,
+			change:  func(arg *service.AuthorizeOAuthClientInput) { arg.Scopes = []string{"users:manage"} },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:    "Unknown client",
+			payload: customer,
+			change:  func(arg *service.AuthorizeOAuthClientInput) { arg.ClientID = "cl_unknown" },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), "cl_unknown").Times(1).Return(nil, dbrepo.ErrOAuthClientNotFound)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:    "Revoked client",
+			payload: customer,
+			change:  func(arg *service.AuthorizeOAuthClientInput) { arg.ClientID = revoked.ID },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), revoked.ID).Times(1).Return(revoked, nil)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:    "No code grant",
+			payload: customer,
+			change:  func(arg *service.AuthorizeOAuthClientInput) { arg.ClientID = machine.ID },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), machine.ID).Times(1).Return(machine, nil)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:    "Plain challenge",
+			payload: customer,
+			change:  func(arg *service.AuthorizeOAuthClientInput) { arg.CodeChallengeMethod = "plain" },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "code_challenge_method")
+			},
+		}, {
+			name:    "By another client",
+			payload: &auth.Payload{Username: "hector", ClientID: client.ID, Scope: auth.ScopeAccountsRead},
+			change:  func(*service.AuthorizeOAuthClientInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		}, {
+			name:    "By service account",
+			payload: &auth.Payload{Username: auth.ServiceAccountPrefix + "recon", APIKey: &auth.APIKeyGrant{}},
+			change:  func(*service.AuthorizeOAuthClientInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().GrantOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthAuthorization, err error) {
+				requireAppError(t, err, errorutil.ErrForbidden)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			tc.buildStubs(repo)
+
+			arg := valid
+			tc.change(&arg)
+			svc := service.NewOAuthService(repo, mockdb.NewMockSessionRepository(ctrl), nil, oauthConfig, &nopLogger)
+			authorization, err := svc.AuthorizeOAuthClient(context.Background(), tc.payload, arg)
+			tc.checkResponse(t, authorization, err)
+		})
+	}
+}
+
+func TestOAuthCodeExchange(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	client, secret := oauthClient(t, false, service.GrantAuthorizationCode)
+	machine, machineSecret := oauthClient(t, false, service.GrantClientCredentials)
+	const code = "emailed-code"
+	verifier, challenge := pkce()
+	issued := entity.OAuthAuthorizationCode{
+		CodeHash:      auth.HashSecret(code),
+		ClientID:      client.ID,
+		Username:      "hector",
+		RedirectURI:   partnerCallback,
+		Scopes:        []string{auth.ScopeAccountsRead},
+		CodeChallenge: challenge,
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+	valid := service.OAuthTokenInput{
+		GrantType:    service.GrantAuthorizationCode,
+		ClientID:     client.ID,
+		ClientSecret: secret,
+		Code:         code,
+		RedirectURI:  partnerCallback,
+		CodeVerifier: verifier,
+	}
+	//a rejected code never reaches the consent check or starts a session
+	rejected := func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+		repo.EXPECT().GetOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	}
+
+	testCases := []struct {
+		name          string
+		change        func(arg *service.OAuthTokenInput)
+		buildStubs    func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository)
+		checkResponse func(t *testing.T, token *service.OAuthToken, err error)
+	}{
+		{
+			name:   "OK",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(&issued, nil)
+				repo.EXPECT().GetOAuthConsent(gomock.Any(), "hector", client.ID).Times(1).Return(&entity.OAuthConsent{}, nil)
+				sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, s entity.Session, event entity.AuditEvent) (*entity.Session, error) {
+						require.Equal(t, "hector", s.Username)
+						require.Equal(t, client.ID, s.ClientID)
+						require.Equal(t, audit.ActionLogin, event.Action)
+						require.Equal(t, client.ID, event.Details["client_id"])
+						return &s, nil
+					})
+			},
+			checkResponse: func(t *testing.T, token *service.OAuthToken, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []string{auth.ScopeAccountsRead}, token.Scopes)
+				require.NotEmpty(t, token.RefreshToken)
+
+				payload, err := maker.VerifyToken(token.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, "hector", payload.Username)
+				require.Equal(t, client.ID, payload.ClientID)
+				require.Empty(t, payload.Role)
+				require.True(t, payload.AllowsScope("GET /accounts/:id"))
+				require.False(t, payload.AllowsScope("POST /transfer"))
+			},
+		}, {
+			name: "Redirect uri left out at both ends",
+			change: func(arg *service.OAuthTokenInput) {
+				arg.RedirectURI = ""
+			},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(&issued, nil)
+				repo.EXPECT().GetOAuthConsent(gomock.Any(), "hector", client.ID).Times(1).Return(&entity.OAuthConsent{}, nil)
+				sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, s entity.Session, _ entity.AuditEvent) (*entity.Session, error) {
+						return &s, nil
+					})
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				require.NoError(t, err)
+			},
+		}, {
+			name: "Redirect uri named at authorization left out",
+			change: func(arg *service.OAuthTokenInput) {
+				arg.RedirectURI = ""
+			},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				supplied := issued
+				supplied.RedirectURISupplied = true
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(&supplied, nil)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "Wrong redirect uri",
+			change: func(arg *service.OAuthTokenInput) { arg.RedirectURI = "https://evil.example/cb" },
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(&issued, nil)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name: "Wrong verifier",
+			change: func(arg *service.OAuthTokenInput) {
+				arg.CodeVerifier, _ = pkce()
+			},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(&issued, nil)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "No verifier",
+			change: func(arg *service.OAuthTokenInput) { arg.CodeVerifier = "" },
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(&issued, nil)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "Code used already",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(nil, dbrepo.ErrInvalidAuthorizationCode)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "Code of another client",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				other := issued
+				other.ClientID = "cl_other"
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(&other, nil)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			//the user may revoke consent in the minute a code is valid
+			name:   "Consent revoked",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), auth.HashSecret(code)).Times(1).Return(&issued, nil)
+				repo.EXPECT().GetOAuthConsent(gomock.Any(), "hector", client.ID).Times(1).Return(nil, dbrepo.ErrOAuthConsentNotFound)
+				sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "Wrong secret",
+			change: func(arg *service.OAuthTokenInput) { arg.ClientSecret = "guess" },
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(0)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name: "Client without the grant",
+			change: func(arg *service.OAuthTokenInput) {
+				arg.ClientID, arg.ClientSecret = machine.ID, machineSecret
+			},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), machine.ID).Times(1).Return(machine, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(0)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "Unknown grant",
+			change: func(arg *service.OAuthTokenInput) { arg.GrantType = "password" },
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(0)
+				rejected(repo, sessions)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			sessions := mockdb.NewMockSessionRepository(ctrl)
+			tc.buildStubs(repo, sessions)
+
+			arg := valid
+			tc.change(&arg)
+			svc := service.NewOAuthService(repo, sessions, maker, oauthConfig, &nopLogger)
+			token, err := svc.Token(context.Background(), arg)
+			tc.checkResponse(t, token, err)
+		})
+	}
+}
+
+func TestOAuthRefreshToken(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	client, _ := oauthClient(t, true, service.GrantAuthorizationCode)
+	scopes := []string{auth.ScopeAccountsRead, auth.ScopeTransfersWrite}
+	refreshToken, refreshPayload, err := maker.GenerateToken("hector", time.Hour, auth.WithClient(client.ID, scopes))
+	require.NoError(t, err)
+	familyID := uuid.New()
+	session := entity.Session{
+		ID:           uuid.MustParse(refreshPayload.ID),
+		Username:     "hector",
+		RefreshToken: refreshToken,
+		ExpiresAt:    refreshPayload.ExpiresAt.Time,
+		FamilyID:     familyID,
+		ClientID:     client.ID,
+	}
+	valid := service.OAuthTokenInput{
+		GrantType:    service.GrantRefreshToken,
+		ClientID:     client.ID,
+		RefreshToken: refreshToken,
+	}
+
+	testCases := []struct {
+		name          string
+		change        func(arg *service.OAuthTokenInput)
+		buildStubs    func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository)
+		checkResponse func(t *testing.T, token *service.OAuthToken, err error)
+	}{
+		{
+			name:   "Narrowed scopes",
+			change: func(arg *service.OAuthTokenInput) { arg.Scopes = []string{auth.ScopeAccountsRead} },
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				sessions.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(&session, nil)
+				sessions.EXPECT().RotateSession(gomock.Any(), session.ID, gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, next entity.Session) (*entity.Session, error) {
+						require.Equal(t, familyID, next.FamilyID)
+						require.Equal(t, session.ID, next.ParentID)
+						require.Equal(t, client.ID, next.ClientID)
+						return &next, nil
+					})
+				sessions.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, token *service.OAuthToken, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []string{auth.ScopeAccountsRead}, token.Scopes)
+				payload, err := maker.VerifyToken(token.AccessToken)
+				require.NoError(t, err)
+				require.False(t, payload.AllowsScope("POST /transfer"))
+			},
+		}, {
+			name:   "Widened scopes",
+			change: func(arg *service.OAuthTokenInput) { arg.Scopes = []string{"users:manage"} },
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				sessions.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+				sessions.EXPECT().RotateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			//presenting a rotated refresh token again blocks every session of the login
+			name:   "Rotated token",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				rotated := session
+				rotated.RotatedAt = time.Now()
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				sessions.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(&rotated, nil)
+				sessions.EXPECT().RotateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				sessions.EXPECT().BlockSessionFamily(gomock.Any(), familyID).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "Rotated concurrently",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				sessions.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(&session, nil)
+				sessions.EXPECT().RotateSession(gomock.Any(), session.ID, gomock.Any()).Times(1).Return(nil, dbrepo.ErrSessionReused)
+				sessions.EXPECT().BlockSessionFamily(gomock.Any(), familyID).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "Blocked session",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				blocked := session
+				blocked.IsBlocked = true
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				sessions.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(&blocked, nil)
+				sessions.EXPECT().RotateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				sessions.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name: "User's own refresh token",
+			change: func(arg *service.OAuthTokenInput) {
+				own, _, err := maker.GenerateToken("hector", time.Hour)
+				require.NoError(t, err)
+				arg.RefreshToken = own
+			},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				sessions.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			sessions := mockdb.NewMockSessionRepository(ctrl)
+			tc.buildStubs(repo, sessions)
+
+			arg := valid
+			tc.change(&arg)
+			svc := service.NewOAuthService(repo, sessions, maker, oauthConfig, &nopLogger)
+			token, err := svc.Token(context.Background(), arg)
+			tc.checkResponse(t, token, err)
+		})
+	}
+
+	t.Run("Refused by the user's renewal", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		//renewing it as a login would drop the client's scopes
+		sessions := mockdb.NewMockSessionRepository(ctrl)
+		sessions.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+		usrSvc := service.NewUserService(service.UserServiceDeps{
+			Users:    mockdb.NewMockUserRepository(ctrl),
+			Token:    maker,
+			Sessions: sessions,
+			Logger:   &nopLogger,
+		})
+		_, err := usrSvc.RenewAccessToken(context.Background(), refreshToken)
+		requireAppError(t, err, errorutil.ErrUnauthorized)
+	})
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	client, secret := oauthClient(t, false, service.GrantClientCredentials)
+	public, _ := oauthClient(t, true, service.GrantAuthorizationCode)
+	valid := service.OAuthTokenInput{
+		GrantType:    service.GrantClientCredentials,
+		ClientID:     client.ID,
+		ClientSecret: secret,
+		Scopes:       []string{auth.ScopeAccountsRead},
+	}
+
+	testCases := []struct {
+		name          string
+		change        func(arg *service.OAuthTokenInput)
+		buildStubs    func(repo *mockdb.MockOAuthRepository)
+		checkResponse func(t *testing.T, token *service.OAuthToken, err error)
+	}{
+		{
+			name:   "OK",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().CreateOAuthClientToken(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, token entity.OAuthClientToken) error {
+						require.Equal(t, client.ID, token.ClientID)
+						require.Equal(t, []string{auth.ScopeAccountsRead}, token.Scopes)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, token *service.OAuthToken, err error) {
+				require.NoError(t, err)
+				require.Empty(t, token.RefreshToken)
+				payload, err := maker.VerifyToken(token.AccessToken)
+				require.NoError(t, err)
+				require.True(t, payload.IsClientToken())
+			},
+		}, {
+			name:   "Scope not registered",
+			change: func(arg *service.OAuthTokenInput) { arg.Scopes = []string{"users:manage"} },
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().CreateOAuthClientToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name: "Public client",
+			change: func(arg *service.OAuthTokenInput) {
+				arg.ClientID, arg.ClientSecret = public.ID, ""
+			},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Times(1).Return(public, nil)
+				repo.EXPECT().CreateOAuthClientToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:   "Revoked client",
+			change: func(*service.OAuthTokenInput) {},
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				revoked := *client
+				revoked.RevokedAt = time.Now()
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(&revoked, nil)
+				repo.EXPECT().CreateOAuthClientToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, _ *service.OAuthToken, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			tc.buildStubs(repo)
+
+			arg := valid
+			tc.change(&arg)
+			svc := service.NewOAuthService(repo, mockdb.NewMockSessionRepository(ctrl), maker, oauthConfig, &nopLogger)
+			token, err := svc.Token(context.Background(), arg)
+			tc.checkResponse(t, token, err)
+		})
+	}
+}
+
+func TestIntrospectAndRevokeOAuthToken(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	client, secret := oauthClient(t, false, service.GrantAuthorizationCode, service.GrantClientCredentials)
+	other, otherSecret := oauthClient(t, false, service.GrantClientCredentials)
+
+	clientToken, clientPayload, err := maker.GenerateToken("", time.Minute, auth.WithClient(client.ID, []string{auth.ScopeAccountsRead}))
+	require.NoError(t, err)
+	stored := entity.OAuthClientToken{ID: uuid.MustParse(clientPayload.ID), ClientID: client.ID, Scopes: []string{auth.ScopeAccountsRead}}
+	session := entity.Session{ID: uuid.New(), Username: "hector", FamilyID: uuid.New(), ClientID: client.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	userToken, _, err := maker.GenerateToken("hector", time.Minute, auth.WithSessionID(session.ID), auth.WithClient(client.ID, []string{auth.ScopeAccountsRead}))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		request       service.OAuthTokenRequest
+		buildStubs    func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository)
+		checkResponse func(t *testing.T, svc *service.OAuthService, request service.OAuthTokenRequest)
+	}{
+		{
+			name:    "Client token",
+			request: service.OAuthTokenRequest{ClientID: client.ID, ClientSecret: secret, Token: clientToken},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, _ *mockdb.MockSessionRepository) {
+				//introspection authenticates the caller and checks the token's client, revocation only authenticates
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(3).Return(client, nil)
+				repo.EXPECT().GetOAuthClientToken(gomock.Any(), stored.ID).Times(1).Return(&stored, nil)
+				repo.EXPECT().RevokeOAuthClientToken(gomock.Any(), stored.ID, client.ID).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, request service.OAuthTokenRequest) {
+				introspection, err := svc.IntrospectToken(context.Background(), request)
+				require.NoError(t, err)
+				require.True(t, introspection.Active)
+				require.Equal(t, []string{auth.ScopeAccountsRead}, introspection.Scopes)
+				require.NoError(t, svc.RevokeToken(context.Background(), request))
+			},
+		}, {
+			name:    "Revoked client token",
+			request: service.OAuthTokenRequest{ClientID: client.ID, ClientSecret: secret, Token: clientToken},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, _ *mockdb.MockSessionRepository) {
+				revoked := stored
+				revoked.RevokedAt = time.Now()
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				repo.EXPECT().GetOAuthClientToken(gomock.Any(), stored.ID).Times(1).Return(&revoked, nil)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, request service.OAuthTokenRequest) {
+				introspection, err := svc.IntrospectToken(context.Background(), request)
+				require.NoError(t, err)
+				require.False(t, introspection.Active)
+			},
+		}, {
+			//another client can neither see nor revoke the token, and isn't told it exists
+			name:    "Another client's token",
+			request: service.OAuthTokenRequest{ClientID: other.ID, ClientSecret: otherSecret, Token: clientToken},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), other.ID).Times(2).Return(other, nil)
+				repo.EXPECT().GetOAuthClientToken(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().RevokeOAuthClientToken(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				sessions.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, request service.OAuthTokenRequest) {
+				introspection, err := svc.IntrospectToken(context.Background(), request)
+				require.NoError(t, err)
+				require.False(t, introspection.Active)
+				require.NoError(t, svc.RevokeToken(context.Background(), request))
+			},
+		}, {
+			name:    "User's token",
+			request: service.OAuthTokenRequest{ClientID: client.ID, ClientSecret: secret, Token: userToken},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(2).Return(client, nil)
+				sessions.EXPECT().GetSession(gomock.Any(), session.ID).Times(2).Return(&session, nil)
+				//revoking a user's token ends the whole session
+				sessions.EXPECT().BlockSessionFamily(gomock.Any(), session.FamilyID).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, request service.OAuthTokenRequest) {
+				introspection, err := svc.IntrospectToken(context.Background(), request)
+				require.NoError(t, err)
+				require.True(t, introspection.Active)
+				require.Equal(t, "hector", introspection.Username)
+				require.Equal(t, "access_token", introspection.TokenType)
+				require.NoError(t, svc.RevokeToken(context.Background(), request))
+			},
+		}, {
+			name:    "Blocked session",
+			request: service.OAuthTokenRequest{ClientID: client.ID, ClientSecret: secret, Token: userToken},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, sessions *mockdb.MockSessionRepository) {
+				blocked := session
+				blocked.IsBlocked = true
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				sessions.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(&blocked, nil)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, request service.OAuthTokenRequest) {
+				introspection, err := svc.IntrospectToken(context.Background(), request)
+				require.NoError(t, err)
+				require.False(t, introspection.Active)
+			},
+		}, {
+			name:    "Wrong secret",
+			request: service.OAuthTokenRequest{ClientID: client.ID, ClientSecret: "guess", Token: clientToken},
+			buildStubs: func(repo *mockdb.MockOAuthRepository, _ *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(2).Return(client, nil)
+				repo.EXPECT().GetOAuthClientToken(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().RevokeOAuthClientToken(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, request service.OAuthTokenRequest) {
+				_, err := svc.IntrospectToken(context.Background(), request)
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+				requireAppError(t, svc.RevokeToken(context.Background(), request), errorutil.ErrUnauthorized)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			sessions := mockdb.NewMockSessionRepository(ctrl)
+			tc.buildStubs(repo, sessions)
+
+			tc.checkResponse(t, service.NewOAuthService(repo, sessions, maker, oauthConfig, &nopLogger), tc.request)
+		})
+	}
+}
+
+func TestCheckClientToken(t *testing.T) {
+	client, _ := oauthClient(t, false, service.GrantClientCredentials)
+	payload := &auth.Payload{RegisteredClaims: jwt.RegisteredClaims{ID: uuid.NewString()}, ClientID: client.ID}
+	stored := entity.OAuthClientToken{ID: uuid.MustParse(payload.ID), ClientID: client.ID}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(repo *mockdb.MockOAuthRepository)
+		checkErr   func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClientToken(gomock.Any(), stored.ID).Times(1).Return(&stored, nil)
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		}, {
+			//revoking a client ends the tokens it got for itself
+			name: "Client revoked",
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				revoked := *client
+				revoked.RevokedAt = time.Now()
+				repo.EXPECT().GetOAuthClientToken(gomock.Any(), stored.ID).Times(1).Return(&stored, nil)
+				repo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(&revoked, nil)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.Error(t, err)
+			},
+		}, {
+			name: "Token revoked",
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				revoked := stored
+				revoked.RevokedAt = time.Now()
+				repo.EXPECT().GetOAuthClientToken(gomock.Any(), stored.ID).Times(1).Return(&revoked, nil)
+				repo.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.Error(t, err)
+			},
+		}, {
+			name: "Lookup fails",
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().GetOAuthClientToken(gomock.Any(), stored.ID).Times(1).Return(nil, sql.ErrConnDone)
+				repo.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			tc.buildStubs(repo)
+
+			svc := service.NewOAuthService(repo, mockdb.NewMockSessionRepository(ctrl), nil, oauthConfig, &nopLogger)
+			tc.checkErr(t, svc.CheckClientToken(context.Background(), payload))
+		})
+	}
+}
+
+func TestOAuthConsents(t *testing.T) {
+	customer := &auth.Payload{Username: "hector", SessionID: uuid.NewString(), Role: auth.RoleCustomer}
+	//the partner can't manage consents with the token it was given
+	partner := &auth.Payload{Username: "hector", ClientID: "cl_partner", Scope: auth.ScopeAccountsRead}
+
+	testCases := []struct {
+		name          string
+		payload       *auth.Payload
+		buildStubs    func(repo *mockdb.MockOAuthRepository)
+		checkResponse func(t *testing.T, svc *service.OAuthService, payload *auth.Payload)
+	}{
+		{
+			name:    "List",
+			payload: customer,
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().ListOAuthConsents(gomock.Any(), "hector").Times(1).
+					Return([]*entity.OAuthConsent{{Username: "hector", ClientID: "cl_partner"}}, nil)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, payload *auth.Payload) {
+				consents, err := svc.ListOAuthConsents(context.Background(), payload)
+				require.NoError(t, err)
+				require.Len(t, consents, 1)
+			},
+		}, {
+			name:    "Revoke",
+			payload: customer,
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				//the repository ends the client's sessions and account consents in the same transaction
+				repo.EXPECT().RevokeOAuthConsent(gomock.Any(), "hector", "cl_partner", gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, _, _ string, event entity.AuditEvent) error {
+						require.Equal(t, audit.ActionOAuthConsentRevoke, event.Action)
+						require.Equal(t, audit.OAuthClientSubject("cl_partner"), event.Subject)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, payload *auth.Payload) {
+				require.NoError(t, svc.RevokeOAuthConsent(context.Background(), payload, "cl_partner"))
+			},
+		}, {
+			name:    "Revoke twice",
+			payload: customer,
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().RevokeOAuthConsent(gomock.Any(), "hector", "cl_partner", gomock.Any()).Times(1).Return(dbrepo.ErrOAuthConsentNotFound)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, payload *auth.Payload) {
+				requireAppError(t, svc.RevokeOAuthConsent(context.Background(), payload, "cl_partner"), errorutil.ErrNotFound)
+			},
+		}, {
+			name:    "By the partner",
+			payload: partner,
+			buildStubs: func(repo *mockdb.MockOAuthRepository) {
+				repo.EXPECT().ListOAuthConsents(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().RevokeOAuthConsent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, svc *service.OAuthService, payload *auth.Payload) {
+				_, err := svc.ListOAuthConsents(context.Background(), payload)
+				requireAppError(t, err, errorutil.ErrForbidden)
+				requireAppError(t, svc.RevokeOAuthConsent(context.Background(), payload, "cl_partner"), errorutil.ErrForbidden)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockOAuthRepository(ctrl)
+			tc.buildStubs(repo)
+
+			tc.checkResponse(t, service.NewOAuthService(repo, mockdb.NewMockSessionRepository(ctrl), nil, oauthConfig, &nopLogger), tc.payload)
+		})
+	}
+}
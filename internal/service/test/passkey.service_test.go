@@ -0,0 +1,516 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testRPID     = "bank.example"
+	testRPOrigin = "https://bank.example"
+)
+
+var b64 = base64.RawURLEncoding
+
+// softAuthenticator stands in for a platform authenticator holding a single ES256 passkey.
+// It makes "none" attestations and always reports the user present and verified.
+type softAuthenticator struct {
+	origin       string
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+	return &softAuthenticator{origin: testRPOrigin, key: key, credentialID: credentialID}
+}
+
+func (a *softAuthenticator) authData(rpID string, flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, kind, challenge string) []byte {
+	data, err := json.Marshal(map[string]any{"type": kind, "challenge": challenge, "origin": a.origin})
+	require.NoError(t, err)
+	return data
+}
+
+// publicKey is the passkey's public key in COSE form, as it is stored on registration.
+func (a *softAuthenticator) publicKey(t *testing.T) []byte {
+	publicKey, err := cbor.Marshal(map[int]any{
+		1:  2,  //kty: EC2
+		3:  -7, //alg: ES256
+		-1: 1,  //crv: P-256
+		-2: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		-3: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+	return publicKey
+}
+
+// credential is the passkey as the repository holds it once registered.
+func (a *softAuthenticator) credential(t *testing.T) *entity.WebAuthnCredential {
+	return &entity.WebAuthnCredential{
+		ID:              1,
+		Username:        "hector",
+		CredentialID:    a.credentialID,
+		PublicKey:       a.publicKey(t),
+		AttestationType: "none",
+		SignCount:       a.signCount,
+	}
+}
+
+// create answers navigator.credentials.create options with a PublicKeyCredential as JSON.
+func (a *softAuthenticator) create(t *testing.T, options json.RawMessage) string {
+	var creation struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+			RP        struct {
+				ID string `json:"id"`
+			} `json:"rp"`
+			User struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"publicKey"`
+	}
+	require.NoError(t, json.Unmarshal(options, &creation))
+	handle, err := b64.DecodeString(creation.PublicKey.User.ID)
+	require.NoError(t, err)
+	a.userHandle = handle
+
+	attested := make([]byte, 16) //zero aaguid
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, a.publicKey(t)...)
+
+	//user present, user verified, attested credential data included
+	authData := a.authData(creation.PublicKey.RP.ID, 0x01|0x04|0x40, attested)
+	attestation, err := cbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	require.NoError(t, err)
+
+	credential, err := json.Marshal(map[string]any{
+		"id":    b64.EncodeToString(a.credentialID),
+		"rawId": b64.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]any{
+			"clientDataJSON":    b64.EncodeToString(a.clientData(t, "webauthn.create", creation.PublicKey.Challenge)),
+			"attestationObject": b64.EncodeToString(attestation),
+			"transports":        []string{"internal"},
+		},
+	})
+	require.NoError(t, err)
+	return string(credential)
+}
+
+// get answers navigator.credentials.get options with a PublicKeyCredential as JSON.
+func (a *softAuthenticator) get(t *testing.T, options json.RawMessage) string {
+	var assertion struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+			RPID      string `json:"rpId"`
+		} `json:"publicKey"`
+	}
+	require.NoError(t, json.Unmarshal(options, &assertion))
+
+	a.signCount++
+	authData := a.authData(assertion.PublicKey.RPID, 0x01|0x04, nil)
+	clientData := a.clientData(t, "webauthn.get", assertion.PublicKey.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(bytes.Clone(authData), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	credential, err := json.Marshal(map[string]any{
+		"id":    b64.EncodeToString(a.credentialID),
+		"rawId": b64.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]any{
+			"clientDataJSON":    b64.EncodeToString(clientData),
+			"authenticatorData": b64.EncodeToString(authData),
+			"signature":         b64.EncodeToString(signature),
+			"userHandle":        b64.EncodeToString(a.userHandle),
+		},
+	})
+	require.NoError(t, err)
+	return string(credential)
+}
+
+func TestPasskeyRegistration(t *testing.T) {
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+	handle := []byte("hector's user handle")
+	payload := &auth.Payload{Username: "hector"}
+	authenticator := newSoftAuthenticator(t)
+	//loading the user's passkeys again to verify the attestation
+	reloaded := func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository) {
+		users.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+		repo.EXPECT().SaveWebAuthnUser(gomock.Any(), "hector", gomock.Any()).Times(1).Return(handle, nil)
+		repo.EXPECT().ListWebAuthnCredentials(gomock.Any(), "hector").Times(1).Return(nil, nil)
+	}
+
+	testCases := []struct {
+		name       string
+		payload    *auth.Payload
+		credential func(t *testing.T, options json.RawMessage) string
+		buildStubs func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, ceremony *entity.WebAuthnCeremony)
+		checkErr   func(t *testing.T, credential *entity.WebAuthnCredential, err error)
+	}{
+		{
+			name:       "OK",
+			payload:    payload,
+			credential: authenticator.create,
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, ceremony *entity.WebAuthnCeremony) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "registration").Times(1).Return(ceremony, nil)
+				reloaded(repo, users)
+				repo.EXPECT().CreateWebAuthnCredential(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, c entity.WebAuthnCredential, event entity.AuditEvent) (*entity.WebAuthnCredential, error) {
+						require.Equal(t, "hector", c.Username)
+						require.Equal(t, authenticator.credentialID, c.CredentialID)
+						require.Equal(t, "none", c.AttestationType)
+						require.Equal(t, []string{"internal"}, c.Transports)
+						require.Equal(t, audit.ActionPasskeyCreate, event.Action)
+						c.ID = 1
+						return &c, nil
+					})
+			},
+			checkErr: func(t *testing.T, credential *entity.WebAuthnCredential, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "laptop", credential.Name)
+			},
+		}, {
+			name:       "Ceremony of another user",
+			payload:    &auth.Payload{Username: "mallory"},
+			credential: newSoftAuthenticator(t).create,
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, _ *mockdb.MockUserRepository, ceremony *entity.WebAuthnCeremony) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "registration").Times(1).Return(ceremony, nil)
+				repo.EXPECT().CreateWebAuthnCredential(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.WebAuthnCredential, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:       "Ceremony expired",
+			payload:    payload,
+			credential: authenticator.create,
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, _ *mockdb.MockUserRepository, ceremony *entity.WebAuthnCeremony) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "registration").Times(1).Return(nil, dbrepo.ErrInvalidWebAuthnCeremony)
+				repo.EXPECT().CreateWebAuthnCredential(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.WebAuthnCredential, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:    "Wrong origin",
+			payload: payload,
+			credential: func(t *testing.T, options json.RawMessage) string {
+				phished := newSoftAuthenticator(t)
+				phished.origin = "https://bank.example.evil"
+				return phished.create(t, options)
+			},
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, ceremony *entity.WebAuthnCeremony) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "registration").Times(1).Return(ceremony, nil)
+				reloaded(repo, users)
+				repo.EXPECT().CreateWebAuthnCredential(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, _ *entity.WebAuthnCredential, err error) {
+				requireAppError(t, err, errorutil.ErrBadRequest)
+			},
+		}, {
+			name:       "Already registered",
+			payload:    payload,
+			credential: authenticator.create,
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, ceremony *entity.WebAuthnCeremony) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "registration").Times(1).Return(ceremony, nil)
+				reloaded(repo, users)
+				repo.EXPECT().CreateWebAuthnCredential(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, dbrepo.ErrDuplicateWebAuthnCredential)
+			},
+			checkErr: func(t *testing.T, _ *entity.WebAuthnCredential, err error) {
+				requireAppError(t, err, errorutil.ErrConflict)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockPasskeyRepository(ctrl)
+			users := mockdb.NewMockUserRepository(ctrl)
+			passkeys, err := service.NewPasskeys(repo, service.RelyingParty{ID: testRPID, Name: "Bank"})
+			require.NoError(t, err)
+			usrSvc := service.NewUserService(service.UserServiceDeps{Users: users, Passkeys: passkeys, Logger: &nopLogger})
+
+			var stored entity.WebAuthnCeremony
+			users.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+			repo.EXPECT().SaveWebAuthnUser(gomock.Any(), "hector", gomock.Any()).Times(1).Return(handle, nil)
+			repo.EXPECT().ListWebAuthnCredentials(gomock.Any(), "hector").Times(1).Return(nil, nil)
+			repo.EXPECT().CreateWebAuthnCeremony(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ context.Context, c entity.WebAuthnCeremony) error {
+					require.Equal(t, "hector", c.Username)
+					stored = c
+					return nil
+				})
+			ceremony, err := usrSvc.BeginPasskeyRegistration(context.Background(), payload)
+			require.NoError(t, err)
+			var options struct {
+				PublicKey struct {
+					AuthenticatorSelection struct {
+						ResidentKey      string `json:"residentKey"`
+						UserVerification string `json:"userVerification"`
+					} `json:"authenticatorSelection"`
+				} `json:"publicKey"`
+			}
+			require.NoError(t, json.Unmarshal(ceremony.Options, &options))
+			require.Equal(t, "required", options.PublicKey.AuthenticatorSelection.ResidentKey)
+			require.Equal(t, "required", options.PublicKey.AuthenticatorSelection.UserVerification)
+
+			tc.buildStubs(repo, users, &stored)
+			credential, err := usrSvc.FinishPasskeyRegistration(context.Background(), tc.payload, service.FinishPasskeyRegistrationInput{
+				CeremonyID: ceremony.ID.String(),
+				Name:       "laptop",
+				Credential: tc.credential(t, ceremony.Options),
+			})
+			tc.checkErr(t, credential, err)
+		})
+	}
+
+	t.Run("Invalid input", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := mockdb.NewMockPasskeyRepository(ctrl)
+		passkeys, err := service.NewPasskeys(repo, service.RelyingParty{ID: testRPID, Name: "Bank"})
+		require.NoError(t, err)
+		usrSvc := service.NewUserService(service.UserServiceDeps{Users: mockdb.NewMockUserRepository(ctrl), Passkeys: passkeys, Logger: &nopLogger})
+
+		repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		_, err = usrSvc.FinishPasskeyRegistration(context.Background(), payload, service.FinishPasskeyRegistrationInput{
+			CeremonyID: "not-a-uuid",
+		})
+		require.Error(t, err)
+		_, ok := err.(*errorutil.AppError)
+		require.False(t, ok)
+	})
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+	handle := []byte("hector's user handle")
+	//resolving the passkey's user handle to hector and his registered passkey
+	lookedUp := func(repo *mockdb.MockPasskeyRepository, registered *entity.WebAuthnCredential) {
+		repo.EXPECT().GetWebAuthnUsername(gomock.Any(), handle).Times(1).Return("hector", nil)
+		repo.EXPECT().ListWebAuthnCredentials(gomock.Any(), "hector").Times(1).Return([]*entity.WebAuthnCredential{registered}, nil)
+	}
+	//nothing is signed in when the passkey is refused
+	refused := func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, sessions *mockdb.MockSessionRepository) {
+		repo.EXPECT().UseWebAuthnCredential(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		users.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+		sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	}
+	failedLogin := func(auditRepo *mockdb.MockAuditRepository, reason string) {
+		auditRepo.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, event entity.AuditEvent) error {
+				require.Equal(t, audit.ActionLoginFailed, event.Action)
+				require.Equal(t, "hector", event.Actor)
+				require.Equal(t, reason, event.Details["reason"])
+				return nil
+			})
+	}
+
+	testCases := []struct {
+		name       string
+		credential func(t *testing.T, authenticator *softAuthenticator, options json.RawMessage) string
+		buildStubs func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, sessions *mockdb.MockSessionRepository, auditRepo *mockdb.MockAuditRepository, ceremony *entity.WebAuthnCeremony, registered *entity.WebAuthnCredential)
+		check      func(t *testing.T, result *service.AuthResult, err error)
+	}{
+		{
+			name: "OK",
+			credential: func(t *testing.T, authenticator *softAuthenticator, options json.RawMessage) string {
+				return authenticator.get(t, options)
+			},
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, sessions *mockdb.MockSessionRepository, auditRepo *mockdb.MockAuditRepository, ceremony *entity.WebAuthnCeremony, registered *entity.WebAuthnCredential) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "login").Times(1).Return(ceremony, nil)
+				lookedUp(repo, registered)
+				repo.EXPECT().UseWebAuthnCredential(gomock.Any(), registered.CredentialID, registered.SignCount+1, false).Times(1).Return(nil)
+				users.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+				sessions.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, s entity.Session, _ entity.AuditEvent) (*entity.Session, error) {
+						return &s, nil
+					})
+				auditRepo.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result *service.AuthResult, err error) {
+				require.NoError(t, err)
+				require.False(t, result.MFARequired)
+				payload, err := maker.VerifyToken(result.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, "hector", payload.Username)
+				//the passkey verified the user, so it counts as two factors
+				require.Equal(t, auth.AssuranceMFA, payload.Assurance)
+			},
+		}, {
+			name: "Ceremony already answered",
+			credential: func(t *testing.T, authenticator *softAuthenticator, options json.RawMessage) string {
+				return authenticator.get(t, options)
+			},
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, sessions *mockdb.MockSessionRepository, _ *mockdb.MockAuditRepository, ceremony *entity.WebAuthnCeremony, _ *entity.WebAuthnCredential) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "login").Times(1).Return(nil, dbrepo.ErrInvalidWebAuthnCeremony)
+				repo.EXPECT().GetWebAuthnUsername(gomock.Any(), gomock.Any()).Times(0)
+				refused(repo, users, sessions)
+			},
+			check: func(t *testing.T, _ *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name: "Signed by another key",
+			credential: func(t *testing.T, authenticator *softAuthenticator, options json.RawMessage) string {
+				forged := newSoftAuthenticator(t)
+				forged.credentialID, forged.userHandle = authenticator.credentialID, authenticator.userHandle
+				forged.signCount = authenticator.signCount
+				return forged.get(t, options)
+			},
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, sessions *mockdb.MockSessionRepository, auditRepo *mockdb.MockAuditRepository, ceremony *entity.WebAuthnCeremony, registered *entity.WebAuthnCredential) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "login").Times(1).Return(ceremony, nil)
+				lookedUp(repo, registered)
+				refused(repo, users, sessions)
+				failedLogin(auditRepo, "wrong_passkey")
+			},
+			check: func(t *testing.T, _ *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name: "Unknown user handle",
+			credential: func(t *testing.T, authenticator *softAuthenticator, options json.RawMessage) string {
+				authenticator.userHandle = []byte("someone else")
+				return authenticator.get(t, options)
+			},
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, sessions *mockdb.MockSessionRepository, auditRepo *mockdb.MockAuditRepository, ceremony *entity.WebAuthnCeremony, _ *entity.WebAuthnCredential) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "login").Times(1).Return(ceremony, nil)
+				repo.EXPECT().GetWebAuthnUsername(gomock.Any(), []byte("someone else")).Times(1).Return("", dbrepo.ErrWebAuthnUserNotFound)
+				repo.EXPECT().ListWebAuthnCredentials(gomock.Any(), gomock.Any()).Times(0)
+				refused(repo, users, sessions)
+				auditRepo.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, _ *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		}, {
+			name: "Cloned passkey",
+			credential: func(t *testing.T, authenticator *softAuthenticator, options json.RawMessage) string {
+				//a copy of the key still has an older counter than the one last used
+				authenticator.signCount -= 2
+				return authenticator.get(t, options)
+			},
+			buildStubs: func(repo *mockdb.MockPasskeyRepository, users *mockdb.MockUserRepository, sessions *mockdb.MockSessionRepository, auditRepo *mockdb.MockAuditRepository, ceremony *entity.WebAuthnCeremony, registered *entity.WebAuthnCredential) {
+				repo.EXPECT().TakeWebAuthnCeremony(gomock.Any(), ceremony.ID, "login").Times(1).Return(ceremony, nil)
+				lookedUp(repo, registered)
+				refused(repo, users, sessions)
+				failedLogin(auditRepo, "cloned_passkey")
+			},
+			check: func(t *testing.T, _ *service.AuthResult, err error) {
+				requireAppError(t, err, errorutil.ErrUnauthorized)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockPasskeyRepository(ctrl)
+			users := mockdb.NewMockUserRepository(ctrl)
+			sessions := mockdb.NewMockSessionRepository(ctrl)
+			auditRepo := mockdb.NewMockAuditRepository(ctrl)
+			passkeys, err := service.NewPasskeys(repo, service.RelyingParty{ID: testRPID, Name: "Bank"})
+			require.NoError(t, err)
+			usrSvc := service.NewUserService(service.UserServiceDeps{
+				Users:    users,
+				Token:    maker,
+				Config:   config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour},
+				Sessions: sessions,
+				Audit:    auditRepo,
+				Passkeys: passkeys,
+				Logger:   &nopLogger,
+			})
+
+			//hector registered the passkey earlier and has used it a few times since
+			authenticator := newSoftAuthenticator(t)
+			authenticator.userHandle, authenticator.signCount = handle, 4
+			registered := authenticator.credential(t)
+
+			var stored entity.WebAuthnCeremony
+			repo.EXPECT().CreateWebAuthnCeremony(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ context.Context, c entity.WebAuthnCeremony) error {
+					//the login is begun before anyone is identified
+					require.Empty(t, c.Username)
+					stored = c
+					return nil
+				})
+			ceremony, err := usrSvc.BeginPasskeyLogin(context.Background())
+			require.NoError(t, err)
+
+			tc.buildStubs(repo, users, sessions, auditRepo, &stored, registered)
+			result, err := usrSvc.FinishPasskeyLogin(context.Background(), service.FinishPasskeyLoginInput{
+				CeremonyID: ceremony.ID.String(),
+				Credential: tc.credential(t, authenticator, ceremony.Options),
+			})
+			tc.check(t, result, err)
+		})
+	}
+}
+
+func TestPasskeysDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    mockdb.NewMockUserRepository(ctrl),
+		Token:    maker,
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		Logger:   &nopLogger,
+	})
+
+	_, err = usrSvc.BeginPasskeyLogin(context.Background())
+	requireAppError(t, err, errorutil.ErrBadRequest)
+	_, err = usrSvc.BeginPasskeyRegistration(context.Background(), &auth.Payload{Username: "hector"})
+	requireAppError(t, err, errorutil.ErrBadRequest)
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/validator"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queue := mockdb.NewMockPasswordResetQueue(ctrl)
+	svc := service.NewPasswordResetService(mockdb.NewMockPasswordResetRepository(ctrl), queue, nil)
+
+	//the account lookup is left to the worker, so every valid address is queued alike
+	queue.EXPECT().JobPasswordReset(gomock.Any(), &jobs.PasswordResetPayload{Email: "hector@gmail.com"}).Times(1).Return(nil)
+	require.NoError(t, svc.RequestPasswordReset(context.Background(), "hector@gmail.com"))
+
+	queue.EXPECT().JobPasswordReset(gomock.Any(), gomock.Any()).Times(0)
+	err := svc.RequestPasswordReset(context.Background(), "not-an-email")
+	var appErr *errorutil.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, errorutil.ErrBadRequest, appErr.Code)
+}
+
+func TestResetPassword(t *testing.T) {
+	const token = "emailed-token"
+	owner, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		token       string
+		newPassword string
+		buildStubs  func(repo *mockdb.MockPasswordResetRepository)
+		checkErr    func(t *testing.T, err error)
+	}{
+		{
+			name:        "OK",
+			token:       token,
+			newPassword: "new-secret123",
+			buildStubs: func(repo *mockdb.MockPasswordResetRepository) {
+				repo.EXPECT().GetPasswordResetUser(gomock.Any(), auth.HashSecret(token)).Times(1).Return(&owner, nil)
+				repo.EXPECT().ResetPassword(gomock.Any(), auth.HashSecret(token), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, _ string, hashed string, event entity.AuditEvent) (*entity.User, error) {
+						require.True(t, auth.ComparePassword([]byte(hashed), "new-secret123"))
+						require.Equal(t, audit.ActionPasswordReset, event.Action)
+						require.Equal(t, "hector", event.Actor)
+						return &entity.User{Username: "hector", HashedPassword: hashed}, nil
+					})
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		}, {
+			name:        "Invalid token",
+			token:       token,
+			newPassword: "new-secret123",
+			buildStubs: func(repo *mockdb.MockPasswordResetRepository) {
+				repo.EXPECT().GetPasswordResetUser(gomock.Any(), gomock.Any()).Times(1).Return(nil, dbrepo.ErrInvalidResetToken)
+				repo.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				var appErr *errorutil.AppError
+				require.ErrorAs(t, err, &appErr)
+				require.Equal(t, errorutil.ErrBadRequest, appErr.Code)
+			},
+		}, {
+			name:        "Weak password",
+			token:       token,
+			newPassword: "short",
+			buildStubs: func(repo *mockdb.MockPasswordResetRepository) {
+				repo.EXPECT().GetPasswordResetUser(gomock.Any(), gomock.Any()).Times(1).Return(&owner, nil)
+				repo.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Contains(t, v.ErrVal, "new_password")
+			},
+		}, {
+			name:        "Contains username",
+			token:       token,
+			newPassword: "hector-secret123",
+			buildStubs: func(repo *mockdb.MockPasswordResetRepository) {
+				repo.EXPECT().GetPasswordResetUser(gomock.Any(), gomock.Any()).Times(1).Return(&owner, nil)
+				repo.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				var v *validator.Validator
+				require.ErrorAs(t, err, &v)
+				require.Equal(t, "must not contain your username or email", v.ErrVal["new_password"])
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mockdb.NewMockPasswordResetRepository(ctrl)
+			tc.buildStubs(repo)
+
+			svc := service.NewPasswordResetService(repo, mockdb.NewMockPasswordResetQueue(ctrl), &auth.DefaultPasswordPolicy)
+			_, err := svc.ResetPassword(context.Background(), tc.token, tc.newPassword)
+			tc.checkErr(t, err)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccountAccessByRole(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	account := &entity.Account{ID: 1, Owner: "hector", Balance: 100, Currency: "USD"}
+
+	tokenFor := func(username string, role auth.Role) string {
+		token, _, err := maker.GenerateToken(username, time.Minute, auth.WithRole(role))
+		require.NoError(t, err)
+		return token
+	}
+
+	testCases := []struct {
+		name       string
+		token      string
+		url        string
+		buildStubs func(accountRepo *mockdb.MockAccountRepository)
+		status     int
+	}{
+		{
+			name:  "Owner reads own account",
+			token: tokenFor("hector", auth.RoleCustomer),
+			url:   "/accounts/1",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(account, nil)
+			},
+			status: http.StatusOK,
+		}, {
+			name:  "Customer reads someone else's account",
+			token: tokenFor("tina", auth.RoleCustomer),
+			url:   "/accounts/1",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(account, nil)
+			},
+			status: http.StatusForbidden,
+		}, {
+			name:  "Teller reads any account",
+			token: tokenFor("tina", auth.RoleTeller),
+			url:   "/accounts/1",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(account, nil)
+			},
+			status: http.StatusOK,
+		}, {
+			name:  "Customer lists someone else's accounts",
+			token: tokenFor("tina", auth.RoleCustomer),
+			url:   "/accounts?page_id=1&page_size=5&owner=hector",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().ListAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusForbidden,
+		}, {
+			name:  "Admin lists someone else's accounts",
+			token: tokenFor("ada", auth.RoleAdmin),
+			url:   "/accounts?page_id=1&page_size=5&owner=hector",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().ListAccount(gomock.Any(), entity.ListAccountInput{User: "hector", Limit: 5}).
+					Times(1).Return([]*entity.Account{account}, nil)
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo := mockdb.NewMockAccountRepository(ctrl)
+			tc.buildStubs(accountRepo)
+
+			router := newTestRouter(ctrl, testRouter{
+				Users:    service.UserServiceDeps{Token: maker},
+				Accounts: accountRepo,
+			})
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+
+			rec := httptest.NewRecorder()
+			router.Mux.ServeHTTP(rec, req)
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
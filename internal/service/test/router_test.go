@@ -0,0 +1,55 @@
+package service_test
+
+import (
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/service"
+	httptransport "github.com/0xOnah/bank/internal/transport/http"
+	"github.com/0xOnah/bank/internal/transport/sdk/middleware"
+	"go.uber.org/mock/gomock"
+)
+
+// testRouter is what newTestRouter wires into the gin router. Repositories left nil
+// become fresh mocks, and Auth defaults to Users.Token.
+type testRouter struct {
+	Users        service.UserServiceDeps
+	Auth         auth.Authenticator
+	SessionCheck bool
+	Accounts     service.AccountRepository
+	Transfers    service.TransferRepository
+	StepUp       auth.StepUpPolicy
+	HighValue    int64
+}
+
+func newTestRouter(ctrl *gomock.Controller, r testRouter) *httptransport.Router {
+	if r.Users.Users == nil {
+		r.Users.Users = mockdb.NewMockUserRepository(ctrl)
+	}
+	if r.Users.Sessions == nil {
+		r.Users.Sessions = mockdb.NewMockSessionRepository(ctrl)
+	}
+	if r.Users.Logger == nil {
+		r.Users.Logger = &nopLogger
+	}
+	if r.Accounts == nil {
+		r.Accounts = mockdb.NewMockAccountRepository(ctrl)
+	}
+	if r.Transfers == nil {
+		r.Transfers = mockdb.NewMockTransferRepository(ctrl)
+	}
+	if r.Auth == nil {
+		r.Auth = r.Users.Token
+	}
+
+	usrSvc := service.NewUserService(r.Users)
+	if r.SessionCheck {
+		r.Auth = auth.WithSessionCheck(r.Auth, usrSvc, nil)
+	}
+	limit := middleware.RateLimit(nil, nil, nil)
+	userHand := httptransport.NewUserHandler(usrSvc, r.Auth, limit)
+	accountHand := httptransport.NewAccountHandler(service.NewAccountService(r.Accounts), r.Auth)
+	transfHand := httptransport.NewTranserHandler(
+		service.NewTransferService(r.Transfers, r.Accounts, nil),
+		r.Auth, limit, r.StepUp, r.HighValue)
+	return httptransport.NewRouter(accountHand, transfHand, userHand)
+}
@@ -0,0 +1,206 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateServiceAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockdb.NewMockServiceAccountRepository(ctrl)
+	svc := service.NewServiceAccountService(repo)
+	input := service.CreateServiceAccountInput{
+		Name:       "reconciliation",
+		Scopes:     []string{"GET /accounts/:id"},
+		AccountIDs: []int64{1},
+	}
+
+	_, _, err := svc.CreateServiceAccount(context.Background(), &auth.Payload{Username: "hector", Role: auth.RoleCustomer}, input)
+	requireAppError(t, err, errorutil.ErrForbidden)
+
+	var storedHash string
+	repo.EXPECT().CreateServiceAccount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, sa entity.ServiceAccount, prefix, hash string, event entity.AuditEvent) (*entity.ServiceAccount, *entity.APIKey, error) {
+			require.Equal(t, "ada", sa.CreatedBy)
+			require.Equal(t, audit.ActionServiceAccountCreate, event.Action)
+			require.Equal(t, audit.ServiceAccountSubject("reconciliation"), event.Subject)
+			storedHash = hash
+			return &sa, &entity.APIKey{ServiceAccount: sa.Name, Prefix: prefix, KeyHash: hash}, nil
+		})
+	sa, key, err := svc.CreateServiceAccount(context.Background(), &auth.Payload{Username: "ada", Role: auth.RoleAdmin}, input)
+	require.NoError(t, err)
+	require.Equal(t, "reconciliation", sa.Name)
+	require.True(t, strings.HasPrefix(key.Key, auth.APIKeyPrefix+key.Prefix+"_"))
+	//only the hash is stored
+	require.Equal(t, auth.HashSecret(key.Key), storedHash)
+
+	input.Name = "Not Valid!"
+	_, _, err = svc.CreateServiceAccount(context.Background(), &auth.Payload{Username: "ada", Role: auth.RoleAdmin}, input)
+	require.Error(t, err)
+}
+
+func TestRotateAndRevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockdb.NewMockServiceAccountRepository(ctrl)
+	svc := service.NewServiceAccountService(repo)
+	admin := &auth.Payload{Username: "ada", Role: auth.RoleAdmin}
+
+	repo.EXPECT().GetServiceAccount(gomock.Any(), "reconciliation").Times(1).Return(&entity.ServiceAccount{Name: "reconciliation"}, nil)
+	repo.EXPECT().RotateAPIKey(gomock.Any(), "reconciliation", gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, name, prefix, hash string, event entity.AuditEvent) (*entity.APIKey, error) {
+			require.Equal(t, audit.ActionAPIKeyRotate, event.Action)
+			require.Equal(t, "ada", event.Actor)
+			require.Equal(t, prefix, event.Details["prefix"])
+			return &entity.APIKey{ServiceAccount: name, Prefix: prefix, KeyHash: hash}, nil
+		})
+	key, err := svc.RotateAPIKey(context.Background(), admin, "reconciliation")
+	require.NoError(t, err)
+
+	repo.EXPECT().RevokeAPIKey(gomock.Any(), key.Prefix, gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, prefix string, event entity.AuditEvent) error {
+			require.Equal(t, audit.ActionAPIKeyRevoke, event.Action)
+			require.Equal(t, audit.APIKeySubject(prefix), event.Subject)
+			return nil
+		})
+	require.NoError(t, svc.RevokeAPIKey(context.Background(), admin, key.Prefix))
+
+	repo.EXPECT().RevokeAPIKey(gomock.Any(), "unknown1", gomock.Any()).Times(1).Return(dbrepo.ErrAPIKeyNotFound)
+	requireAppError(t, svc.RevokeAPIKey(context.Background(), admin, "unknown1"), errorutil.ErrNotFound)
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	key, prefix, err := auth.NewAPIKey()
+	require.NoError(t, err)
+	stored := &entity.APIKey{ID: 3, ServiceAccount: "reconciliation", Prefix: prefix, KeyHash: auth.HashSecret(key)}
+	sa := &entity.ServiceAccount{Name: "reconciliation", Scopes: []string{"GET /accounts/:id"}, AccountIDs: []int64{1}}
+
+	t.Run("OK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockServiceAccountRepository(ctrl)
+
+		repo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Times(1).Return(stored, nil)
+		repo.EXPECT().GetServiceAccount(gomock.Any(), "reconciliation").Times(1).Return(sa, nil)
+		repo.EXPECT().TouchAPIKey(gomock.Any(), int64(3)).Times(1).Return(nil)
+		payload, err := service.NewServiceAccountService(repo).VerifyAPIKey(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, "sa:reconciliation", payload.Username)
+		require.Equal(t, sa.Scopes, payload.APIKey.Scopes)
+		require.Equal(t, sa.AccountIDs, payload.APIKey.AccountIDs)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockServiceAccountRepository(ctrl)
+
+		repo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Times(1).Return(stored, nil)
+		repo.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+		_, err := service.NewServiceAccountService(repo).VerifyAPIKey(context.Background(), key+"x")
+		require.ErrorIs(t, err, auth.ErrInvalidAPIKey)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mockdb.NewMockServiceAccountRepository(ctrl)
+
+		revoked := *stored
+		revoked.RevokedAt = time.Now()
+		repo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).Times(1).Return(&revoked, nil)
+		repo.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+		_, err := service.NewServiceAccountService(repo).VerifyAPIKey(context.Background(), key)
+		require.Error(t, err)
+	})
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	key, prefix, err := auth.NewAPIKey()
+	require.NoError(t, err)
+	stored := &entity.APIKey{ID: 3, ServiceAccount: "reconciliation", Prefix: prefix, KeyHash: auth.HashSecret(key)}
+	sa := &entity.ServiceAccount{Name: "reconciliation", Scopes: []string{"GET /accounts/:id"}, AccountIDs: []int64{1}}
+
+	testCases := []struct {
+		name       string
+		method     string
+		url        string
+		buildStubs func(accountRepo *mockdb.MockAccountRepository)
+		status     int
+	}{
+		{
+			name:   "Granted account",
+			method: http.MethodGet,
+			url:    "/accounts/1",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).
+					Return(&entity.Account{ID: 1, Owner: "hector", Currency: "USD"}, nil)
+			},
+			status: http.StatusOK,
+		}, {
+			name:   "Account outside the grant",
+			method: http.MethodGet,
+			url:    "/accounts/2",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), int64(2)).Times(1).
+					Return(&entity.Account{ID: 2, Owner: "hector", Currency: "USD"}, nil)
+			},
+			status: http.StatusForbidden,
+		}, {
+			name:   "Route outside the scopes",
+			method: http.MethodGet,
+			url:    "/accounts?page_id=1&page_size=5",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().ListAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			saRepo := mockdb.NewMockServiceAccountRepository(ctrl)
+			saRepo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), prefix).AnyTimes().Return(stored, nil)
+			saRepo.EXPECT().GetServiceAccount(gomock.Any(), "reconciliation").AnyTimes().Return(sa, nil)
+			saRepo.EXPECT().TouchAPIKey(gomock.Any(), int64(3)).AnyTimes().Return(nil)
+			accountRepo := mockdb.NewMockAccountRepository(ctrl)
+			tc.buildStubs(accountRepo)
+
+			router := newTestRouter(ctrl, testRouter{
+				Users:    service.UserServiceDeps{Token: maker},
+				Auth:     auth.WithAPIKeys(maker, service.NewServiceAccountService(saRepo)),
+				Accounts: accountRepo,
+			})
+
+			req, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+key)
+
+			rec := httptest.NewRecorder()
+			router.Mux.ServeHTTP(rec, req)
+			require.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/config"
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHighValueTransferStepUp(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	policy := auth.StepUpPolicy{
+		auth.OpHighValueTransfer: {MaxAge: 5 * time.Minute, Assurance: auth.AssurancePassword},
+	}
+	staleToken, _, err := maker.GenerateToken("hector", time.Minute,
+		auth.WithSessionID(uuid.New()), auth.WithAuthTime(time.Now().Add(-time.Hour), auth.AssurancePassword))
+	require.NoError(t, err)
+	freshToken, _, err := maker.GenerateToken("hector", time.Minute,
+		auth.WithSessionID(uuid.New()), auth.WithAuthTime(time.Now(), auth.AssurancePassword))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		token         string
+		amount        int64
+		buildStubs    func(accountRepo *mockdb.MockAccountRepository)
+		checkResponse func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Stale authentication",
+			token:  staleToken,
+			amount: 5000,
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+				require.Contains(t, r.Header().Get("WWW-Authenticate"), `error="insufficient_user_authentication"`)
+				require.Contains(t, r.Header().Get("WWW-Authenticate"), "max_age=300")
+
+				var body struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				require.NoError(t, json.Unmarshal(r.Body.Bytes(), &body))
+				require.Equal(t, auth.StepUpErrorCode, body.Error.Code)
+			},
+		}, {
+			name:   "Fresh authentication",
+			token:  freshToken,
+			amount: 5000,
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(nil, repo.ErrRecordNotFound)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				//past the step-up check and into the transfer
+				require.Equal(t, http.StatusNotFound, r.Code)
+			},
+		}, {
+			name:   "Below threshold",
+			token:  staleToken,
+			amount: 10,
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository) {
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).Return(nil, repo.ErrRecordNotFound)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo := mockdb.NewMockAccountRepository(ctrl)
+			tc.buildStubs(accountRepo)
+
+			router := newTestRouter(ctrl, testRouter{
+				Users:     service.UserServiceDeps{Token: maker},
+				Accounts:  accountRepo,
+				StepUp:    policy,
+				HighValue: 1000,
+			})
+
+			body, err := json.Marshal(map[string]any{
+				"from_account_id": 1,
+				"to_account_id":   2,
+				"amount":          tc.amount,
+				"currency":        "USD",
+			})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+
+			rec := httptest.NewRecorder()
+			router.Mux.ServeHTTP(rec, req)
+			tc.checkResponse(rec)
+		})
+	}
+}
+
+func TestReauthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+
+	userRepo := mockdb.NewMockUserRepository(ctrl)
+	mfa := mockdb.NewMockMFAVerifier(ctrl)
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    userRepo,
+		Token:    maker,
+		Config:   config.Config{ACCESS_TOKEN_DURATATION: time.Minute},
+		Sessions: mockdb.NewMockSessionRepository(ctrl),
+		MFA:      mfa,
+		Logger:   &nopLogger,
+	})
+
+	sessionID := uuid.New()
+	_, stale, err := maker.GenerateToken("hector", time.Minute,
+		auth.WithSessionID(sessionID), auth.WithAuthTime(time.Now().Add(-time.Hour), auth.AssuranceMFA))
+	require.NoError(t, err)
+
+	userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(2).Return(&user, nil)
+	_, err = usrSvc.Reauthenticate(context.Background(), stale, service.ReauthenticateInput{Password: "wrong-password"})
+	requireAppError(t, err, errorutil.ErrUnauthorized)
+
+	result, err := usrSvc.Reauthenticate(context.Background(), stale, service.ReauthenticateInput{Password: "secret12345"})
+	require.NoError(t, err)
+	fresh, err := maker.VerifyToken(result.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, sessionID.String(), fresh.SessionID)
+	require.WithinDuration(t, time.Now(), fresh.AuthTime.Time, time.Second)
+	require.Equal(t, auth.AssurancePassword, fresh.Assurance)
+
+	mfa.EXPECT().VerifyCode(gomock.Any(), "hector", "123456").Times(1).Return(nil)
+	result, err = usrSvc.Reauthenticate(context.Background(), stale, service.ReauthenticateInput{Code: "123456"})
+	require.NoError(t, err)
+	fresh, err = maker.VerifyToken(result.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, auth.AssuranceMFA, fresh.Assurance)
+
+	//one of password or code, not both
+	_, err = usrSvc.Reauthenticate(context.Background(), stale, service.ReauthenticateInput{Password: "secret12345", Code: "123456"})
+	require.Error(t, err)
+}
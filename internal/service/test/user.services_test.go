@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -9,15 +10,19 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/0xOnah/bank/internal/config"
 	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
 	"github.com/0xOnah/bank/internal/entity"
 	"github.com/0xOnah/bank/internal/sdk/auth"
 	"github.com/0xOnah/bank/internal/service"
 	httptransport "github.com/0xOnah/bank/internal/transport/http"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type createUserTest struct {
@@ -70,7 +75,7 @@ func TestCreateUser(t *testing.T) {
 				"email":    user.Email.String(),
 			},
 			buildStubs: func(repo *mockdb.MockUserRepository) {
-				repo.EXPECT().CreateUser(gomock.Any(), EqCreateUser(user, "secret12345")).Times(1).Return(&user, nil)
+				repo.EXPECT().CreateUser(gomock.Any(), EqCreateUser(user, "secret12345"), gomock.Any()).Times(1).Return(&user, nil)
 			},
 			checkResponse: func(r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, r.Code)
@@ -80,7 +85,7 @@ func TestCreateUser(t *testing.T) {
 			name: "Empty User: Not created",
 			body: map[string]string{},
 			buildStubs: func(repo *mockdb.MockUserRepository) {
-				repo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, r.Code)
@@ -94,7 +99,7 @@ func TestCreateUser(t *testing.T) {
 				"email":    user.Email.String(),
 			},
 			buildStubs: func(repo *mockdb.MockUserRepository) {
-				repo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				repo.EXPECT().CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
 			},
 			checkResponse: func(r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, r.Code)
@@ -108,7 +113,21 @@ func TestCreateUser(t *testing.T) {
 				"email":    "invalid_email",
 			},
 			buildStubs: func(repo *mockdb.MockUserRepository) {
-				repo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		}, {
+			name: "Password contains username",
+			body: map[string]string{
+				"username": user.Username,
+				"password": "Hector12345",
+				"fullname": user.FullName,
+				"email":    user.Email.String(),
+			},
+			buildStubs: func(repo *mockdb.MockUserRepository) {
+				repo.EXPECT().CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, r.Code)
@@ -123,20 +142,8 @@ func TestCreateUser(t *testing.T) {
 			maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
 			require.NoError(t, err)
 
-			accountRepo := mockdb.NewMockAccountRepository(ctrl)
-			transferRepo := mockdb.NewMockTransferRepository(ctrl)
 			UserRepo := mockdb.NewMockUserRepository(ctrl)
-			sessionRepo := mockdb.NewMockSessionRepository(ctrl)
-			accountSvc := service.NewAccountService(accountRepo)
-			accountHandler := httptransport.NewAccountHandler(accountSvc, maker)
-
-			transferSvc := service.NewTransferService(transferRepo, accountRepo)
-			transfHand := httptransport.NewTranserHandler(transferSvc, maker)
-
-			usrSvc := service.NewUserService(UserRepo, maker, config.Config{}, sessionRepo)
-			userHand := httptransport.NewUserHandler(usrSvc, maker)
-
-			router := httptransport.NewRouter(accountHandler, transfHand, userHand)
+			router := newTestRouter(ctrl, testRouter{Users: service.UserServiceDeps{Users: UserRepo, Token: maker}})
 
 			data, err := json.Marshal(value.body)
 			require.NoError(t, err)
@@ -155,5 +162,293 @@ func TestCreateUser(t *testing.T) {
 }
 
 func TestLogin(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	cfg := config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour}
+
+	current, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+	legacy := current
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret12345"), bcrypt.MinCost)
+	require.NoError(t, err)
+	legacy.HashedPassword = string(bcryptHash)
+
+	testCases := []struct {
+		name       string
+		user       entity.User
+		buildStubs func(userRepo *mockdb.MockUserRepository)
+	}{
+		{
+			name: "Current hash",
+			user: current,
+			buildStubs: func(userRepo *mockdb.MockUserRepository) {
+				userRepo.EXPECT().RehashPassword(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		}, {
+			name: "Bcrypt hash is upgraded",
+			user: legacy,
+			buildStubs: func(userRepo *mockdb.MockUserRepository) {
+				userRepo.EXPECT().RehashPassword(gomock.Any(), "hector", string(bcryptHash), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, _, _, newHash string) error {
+						require.True(t, auth.ComparePassword([]byte(newHash), "secret12345"))
+						require.False(t, auth.PasswordNeedsRehash([]byte(newHash)))
+						return nil
+					})
+			},
+		}, {
+			name: "Failed upgrade still logs in",
+			user: legacy,
+			buildStubs: func(userRepo *mockdb.MockUserRepository) {
+				userRepo.EXPECT().RehashPassword(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mockdb.NewMockUserRepository(ctrl)
+			sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+			mfa := mockdb.NewMockMFAVerifier(ctrl)
+			user := tc.user
+			userRepo.EXPECT().GetUser(gomock.Any(), "hector").Times(1).Return(&user, nil)
+			mfa.EXPECT().MFAEnabled(gomock.Any(), "hector").Times(1).Return(false, nil)
+			sessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ context.Context, s entity.Session, _ entity.AuditEvent) (*entity.Session, error) {
+					return &s, nil
+				})
+			tc.buildStubs(userRepo)
+
+			usrSvc := service.NewUserService(service.UserServiceDeps{
+				Users:    userRepo,
+				Token:    maker,
+				Config:   cfg,
+				Sessions: sessionRepo,
+				MFA:      mfa,
+				Logger:   &nopLogger,
+			})
+			result, err := usrSvc.Login(context.Background(), service.Logininput{Username: "hector", Password: "secret12345"})
+			require.NoError(t, err)
+			require.NotEmpty(t, result.AccessToken)
+		})
+	}
+}
+
+func TestRenewAccessToken(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	cfg := config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour}
+
+	refreshToken, payload, err := maker.GenerateToken("hector", time.Hour)
+	require.NoError(t, err)
+	session := &entity.Session{
+		ID:           uuid.MustParse(payload.ID),
+		Username:     "hector",
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiresAt.Time,
+		FamilyID:     uuid.New(),
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(repo *mockdb.MockSessionRepository)
+		checkResponse func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK: refresh token rotated",
+			buildStubs: func(repo *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(session, nil)
+				repo.EXPECT().RotateSession(gomock.Any(), session.ID, gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, next entity.Session) (*entity.Session, error) {
+						require.Equal(t, session.FamilyID, next.FamilyID)
+						require.Equal(t, session.ID, next.ParentID)
+						require.NotEqual(t, refreshToken, next.RefreshToken)
+						return &next, nil
+					})
+				repo.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+				var resp httptransport.RenewAccessTokenResp
+				require.NoError(t, json.Unmarshal(r.Body.Bytes(), &resp))
+				require.NotEmpty(t, resp.AccessToken)
+				require.NotEmpty(t, resp.RefreshToken)
+				require.NotEqual(t, refreshToken, resp.RefreshToken)
+				require.NotEqual(t, session.ID, resp.SessionID)
+			},
+		}, {
+			name: "Reused rotated token blocks family",
+			buildStubs: func(repo *mockdb.MockSessionRepository) {
+				rotated := *session
+				rotated.RotatedAt = time.Now()
+				repo.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(&rotated, nil)
+				repo.EXPECT().RotateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				repo.EXPECT().BlockSessionFamily(gomock.Any(), session.FamilyID).Times(1).Return(nil)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		}, {
+			name: "Concurrent reuse blocks family",
+			buildStubs: func(repo *mockdb.MockSessionRepository) {
+				repo.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(session, nil)
+				repo.EXPECT().RotateSession(gomock.Any(), session.ID, gomock.Any()).Times(1).Return(nil, dbrepo.ErrSessionReused)
+				repo.EXPECT().BlockSessionFamily(gomock.Any(), session.FamilyID).Times(1).Return(nil)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		}, {
+			name: "Blocked session",
+			buildStubs: func(repo *mockdb.MockSessionRepository) {
+				blocked := *session
+				blocked.IsBlocked = true
+				repo.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(&blocked, nil)
+				repo.EXPECT().RotateSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+			tc.buildStubs(sessionRepo)
+			userRepo := mockdb.NewMockUserRepository(ctrl)
+			userRepo.EXPECT().GetUser(gomock.Any(), "hector").AnyTimes().Return(&entity.User{Username: "hector", Role: "customer"}, nil)
+
+			router := newTestRouter(ctrl, testRouter{
+				Users: service.UserServiceDeps{Users: userRepo, Token: maker, Config: cfg, Sessions: sessionRepo},
+			})
+
+			body, err := json.Marshal(map[string]string{"refresh_token": refreshToken})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/token/renew_access", bytes.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			router.Mux.ServeHTTP(rec, req)
+			tc.checkResponse(rec)
+		})
+	}
+}
+
+func TestRenewAccessTokenAfterDemotion(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	refreshToken, payload, err := maker.GenerateToken("ada", time.Hour, auth.WithRole(auth.RoleAdmin))
+	require.NoError(t, err)
+	session := &entity.Session{
+		ID:           uuid.MustParse(payload.ID),
+		Username:     "ada",
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiresAt.Time,
+		FamilyID:     uuid.New(),
+	}
+
+	userRepo := mockdb.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().GetUser(gomock.Any(), "ada").Times(1).Return(&entity.User{Username: "ada", Role: "customer"}, nil)
+	sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+	sessionRepo.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(session, nil)
+	sessionRepo.EXPECT().RotateSession(gomock.Any(), session.ID, gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, next entity.Session) (*entity.Session, error) {
+			return &next, nil
+		})
+
+	usrSvc := service.NewUserService(service.UserServiceDeps{
+		Users:    userRepo,
+		Token:    maker,
+		Config:   config.Config{ACCESS_TOKEN_DURATATION: time.Minute, REFRESH_TOKEN_DURATION: time.Hour},
+		Sessions: sessionRepo,
+		Logger:   &nopLogger,
+	})
+	renewed, err := usrSvc.RenewAccessToken(context.Background(), refreshToken)
+	require.NoError(t, err)
+
+	//the admin was demoted after logging in, so neither new token may carry the old role
+	access, err := maker.VerifyToken(renewed.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, auth.RoleCustomer, access.Role)
+	refresh, err := maker.VerifyToken(renewed.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, auth.RoleCustomer, refresh.Role)
+}
 
+func TestRevokedSessionAccessToken(t *testing.T) {
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	sessionID := uuid.New()
+	accessToken, _, err := maker.GenerateToken("hector", time.Minute, auth.WithSessionID(sessionID))
+	require.NoError(t, err)
+	session := &entity.Session{
+		ID:        sessionID,
+		Username:  "hector",
+		ExpiresAt: time.Now().Add(time.Hour),
+		FamilyID:  sessionID,
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(accountRepo *mockdb.MockAccountRepository, sessionRepo *mockdb.MockSessionRepository)
+		checkResponse func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK: active session",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository, sessionRepo *mockdb.MockSessionRepository) {
+				sessionRepo.EXPECT().GetSession(gomock.Any(), sessionID).Times(1).Return(session, nil)
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), int64(1)).Times(1).
+					Return(&entity.Account{ID: 1, Owner: "hector"}, nil)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+			},
+		}, {
+			name: "Revoked session",
+			buildStubs: func(accountRepo *mockdb.MockAccountRepository, sessionRepo *mockdb.MockSessionRepository) {
+				revoked := *session
+				revoked.IsBlocked = true
+				sessionRepo.EXPECT().GetSession(gomock.Any(), sessionID).Times(1).Return(&revoked, nil)
+				accountRepo.EXPECT().GetAccountByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo := mockdb.NewMockAccountRepository(ctrl)
+			sessionRepo := mockdb.NewMockSessionRepository(ctrl)
+			tc.buildStubs(accountRepo, sessionRepo)
+
+			router := newTestRouter(ctrl, testRouter{
+				Users:        service.UserServiceDeps{Token: maker, Sessions: sessionRepo},
+				Accounts:     accountRepo,
+				SessionCheck: true,
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/accounts/1", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			rec := httptest.NewRecorder()
+			router.Mux.ServeHTTP(rec, req)
+			tc.checkResponse(rec)
+		})
+	}
 }
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/0xOnah/bank/internal/db/mock"
+	dbrepo "github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestResendVerifyEmail(t *testing.T) {
+	user, err := entity.NewUser("hector", "secret12345", "hector king", "hector@gmail.com")
+	require.NoError(t, err)
+	verified := user
+	verified.IsEmailVerified = true
+
+	testCases := []struct {
+		name       string
+		buildStubs func(ur *mockdb.MockUserRepository, vr *mockdb.MockVerifyEmailRepository, q *mockdb.MockVerifyEmailQueue)
+		wantCode   errorutil.ErrorKind
+	}{
+		{
+			name: "OK: first email",
+			buildStubs: func(ur *mockdb.MockUserRepository, vr *mockdb.MockVerifyEmailRepository, q *mockdb.MockVerifyEmailQueue) {
+				ur.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(&user, nil)
+				vr.EXPECT().GetLastVerifyEmail(gomock.Any(), user.Username).Times(1).Return(nil, dbrepo.ErrVerifyEmailNotFound)
+				q.EXPECT().JobVerifyEmail(gomock.Any(), &jobs.VerifyEmailPayload{Username: user.Username}).Times(1).Return(nil)
+			},
+		}, {
+			name: "OK: previous email is old",
+			buildStubs: func(ur *mockdb.MockUserRepository, vr *mockdb.MockVerifyEmailRepository, q *mockdb.MockVerifyEmailQueue) {
+				ur.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(&user, nil)
+				vr.EXPECT().GetLastVerifyEmail(gomock.Any(), user.Username).Times(1).
+					Return(&entity.VerifyEmail{CreatedAt: time.Now().Add(-time.Hour)}, nil)
+				q.EXPECT().JobVerifyEmail(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		}, {
+			name: "Throttled",
+			buildStubs: func(ur *mockdb.MockUserRepository, vr *mockdb.MockVerifyEmailRepository, q *mockdb.MockVerifyEmailQueue) {
+				ur.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(&user, nil)
+				vr.EXPECT().GetLastVerifyEmail(gomock.Any(), user.Username).Times(1).
+					Return(&entity.VerifyEmail{CreatedAt: time.Now().Add(-10 * time.Second)}, nil)
+				q.EXPECT().JobVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: errorutil.ErrTooManyRequests,
+		}, {
+			name: "Already verified",
+			buildStubs: func(ur *mockdb.MockUserRepository, vr *mockdb.MockVerifyEmailRepository, q *mockdb.MockVerifyEmailQueue) {
+				ur.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(&verified, nil)
+				vr.EXPECT().GetLastVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+				q.EXPECT().JobVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: errorutil.ErrBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ur := mockdb.NewMockUserRepository(ctrl)
+			vr := mockdb.NewMockVerifyEmailRepository(ctrl)
+			q := mockdb.NewMockVerifyEmailQueue(ctrl)
+			tc.buildStubs(ur, vr, q)
+
+			err := service.NewVerifyEmailService(vr, ur, q).ResendVerifyEmail(context.Background(), user.Username)
+			if tc.wantCode == errorutil.ErrUnknown {
+				require.NoError(t, err)
+				return
+			}
+			var appErr *errorutil.AppError
+			require.ErrorAs(t, err, &appErr)
+			require.Equal(t, tc.wantCode, appErr.Code)
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vr := mockdb.NewMockVerifyEmailRepository(ctrl)
+	svc := service.NewVerifyEmailService(vr, mockdb.NewMockUserRepository(ctrl), mockdb.NewMockVerifyEmailQueue(ctrl))
+
+	//only the code's hash is looked up
+	vr.EXPECT().VerifyEmail(gomock.Any(), int64(1), auth.HashSecret("code")).Times(1).Return(&entity.VerifyEmail{ID: 1, IsUsed: true}, nil)
+	verifyEmail, err := svc.VerifyEmail(context.Background(), 1, "code")
+	require.NoError(t, err)
+	require.True(t, verifyEmail.IsUsed)
+
+	vr.EXPECT().VerifyEmail(gomock.Any(), int64(1), auth.HashSecret("used")).Times(1).Return(nil, dbrepo.ErrInvalidVerifyCode)
+	_, err = svc.VerifyEmail(context.Background(), 1, "used")
+	var appErr *errorutil.AppError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, errorutil.ErrBadRequest, appErr.Code)
+
+	//malformed requests never reach the database
+	_, err = svc.VerifyEmail(context.Background(), 0, "")
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, errorutil.ErrBadRequest, appErr.Code)
+}
@@ -3,8 +3,13 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/metrics"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
 	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
 )
 
@@ -12,18 +17,20 @@ type TransferRepository interface {
 	CreateTransfer(ctx context.Context, arg entity.CreateTransferInput) (*entity.Transfer, error)
 	GetTransfer(ctx context.Context, id int64) (*entity.Transfer, error)
 	ListTransfers(ctx context.Context, arg entity.ListTransfersInput) ([]*entity.Transfer, error)
-	CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput) (*entity.TransferTxResult, error)
+	CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput, event entity.AuditEvent) (*entity.TransferTxResult, error)
 }
 
 type TransferService struct {
 	transferRepo TransferRepository
 	accountRepo  AccountRepository
+	metrics      *metrics.Metrics
 }
 
-func NewTransferService(transRepo TransferRepository, accountRepo AccountRepository) *TransferService {
+func NewTransferService(transRepo TransferRepository, accountRepo AccountRepository, m *metrics.Metrics) *TransferService {
 	return &TransferService{
 		accountRepo:  accountRepo,
 		transferRepo: transRepo,
+		metrics:      m,
 	}
 }
 func (t *TransferService) validateAccount(ctx context.Context, accountId int64, currency string) (*entity.Account, error) {
@@ -38,7 +45,10 @@ func (t *TransferService) validateAccount(ctx context.Context, accountId int64,
 }
 
 // Todo: balance check
-func (t *TransferService) CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput, username string, currency string) (*entity.TransferTxResult, error) {
+func (t *TransferService) CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput, payload *auth.Payload, currency string) (_ *entity.TransferTxResult, err error) {
+	ctx, span := tracer.Start(ctx, "TransferService.CreateTransferTX")
+	defer func() { tracing.End(span, err) }()
+
 	//sameAccount
 	if arg.FromAccountID == arg.ToAccountID {
 		return nil, errorutil.NewAppError(errorutil.ErrInvalidInput, "cannot transfer to the same account", nil)
@@ -48,8 +58,8 @@ func (t *TransferService) CreateTransferTX(ctx context.Context, arg entity.Creat
 	if err != nil {
 		return nil, err
 	}
-	if account.Owner != username {
-		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "you do not own this account", nil)
+	if err := auth.AuthorizeAccount(payload, account.Owner, account.ID, auth.PermTransferAnyAccount); err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrForbidden, "you do not own this account", err)
 	}
 	//to
 	_, err = t.validateAccount(ctx, arg.ToAccountID, currency)
@@ -57,10 +67,17 @@ func (t *TransferService) CreateTransferTX(ctx context.Context, arg entity.Creat
 		return nil, err
 	}
 	//transfer
-	tranfer, err := t.transferRepo.CreateTransferTX(ctx, arg)
+	event := audit.NewEvent(ctx, audit.ActionTransfer, payload.Username, audit.AccountSubject(arg.FromAccountID))
+	event.Details = map[string]string{
+		"to_account": strconv.FormatInt(arg.ToAccountID, 10),
+		"amount":     strconv.FormatInt(arg.Amount, 10),
+		"currency":   currency,
+	}
+	tranfer, err := t.transferRepo.CreateTransferTX(ctx, arg, event)
 	if err != nil {
 		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal error", err)
 	}
+	t.metrics.RecordTransfer(currency, arg.Amount)
 
 	return tranfer, nil
 }
@@ -5,25 +5,62 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/0xOnah/bank/internal/config"
 	"github.com/0xOnah/bank/internal/db/repo"
 	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/audit"
 	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
 	"github.com/0xOnah/bank/internal/sdk/validator"
 	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"github.com/rs/zerolog"
 )
 
+var (
+	errSessionBlocked     = errors.New("session is blocked")
+	errSessionMismatch    = errors.New("refresh token does not match session")
+	errSessionExpired     = errors.New("session has expired")
+	errRefreshTokenReused = errors.New("refresh token reused")
+	errNotMFAToken        = errors.New("token is not an mfa challenge")
+	errNotRefreshToken    = errors.New("token is not a refresh token")
+)
+
+// mfaChallengeDuration is how long a user has to enter their second factor after the password.
+const mfaChallengeDuration = 5 * time.Minute
+
+// dummyPasswordHash is compared against when the username doesn't exist, so those logins take
+// as long as a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := auth.HashPassword("not-a-real-password")
+	return []byte(hash)
+})
+
 type UserRepository interface {
-	CreateUser(ctx context.Context, arg entity.User) (*entity.User, error)
+	CreateUser(ctx context.Context, arg entity.User, event entity.AuditEvent) (*entity.User, error)
 	GetUser(ctx context.Context, username string) (*entity.User, error)
+	RehashPassword(ctx context.Context, username, oldHash, newHash string) error
 }
 type SessionRepository interface {
-	CreateSession(ctx context.Context, arg entity.Session) (*entity.Session, error)
+	CreateSession(ctx context.Context, arg entity.Session, event entity.AuditEvent) (*entity.Session, error)
 	GetSession(ctx context.Context, id uuid.UUID) (*entity.Session, error)
+	RotateSession(ctx context.Context, previousID uuid.UUID, next entity.Session) (*entity.Session, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error
+	ListActiveSessions(ctx context.Context, username string) ([]*entity.Session, error)
+	BlockUserSessionFamily(ctx context.Context, username string, familyID uuid.UUID, event entity.AuditEvent) error
+	BlockOtherSessionFamilies(ctx context.Context, username string, keepFamilyID uuid.UUID, event entity.AuditEvent) error
+	SpendMFAToken(ctx context.Context, id uuid.UUID, username string, expiresAt time.Time) error
+}
+
+// MFAVerifier is the part of MFAService logins depend on.
+type MFAVerifier interface {
+	MFAEnabled(ctx context.Context, username string) (bool, error)
+	VerifyCode(ctx context.Context, username, code string) error
 }
 
 type userService struct {
@@ -31,14 +68,47 @@ type userService struct {
 	token       auth.Authenticator
 	config      *config.Config
 	SessionRepo SessionRepository
+	mfa         MFAVerifier
+	lockout     *LoginLockout
+	devices     *DeviceDetector
+	audit       AuditRepository
+	links       *LoginLinks
+	passkeys    *Passkeys
+	passwords   *auth.PasswordPolicy
+	logger      *zerolog.Logger
+}
+
+// UserServiceDeps holds what NewUserService needs. Users, Token, Sessions and Logger
+// are required; the rest turn their feature off when nil.
+type UserServiceDeps struct {
+	Users     UserRepository
+	Token     auth.Authenticator
+	Config    config.Config
+	Sessions  SessionRepository
+	MFA       MFAVerifier
+	Lockout   *LoginLockout
+	Devices   *DeviceDetector
+	Audit     AuditRepository
+	Links     *LoginLinks
+	Passkeys  *Passkeys
+	Passwords *auth.PasswordPolicy
+	Logger    *zerolog.Logger
 }
 
-func NewUserService(ur UserRepository, token auth.Authenticator, config config.Config, sr SessionRepository) *userService {
+func NewUserService(deps UserServiceDeps) *userService {
 	return &userService{
-		UserRepo:    ur,
-		token:       token,
-		config:      &config,
-		SessionRepo: sr,
+		UserRepo:    deps.Users,
+		token:       deps.Token,
+		config:      &deps.Config,
+		SessionRepo: deps.Sessions,
+		mfa:         deps.MFA,
+		lockout:     deps.Lockout,
+		devices:     deps.Devices,
+		audit:       deps.Audit,
+		links:       deps.Links,
+		passkeys:    deps.Passkeys,
+		passwords:   deps.Passwords,
+		logger:      logger.ServiceLogger(deps.Logger, "user_service"),
 	}
 }
 
@@ -49,13 +119,20 @@ type CreateUserInput struct {
 	Email    string
 }
 
-func (us *userService) CreateUser(ctx context.Context, cr CreateUserInput) (entity.User, error) {
+func (us *userService) CreateUser(ctx context.Context, cr CreateUserInput) (_ entity.User, err error) {
+	ctx, span := tracer.Start(ctx, "userService.CreateUser")
+	defer func() { tracing.End(span, err) }()
+
+	if err := us.CheckNewPassword(ctx, cr.Username, cr.Email, cr.Password); err != nil {
+		return entity.User{}, err
+	}
 	user, err := entity.NewUser(cr.Username, cr.Password, cr.Fullname, cr.Email)
 	if err != nil {
 		return entity.User{}, errorutil.NewAppError(errorutil.ErrBadRequest, "failed validation", err)
 	}
 
-	createdUser, err := us.UserRepo.CreateUser(ctx, user)
+	event := audit.NewEvent(ctx, audit.ActionUserCreate, user.Username, audit.UserSubject(user.Username))
+	createdUser, err := us.UserRepo.CreateUser(ctx, user, event)
 	if err != nil {
 		errvalue, ok := err.(*pq.Error)
 		if ok {
@@ -71,6 +148,20 @@ func (us *userService) CreateUser(ctx context.Context, cr CreateUserInput) (enti
 	return *createdUser, nil
 }
 
+// CheckNewPassword checks a password the user is about to set against the password policy.
+func (us *userService) CheckNewPassword(ctx context.Context, username, email, password string) error {
+	v := validator.NewValidator()
+	if err := us.passwords.Check(v, "password", password, username, email); err != nil {
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if !v.Valid() {
+		return errorutil.NewAppError(errorutil.ErrBadRequest, "failed validation", v)
+	}
+	return nil
+}
+
+// AuthResult is a new session, or when MFARequired is set only an MFA token to pass to VerifyMFA
+// along with the second factor.
 type AuthResult struct {
 	SessionID             uuid.UUID
 	AccessToken           string
@@ -78,6 +169,12 @@ type AuthResult struct {
 	RefreshToken          string
 	RefreshTokenExpiresAt time.Time
 	User                  *entity.User
+	MFARequired           bool
+	MFAToken              string
+	MFATokenExpiresAt     time.Time
+	// DeviceConfirmationRequired is set instead of a session when the login came from an
+	// unrecognized device: a link to approve it was emailed, after which the user signs in again.
+	DeviceConfirmationRequired bool
 }
 
 type Logininput struct {
@@ -87,7 +184,10 @@ type Logininput struct {
 	UserAgent string
 }
 
-func (us *userService) Login(ctx context.Context, arg Logininput) (*AuthResult, error) {
+func (us *userService) Login(ctx context.Context, arg Logininput) (_ *AuthResult, err error) {
+	ctx, span := tracer.Start(ctx, "userService.Login")
+	defer func() { tracing.End(span, err) }()
+
 	v := validator.NewValidator()
 	v.Check(arg.Username != "", "username", "cannot be empty")
 	v.Check(regexp.MustCompile(`^[a-zA-Z0-9\s]+$`).MatchString(arg.Username), "username", "can only contain letters, numbers and spaces")
@@ -97,37 +197,198 @@ func (us *userService) Login(ctx context.Context, arg Logininput) (*AuthResult,
 		return nil, v
 	}
 
+	//unknown usernames and wrong passwords look the same from outside, locked or not
+	if err := us.checkLockout(ctx, arg.Username, arg.ClientIP, arg.UserAgent); err != nil {
+		return nil, err
+	}
+
 	user, err := us.UserRepo.GetUser(ctx, arg.Username)
 	if err != nil {
 		if errors.Is(err, repo.ErrUserNotFound) {
-			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "user not found", err)
+			auth.ComparePassword(dummyPasswordHash(), arg.Password)
+			us.lockout.failed(ctx, arg, false)
+			us.loginFailed(ctx, arg.Username, arg.ClientIP, arg.UserAgent, "unknown_user")
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid username or password", err)
 		}
 		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error:", err)
 	}
 
 	if !auth.ComparePassword([]byte(user.HashedPassword), arg.Password) {
-		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "wrong password", nil)
+		us.lockout.failed(ctx, arg, true)
+		us.loginFailed(ctx, arg.Username, arg.ClientIP, arg.UserAgent, "wrong_password")
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid username or password", nil)
 	}
+	us.upgradePasswordHash(ctx, user, arg.Password)
+
+	return us.completeLogin(ctx, user, arg.ClientIP, arg.UserAgent)
+}
 
-	accessToken, accessPayload, err := us.token.GenerateToken(user.Username, us.config.ACCESS_TOKEN_DURATATION)
+// completeLogin starts a session for a user who proved the first factor, or asks for the second
+// one when MFA is enabled.
+func (us *userService) completeLogin(ctx context.Context, user *entity.User, clientIP, userAgent string) (*AuthResult, error) {
+	enabled, err := us.mfa.MFAEnabled(ctx, user.Username)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if enabled {
+		mfaToken, mfaPayload, err := us.token.GenerateToken(user.Username, mfaChallengeDuration, auth.WithPurpose(auth.PurposeMFA))
+		if err != nil {
+			return nil, errorutil.NewAppError(errorutil.ErrInternal, "token generation failed: %w", err)
+		}
+		return &AuthResult{
+			MFARequired:       true,
+			MFAToken:          mfaToken,
+			MFATokenExpiresAt: mfaPayload.ExpiresAt.Time,
+		}, nil
+	}
+
+	//with a second factor pending the failures stay, so logging in again doesn't reset code guesses
+	us.lockout.succeeded(ctx, user.Username)
+	return us.startSession(ctx, user, clientIP, userAgent, auth.AssurancePassword)
+}
+
+// checkLockout refuses a login attempt against a locked username or from a locked ip.
+func (us *userService) checkLockout(ctx context.Context, username, clientIP, userAgent string) error {
+	if err := us.lockout.check(ctx, username, clientIP); err != nil {
+		if errors.Is(err, errLoginLocked) {
+			us.loginFailed(ctx, username, clientIP, userAgent, "locked")
+			return errorutil.NewAppError(errorutil.ErrTooManyRequests, "too many failed login attempts, try again later", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return nil
+}
+
+// loginFailed records a failed login in the audit log. There is nothing else to write, so it
+// is stored on its own and a failure to store it only gets logged.
+func (us *userService) loginFailed(ctx context.Context, username, clientIP, userAgent, reason string) {
+	if us.audit == nil {
+		return
+	}
+	event := audit.NewEvent(ctx, audit.ActionLoginFailed, username, audit.UserSubject(username))
+	event.ClientIP, event.UserAgent = clientIP, userAgent
+	event.Details = map[string]string{"reason": reason}
+	if err := us.audit.RecordAuditEvent(ctx, event); err != nil {
+		logger.FromCTX(ctx, us.logger).Error().Err(err).Str("username", username).Msg("failed to record failed login")
+	}
+}
+
+// upgradePasswordHash rehashes a just verified password when its stored hash uses bcrypt or
+// older argon2id settings. It is best effort: the login goes ahead if it fails.
+func (us *userService) upgradePasswordHash(ctx context.Context, user *entity.User, password string) {
+	if !auth.PasswordNeedsRehash([]byte(user.HashedPassword)) {
+		return
+	}
+	log := logger.FromCTX(ctx, us.logger)
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		log.Error().Err(err).Str("username", user.Username).Msg("failed to rehash password")
+		return
+	}
+	if err := us.UserRepo.RehashPassword(ctx, user.Username, user.HashedPassword, hash); err != nil {
+		log.Error().Err(err).Str("username", user.Username).Msg("failed to store rehashed password")
+		return
+	}
+	user.HashedPassword = hash
+}
+
+type VerifyMFAInput struct {
+	MFAToken  string
+	Code      string
+	ClientIP  string
+	UserAgent string
+}
+
+// VerifyMFA completes a login that needed a second factor: it exchanges the MFA token from Login
+// and a TOTP or recovery code for a session. Each MFA token completes one login, and wrong
+// codes count toward the lockout like wrong passwords.
+func (us *userService) VerifyMFA(ctx context.Context, arg VerifyMFAInput) (_ *AuthResult, err error) {
+	ctx, span := tracer.Start(ctx, "userService.VerifyMFA")
+	defer func() { tracing.End(span, err) }()
+
+	v := validator.NewValidator()
+	v.Check(arg.MFAToken != "", "mfa_token", "cannot be empty")
+	v.Check(arg.Code != "", "code", "cannot be empty")
+	if !v.Valid() {
+		return nil, v
+	}
+
+	payload, err := us.token.VerifyToken(arg.MFAToken)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired mfa token", err)
+	}
+	if payload.Purpose != auth.PurposeMFA {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired mfa token", errNotMFAToken)
+	}
+	tokenID, err := uuid.Parse(payload.ID)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired mfa token", err)
+	}
+	if err := us.checkLockout(ctx, payload.Username, arg.ClientIP, arg.UserAgent); err != nil {
+		return nil, err
+	}
+
+	if err := us.mfa.VerifyCode(ctx, payload.Username, arg.Code); err != nil {
+		if _, ok := err.(*errorutil.AppError); ok {
+			us.lockout.failed(ctx, Logininput{Username: payload.Username, ClientIP: arg.ClientIP, UserAgent: arg.UserAgent}, true)
+			us.loginFailed(ctx, payload.Username, arg.ClientIP, arg.UserAgent, "wrong_mfa_code")
+			return nil, err
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if err := us.SessionRepo.SpendMFAToken(ctx, tokenID, payload.Username, payload.ExpiresAt.Time); err != nil {
+		if errors.Is(err, repo.ErrMFATokenSpent) {
+			us.loginFailed(ctx, payload.Username, arg.ClientIP, arg.UserAgent, "mfa_token_reused")
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired mfa token", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	us.lockout.succeeded(ctx, payload.Username)
+
+	user, err := us.UserRepo.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid or expired mfa token", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return us.startSession(ctx, user, arg.ClientIP, arg.UserAgent, auth.AssuranceMFA)
+}
+
+// startSession issues the access and refresh tokens for a fully authenticated login.
+func (us *userService) startSession(ctx context.Context, user *entity.User, clientIP, userAgent string, assurance auth.Assurance) (*AuthResult, error) {
+	recognized, err := us.devices.recognize(ctx, user.Username, clientIP, userAgent)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if !recognized {
+		return &AuthResult{DeviceConfirmationRequired: true}, nil
+	}
+
+	authTime, role := auth.WithAuthTime(time.Now(), assurance), auth.WithRole(auth.Role(user.Role))
+	refreshToken, refreshpayload, err := us.token.GenerateToken(user.Username, us.config.REFRESH_TOKEN_DURATION, authTime, role)
 	if err != nil {
 		return nil, errorutil.NewAppError(errorutil.ErrInternal, "token generation failed: %w", err)
 	}
+	sessionID := uuid.MustParse(refreshpayload.ID)
 
-	refreshToken, refreshpayload, err := us.token.GenerateToken(user.Username, us.config.REFRESH_TOKEN_DURATION)
+	accessToken, accessPayload, err := us.token.GenerateToken(user.Username, us.config.ACCESS_TOKEN_DURATATION, auth.WithSessionID(sessionID), authTime, role)
 	if err != nil {
 		return nil, errorutil.NewAppError(errorutil.ErrInternal, "token generation failed: %w", err)
 	}
 
+	event := audit.NewEvent(ctx, audit.ActionLogin, user.Username, audit.SessionSubject(sessionID))
+	event.ClientIP, event.UserAgent = clientIP, userAgent
+	event.Details = map[string]string{"assurance": assurance.String()}
 	session, err := us.SessionRepo.CreateSession(ctx, entity.Session{
-		ID:           uuid.MustParse(refreshpayload.ID),
+		ID:           sessionID,
 		Username:     user.Username,
 		RefreshToken: refreshToken,
-		ClientIp:     arg.ClientIP,
-		UserAgent:    arg.UserAgent,
+		ClientIp:     clientIP,
+		UserAgent:    userAgent,
 		IsBlocked:    false,
 		ExpiresAt:    refreshpayload.ExpiresAt.Time,
-	})
+	}, event)
 	if err != nil {
 		return nil, errorutil.NewAppError(errorutil.ErrInternal, "failed to create session", err)
 	}
@@ -140,21 +401,107 @@ func (us *userService) Login(ctx context.Context, arg Logininput) (*AuthResult,
 		RefreshTokenExpiresAt: refreshpayload.ExpiresAt.Time,
 		User:                  user,
 	}, nil
+}
 
+type ReauthenticateInput struct {
+	Password string
+	Code     string
 }
 
-type RenewAccessToken struct {
+type Reauthentication struct {
 	AccessToken          string
 	AccessTokenExpiresAt time.Time
+	AuthTime             time.Time
+}
+
+// Reauthenticate checks the password or a TOTP/recovery code again for a signed-in user and
+// returns an access token for the same session with a fresh auth time, satisfying step-up
+// requirements. The session's refresh token is unchanged, so renewed tokens fall back to the
+// login's auth time. Wrong answers count toward the username's lockout.
+func (us *userService) Reauthenticate(ctx context.Context, payload *auth.Payload, arg ReauthenticateInput) (_ *Reauthentication, err error) {
+	ctx, span := tracer.Start(ctx, "userService.Reauthenticate")
+	defer func() { tracing.End(span, err) }()
+
+	v := validator.NewValidator()
+	v.Check((arg.Password == "") != (arg.Code == ""), "password", "provide either a password or a code")
+	if !v.Valid() {
+		return nil, v
+	}
+	sessionID, err := uuid.Parse(payload.SessionID)
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid session", errNoSessionID)
+	}
+	client := audit.ClientFromContext(ctx)
+	if err := us.checkLockout(ctx, payload.Username, client.IP, client.UserAgent); err != nil {
+		return nil, err
+	}
+	attempt := Logininput{Username: payload.Username, ClientIP: client.IP, UserAgent: client.UserAgent}
+
+	assurance := auth.AssurancePassword
+	if arg.Code != "" {
+		if err := us.mfa.VerifyCode(ctx, payload.Username, arg.Code); err != nil {
+			if _, ok := err.(*errorutil.AppError); ok {
+				us.lockout.failed(ctx, attempt, true)
+				us.loginFailed(ctx, payload.Username, client.IP, client.UserAgent, "wrong_mfa_code")
+				return nil, err
+			}
+			return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+		}
+		assurance = auth.AssuranceMFA
+	} else {
+		user, err := us.UserRepo.GetUser(ctx, payload.Username)
+		if err != nil {
+			return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+		}
+		if !auth.ComparePassword([]byte(user.HashedPassword), arg.Password) {
+			us.lockout.failed(ctx, attempt, true)
+			us.loginFailed(ctx, payload.Username, client.IP, client.UserAgent, "wrong_password")
+			return nil, errorutil.NewAppError(errorutil.ErrUnauthorized, "wrong password", nil)
+		}
+	}
+	us.lockout.succeeded(ctx, payload.Username)
+
+	accessToken, accessPayload, err := us.token.GenerateToken(payload.Username, us.config.ACCESS_TOKEN_DURATATION,
+		auth.WithSessionID(sessionID), auth.WithAuthTime(time.Now(), assurance), auth.WithRole(payload.Role))
+	if err != nil {
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return &Reauthentication{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiresAt.Time,
+		AuthTime:             accessPayload.AuthTime.Time,
+	}, nil
+}
+
+type RenewAccessToken struct {
+	SessionID             uuid.UUID
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
 }
 
-func (us *userService) RenewAccessToken(ctx context.Context, refreshToken string) (RenewAccessToken, error) {
+// RenewAccessToken exchanges a refresh token for a new access token and a new refresh token.
+// Each refresh token works once: presenting a rotated-out token again means it was stolen,
+// so every session descending from the same login is blocked.
+func (us *userService) RenewAccessToken(ctx context.Context, refreshToken string) (_ RenewAccessToken, err error) {
+	ctx, span := tracer.Start(ctx, "userService.RenewAccessToken")
+	defer func() { tracing.End(span, err) }()
+
 	refreshPayload, err := us.token.VerifyToken(refreshToken)
 	if err != nil {
 		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "session has expired", err)
 	}
+	//an application's refresh tokens are renewed at the oauth token endpoint, which keeps their scopes
+	if refreshPayload.Purpose != "" || refreshPayload.ClientID != "" {
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid refresh token", errNotRefreshToken)
+	}
+	sessionID, err := uuid.Parse(refreshPayload.ID)
+	if err != nil {
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "invalid refresh token", err)
+	}
 
-	session, err := us.SessionRepo.GetSession(ctx, uuid.MustParse(refreshPayload.ID))
+	session, err := us.SessionRepo.GetSession(ctx, sessionID)
 	if err != nil {
 		switch {
 		case errors.Is(err, repo.ErrSessionNotFound):
@@ -164,27 +511,85 @@ func (us *userService) RenewAccessToken(ctx context.Context, refreshToken string
 	}
 
 	if session.IsSessionBlocked() {
-		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "session is blocked", err)
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "session is blocked", errSessionBlocked)
 	}
 	if session.UsernameCheck(refreshPayload.Username) {
-		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "incorrect session user", err)
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "incorrect session user", errSessionMismatch)
 	}
 
 	if session.RefreshTokenCheck(refreshToken) {
-		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "mismatched session token", err)
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "mismatched session token", errSessionMismatch)
 	}
 
 	if session.IsSessionExpired() {
-		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "expired session", err)
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "expired session", errSessionExpired)
+	}
+
+	if session.IsRotated() {
+		return RenewAccessToken{}, us.refreshTokenReused(ctx, session)
+	}
+
+	//the role may have changed since login, so it comes from the user rather than the old token
+	user, err := us.UserRepo.GetUser(ctx, session.Username)
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrUnauthorized, "incorrect session user", err)
+		}
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
 	}
+	role := auth.WithRole(auth.Role(user.Role))
 
-	accessToken, accessPayload, err := us.token.GenerateToken(refreshPayload.Username, us.config.ACCESS_TOKEN_DURATATION)
+	newRefreshToken, newRefreshPayload, err := us.token.GenerateToken(session.Username, us.config.REFRESH_TOKEN_DURATION,
+		auth.WithAuthenticationOf(refreshPayload), role)
+	if err != nil {
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	next, err := us.SessionRepo.RotateSession(ctx, session.ID, entity.Session{
+		ID:           uuid.MustParse(newRefreshPayload.ID),
+		Username:     session.Username,
+		RefreshToken: newRefreshToken,
+		UserAgent:    session.UserAgent,
+		ClientIp:     session.ClientIp,
+		ExpiresAt:    newRefreshPayload.ExpiresAt.Time,
+		FamilyID:     session.FamilyID,
+		ParentID:     session.ID,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrSessionReused) {
+			//lost a race with another renewal using the same token
+			return RenewAccessToken{}, us.refreshTokenReused(ctx, session)
+		}
+		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	accessToken, accessPayload, err := us.token.GenerateToken(session.Username, us.config.ACCESS_TOKEN_DURATATION,
+		auth.WithSessionID(next.ID), auth.WithAuthenticationOf(refreshPayload), role)
 	if err != nil {
 		return RenewAccessToken{}, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
 	}
 
 	return RenewAccessToken{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessPayload.ExpiresAt.Time,
+		SessionID:             next.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiresAt.Time,
+		RefreshToken:          newRefreshToken,
+		RefreshTokenExpiresAt: next.ExpiresAt,
 	}, nil
 }
+
+// refreshTokenReused blocks the session family of a reused refresh token and records the incident.
+func (us *userService) refreshTokenReused(ctx context.Context, session *entity.Session) error {
+	log := logger.FromCTX(ctx, us.logger)
+	if err := us.SessionRepo.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		log.Error().Err(err).Str("family_id", session.FamilyID.String()).Msg("failed to block session family")
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	log.Warn().
+		Str("security_event", "refresh_token_reuse").
+		Str("username", session.Username).
+		Str("session_id", session.ID.String()).
+		Str("family_id", session.FamilyID.String()).
+		Msg("rotated refresh token presented again, blocked session family")
+	return errorutil.NewAppError(errorutil.ErrUnauthorized, "session is blocked", errRefreshTokenReused)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/tracing"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+)
+
+// verifyEmailResendInterval is how long a user waits between verification emails.
+const verifyEmailResendInterval = time.Minute
+
+type VerifyEmailRepository interface {
+	GetLastVerifyEmail(ctx context.Context, username string) (*entity.VerifyEmail, error)
+	VerifyEmail(ctx context.Context, emailID int64, secretCodeHash string) (*entity.VerifyEmail, error)
+}
+
+type VerifyEmailQueue interface {
+	JobVerifyEmail(ctx context.Context, payload *jobs.VerifyEmailPayload) error
+}
+
+type VerifyEmailService struct {
+	repo  VerifyEmailRepository
+	users UserRepository
+	tasks VerifyEmailQueue
+}
+
+func NewVerifyEmailService(vr VerifyEmailRepository, ur UserRepository, tasks VerifyEmailQueue) *VerifyEmailService {
+	return &VerifyEmailService{repo: vr, users: ur, tasks: tasks}
+}
+
+// VerifyEmail consumes a code from a verification email and marks the address verified.
+func (vs *VerifyEmailService) VerifyEmail(ctx context.Context, emailID int64, secretCode string) (_ *entity.VerifyEmail, err error) {
+	ctx, span := tracer.Start(ctx, "VerifyEmailService.VerifyEmail")
+	defer func() { tracing.End(span, err) }()
+
+	if emailID <= 0 || secretCode == "" {
+		return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "email_id and secret_code are required", nil)
+	}
+	verifyEmail, err := vs.repo.VerifyEmail(ctx, emailID, auth.HashSecret(secretCode))
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidVerifyCode) {
+			return nil, errorutil.NewAppError(errorutil.ErrBadRequest, "invalid or expired verification code", err)
+		}
+		return nil, errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	return verifyEmail, nil
+}
+
+// ResendVerifyEmail queues a new verification email, at most one per verifyEmailResendInterval.
+func (vs *VerifyEmailService) ResendVerifyEmail(ctx context.Context, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "VerifyEmailService.ResendVerifyEmail")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := vs.users.GetUser(ctx, username)
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return errorutil.NewAppError(errorutil.ErrNotFound, "user not found", err)
+		}
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+	if user.IsEmailVerified {
+		return errorutil.NewAppError(errorutil.ErrBadRequest, "email is already verified", nil)
+	}
+
+	last, err := vs.repo.GetLastVerifyEmail(ctx, username)
+	switch {
+	case err == nil:
+		if time.Since(last.CreatedAt) < verifyEmailResendInterval {
+			return errorutil.NewAppError(errorutil.ErrTooManyRequests, "verification email sent recently, try again later", nil)
+		}
+	case !errors.Is(err, repo.ErrVerifyEmailNotFound):
+		return errorutil.NewAppError(errorutil.ErrInternal, "internal server error", err)
+	}
+
+	if err := vs.tasks.JobVerifyEmail(ctx, &jobs.VerifyEmailPayload{Username: username}); err != nil {
+		return errorutil.NewAppError(errorutil.ErrInternal, "failed to queue verification email", err)
+	}
+	return nil
+}
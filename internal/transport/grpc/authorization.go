@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -35,13 +37,28 @@ func (us *UserHandler) authenication(ctx context.Context) (*auth.Payload, error)
 		return nil, fmt.Errorf("unsupported authorization type: %s", authType)
 	}
 
-	payload, err := us.jwtMaker.VerifyToken(params[1])
+	payload, err := auth.VerifyTokenContext(ctx, us.jwtMaker, params[1])
 	if err != nil {
 		if errors.Is(err, auth.ErrExpired) {
 			return nil, fmt.Errorf("access token expired")
 		}
+		if errors.Is(err, auth.ErrSessionRevoked) {
+			return nil, fmt.Errorf("session revoked")
+		}
 		return nil, fmt.Errorf("invalid access token")
 	}
+	if method := rpcMethod(ctx); !payload.AllowsScope(method) {
+		return nil, fmt.Errorf("token is not allowed to call %s", method)
+	}
 
 	return payload, nil
 }
+
+// rpcMethod is the full method being called, for grpc and for gateway requests alike.
+func rpcMethod(ctx context.Context) string {
+	if method, ok := runtime.RPCMethod(ctx); ok {
+		return method
+	}
+	method, _ := grpc.Method(ctx)
+	return method
+}
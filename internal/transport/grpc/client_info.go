@@ -0,0 +1,20 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/netutil"
+	"google.golang.org/grpc"
+)
+
+// ClientInfoInterceptor stores the caller's ip and user agent in the context for the audit log,
+// lockouts and rate limits. x-forwarded-for is only read from proxies.
+func ClientInfoInterceptor(proxies netutil.Proxies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (any, error) {
+		md := extractMetadata(ctx)
+		ctx = audit.WithClient(ctx, audit.Client{IP: clientIP(ctx, proxies), UserAgent: md.UserAgent})
+		return handler(ctx, req)
+	}
+}
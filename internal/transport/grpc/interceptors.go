@@ -7,6 +7,8 @@ import (
 	"runtime/debug"
 	"time"
 
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/metrics"
 	"github.com/rs/zerolog"
 
 	"google.golang.org/grpc"
@@ -19,7 +21,7 @@ func UnaryRecoverPanicInterceptor(log *zerolog.Logger) grpc.UnaryServerIntercept
 	) (resp any, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Error().
+				logger.FromCTX(ctx, log).Error().
 					Str("panic", fmt.Sprintf("%v", r)).
 					Bytes("stack", debug.Stack()).
 					Msg("panic recoverd")
@@ -30,17 +32,18 @@ func UnaryRecoverPanicInterceptor(log *zerolog.Logger) grpc.UnaryServerIntercept
 	}
 }
 
-func LoggingInterceptor(log *zerolog.Logger) grpc.UnaryServerInterceptor {
+func LoggingInterceptor(log *zerolog.Logger, m *metrics.Metrics) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
 	) (resp any, err error) {
 		start := time.Now()
 		resp, err = handler(ctx, req)
 
-		logger := log.Info()
+		log := logger.FromCTX(ctx, log)
+		event := log.Info()
 		statusCode := codes.OK
 		if err != nil {
 			st, ok := status.FromError(err)
-			logger = log.Error().Err(errors.New(st.Message()))
+			event = log.Error().Err(errors.New(st.Message()))
 			if ok {
 				statusCode = st.Code()
 			} else {
@@ -49,8 +52,10 @@ func LoggingInterceptor(log *zerolog.Logger) grpc.UnaryServerInterceptor {
 		}
 
 		defer func() {
-			logger.
-				Dur("duration_ms", time.Since(start)).
+			duration := time.Since(start)
+			m.ObserveGRPC(info.FullMethod, statusCode, duration)
+			event.
+				Dur("duration_ms", duration).
 				Str("method", info.FullMethod).
 				Str("grpc_type", "unary").
 				Int("status_code", int(statusCode)).
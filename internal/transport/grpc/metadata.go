@@ -3,8 +3,8 @@ package grpctransport
 import (
 	"context"
 	"errors"
-	"net"
 
+	"github.com/0xOnah/bank/internal/sdk/audit"
 	"github.com/0xOnah/bank/internal/sdk/netutil"
 	"github.com/0xOnah/bank/internal/sdk/validator"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -26,7 +26,7 @@ type Metadata struct {
 }
 
 func extractMetadata(ctx context.Context) *Metadata {
-	mdt := &Metadata{}
+	mdt := &Metadata{ClientIP: audit.ClientFromContext(ctx).IP}
 	md, ok := metadata.FromIncomingContext(ctx)
 	if ok {
 		if ua := md[grpcGateWayUserAgent]; len(ua) > 0 {
@@ -36,28 +36,29 @@ func extractMetadata(ctx context.Context) *Metadata {
 		if ua := md[userAgent]; len(ua) > 0 {
 			mdt.UserAgent = ua[0]
 		}
-
-		ui := md[xForwardedFor]
-		if len(ui) > 0 {
-			//prevent storing private ip address as they are no use to us
-			if !netutil.IsPrivate(ui[0]) {
-				mdt.ClientIP = ui[0]
-			}
-		}
 	}
 
+	//set by ClientInfoInterceptor, or the gateway's ClientInfo middleware for proxied calls
 	if mdt.ClientIP == "" {
-		peer, ok := peer.FromContext(ctx)
-		if ok {
-			host, _, err := net.SplitHostPort(peer.Addr.String())
-			if err == nil && !netutil.IsPrivate(host) {
-				mdt.ClientIP = peer.Addr.String()
-			}
-		}
+		mdt.ClientIP = netutil.Host(peerAddr(ctx))
 	}
 	return mdt
 }
 
+// clientIP returns the caller's ip, read from x-forwarded-for only when the peer is one of proxies.
+func clientIP(ctx context.Context, proxies netutil.Proxies) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return proxies.ClientIP(peerAddr(ctx), md[xForwardedFor])
+}
+
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return p.Addr.String()
+}
+
 func MapValidationErrors(err error) error {
 	var violations []*errdetails.BadRequest_FieldViolation
 	var validatorErr *validator.Validator
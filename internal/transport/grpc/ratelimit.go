@@ -0,0 +1,53 @@
+package grpctransport
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/ratelimit"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const retryAfter = "retry-after"
+
+// usernameRequest is implemented by requests naming the account they act on, e.g. LoginUser,
+// so guesses against one account are throttled across ips.
+type usernameRequest interface {
+	GetUsername() string
+}
+
+// mfaTokenRequest is implemented by second factor requests, which name their account with the
+// MFA token login issued.
+type mfaTokenRequest interface {
+	GetMfaToken() string
+}
+
+// RateLimitInterceptor rejects calls over the enforcer's limits with ResourceExhausted,
+// a RetryInfo detail and a retry-after header. It fails open when the limiter errors.
+func RateLimitInterceptor(enforcer *ratelimit.Enforcer, tokens auth.Authenticator, log *zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (any, error) {
+		subjects := []ratelimit.Subject{ratelimit.IP(extractMetadata(ctx).ClientIP)}
+		switch r := req.(type) {
+		case usernameRequest:
+			subjects = append(subjects, ratelimit.User(r.GetUsername()))
+		case mfaTokenRequest:
+			subjects = append(subjects, ratelimit.User(auth.MFATokenUsername(tokens, r.GetMfaToken())))
+		}
+
+		result, err := enforcer.Allow(ctx, info.FullMethod, subjects...)
+		if err != nil {
+			logger.FromCTX(ctx, log).Error().Err(err).Str("method", info.FullMethod).Msg("rate limiter unavailable")
+			return handler(ctx, req)
+		}
+		if !result.Allowed {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfter, strconv.Itoa(result.RetryAfterSeconds())))
+			return nil, result.Status().Err()
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,65 @@
+package grpctransport
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDInterceptor accepts the caller's x-request-id (or generates one), echoes it in the
+// response header, attaches it to errors and stores a logger tagged with it in the context.
+// It must run before the interceptors that log.
+func RequestIDInterceptor(log *zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (any, error) {
+		var incoming string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ids := md.Get(requestid.MetadataKey); len(ids) > 0 {
+				incoming = ids[0]
+			}
+		}
+		id := requestid.FromIncoming(incoming)
+
+		reqLog := log.With().Str("request_id", id).Logger()
+		ctx = requestid.NewContext(ctx, id)
+		ctx = logger.WithCTXLogger(ctx, &reqLog)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, id))
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, WithRequestID(err, id)
+		}
+		return resp, nil
+	}
+}
+
+// WithRequestID adds id to the status details of err so clients can quote it when reporting a failure.
+func WithRequestID(err error, id string) error {
+	st := status.Convert(err)
+	for _, detail := range st.Details() {
+		if _, ok := detail.(*errdetails.RequestInfo); ok {
+			return err
+		}
+	}
+	withID, detailErr := st.WithDetails(&errdetails.RequestInfo{RequestId: id})
+	if detailErr != nil {
+		return err
+	}
+	return withID.Err()
+}
+
+// GatewayErrorHandler is the default gateway error handler with the request id added to the error body.
+func GatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if id := requestid.FromContext(r.Context()); id != "" {
+		err = WithRequestID(err, id)
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
@@ -0,0 +1,176 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/pb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toPbAccountConsent(c *entity.AccountConsent) *pb.AccountConsent {
+	return &pb.AccountConsent{
+		ConsentId:   c.ID.String(),
+		ClientId:    c.ClientID,
+		AccountIds:  c.AccountIDs,
+		Permissions: c.Permissions,
+		Status:      c.Status(),
+		CreatedAt:   timestamppb.New(c.CreatedAt),
+		ExpiresAt:   timestamppb.New(c.ExpiresAt),
+	}
+}
+
+func (uh *UserHandler) CreateAccountConsent(ctx context.Context, req *pb.CreateAccountConsentRequest) (*pb.CreateAccountConsentResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err := uh.stepUp.Check(auth.OpShareAccounts, authPayload); err != nil {
+		return nil, stepUpStatus(err)
+	}
+	if req.GetExpiresAt() == nil {
+		return nil, status.Error(codes.InvalidArgument, "expires_at must be provided")
+	}
+
+	consent, err := uh.consents.CreateAccountConsent(ctx, authPayload, service.CreateAccountConsentInput{
+		ClientID:    req.GetClientId(),
+		AccountIDs:  req.GetAccountIds(),
+		Permissions: req.GetPermissions(),
+		ExpiresAt:   req.GetExpiresAt().AsTime(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.CreateAccountConsentResponse{Consent: toPbAccountConsent(consent)}, nil
+}
+
+func (uh *UserHandler) ListAccountConsents(ctx context.Context, req *pb.ListAccountConsentsRequest) (*pb.ListAccountConsentsResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	consents, err := uh.consents.ListAccountConsents(ctx, authPayload)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	resp := &pb.ListAccountConsentsResponse{Consents: make([]*pb.AccountConsent, 0, len(consents))}
+	for _, c := range consents {
+		resp.Consents = append(resp.Consents, toPbAccountConsent(c))
+	}
+	return resp, nil
+}
+
+func (uh *UserHandler) RevokeAccountConsent(ctx context.Context, req *pb.RevokeAccountConsentRequest) (*pb.RevokeAccountConsentResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	consentID, err := uuid.Parse(req.GetConsentId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid consent id")
+	}
+	if err := uh.consents.RevokeAccountConsent(ctx, authPayload, consentID); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RevokeAccountConsentResponse{}, nil
+}
+
+func (uh *UserHandler) GetAccountConsent(ctx context.Context, req *pb.GetAccountConsentRequest) (*pb.GetAccountConsentResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	consentID, err := uuid.Parse(req.GetConsentId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid consent id")
+	}
+	consent, err := uh.consents.GetAccountConsent(ctx, authPayload, consentID)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.GetAccountConsentResponse{Consent: toPbAccountConsent(consent)}, nil
+}
+
+func (uh *UserHandler) ListConsentedAccounts(ctx context.Context, req *pb.ListConsentedAccountsRequest) (*pb.ListConsentedAccountsResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	consentID, err := uuid.Parse(req.GetConsentId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid consent id")
+	}
+	accounts, err := uh.consents.ListConsentedAccounts(ctx, authPayload, consentID)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	//balances need their own permission, so only say which accounts there are
+	resp := &pb.ListConsentedAccountsResponse{Accounts: make([]*pb.ConsentedAccount, 0, len(accounts))}
+	for _, a := range accounts {
+		resp.Accounts = append(resp.Accounts, &pb.ConsentedAccount{
+			AccountId: a.ID,
+			Currency:  a.Currency,
+		})
+	}
+	return resp, nil
+}
+
+func (uh *UserHandler) GetConsentedAccountBalance(ctx context.Context, req *pb.GetConsentedAccountBalanceRequest) (*pb.GetConsentedAccountBalanceResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	consentID, err := uuid.Parse(req.GetConsentId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid consent id")
+	}
+	account, err := uh.consents.GetConsentedAccountBalance(ctx, authPayload, consentID, req.GetAccountId())
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.GetConsentedAccountBalanceResponse{
+		AccountId: account.ID,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+	}, nil
+}
+
+func (uh *UserHandler) ListConsentedAccountTransactions(ctx context.Context, req *pb.ListConsentedAccountTransactionsRequest) (*pb.ListConsentedAccountTransactionsResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	consentID, err := uuid.Parse(req.GetConsentId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid consent id")
+	}
+	entries, err := uh.consents.ListConsentedTransactions(ctx, authPayload, service.ListConsentedTransactionsInput{
+		ConsentID: consentID,
+		AccountID: req.GetAccountId(),
+		PageID:    req.GetPageId(),
+		PageSize:  req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	resp := &pb.ListConsentedAccountTransactionsResponse{Transactions: make([]*pb.ConsentedTransaction, 0, len(entries))}
+	for _, e := range entries {
+		resp.Transactions = append(resp.Transactions, &pb.ConsentedTransaction{
+			TransactionId: e.ID,
+			Amount:        e.Amount,
+			CreatedAt:     timestamppb.New(e.CreatedAt),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package grpctransport
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (uh *UserHandler) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	var since, until time.Time
+	if req.Since != nil {
+		since = req.GetSince().AsTime()
+	}
+	if req.Until != nil {
+		until = req.GetUntil().AsTime()
+	}
+	events, err := uh.audit.ListAuditEvents(ctx, authPayload, entity.AuditEventFilter{
+		Actor:    req.GetActor(),
+		Subject:  req.GetSubject(),
+		Action:   req.GetAction(),
+		Since:    since,
+		Until:    until,
+		BeforeID: req.GetBeforeId(),
+		PageSize: int(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	resp := &pb.ListAuditEventsResponse{Events: make([]*pb.AuditEvent, 0, len(events))}
+	for _, e := range events {
+		resp.Events = append(resp.Events, &pb.AuditEvent{
+			Id:        e.ID,
+			Action:    e.Action,
+			Actor:     e.Actor,
+			Subject:   e.Subject,
+			ClientIp:  e.ClientIP,
+			UserAgent: e.UserAgent,
+			RequestId: e.RequestID,
+			Details:   e.Details,
+			CreatedAt: timestamppb.New(e.CreatedAt),
+		})
+	}
+	return resp, nil
+}
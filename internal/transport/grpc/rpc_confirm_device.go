@@ -0,0 +1,15 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/pb"
+)
+
+// ConfirmDevice is where the link in the new device email points, so it needs no token.
+func (uh *UserHandler) ConfirmDevice(ctx context.Context, req *pb.ConfirmDeviceRequest) (*pb.ConfirmDeviceResponse, error) {
+	if err := uh.us.ConfirmDevice(ctx, req.GetToken()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.ConfirmDeviceResponse{}, nil
+}
@@ -0,0 +1,30 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/pb"
+)
+
+// RequestLoginLink answers the same way whether or not the email belongs to an account.
+func (uh *UserHandler) RequestLoginLink(ctx context.Context, req *pb.RequestLoginLinkRequest) (*pb.RequestLoginLinkResponse, error) {
+	if err := uh.us.RequestLoginLink(ctx, req.GetEmail()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RequestLoginLinkResponse{}, nil
+}
+
+// ConsumeLoginLink signs in the device that presents the link, not the one that asked for it.
+func (uh *UserHandler) ConsumeLoginLink(ctx context.Context, req *pb.ConsumeLoginLinkRequest) (*pb.LoginUserResponse, error) {
+	metadata := extractMetadata(ctx)
+	result, err := uh.us.ConsumeLoginLink(ctx, service.ConsumeLoginLinkInput{
+		Token:     req.GetToken(),
+		ClientIP:  metadata.ClientIP,
+		UserAgent: metadata.UserAgent,
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return loginUserResponse(result), nil
+}
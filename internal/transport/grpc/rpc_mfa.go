@@ -0,0 +1,65 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (uh *UserHandler) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.LoginUserResponse, error) {
+	metadata := extractMetadata(ctx)
+	result, err := uh.us.VerifyMFA(ctx, service.VerifyMFAInput{
+		MFAToken:  req.GetMfaToken(),
+		Code:      req.GetCode(),
+		ClientIP:  metadata.ClientIP,
+		UserAgent: metadata.UserAgent,
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return loginUserResponse(result), nil
+}
+
+func (uh *UserHandler) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	enrollment, err := uh.mfa.EnrollTOTP(ctx, authPayload)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.EnrollTOTPResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningUri: enrollment.ProvisioningURI,
+	}, nil
+}
+
+func (uh *UserHandler) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	recoveryCodes, err := uh.mfa.ConfirmTOTP(ctx, authPayload, req.GetCode())
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (uh *UserHandler) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if err := uh.mfa.DisableTOTP(ctx, authPayload, req.GetCode()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.DisableTOTPResponse{}, nil
+}
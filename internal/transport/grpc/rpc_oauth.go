@@ -0,0 +1,181 @@
+package grpctransport
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (uh *UserHandler) CreateOAuthClient(ctx context.Context, req *pb.CreateOAuthClientRequest) (*pb.CreateOAuthClientResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	client, err := uh.oauth.CreateOAuthClient(ctx, authPayload, service.CreateOAuthClientInput{
+		Name:         req.GetName(),
+		RedirectURIs: req.GetRedirectUris(),
+		Scopes:       req.GetScopes(),
+		GrantTypes:   req.GetGrantTypes(),
+		Public:       req.GetPublic(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.CreateOAuthClientResponse{
+		Client: &pb.OAuthClient{
+			ClientId:     client.ID,
+			Name:         client.Name,
+			RedirectUris: client.RedirectURIs,
+			Scopes:       client.Scopes,
+			GrantTypes:   client.GrantTypes,
+			Public:       client.Public(),
+			CreatedBy:    client.CreatedBy,
+			CreatedAt:    timestamppb.New(client.CreatedAt),
+		},
+		ClientSecret: client.Secret,
+	}, nil
+}
+
+func (uh *UserHandler) RevokeOAuthClient(ctx context.Context, req *pb.RevokeOAuthClientRequest) (*pb.RevokeOAuthClientResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if err := uh.oauth.RevokeOAuthClient(ctx, authPayload, req.GetClientId()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RevokeOAuthClientResponse{}, nil
+}
+
+func (uh *UserHandler) AuthorizeOAuthClient(ctx context.Context, req *pb.AuthorizeOAuthClientRequest) (*pb.AuthorizeOAuthClientResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	//a consent hands the client a long lived refresh token, so it takes a recent login
+	if err := uh.stepUp.Check(auth.OpAuthorizeClient, authPayload); err != nil {
+		return nil, stepUpStatus(err)
+	}
+
+	authorization, err := uh.oauth.AuthorizeOAuthClient(ctx, authPayload, service.AuthorizeOAuthClientInput{
+		ClientID:            req.GetClientId(),
+		RedirectURI:         req.GetRedirectUri(),
+		Scopes:              strings.Fields(req.GetScope()),
+		State:               req.GetState(),
+		CodeChallenge:       req.GetCodeChallenge(),
+		CodeChallengeMethod: req.GetCodeChallengeMethod(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.AuthorizeOAuthClientResponse{
+		Code:        authorization.Code,
+		RedirectUri: authorization.RedirectURI,
+	}, nil
+}
+
+// OAuthToken is the token endpoint. Clients authenticate with their id and secret in the request,
+// not a bearer token.
+func (uh *UserHandler) OAuthToken(ctx context.Context, req *pb.OAuthTokenRequest) (*pb.OAuthTokenResponse, error) {
+	metadata := extractMetadata(ctx)
+	token, err := uh.oauth.Token(ctx, service.OAuthTokenInput{
+		GrantType:    req.GetGrantType(),
+		ClientID:     req.GetClientId(),
+		ClientSecret: req.GetClientSecret(),
+		Code:         req.GetCode(),
+		RedirectURI:  req.GetRedirectUri(),
+		CodeVerifier: req.GetCodeVerifier(),
+		RefreshToken: req.GetRefreshToken(),
+		Scopes:       strings.Fields(req.GetScope()),
+		ClientIP:     metadata.ClientIP,
+		UserAgent:    metadata.UserAgent,
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.OAuthTokenResponse{
+		AccessToken:  token.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(token.AccessTokenExpiresAt) / time.Second),
+		RefreshToken: token.RefreshToken,
+		Scope:        strings.Join(token.Scopes, " "),
+	}, nil
+}
+
+func (uh *UserHandler) RevokeOAuthToken(ctx context.Context, req *pb.RevokeOAuthTokenRequest) (*pb.RevokeOAuthTokenResponse, error) {
+	err := uh.oauth.RevokeToken(ctx, service.OAuthTokenRequest{
+		ClientID:     req.GetClientId(),
+		ClientSecret: req.GetClientSecret(),
+		Token:        req.GetToken(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RevokeOAuthTokenResponse{}, nil
+}
+
+func (uh *UserHandler) IntrospectOAuthToken(ctx context.Context, req *pb.IntrospectOAuthTokenRequest) (*pb.IntrospectOAuthTokenResponse, error) {
+	introspection, err := uh.oauth.IntrospectToken(ctx, service.OAuthTokenRequest{
+		ClientID:     req.GetClientId(),
+		ClientSecret: req.GetClientSecret(),
+		Token:        req.GetToken(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	if !introspection.Active {
+		return &pb.IntrospectOAuthTokenResponse{}, nil
+	}
+	return &pb.IntrospectOAuthTokenResponse{
+		Active:    true,
+		Scope:     strings.Join(introspection.Scopes, " "),
+		ClientId:  introspection.ClientID,
+		Username:  introspection.Username,
+		TokenType: introspection.TokenType,
+		Exp:       introspection.ExpiresAt.Unix(),
+		Iat:       introspection.IssuedAt.Unix(),
+	}, nil
+}
+
+func (uh *UserHandler) ListOAuthConsents(ctx context.Context, req *pb.ListOAuthConsentsRequest) (*pb.ListOAuthConsentsResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	consents, err := uh.oauth.ListOAuthConsents(ctx, authPayload)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	resp := &pb.ListOAuthConsentsResponse{Consents: make([]*pb.OAuthConsent, 0, len(consents))}
+	for _, c := range consents {
+		resp.Consents = append(resp.Consents, &pb.OAuthConsent{
+			ClientId:  c.ClientID,
+			Scopes:    c.Scopes,
+			CreatedAt: timestamppb.New(c.CreatedAt),
+			UpdatedAt: timestamppb.New(c.UpdatedAt),
+		})
+	}
+	return resp, nil
+}
+
+func (uh *UserHandler) RevokeOAuthConsent(ctx context.Context, req *pb.RevokeOAuthConsentRequest) (*pb.RevokeOAuthConsentResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if err := uh.oauth.RevokeOAuthConsent(ctx, authPayload, req.GetClientId()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RevokeOAuthConsentResponse{}, nil
+}
@@ -0,0 +1,124 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func passkeyCeremony(ceremony *service.PasskeyCeremony) *pb.PasskeyCeremony {
+	return &pb.PasskeyCeremony{
+		CeremonyId: ceremony.ID.String(),
+		Options:    string(ceremony.Options),
+		ExpiresAt:  timestamppb.New(ceremony.ExpiresAt),
+	}
+}
+
+func passkey(c *entity.WebAuthnCredential) *pb.Passkey {
+	resp := &pb.Passkey{
+		Id:        c.ID,
+		Name:      c.Name,
+		CreatedAt: timestamppb.New(c.CreatedAt),
+	}
+	if !c.LastUsedAt.IsZero() {
+		resp.LastUsedAt = timestamppb.New(c.LastUsedAt)
+	}
+	return resp
+}
+
+func (uh *UserHandler) BeginPasskeyLogin(ctx context.Context, req *pb.BeginPasskeyLoginRequest) (*pb.PasskeyCeremony, error) {
+	ceremony, err := uh.us.BeginPasskeyLogin(ctx)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return passkeyCeremony(ceremony), nil
+}
+
+func (uh *UserHandler) FinishPasskeyLogin(ctx context.Context, req *pb.FinishPasskeyLoginRequest) (*pb.LoginUserResponse, error) {
+	metadata := extractMetadata(ctx)
+	result, err := uh.us.FinishPasskeyLogin(ctx, service.FinishPasskeyLoginInput{
+		CeremonyID: req.GetCeremonyId(),
+		Credential: req.GetCredential(),
+		ClientIP:   metadata.ClientIP,
+		UserAgent:  metadata.UserAgent,
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return loginUserResponse(result), nil
+}
+
+func (uh *UserHandler) BeginPasskeyRegistration(ctx context.Context, req *pb.BeginPasskeyRegistrationRequest) (*pb.PasskeyCeremony, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	//a passkey signs in without the password, so adding one takes a recent login
+	if err := uh.stepUp.Check(auth.OpRegisterPasskey, authPayload); err != nil {
+		return nil, stepUpStatus(err)
+	}
+
+	ceremony, err := uh.us.BeginPasskeyRegistration(ctx, authPayload)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return passkeyCeremony(ceremony), nil
+}
+
+func (uh *UserHandler) FinishPasskeyRegistration(ctx context.Context, req *pb.FinishPasskeyRegistrationRequest) (*pb.FinishPasskeyRegistrationResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err := uh.stepUp.Check(auth.OpRegisterPasskey, authPayload); err != nil {
+		return nil, stepUpStatus(err)
+	}
+
+	credential, err := uh.us.FinishPasskeyRegistration(ctx, authPayload, service.FinishPasskeyRegistrationInput{
+		CeremonyID: req.GetCeremonyId(),
+		Name:       req.GetName(),
+		Credential: req.GetCredential(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.FinishPasskeyRegistrationResponse{Passkey: passkey(credential)}, nil
+}
+
+func (uh *UserHandler) ListPasskeys(ctx context.Context, req *pb.ListPasskeysRequest) (*pb.ListPasskeysResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	credentials, err := uh.us.ListPasskeys(ctx, authPayload)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	resp := &pb.ListPasskeysResponse{Passkeys: make([]*pb.Passkey, 0, len(credentials))}
+	for _, c := range credentials {
+		resp.Passkeys = append(resp.Passkeys, passkey(c))
+	}
+	return resp, nil
+}
+
+func (uh *UserHandler) DeletePasskey(ctx context.Context, req *pb.DeletePasskeyRequest) (*pb.DeletePasskeyResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err := uh.stepUp.Check(auth.OpRegisterPasskey, authPayload); err != nil {
+		return nil, stepUpStatus(err)
+	}
+
+	if err := uh.us.DeletePasskey(ctx, authPayload, req.GetId()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.DeletePasskeyResponse{}, nil
+}
@@ -0,0 +1,22 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/pb"
+)
+
+// RequestPasswordReset answers the same way whether or not the email belongs to an account.
+func (uh *UserHandler) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	if err := uh.pr.RequestPasswordReset(ctx, req.GetEmail()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RequestPasswordResetResponse{}, nil
+}
+
+func (uh *UserHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if _, err := uh.pr.ResetPassword(ctx, req.GetToken(), req.GetNewPassword()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.ResetPasswordResponse{}, nil
+}
@@ -0,0 +1,31 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (uh *UserHandler) Reauthenticate(ctx context.Context, req *pb.ReauthenticateRequest) (*pb.ReauthenticateResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	result, err := uh.us.Reauthenticate(ctx, authPayload, service.ReauthenticateInput{
+		Password: req.GetPassword(),
+		Code:     req.GetCode(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.ReauthenticateResponse{
+		AccessToken:          result.AccessToken,
+		AccessTokenExpiresAt: timestamppb.New(result.AccessTokenExpiresAt),
+		AuthTime:             timestamppb.New(result.AuthTime),
+	}, nil
+}
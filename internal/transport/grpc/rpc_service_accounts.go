@@ -0,0 +1,103 @@
+package grpctransport
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xOnah/bank/internal/entity"
+	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (uh *UserHandler) CreateServiceAccount(ctx context.Context, req *pb.CreateServiceAccountRequest) (*pb.CreateServiceAccountResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	sa, key, err := uh.sa.CreateServiceAccount(ctx, authPayload, service.CreateServiceAccountInput{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Scopes:      req.GetScopes(),
+		AccountIDs:  req.GetAccountIds(),
+	})
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.CreateServiceAccountResponse{
+		ServiceAccount: &pb.ServiceAccount{
+			Name:        sa.Name,
+			Description: sa.Description,
+			Scopes:      sa.Scopes,
+			AccountIds:  sa.AccountIDs,
+			CreatedBy:   sa.CreatedBy,
+			CreatedAt:   timestamppb.New(sa.CreatedAt),
+		},
+		ApiKey: toPBAPIKey(key.APIKey),
+		Key:    key.Key,
+	}, nil
+}
+
+func (uh *UserHandler) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	keys, err := uh.sa.ListAPIKeys(ctx, authPayload, req.GetName())
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	resp := &pb.ListAPIKeysResponse{ApiKeys: make([]*pb.APIKey, 0, len(keys))}
+	for _, k := range keys {
+		resp.ApiKeys = append(resp.ApiKeys, toPBAPIKey(k))
+	}
+	return resp, nil
+}
+
+func (uh *UserHandler) RotateAPIKey(ctx context.Context, req *pb.RotateAPIKeyRequest) (*pb.RotateAPIKeyResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	key, err := uh.sa.RotateAPIKey(ctx, authPayload, req.GetName())
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RotateAPIKeyResponse{
+		ApiKey: toPBAPIKey(key.APIKey),
+		Key:    key.Key,
+	}, nil
+}
+
+func (uh *UserHandler) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if err := uh.sa.RevokeAPIKey(ctx, authPayload, req.GetPrefix()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RevokeAPIKeyResponse{}, nil
+}
+
+func toPBAPIKey(k *entity.APIKey) *pb.APIKey {
+	return &pb.APIKey{
+		Prefix:     k.Prefix,
+		CreatedAt:  timestamppb.New(k.CreatedAt),
+		LastUsedAt: optionalTimestamp(k.LastUsedAt),
+		RevokedAt:  optionalTimestamp(k.RevokedAt),
+	}
+}
+
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,65 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/pb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (uh *UserHandler) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	sessions, err := uh.us.ListSessions(ctx, authPayload)
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+
+	resp := &pb.ListSessionsResponse{Sessions: make([]*pb.Session, 0, len(sessions))}
+	for _, s := range sessions {
+		resp.Sessions = append(resp.Sessions, &pb.Session{
+			SessionId:    s.ID.String(),
+			UserAgent:    s.UserAgent,
+			ClientIp:     s.ClientIP,
+			LastActiveAt: timestamppb.New(s.LastActiveAt),
+			ExpiresAt:    timestamppb.New(s.ExpiresAt),
+			Current:      s.Current,
+			ClientId:     s.ClientID,
+		})
+	}
+	return resp, nil
+}
+
+func (uh *UserHandler) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session id")
+	}
+	if err := uh.us.RevokeSession(ctx, authPayload, sessionID); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+func (uh *UserHandler) RevokeOtherSessions(ctx context.Context, req *pb.RevokeOtherSessionsRequest) (*pb.RevokeOtherSessionsResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if err := uh.us.RevokeOtherSessions(ctx, authPayload); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.RevokeOtherSessionsResponse{}, nil
+}
@@ -0,0 +1,21 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (uh *UserHandler) UnlockUser(ctx context.Context, req *pb.UnlockUserRequest) (*pb.UnlockUserResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if err := uh.us.UnlockUser(ctx, authPayload, req.GetUsername()); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.UnlockUserResponse{}, nil
+}
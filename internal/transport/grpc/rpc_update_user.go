@@ -3,10 +3,17 @@ package grpctransport
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
 
 	"github.com/0xOnah/bank/internal/db/repo"
+	"github.com/0xOnah/bank/internal/sdk/audit"
 	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/validator"
 	"github.com/0xOnah/bank/pb"
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -18,30 +25,98 @@ func (uh *UserHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest
 		return nil, status.Error(codes.PermissionDenied, err.Error())
 	}
 
-	if req.GetUsername() != authPayload.Username {
+	if err := auth.Authorize(authPayload, req.GetUsername(), auth.PermManageUsers); err != nil {
 		return nil, status.Error(codes.PermissionDenied, "you do not have access to this resources")
 	}
+	var role *auth.Role
+	if req.Role != nil {
+		if err := auth.Require(authPayload, auth.PermManageUsers); err != nil {
+			return nil, status.Error(codes.PermissionDenied, "you cannot change roles")
+		}
+		r := auth.Role(req.GetRole())
+		if !r.Valid() {
+			return nil, status.Error(codes.InvalidArgument, "unknown role")
+		}
+		role = &r
+	}
+	if req.Email != nil {
+		if err := uh.stepUp.Check(auth.OpChangeEmail, authPayload); err != nil {
+			return nil, stepUpStatus(err)
+		}
+	}
+	if req.Password != nil {
+		if err := uh.stepUp.Check(auth.OpChangePassword, authPayload); err != nil {
+			return nil, stepUpStatus(err)
+		}
+	}
+
+	if req.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username must be provided")
+	}
+	if req.Email != nil && !validator.EmailCheck(req.GetEmail()) {
+		v := validator.NewValidator()
+		v.Add("email", "invalid email format")
+		return nil, MapValidationErrors(v)
+	}
 
-	//TODO: validate data before
 	var password *string
+	var keepSession *uuid.UUID
 	if req.Password != nil {
-		hashed, err := auth.HashPassword(*req.Password)
+		email := req.GetEmail()
+		if req.Email == nil {
+			current, err := uh.ur.GetUser(ctx, req.GetUsername())
+			if err != nil {
+				if errors.Is(err, repo.ErrUserNotFound) {
+					return nil, status.Error(codes.NotFound, "user does not exist")
+				}
+				return nil, status.Error(codes.Internal, "internal server error")
+			}
+			email = current.Email.String()
+		}
+		if err := uh.us.CheckNewPassword(ctx, req.GetUsername(), email, req.GetPassword()); err != nil {
+			return nil, serviceStatus(err)
+		}
+		hashed, err := auth.HashPassword(req.GetPassword())
 		if err != nil {
 			return nil, status.Error(codes.Internal, "failed to update user")
 		}
 		password = &hashed
+		//users changing their own password stay signed in on this device only
+		if authPayload.Username == req.GetUsername() {
+			if id, err := uuid.Parse(authPayload.SessionID); err == nil {
+				keepSession = &id
+			}
+		}
 	}
 
-	if req.Username == "" {
-		return nil, status.Error(codes.InvalidArgument, "username must be provided")
+	//which fields changed is recorded, never what they changed to
+	var changed []string
+	if req.FullName != nil {
+		changed = append(changed, "full_name")
+	}
+	if req.Email != nil {
+		changed = append(changed, "email")
+	}
+	if password != nil {
+		changed = append(changed, "password")
+	}
+	if role != nil {
+		changed = append(changed, "role")
+	}
+	event := audit.NewEvent(ctx, audit.ActionUserUpdate, authPayload.Username, audit.UserSubject(req.GetUsername()))
+	event.Details = map[string]string{"fields": strings.Join(changed, ",")}
+	if role != nil {
+		event.Details["role"] = string(*role)
 	}
 
 	user, err := uh.ur.UpdateUser(ctx, repo.UpdateUserParams{
 		FullName:       req.FullName,
 		Email:          req.Email,
 		HashedPassword: password,
+		Role:           role,
 		Username:       req.Username,
-	})
+		KeepSessionID:  keepSession,
+	}, event)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, status.Error(codes.NotFound, "user does not exist")
@@ -50,6 +125,13 @@ func (uh *UserHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest
 
 	}
 
+	if req.Email != nil && !user.IsEmailVerified {
+		err = uh.taskqueue.JobVerifyEmail(ctx, &jobs.VerifyEmailPayload{Username: user.Username})
+		if err != nil {
+			logger.FromCTX(ctx, uh.logger).Error().Err(err).Msg("jobVerifyEmail fail")
+		}
+	}
+
 	return &pb.UpdateUserResponse{
 		User: &pb.User{
 			Username:          user.Username,
@@ -57,6 +139,8 @@ func (uh *UserHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest
 			FullName:          user.FullName,
 			PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 			CreatedAt:         timestamppb.New(user.CreatedAt),
+			IsEmailVerified:   user.IsEmailVerified,
+			Role:              user.Role,
 		},
 	}, nil
 
@@ -0,0 +1,29 @@
+package grpctransport
+
+import (
+	"context"
+
+	"github.com/0xOnah/bank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (uh *UserHandler) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	_, err := uh.ve.VerifyEmail(ctx, req.GetEmailId(), req.GetSecretCode())
+	if err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.VerifyEmailResponse{IsVerified: true}, nil
+}
+
+func (uh *UserHandler) ResendVerifyEmail(ctx context.Context, req *pb.ResendVerifyEmailRequest) (*pb.ResendVerifyEmailResponse, error) {
+	authPayload, err := uh.authenication(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if err := uh.ve.ResendVerifyEmail(ctx, authPayload.Username); err != nil {
+		return nil, serviceStatus(err)
+	}
+	return &pb.ResendVerifyEmailResponse{}, nil
+}
@@ -3,53 +3,38 @@ package grpctransport
 import (
 	"context"
 
-	"github.com/0xOnah/bank/internal/db/sqlc"
 	"github.com/0xOnah/bank/internal/sdk/jobs"
+	"github.com/0xOnah/bank/internal/sdk/logger"
 	"github.com/0xOnah/bank/internal/service"
-	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
 	"github.com/0xOnah/bank/pb"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (uh *UserHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
-	var user *sqlc.User
-	err := uh.ur.WithTx(ctx, func(q *sqlc.Queries) error {
-		var err error
-		user, err = q.CreateUser(ctx, sqlc.CreateUserParams{
-			Username:       req.Username,
-			HashedPassword: req.Password,
-			Email:          req.Email,
-			FullName:       req.FullName,
-		})
-		if err != nil {
-			grpcErr := MapValidationErrors(err)
-			if grpcErr != nil {
-				return grpcErr
-			}
-			if appErr, ok := err.(*errorutil.AppError); ok {
-				return status.Errorf(errorutil.MapErrorToGRPCStatus(appErr), appErr.Message)
-			}
-		}
-		return nil
+	user, err := uh.us.CreateUser(ctx, service.CreateUserInput{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+		Fullname: req.GetFullName(),
+		Email:    req.GetEmail(),
 	})
 	if err != nil {
-		return nil, err
+		return nil, serviceStatus(err)
 	}
 
 	payload := jobs.VerifyEmailPayload{Username: user.Username}
 	err = uh.taskqueue.JobVerifyEmail(ctx, &payload)
 	if err != nil {
-		uh.logger.Error().Err(err).Msg("jobVerifyEmail fail")
+		logger.FromCTX(ctx, uh.logger).Error().Err(err).Msg("jobVerifyEmail fail")
 	}
 
 	return &pb.CreateUserResponse{
 		User: &pb.User{
 			Username:          user.Username,
-			Email:             user.Email,
+			Email:             user.Email.String(),
 			FullName:          user.FullName,
 			PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 			CreatedAt:         timestamppb.New(user.CreatedAt),
+			Role:              user.Role,
 		},
 	}, nil
 }
@@ -63,12 +48,22 @@ func (uh *UserHandler) LoginUser(ctx context.Context, req *pb.LoginUserRequest)
 		UserAgent: metadata.UserAgent,
 	})
 	if err != nil {
-		grpcErr := MapValidationErrors(err)
-		if grpcErr != nil {
-			return nil, grpcErr
-		}
-		if appErr, ok := err.(*errorutil.AppError); ok {
-			return nil, status.Errorf(errorutil.MapErrorToGRPCStatus(appErr), appErr.Message)
+		return nil, serviceStatus(err)
+	}
+	return loginUserResponse(userValue), nil
+}
+
+// loginUserResponse converts a login result, which is a session, an MFA challenge or a pending
+// device confirmation.
+func loginUserResponse(userValue *service.AuthResult) *pb.LoginUserResponse {
+	if userValue.DeviceConfirmationRequired {
+		return &pb.LoginUserResponse{DeviceConfirmationRequired: true}
+	}
+	if userValue.MFARequired {
+		return &pb.LoginUserResponse{
+			MfaRequired:       true,
+			MfaToken:          userValue.MFAToken,
+			MfaTokenExpiresAt: timestamppb.New(userValue.MFATokenExpiresAt),
 		}
 	}
 	return &pb.LoginUserResponse{
@@ -76,13 +71,15 @@ func (uh *UserHandler) LoginUser(ctx context.Context, req *pb.LoginUserRequest)
 		AccessToken:           userValue.AccessToken,
 		AccessTokenExpiresAt:  timestamppb.New(userValue.AccessTokenExpiresAt),
 		RefreshToken:          userValue.RefreshToken,
-		RefreshTokenExpiresAt: timestamppb.New(userValue.AccessTokenExpiresAt),
+		RefreshTokenExpiresAt: timestamppb.New(userValue.RefreshTokenExpiresAt),
 		User: &pb.User{
 			Username:          userValue.User.Username,
 			FullName:          userValue.User.FullName,
 			Email:             userValue.User.Email.String(),
 			PasswordChangedAt: timestamppb.New(userValue.User.PasswordChangedAt),
 			CreatedAt:         timestamppb.New(userValue.User.CreatedAt),
+			IsEmailVerified:   userValue.User.IsEmailVerified,
+			Role:              userValue.User.Role,
 		},
-	}, nil
+	}
 }
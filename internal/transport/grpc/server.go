@@ -2,6 +2,7 @@ package grpctransport
 
 import (
 	"context"
+	"errors"
 
 	"github.com/0xOnah/bank/internal/db/repo"
 	"github.com/0xOnah/bank/internal/entity"
@@ -9,32 +10,137 @@ import (
 	"github.com/0xOnah/bank/internal/sdk/jobs"
 	"github.com/0xOnah/bank/internal/sdk/logger"
 	"github.com/0xOnah/bank/internal/service"
+	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
 	"github.com/0xOnah/bank/pb"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type userService interface {
 	CreateUser(ctx context.Context, cu service.CreateUserInput) (entity.User, error)
+	CheckNewPassword(ctx context.Context, username, email, password string) error
 	Login(ctx context.Context, lg service.Logininput) (*service.AuthResult, error)
 	RenewAccessToken(ctx context.Context, refreshToken string) (service.RenewAccessToken, error)
+	VerifyMFA(ctx context.Context, arg service.VerifyMFAInput) (*service.AuthResult, error)
+	Reauthenticate(ctx context.Context, payload *auth.Payload, arg service.ReauthenticateInput) (*service.Reauthentication, error)
+	ListSessions(ctx context.Context, payload *auth.Payload) ([]service.ActiveSession, error)
+	RevokeSession(ctx context.Context, payload *auth.Payload, sessionID uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, payload *auth.Payload) error
+	UnlockUser(ctx context.Context, payload *auth.Payload, username string) error
+	ConfirmDevice(ctx context.Context, token string) error
+	RequestLoginLink(ctx context.Context, email string) error
+	ConsumeLoginLink(ctx context.Context, arg service.ConsumeLoginLinkInput) (*service.AuthResult, error)
+	BeginPasskeyLogin(ctx context.Context) (*service.PasskeyCeremony, error)
+	FinishPasskeyLogin(ctx context.Context, arg service.FinishPasskeyLoginInput) (*service.AuthResult, error)
+	BeginPasskeyRegistration(ctx context.Context, payload *auth.Payload) (*service.PasskeyCeremony, error)
+	FinishPasskeyRegistration(ctx context.Context, payload *auth.Payload, arg service.FinishPasskeyRegistrationInput) (*entity.WebAuthnCredential, error)
+	ListPasskeys(ctx context.Context, payload *auth.Payload) ([]*entity.WebAuthnCredential, error)
+	DeletePasskey(ctx context.Context, payload *auth.Payload, id int64) error
+}
+
+type verifyEmailService interface {
+	VerifyEmail(ctx context.Context, emailID int64, secretCode string) (*entity.VerifyEmail, error)
+	ResendVerifyEmail(ctx context.Context, username string) error
+}
+
+type passwordResetService interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) (*entity.User, error)
+}
+
+type mfaService interface {
+	EnrollTOTP(ctx context.Context, payload *auth.Payload) (*service.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, payload *auth.Payload, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, payload *auth.Payload, code string) error
+}
+
+type serviceAccountService interface {
+	CreateServiceAccount(ctx context.Context, payload *auth.Payload, arg service.CreateServiceAccountInput) (*entity.ServiceAccount, *service.IssuedAPIKey, error)
+	ListAPIKeys(ctx context.Context, payload *auth.Payload, name string) ([]*entity.APIKey, error)
+	RotateAPIKey(ctx context.Context, payload *auth.Payload, name string) (*service.IssuedAPIKey, error)
+	RevokeAPIKey(ctx context.Context, payload *auth.Payload, prefix string) error
+}
+
+type oauthService interface {
+	CreateOAuthClient(ctx context.Context, payload *auth.Payload, arg service.CreateOAuthClientInput) (*service.RegisteredOAuthClient, error)
+	RevokeOAuthClient(ctx context.Context, payload *auth.Payload, clientID string) error
+	AuthorizeOAuthClient(ctx context.Context, payload *auth.Payload, arg service.AuthorizeOAuthClientInput) (*service.OAuthAuthorization, error)
+	Token(ctx context.Context, arg service.OAuthTokenInput) (*service.OAuthToken, error)
+	RevokeToken(ctx context.Context, arg service.OAuthTokenRequest) error
+	IntrospectToken(ctx context.Context, arg service.OAuthTokenRequest) (*service.TokenIntrospection, error)
+	ListOAuthConsents(ctx context.Context, payload *auth.Payload) ([]*entity.OAuthConsent, error)
+	RevokeOAuthConsent(ctx context.Context, payload *auth.Payload, clientID string) error
+}
+
+type accountConsentService interface {
+	CreateAccountConsent(ctx context.Context, payload *auth.Payload, arg service.CreateAccountConsentInput) (*entity.AccountConsent, error)
+	ListAccountConsents(ctx context.Context, payload *auth.Payload) ([]*entity.AccountConsent, error)
+	RevokeAccountConsent(ctx context.Context, payload *auth.Payload, id uuid.UUID) error
+	GetAccountConsent(ctx context.Context, payload *auth.Payload, id uuid.UUID) (*entity.AccountConsent, error)
+	ListConsentedAccounts(ctx context.Context, payload *auth.Payload, consentID uuid.UUID) ([]*entity.Account, error)
+	GetConsentedAccountBalance(ctx context.Context, payload *auth.Payload, consentID uuid.UUID, accountID int64) (*entity.Account, error)
+	ListConsentedTransactions(ctx context.Context, payload *auth.Payload, arg service.ListConsentedTransactionsInput) ([]entity.Entry, error)
+}
+
+type auditService interface {
+	ListAuditEvents(ctx context.Context, payload *auth.Payload, filter entity.AuditEventFilter) ([]*entity.AuditEvent, error)
 }
 
 type UserHandler struct {
 	pb.UnimplementedUserServiceServer
 	us        userService
+	ve        verifyEmailService
+	pr        passwordResetService
+	mfa       mfaService
+	sa        serviceAccountService
+	audit     auditService
+	oauth     oauthService
+	consents  accountConsentService
 	ur        *repo.UserRepo
 	jwtMaker  auth.Authenticator
+	stepUp    auth.StepUpPolicy
 	logger    *zerolog.Logger
 	taskqueue jobs.TaskDistributor
 }
 
-func NewUserHandler(us userService, ur *repo.UserRepo, jtmaker auth.Authenticator, log *zerolog.Logger, taskqueue jobs.TaskDistributor) *UserHandler {
+func NewUserHandler(us userService, ve verifyEmailService, pr passwordResetService, mfa mfaService, sa serviceAccountService, audit auditService, oauth oauthService, consents accountConsentService, ur *repo.UserRepo, jtmaker auth.Authenticator, stepUp auth.StepUpPolicy, log *zerolog.Logger, taskqueue jobs.TaskDistributor) *UserHandler {
 	log = logger.ServiceLogger(log, "grpc_service")
 	return &UserHandler{
 		us:        us,
+		ve:        ve,
+		pr:        pr,
+		mfa:       mfa,
+		sa:        sa,
+		audit:     audit,
+		oauth:     oauth,
+		consents:  consents,
 		ur:        ur,
 		jwtMaker:  jtmaker,
+		stepUp:    stepUp,
 		logger:    log,
 		taskqueue: taskqueue,
 	}
 }
+
+// serviceStatus converts a service error into a grpc status, hiding unexpected errors.
+func serviceStatus(err error) error {
+	if grpcErr := MapValidationErrors(err); grpcErr != nil {
+		return grpcErr
+	}
+	if appErr, ok := err.(*errorutil.AppError); ok {
+		return status.Error(errorutil.MapErrorToGRPCStatus(appErr), appErr.Message)
+	}
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// stepUpStatus converts a failed step-up check into the Unauthenticated status that tells
+// clients to call Reauthenticate and retry.
+func stepUpStatus(err error) error {
+	var stepUp *auth.StepUpError
+	if errors.As(err, &stepUp) {
+		return stepUp.Status().Err()
+	}
+	return status.Error(codes.Internal, "internal server error")
+}
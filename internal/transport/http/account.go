@@ -17,8 +17,8 @@ import (
 
 type AccountService interface {
 	CreateAccount(ctx context.Context, input entity.CreateAccountInput) (*entity.Account, error)
-	GetAccountByID(ctx context.Context, username string, id int64) (*entity.Account, error)
-	ListAccount(ctx context.Context, arg entity.ListAccountInput) ([]*entity.Account, error)
+	GetAccountByID(ctx context.Context, payload *auth.Payload, id int64) (*entity.Account, error)
+	ListAccount(ctx context.Context, payload *auth.Payload, arg entity.ListAccountInput) ([]*entity.Account, error)
 }
 type AccountHandler struct {
 	accSvc AccountService
@@ -43,7 +43,7 @@ type CreateAccountRequest struct {
 func (a *AccountHandler) CreateAccount(ctx *gin.Context) {
 	var req CreateAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 	payload := ctx.MustGet(middleware.AuthorizationPayLoadKey).(*auth.Payload)
@@ -56,14 +56,14 @@ func (a *AccountHandler) CreateAccount(ctx *gin.Context) {
 	if err != nil {
 		var appErr *errorutil.AppError
 		if ok := errors.As(err, &appErr); ok {
-			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(err))
+			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(ctx.Request.Context(), err))
 			slog.Info("Handled client error in CreateAccount",
 				slog.Int("statusCode", int(appErr.Code)),
 				slog.String("message", appErr.Message),
 				slog.String("error", appErr.Err.Error()))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(ctx.Request.Context(), err))
 
 		return
 	}
@@ -83,12 +83,12 @@ type getAccountByID struct {
 func (a *AccountHandler) GetAccountByID(ctx *gin.Context) {
 	var req getAccountByID
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 	payload := ctx.MustGet(middleware.AuthorizationPayLoadKey).(*auth.Payload)
 
-	account, err := a.accSvc.GetAccountByID(ctx.Request.Context(), payload.Username, req.ID)
+	account, err := a.accSvc.GetAccountByID(ctx.Request.Context(), payload, req.ID)
 	if err != nil {
 		var appErr *errorutil.AppError
 		if ok := errors.As(err, &appErr); ok {
@@ -97,10 +97,10 @@ func (a *AccountHandler) GetAccountByID(ctx *gin.Context) {
 				slog.Int("statusCode", statusCode),
 				slog.String("message", appErr.Message),
 				slog.Any("error", appErr.Err))
-			ctx.JSON(statusCode, util.ErrorResponse(err))
+			ctx.JSON(statusCode, util.ErrorResponse(ctx.Request.Context(), err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 
@@ -115,23 +115,34 @@ func (a *AccountHandler) GetAccountByID(ctx *gin.Context) {
 type listAccountRequest struct {
 	PageID   int64 `form:"page_id" binding:"required,min=1"`
 	PageSize int64 `form:"page_size" binding:"required,min=5,max=10"`
+	//staff may list another user's accounts
+	Owner string `form:"owner"`
 }
 
 func (a *AccountHandler) listAccount(ctx *gin.Context) {
 	var arg listAccountRequest
 	if err := ctx.ShouldBindQuery(&arg); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 
 	payload := ctx.MustGet(middleware.AuthorizationPayLoadKey).(*auth.Payload)
-	accounts, err := a.accSvc.ListAccount(ctx.Request.Context(), entity.ListAccountInput{
-		User:   payload.Username,
+	owner := arg.Owner
+	if owner == "" {
+		owner = payload.Username
+	}
+	accounts, err := a.accSvc.ListAccount(ctx.Request.Context(), payload, entity.ListAccountInput{
+		User:   owner,
 		Limit:  int32(arg.PageSize),
 		Offset: int32(arg.PageID-1) * int32(arg.PageSize),
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		var appErr *errorutil.AppError
+		if errors.As(err, &appErr) {
+			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(ctx.Request.Context(), err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 
@@ -12,10 +12,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/0xOnah/bank/internal/sdk/netutil"
 	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/0xOnah/bank/internal/transport/sdk/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	zlog "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type Router struct {
@@ -24,6 +28,7 @@ type Router struct {
 
 func NewRouter(accountHand *AccountHandler, transferHand *TransferHandler, userHand *UserHandler) *Router {
 	router := gin.Default()
+	router.Use(middleware.TraceRoute())
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		if err := v.RegisterValidation("currency", util.ValidCurrency); err != nil {
@@ -41,10 +46,10 @@ func NewRouter(accountHand *AccountHandler, transferHand *TransferHandler, userH
 	return routerSetup
 }
 
-func (r *Router) Serve(port string) error {
+func (r *Router) Serve(port string, proxies netutil.Proxies) error {
 	server := &http.Server{
 		Addr:         port,
-		Handler:      r.Mux,
+		Handler:      middleware.RequestID(&zlog.Logger)(middleware.ClientInfo(proxies)(otelhttp.NewHandler(r.Mux, "http"))),
 		ReadTimeout:  time.Second * 3,
 		WriteTimeout: time.Second * 10,
 		IdleTimeout:  time.Second * 30,
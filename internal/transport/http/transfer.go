@@ -2,6 +2,7 @@ package httptransport
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/0xOnah/bank/internal/entity"
@@ -13,15 +14,19 @@ import (
 )
 
 type TransferService interface {
-	CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput, username string, currency string) (*entity.TransferTxResult, error)
+	CreateTransferTX(ctx context.Context, arg entity.CreateTransferInput, payload *auth.Payload, currency string) (*entity.TransferTxResult, error)
 }
 type TransferHandler struct {
 	tranServ TransferService
 	token    auth.Authenticator
+	limit    gin.HandlerFunc
+	stepUp   auth.StepUpPolicy
+	//transfers of at least this amount are auth.OpHighValueTransfer
+	highValue int64
 }
 
-func NewTranserHandler(svc TransferService, token auth.Authenticator) *TransferHandler {
-	return &TransferHandler{tranServ: svc, token: token}
+func NewTranserHandler(svc TransferService, token auth.Authenticator, limit gin.HandlerFunc, stepUp auth.StepUpPolicy, highValue int64) *TransferHandler {
+	return &TransferHandler{tranServ: svc, token: token, limit: limit, stepUp: stepUp, highValue: highValue}
 }
 
 type transferRequest struct {
@@ -32,17 +37,28 @@ type transferRequest struct {
 }
 
 func (t *TransferHandler) MapAccountRoutes(r *gin.Engine) {
-	r.POST("/transfer", middleware.Authenication(t.token), t.CreateTransfer)
+	r.POST("/transfer", middleware.Authenication(t.token), t.limit, t.CreateTransfer)
 }
 
 func (t *TransferHandler) CreateTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 	ctx.ClientIP()
 	payload := ctx.MustGet(middleware.AuthorizationPayLoadKey).(*auth.Payload)
+	if req.Amount >= t.highValue {
+		if err := t.stepUp.Check(auth.OpHighValueTransfer, payload); err != nil {
+			var stepUp *auth.StepUpError
+			if errors.As(err, &stepUp) {
+				middleware.AbortStepUp(ctx, stepUp)
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(ctx.Request.Context(), err))
+			return
+		}
+	}
 
 	arg := entity.CreateTransferInput{
 		FromAccountID: req.FromAccountID,
@@ -50,13 +66,13 @@ func (t *TransferHandler) CreateTransfer(ctx *gin.Context) {
 		Amount:        req.Amount,
 	}
 
-	transfer, err := t.tranServ.CreateTransferTX(ctx.Request.Context(), arg, payload.Username, req.Currency)
+	transfer, err := t.tranServ.CreateTransferTX(ctx.Request.Context(), arg, payload, req.Currency)
 	if err != nil {
 		if appErr, ok := err.(*errorutil.AppError); ok {
-			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(err))
+			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(ctx.Request.Context(), err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 
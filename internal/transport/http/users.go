@@ -9,10 +9,10 @@ import (
 
 	"github.com/0xOnah/bank/internal/entity"
 	"github.com/0xOnah/bank/internal/sdk/auth"
-	"github.com/0xOnah/bank/internal/sdk/netutil"
 	"github.com/0xOnah/bank/internal/sdk/util"
 	"github.com/0xOnah/bank/internal/service"
 	"github.com/0xOnah/bank/internal/transport/sdk/errorutil"
+	"github.com/0xOnah/bank/internal/transport/sdk/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -21,11 +21,14 @@ type userService interface {
 	CreateUser(ctx context.Context, cr service.CreateUserInput) (entity.User, error)
 	Login(ctx context.Context, lg service.Logininput) (*service.AuthResult, error)
 	RenewAccessToken(ctx context.Context, refreshToken string) (service.RenewAccessToken, error)
+	VerifyMFA(ctx context.Context, arg service.VerifyMFAInput) (*service.AuthResult, error)
+	Reauthenticate(ctx context.Context, payload *auth.Payload, arg service.ReauthenticateInput) (*service.Reauthentication, error)
 }
 
 type UserHandler struct {
 	UsrSvc userService
 	auth   auth.Authenticator
+	limit  gin.HandlerFunc
 }
 
 type userReq struct {
@@ -48,20 +51,22 @@ func (ur userReq) ToUserResponse(u entity.User) userResp {
 		FullName: u.FullName,
 	}
 }
-func NewUserHandler(us userService, auth auth.Authenticator) *UserHandler {
-	return &UserHandler{UsrSvc: us, auth: auth}
+func NewUserHandler(us userService, auth auth.Authenticator, limit gin.HandlerFunc) *UserHandler {
+	return &UserHandler{UsrSvc: us, auth: auth, limit: limit}
 }
 func (t *UserHandler) MapAccountRoutes(r *gin.Engine) {
 	r.POST("/user", t.CreateUser)
-	r.POST("/login", t.LoginAccount)
+	r.POST("/login", t.limit, t.LoginAccount)
+	r.POST("/login/mfa", t.limit, t.VerifyMFA)
 	r.POST("/token/renew_access", t.RenewAccessToken)
+	r.POST("/reauthenticate", middleware.Authenication(t.auth), t.limit, t.Reauthenticate)
 }
 
 func (uh *UserHandler) CreateUser(ctx *gin.Context) {
 	var createUser userReq
 	err := ctx.ShouldBindJSON(&createUser)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 
@@ -74,10 +79,10 @@ func (uh *UserHandler) CreateUser(ctx *gin.Context) {
 	userValue, err := uh.UsrSvc.CreateUser(ctx.Request.Context(), usArg)
 	if err != nil {
 		if appErr, ok := err.(*errorutil.AppError); ok {
-			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(err))
+			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(ctx.Request.Context(), err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 
@@ -99,24 +104,59 @@ type userLoginResp struct {
 	User                  userResp  `json:"user"`
 }
 
+type mfaChallengeResp struct {
+	MFARequired       bool      `json:"mfa_required"`
+	MFAToken          string    `json:"mfa_token"`
+	MFATokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+type deviceConfirmationResp struct {
+	DeviceConfirmationRequired bool `json:"device_confirmation_required"`
+}
+
+func toLoginResponse(data *service.AuthResult) any {
+	if data.DeviceConfirmationRequired {
+		return deviceConfirmationResp{DeviceConfirmationRequired: true}
+	}
+	if data.MFARequired {
+		return mfaChallengeResp{
+			MFARequired:       true,
+			MFAToken:          data.MFAToken,
+			MFATokenExpiresAt: data.MFATokenExpiresAt,
+		}
+	}
+	return userLoginResp{
+		AccessToken:           data.AccessToken,
+		AccessTokenExpiresAt:  data.AccessTokenExpiresAt,
+		RefreshToken:          data.RefreshToken,
+		RefreshTokenExpiresAt: data.RefreshTokenExpiresAt,
+		SessionID:             data.SessionID,
+		User: userResp{
+			Username: data.User.Username,
+			Email:    data.User.Email.String(),
+			FullName: data.User.FullName,
+		},
+	}
+}
+
 func (uh *UserHandler) LoginAccount(ctx *gin.Context) {
 	var loginUser userLoginReq
 	err := ctx.ShouldBindJSON(&loginUser)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 
 	login := service.Logininput{
 		Username:  loginUser.Username,
 		Password:  loginUser.Password,
-		ClientIP:  netutil.GetClientIP(ctx.Request),
+		ClientIP:  middleware.ClientIP(ctx.Request),
 		UserAgent: ctx.Request.UserAgent(),
 	}
 	data, err := uh.UsrSvc.Login(ctx.Request.Context(), login)
 	if err != nil {
 		if appErr, ok := err.(*errorutil.AppError); ok {
-			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(err))
+			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(ctx.Request.Context(), err))
 			slog.Info("Handled client error in CreateAccount",
 				slog.Int("statusCode", int(appErr.Code)),
 				slog.String("message", appErr.Message),
@@ -124,24 +164,41 @@ func (uh *UserHandler) LoginAccount(ctx *gin.Context) {
 			return
 		}
 		fmt.Println(err)
-		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(ctx.Request.Context(), err))
 
 		return
 	}
 
-	result := userLoginResp{
-		AccessToken:           data.AccessToken,
-		AccessTokenExpiresAt:  data.AccessTokenExpiresAt,
-		RefreshToken:          data.RefreshToken,
-		RefreshTokenExpiresAt: data.RefreshTokenExpiresAt,
-		SessionID:             data.SessionID,
-		User: userResp{
-			Username: data.User.Username,
-			Email:    data.User.Email.String(),
-			FullName: data.User.FullName,
-		},
+	ctx.JSON(http.StatusOK, toLoginResponse(data))
+}
+
+type verifyMFAReq struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+func (uh *UserHandler) VerifyMFA(ctx *gin.Context) {
+	var req verifyMFAReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
+		return
+	}
+
+	data, err := uh.UsrSvc.VerifyMFA(ctx.Request.Context(), service.VerifyMFAInput{
+		MFAToken:  req.MFAToken,
+		Code:      req.Code,
+		ClientIP:  middleware.ClientIP(ctx.Request),
+		UserAgent: ctx.Request.UserAgent(),
+	})
+	if err != nil {
+		if appErr, ok := err.(*errorutil.AppError); ok {
+			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(ctx.Request.Context(), err))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
+		return
 	}
-	ctx.JSON(http.StatusOK, result)
+	ctx.JSON(http.StatusOK, toLoginResponse(data))
 }
 
 type renew struct {
@@ -149,22 +206,25 @@ type renew struct {
 }
 
 type RenewAccessTokenResp struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
 func (uh *UserHandler) RenewAccessToken(ctx *gin.Context) {
 	var refreshToken renew
 	err := ctx.ShouldBindJSON(&refreshToken)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(err))
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
 		return
 	}
 	ctx.ClientIP()
 	accessToken, err := uh.UsrSvc.RenewAccessToken(ctx.Request.Context(), refreshToken.RefreshToken)
 	if err != nil {
 		if appErr, ok := err.(*errorutil.AppError); ok {
-			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(err))
+			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(ctx.Request.Context(), err))
 			slog.Info("Handled client error in CreateAccount",
 				slog.Int("statusCode", int(appErr.Code)),
 				slog.String("message", appErr.Message),
@@ -172,14 +232,55 @@ func (uh *UserHandler) RenewAccessToken(ctx *gin.Context) {
 			return
 		}
 		fmt.Println(err)
-		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, util.ErrorResponse(ctx.Request.Context(), err))
 
 		return
 	}
 
 	access := RenewAccessTokenResp{
-		AccessToken:          accessToken.AccessToken,
-		AccessTokenExpiresAt: accessToken.AccessTokenExpiresAt,
+		SessionID:             accessToken.SessionID,
+		AccessToken:           accessToken.AccessToken,
+		AccessTokenExpiresAt:  accessToken.AccessTokenExpiresAt,
+		RefreshToken:          accessToken.RefreshToken,
+		RefreshTokenExpiresAt: accessToken.RefreshTokenExpiresAt,
 	}
 	ctx.JSON(http.StatusOK, access)
 }
+
+type reauthenticateReq struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type reauthenticateResp struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	AuthTime             time.Time `json:"auth_time"`
+}
+
+func (uh *UserHandler) Reauthenticate(ctx *gin.Context) {
+	var req reauthenticateReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
+		return
+	}
+	payload := ctx.MustGet(middleware.AuthorizationPayLoadKey).(*auth.Payload)
+
+	result, err := uh.UsrSvc.Reauthenticate(ctx.Request.Context(), payload, service.ReauthenticateInput{
+		Password: req.Password,
+		Code:     req.Code,
+	})
+	if err != nil {
+		if appErr, ok := err.(*errorutil.AppError); ok {
+			ctx.JSON(errorutil.MapErrorToHttpStatus(appErr), util.ErrorResponse(ctx.Request.Context(), err))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), err))
+		return
+	}
+	ctx.JSON(http.StatusOK, reauthenticateResp{
+		AccessToken:          result.AccessToken,
+		AccessTokenExpiresAt: result.AccessTokenExpiresAt,
+		AuthTime:             result.AuthTime,
+	})
+}
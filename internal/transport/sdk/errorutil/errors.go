@@ -2,7 +2,6 @@ package errorutil
 
 import (
 	"errors"
-	"fmt"
 )
 
 type ErrorKind int8
@@ -15,6 +14,7 @@ const (
 	ErrConflict
 	ErrUnauthorized
 	ErrForbidden
+	ErrTooManyRequests
 	ErrInternal
 )
 
@@ -33,7 +33,7 @@ type AppError struct {
 }
 
 func (e *AppError) Error() string {
-	return fmt.Sprintf(e.Message)
+	return e.Message
 }
 
 func (e *AppError) Unwrap() error {
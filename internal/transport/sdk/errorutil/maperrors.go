@@ -16,6 +16,12 @@ func MapErrorToHttpStatus(err error) int {
 		return http.StatusBadRequest
 	case appErr.Code == ErrConflict:
 		return http.StatusConflict
+	case appErr.Code == ErrUnauthorized:
+		return http.StatusUnauthorized
+	case appErr.Code == ErrForbidden:
+		return http.StatusForbidden
+	case appErr.Code == ErrTooManyRequests:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -32,6 +38,10 @@ func MapErrorToGRPCStatus(err error) codes.Code {
 		return codes.InvalidArgument
 	case appErr.Code == ErrUnauthorized:
 		return codes.PermissionDenied
+	case appErr.Code == ErrForbidden:
+		return codes.PermissionDenied
+	case appErr.Code == ErrTooManyRequests:
+		return codes.ResourceExhausted
 	default:
 		return codes.Internal
 	}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/requestid"
 	"github.com/0xOnah/bank/internal/sdk/util"
 	"github.com/gin-gonic/gin"
 )
@@ -20,25 +22,31 @@ func Authenication(payload auth.Authenticator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader(authorizationHeader)
 		if authHeader == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.ErrorResponse(errors.New("auth header not provided")))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.ErrorResponse(ctx.Request.Context(), errors.New("auth header not provided")))
 			return
 		}
 		authParams := strings.Fields(authHeader)
 		if len(authParams) != 2 {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.ErrorResponse(errors.New("malformed auth header")))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.ErrorResponse(ctx.Request.Context(), errors.New("malformed auth header")))
 			return
 		}
 
 		authorizationType := strings.ToLower(authParams[0])
 		if authorizationType != authorizationTypeBearer {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.ErrorResponse(errors.New("malformed auth header")))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.ErrorResponse(ctx.Request.Context(), errors.New("malformed auth header")))
 			return
 		}
 
 		accessToken := authParams[1]
-		payload, err := payload.VerifyToken(accessToken)
+		payload, err := auth.VerifyTokenContext(ctx.Request.Context(), payload, accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.ErrorResponse(err))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.ErrorResponse(ctx.Request.Context(), err))
+			return
+		}
+
+		//api keys and oauth tokens only reach the routes they were granted
+		if !payload.AllowsScope(ctx.Request.Method + " " + ctx.FullPath()) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, util.ErrorResponse(ctx.Request.Context(), errors.New("token is not allowed to call this route")))
 			return
 		}
 
@@ -47,3 +55,18 @@ func Authenication(payload auth.Authenticator) gin.HandlerFunc {
 	}
 
 }
+
+// AbortStepUp answers a request whose token failed a step-up requirement with a 401, an RFC 9470
+// WWW-Authenticate challenge and the error code clients use to re-prompt the user.
+func AbortStepUp(ctx *gin.Context, err *auth.StepUpError) {
+	ctx.Header("WWW-Authenticate", err.WWWAuthenticate())
+	body := gin.H{
+		"code":    auth.StepUpErrorCode,
+		"message": err.Error(),
+		"max_age": int(err.Requirement.MaxAge / time.Second),
+	}
+	if id := requestid.FromContext(ctx.Request.Context()); id != "" {
+		body["request_id"] = id
+	}
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": body})
+}
@@ -58,24 +58,29 @@ func TestAuth(t *testing.T) {
 			},
 		},
 	}
-	for i := range testAuth {
-		tc := testAuth[i]
-		t.Run(tc.name, func(t *testing.T) {
-			maker, err := auth.NewJWTMaker("helloworldhelloworldhelloworldhelloworld")
-			require.NoError(t, err)
+	jwtMaker, err := auth.NewJWTMaker("helloworldhelloworldhelloworldhelloworld")
+	require.NoError(t, err)
+	pasetoMaker, err := auth.NewPasetoLocalMaker("helloworldhelloworldhelloworld12")
+	require.NoError(t, err)
+	makers := map[string]auth.Authenticator{"jwt": jwtMaker, "paseto": pasetoMaker}
 
-			router := gin.New()
-			router.GET("/auth", Authenication(maker), func(ctx *gin.Context) {
-				ctx.JSON(http.StatusOK, gin.H{})
-			})
+	for makerName, maker := range makers {
+		for i := range testAuth {
+			tc := testAuth[i]
+			t.Run(makerName+"/"+tc.name, func(t *testing.T) {
+				router := gin.New()
+				router.GET("/auth", Authenication(maker), func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				})
 
-			rec := httptest.NewRecorder()
-			req, err := http.NewRequest(http.MethodGet, "/auth", nil)
-			require.NoError(t, err)
+				rec := httptest.NewRecorder()
+				req, err := http.NewRequest(http.MethodGet, "/auth", nil)
+				require.NoError(t, err)
 
-			tc.setupAuth(req, maker)
-			router.ServeHTTP(rec, req)
-			tc.checkResponse(rec)
-		})
+				tc.setupAuth(req, maker)
+				router.ServeHTTP(rec, req)
+				tc.checkResponse(rec)
+			})
+		}
 	}
 }
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/netutil"
+)
+
+// ClientInfo stores the caller's ip and user agent in the request context for the audit log,
+// lockouts and rate limits. X-Forwarded-For is only read from proxies.
+func ClientInfo(proxies netutil.Proxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := audit.WithClient(r.Context(), audit.Client{
+				IP:        proxies.ClientIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For")),
+				UserAgent: r.UserAgent(),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIP returns the ip ClientInfo stored for r, or the connection's address without its
+// port when ClientInfo isn't mounted.
+func ClientIP(r *http.Request) string {
+	if ip := audit.ClientFromContext(r.Context()).IP; ip != "" {
+		return ip
+	}
+	return netutil.Host(r.RemoteAddr)
+}
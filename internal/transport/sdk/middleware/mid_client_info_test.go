@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xOnah/bank/internal/sdk/audit"
+	"github.com/0xOnah/bank/internal/sdk/netutil"
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientInfo(t *testing.T) {
+	log := zerolog.Nop()
+	proxies, err := netutil.ParseProxies("192.0.2.0/24")
+	require.NoError(t, err)
+	var event struct{ ip, userAgent, requestID string }
+	handler := RequestID(&log)(ClientInfo(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := audit.NewEvent(r.Context(), audit.ActionLogin, "hector", audit.UserSubject("hector"))
+		event.ip, event.userAgent, event.requestID = e.ClientIP, e.UserAgent, e.RequestID
+	})))
+
+	//httptest requests come from 192.0.2.1, one of the proxies
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7")
+	req.Header.Set("User-Agent", "Firefox")
+	req.Header.Set(requestid.Header, "client-supplied-id")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	//only the entry the proxy appended is believed
+	require.Equal(t, "203.0.113.7", event.ip)
+	require.Equal(t, "Firefox", event.userAgent)
+	require.Equal(t, "client-supplied-id", event.requestID)
+
+	//a client connecting directly can't choose its address
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.50:41234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.9")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, "203.0.113.50", event.ip)
+}
@@ -2,13 +2,40 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/metrics"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
+type routeKey struct{}
+
+// SetRoute records the matched route template so LogRequest can label the request
+// by route instead of by raw path.
+func SetRoute(ctx context.Context, route string) {
+	if r, ok := ctx.Value(routeKey{}).(*string); ok {
+		*r = route
+	}
+}
+
+// GatewayRoute is a grpc-gateway middleware that reports the matched path pattern
+// to LogRequest and names the active http span after it.
+func GatewayRoute(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		if pattern, ok := runtime.HTTPPattern(r.Context()); ok {
+			SetRoute(r.Context(), pattern.String())
+			trace.SpanFromContext(r.Context()).SetName(r.Method + " " + pattern.String())
+		}
+		next(w, r, pathParams)
+	}
+}
+
 type ResponseWriterWithStatus struct {
 	http.ResponseWriter
 	status  int
@@ -41,21 +68,35 @@ func (rs *ResponseWriterWithStatus) Write(data []byte) (int, error) {
 	return rs.ResponseWriter.Write(data)
 }
 
-func LogRequest(log *zerolog.Logger) func(next http.Handler) http.Handler {
+func LogRequest(log *zerolog.Logger, m *metrics.Metrics) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			wr := NewResponseWS(w)
 			start := time.Now()
+			var route string
+			r = r.WithContext(context.WithValue(r.Context(), routeKey{}, &route))
 			next.ServeHTTP(wr, r)
 
-			logger := log.Info()
+			//fall back to the net/http pattern for handlers outside the gateway
+			if route == "" {
+				route = r.Pattern
+			}
+			if route == "" {
+				route = "unmatched"
+			}
+
+			log := logger.FromCTX(r.Context(), log)
+			event := log.Info()
 			if wr.status != http.StatusOK {
-				logger = log.Error().Err(fmt.Errorf("an error occured")).Str("body", wr.body.String())
+				event = log.Error().Err(fmt.Errorf("an error occured")).Str("body", wr.body.String())
 			}
 			defer func() {
-				logger.
-					Dur("duration_ms", time.Since(start)).
+				duration := time.Since(start)
+				m.ObserveHTTP(r.Method, route, wr.status, duration)
+				event.
+					Dur("duration_ms", duration).
 					Str("method", r.Method).
+					Str("route", route).
 					Int("status_code", int(wr.status)).
 					Str("status_text", http.StatusText(wr.status)).
 					Msg("recieved a http request")
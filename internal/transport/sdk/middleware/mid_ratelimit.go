@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/ratelimit"
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const retryAfterHeader = "Retry-After"
+
+// RateLimit throttles a gin route by client ip and by user: the one authenticated when mounted
+// after Authenication, or else the one a login or MFA body names. Throttled requests get a 429
+// with a Retry-After header. It fails open when the limiter errors.
+func RateLimit(enforcer *ratelimit.Enforcer, tokens auth.Authenticator, log *zerolog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		username := bodyUser(ctx.Request, tokens)
+		if payload, ok := ctx.Get(AuthorizationPayLoadKey); ok {
+			username = payload.(*auth.Payload).Username
+		}
+		subjects := []ratelimit.Subject{ratelimit.IP(ClientIP(ctx.Request)), ratelimit.User(username)}
+
+		op := ctx.Request.Method + " " + ctx.FullPath()
+		result, err := enforcer.Allow(ctx.Request.Context(), op, subjects...)
+		if err != nil {
+			logger.FromCTX(ctx.Request.Context(), log).Error().Err(err).Str("route", op).Msg("rate limiter unavailable")
+			ctx.Next()
+			return
+		}
+		if !result.Allowed {
+			ctx.Header(retryAfterHeader, strconv.Itoa(result.RetryAfterSeconds()))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests,
+				util.ErrorResponse(ctx.Request.Context(), errors.New(result.Status().Message())))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// GatewayRateLimit is a grpc-gateway middleware throttling routes by client ip and by the user
+// a login or MFA body names. Throttled requests get a 429 with a Retry-After header and the
+// gateway's status error body.
+func GatewayRateLimit(enforcer *ratelimit.Enforcer, tokens auth.Authenticator, log *zerolog.Logger) runtime.Middleware {
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			pattern, ok := runtime.HTTPPattern(r.Context())
+			if !ok {
+				next(w, r, pathParams)
+				return
+			}
+
+			op := r.Method + " " + pattern.String()
+			result, err := enforcer.Allow(r.Context(), op, ratelimit.IP(ClientIP(r)), ratelimit.User(bodyUser(r, tokens)))
+			if err != nil {
+				logger.FromCTX(r.Context(), log).Error().Err(err).Str("route", op).Msg("rate limiter unavailable")
+				next(w, r, pathParams)
+				return
+			}
+			if result.Allowed {
+				next(w, r, pathParams)
+				return
+			}
+
+			st := result.Status()
+			if id := requestid.FromContext(r.Context()); id != "" {
+				if withID, err := st.WithDetails(&errdetails.RequestInfo{RequestId: id}); err == nil {
+					st = withID
+				}
+			}
+			body, err := protojson.Marshal(st.Proto())
+			if err != nil {
+				http.Error(w, st.Message(), http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(retryAfterHeader, strconv.Itoa(result.RetryAfterSeconds()))
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write(body)
+		}
+	}
+}
+
+// maxLoginBody bounds how much of a body bodyUser reads.
+const maxLoginBody = 4 << 10
+
+// loginFields are the fields naming who a login or MFA attempt is against. The gateway takes
+// the lowerCamel JSON name of a field as well as its proto name.
+type loginFields struct {
+	Username      string `json:"username"`
+	MFAToken      string `json:"mfa_token"`
+	MFATokenCamel string `json:"mfaToken"`
+}
+
+// bodyUser returns the user a JSON body names by username or MFA token, so guesses against
+// one account are throttled across ips. The body is left for the handler to read.
+func bodyUser(r *http.Request, tokens auth.Authenticator) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxLoginBody))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), r.Body))
+	if err != nil {
+		return ""
+	}
+
+	var fields loginFields
+	if json.Unmarshal(data, &fields) != nil {
+		return ""
+	}
+	if fields.Username != "" {
+		return fields.Username
+	}
+	return auth.MFATokenUsername(tokens, cmp.Or(fields.MFAToken, fields.MFATokenCamel))
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xOnah/bank/internal/sdk/auth"
+	"github.com/0xOnah/bank/internal/sdk/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	log := zerolog.Nop()
+	enforcer := ratelimit.NewEnforcer(ratelimit.NewMemoryLimiter(), ratelimit.Policy{
+		"POST /login": {Name: "login", Rule: ratelimit.PerMinute(2)},
+	})
+
+	router := gin.New()
+	limit := RateLimit(enforcer, nil, &log)
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	router.POST("/login", limit, ok)
+	router.POST("/user", limit, ok)
+
+	send := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec
+	}
+
+	for range 2 {
+		require.Equal(t, http.StatusOK, send("/login").Code)
+	}
+	rec := send("/login")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "30", rec.Header().Get(retryAfterHeader))
+	require.Contains(t, rec.Body.String(), "too many requests")
+
+	//routes outside the policy are not limited
+	for range 5 {
+		require.Equal(t, http.StatusOK, send("/user").Code)
+	}
+}
+
+func TestRateLimitLoginUser(t *testing.T) {
+	log := zerolog.Nop()
+	maker, err := auth.NewJWTMaker("123456789123456789123456789123456789123456789")
+	require.NoError(t, err)
+	enforcer := ratelimit.NewEnforcer(ratelimit.NewMemoryLimiter(), ratelimit.Policy{
+		"POST /login":     {Name: "login", Rule: ratelimit.PerMinute(2)},
+		"POST /login/mfa": {Name: "verify_mfa", Rule: ratelimit.PerMinute(2)},
+	})
+
+	router := gin.New()
+	limit := RateLimit(enforcer, maker, &log)
+	echo := func(ctx *gin.Context) {
+		var body map[string]string
+		require.NoError(t, ctx.ShouldBindJSON(&body))
+		ctx.String(http.StatusOK, body["username"])
+	}
+	router.POST("/login", limit, echo)
+	router.POST("/login/mfa", limit, echo)
+
+	send := func(path, addr, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	//guesses against one user are throttled however many ips they come from
+	for i := range 2 {
+		rec := send("/login", fmt.Sprintf("203.0.11%d.7:4000", i), `{"username":"hector"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "hector", rec.Body.String())
+	}
+	require.Equal(t, http.StatusTooManyRequests, send("/login", "203.0.119.9:4000", `{"username":"hector"}`).Code)
+	require.Equal(t, http.StatusOK, send("/login", "203.0.119.9:4000", `{"username":"achilles"}`).Code)
+
+	//second factor attempts count against the user the mfa token was issued to
+	mfaToken, _, err := maker.GenerateToken("hector", time.Minute, auth.WithPurpose(auth.PurposeMFA))
+	require.NoError(t, err)
+	body := fmt.Sprintf(`{"mfa_token":%q,"code":"123456"}`, mfaToken)
+	for i := range 2 {
+		require.Equal(t, http.StatusOK, send("/login/mfa", fmt.Sprintf("198.51.10%d.7:4000", i), body).Code)
+	}
+	require.Equal(t, http.StatusTooManyRequests, send("/login/mfa", "198.51.109.9:4000", body).Code)
+}
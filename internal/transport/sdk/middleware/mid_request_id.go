@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/0xOnah/bank/internal/sdk/logger"
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/rs/zerolog"
+)
+
+// RequestID accepts the caller's X-Request-ID (or generates one), echoes it in the response
+// and stores it, with a logger tagged by it, in the request context. It must wrap LogRequest.
+func RequestID(log *zerolog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := requestid.FromIncoming(r.Header.Get(requestid.Header))
+			w.Header().Set(requestid.Header, id)
+
+			reqLog := log.With().Str("request_id", id).Logger()
+			ctx := requestid.NewContext(r.Context(), id)
+			ctx = logger.WithCTXLogger(ctx, &reqLog)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xOnah/bank/internal/sdk/requestid"
+	"github.com/0xOnah/bank/internal/sdk/util"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	log := zerolog.New(&logs)
+
+	router := gin.New()
+	router.GET("/fail", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusBadRequest, util.ErrorResponse(ctx.Request.Context(), errors.New("bad request")))
+	})
+	handler := RequestID(&log)(LogRequest(&log, nil)(router))
+
+	testCases := []struct {
+		name     string
+		incoming string
+		check    func(id string)
+	}{
+		{
+			name:     "accepts client id",
+			incoming: "client-supplied-id",
+			check: func(id string) {
+				require.Equal(t, "client-supplied-id", id)
+			},
+		}, {
+			name: "generates id",
+			check: func(id string) {
+				require.True(t, requestid.Valid(id))
+			},
+		}, {
+			name:     "replaces invalid id",
+			incoming: "forged\nlog line",
+			check: func(id string) {
+				require.NotEqual(t, "forged\nlog line", id)
+				require.True(t, requestid.Valid(id))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			if tc.incoming != "" {
+				req.Header.Set(requestid.Header, tc.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(requestid.Header)
+			tc.check(id)
+
+			var body struct {
+				Error struct {
+					RequestID string `json:"request_id"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Equal(t, id, body.Error.RequestID)
+
+			var line struct {
+				RequestID string `json:"request_id"`
+			}
+			require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+			require.Equal(t, id, line.RequestID)
+		})
+	}
+}
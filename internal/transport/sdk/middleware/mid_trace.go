@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceRoute names the active http span after the matched gin route instead of the raw path.
+func TraceRoute() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if route := ctx.FullPath(); route != "" {
+			trace.SpanFromContext(ctx.Request.Context()).SetName(ctx.Request.Method + " " + route)
+			SetRoute(ctx.Request.Context(), route)
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,1051 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: rpc_account_consents.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AccountConsent struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ConsentId  string                 `protobuf:"bytes,1,opt,name=consent_id,json=consentId,proto3" json:"consent_id,omitempty"`
+	ClientId   string                 `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	AccountIds []int64                `protobuf:"varint,3,rep,packed,name=account_ids,json=accountIds,proto3" json:"account_ids,omitempty"`
+	// "balances" and/or "transactions"
+	Permissions []string `protobuf:"bytes,4,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	// "valid", "expired" or "revoked"
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountConsent) Reset() {
+	*x = AccountConsent{}
+	mi := &file_rpc_account_consents_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountConsent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountConsent) ProtoMessage() {}
+
+func (x *AccountConsent) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountConsent.ProtoReflect.Descriptor instead.
+func (*AccountConsent) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AccountConsent) GetConsentId() string {
+	if x != nil {
+		return x.ConsentId
+	}
+	return ""
+}
+
+func (x *AccountConsent) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *AccountConsent) GetAccountIds() []int64 {
+	if x != nil {
+		return x.AccountIds
+	}
+	return nil
+}
+
+func (x *AccountConsent) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *AccountConsent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AccountConsent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *AccountConsent) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CreateAccountConsentRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ClientId    string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	AccountIds  []int64                `protobuf:"varint,2,rep,packed,name=account_ids,json=accountIds,proto3" json:"account_ids,omitempty"`
+	Permissions []string               `protobuf:"bytes,3,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	// at most 90 days away
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccountConsentRequest) Reset() {
+	*x = CreateAccountConsentRequest{}
+	mi := &file_rpc_account_consents_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountConsentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountConsentRequest) ProtoMessage() {}
+
+func (x *CreateAccountConsentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountConsentRequest.ProtoReflect.Descriptor instead.
+func (*CreateAccountConsentRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateAccountConsentRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *CreateAccountConsentRequest) GetAccountIds() []int64 {
+	if x != nil {
+		return x.AccountIds
+	}
+	return nil
+}
+
+func (x *CreateAccountConsentRequest) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *CreateAccountConsentRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CreateAccountConsentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Consent       *AccountConsent        `protobuf:"bytes,1,opt,name=consent,proto3" json:"consent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccountConsentResponse) Reset() {
+	*x = CreateAccountConsentResponse{}
+	mi := &file_rpc_account_consents_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountConsentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountConsentResponse) ProtoMessage() {}
+
+func (x *CreateAccountConsentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountConsentResponse.ProtoReflect.Descriptor instead.
+func (*CreateAccountConsentResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateAccountConsentResponse) GetConsent() *AccountConsent {
+	if x != nil {
+		return x.Consent
+	}
+	return nil
+}
+
+type ListAccountConsentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountConsentsRequest) Reset() {
+	*x = ListAccountConsentsRequest{}
+	mi := &file_rpc_account_consents_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountConsentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountConsentsRequest) ProtoMessage() {}
+
+func (x *ListAccountConsentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountConsentsRequest.ProtoReflect.Descriptor instead.
+func (*ListAccountConsentsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{3}
+}
+
+type ListAccountConsentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Consents      []*AccountConsent      `protobuf:"bytes,1,rep,name=consents,proto3" json:"consents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountConsentsResponse) Reset() {
+	*x = ListAccountConsentsResponse{}
+	mi := &file_rpc_account_consents_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountConsentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountConsentsResponse) ProtoMessage() {}
+
+func (x *ListAccountConsentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountConsentsResponse.ProtoReflect.Descriptor instead.
+func (*ListAccountConsentsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListAccountConsentsResponse) GetConsents() []*AccountConsent {
+	if x != nil {
+		return x.Consents
+	}
+	return nil
+}
+
+type RevokeAccountConsentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsentId     string                 `protobuf:"bytes,1,opt,name=consent_id,json=consentId,proto3" json:"consent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAccountConsentRequest) Reset() {
+	*x = RevokeAccountConsentRequest{}
+	mi := &file_rpc_account_consents_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAccountConsentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAccountConsentRequest) ProtoMessage() {}
+
+func (x *RevokeAccountConsentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAccountConsentRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAccountConsentRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RevokeAccountConsentRequest) GetConsentId() string {
+	if x != nil {
+		return x.ConsentId
+	}
+	return ""
+}
+
+type RevokeAccountConsentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAccountConsentResponse) Reset() {
+	*x = RevokeAccountConsentResponse{}
+	mi := &file_rpc_account_consents_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAccountConsentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAccountConsentResponse) ProtoMessage() {}
+
+func (x *RevokeAccountConsentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAccountConsentResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAccountConsentResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{6}
+}
+
+type GetAccountConsentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsentId     string                 `protobuf:"bytes,1,opt,name=consent_id,json=consentId,proto3" json:"consent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountConsentRequest) Reset() {
+	*x = GetAccountConsentRequest{}
+	mi := &file_rpc_account_consents_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountConsentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountConsentRequest) ProtoMessage() {}
+
+func (x *GetAccountConsentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountConsentRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountConsentRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetAccountConsentRequest) GetConsentId() string {
+	if x != nil {
+		return x.ConsentId
+	}
+	return ""
+}
+
+type GetAccountConsentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Consent       *AccountConsent        `protobuf:"bytes,1,opt,name=consent,proto3" json:"consent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountConsentResponse) Reset() {
+	*x = GetAccountConsentResponse{}
+	mi := &file_rpc_account_consents_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountConsentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountConsentResponse) ProtoMessage() {}
+
+func (x *GetAccountConsentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountConsentResponse.ProtoReflect.Descriptor instead.
+func (*GetAccountConsentResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetAccountConsentResponse) GetConsent() *AccountConsent {
+	if x != nil {
+		return x.Consent
+	}
+	return nil
+}
+
+type ConsentedAccount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsentedAccount) Reset() {
+	*x = ConsentedAccount{}
+	mi := &file_rpc_account_consents_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsentedAccount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsentedAccount) ProtoMessage() {}
+
+func (x *ConsentedAccount) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsentedAccount.ProtoReflect.Descriptor instead.
+func (*ConsentedAccount) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ConsentedAccount) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *ConsentedAccount) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type ListConsentedAccountsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsentId     string                 `protobuf:"bytes,1,opt,name=consent_id,json=consentId,proto3" json:"consent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConsentedAccountsRequest) Reset() {
+	*x = ListConsentedAccountsRequest{}
+	mi := &file_rpc_account_consents_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConsentedAccountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConsentedAccountsRequest) ProtoMessage() {}
+
+func (x *ListConsentedAccountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConsentedAccountsRequest.ProtoReflect.Descriptor instead.
+func (*ListConsentedAccountsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListConsentedAccountsRequest) GetConsentId() string {
+	if x != nil {
+		return x.ConsentId
+	}
+	return ""
+}
+
+type ListConsentedAccountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*ConsentedAccount    `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConsentedAccountsResponse) Reset() {
+	*x = ListConsentedAccountsResponse{}
+	mi := &file_rpc_account_consents_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConsentedAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConsentedAccountsResponse) ProtoMessage() {}
+
+func (x *ListConsentedAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConsentedAccountsResponse.ProtoReflect.Descriptor instead.
+func (*ListConsentedAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListConsentedAccountsResponse) GetAccounts() []*ConsentedAccount {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+type GetConsentedAccountBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsentId     string                 `protobuf:"bytes,1,opt,name=consent_id,json=consentId,proto3" json:"consent_id,omitempty"`
+	AccountId     int64                  `protobuf:"varint,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsentedAccountBalanceRequest) Reset() {
+	*x = GetConsentedAccountBalanceRequest{}
+	mi := &file_rpc_account_consents_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsentedAccountBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsentedAccountBalanceRequest) ProtoMessage() {}
+
+func (x *GetConsentedAccountBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsentedAccountBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetConsentedAccountBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetConsentedAccountBalanceRequest) GetConsentId() string {
+	if x != nil {
+		return x.ConsentId
+	}
+	return ""
+}
+
+func (x *GetConsentedAccountBalanceRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+type GetConsentedAccountBalanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Balance       int64                  `protobuf:"varint,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsentedAccountBalanceResponse) Reset() {
+	*x = GetConsentedAccountBalanceResponse{}
+	mi := &file_rpc_account_consents_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsentedAccountBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsentedAccountBalanceResponse) ProtoMessage() {}
+
+func (x *GetConsentedAccountBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsentedAccountBalanceResponse.ProtoReflect.Descriptor instead.
+func (*GetConsentedAccountBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetConsentedAccountBalanceResponse) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *GetConsentedAccountBalanceResponse) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *GetConsentedAccountBalanceResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type ConsentedTransaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId int64                  `protobuf:"varint,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	// negative for money leaving the account
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsentedTransaction) Reset() {
+	*x = ConsentedTransaction{}
+	mi := &file_rpc_account_consents_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsentedTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsentedTransaction) ProtoMessage() {}
+
+func (x *ConsentedTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsentedTransaction.ProtoReflect.Descriptor instead.
+func (*ConsentedTransaction) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ConsentedTransaction) GetTransactionId() int64 {
+	if x != nil {
+		return x.TransactionId
+	}
+	return 0
+}
+
+func (x *ConsentedTransaction) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ConsentedTransaction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListConsentedAccountTransactionsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ConsentId string                 `protobuf:"bytes,1,opt,name=consent_id,json=consentId,proto3" json:"consent_id,omitempty"`
+	AccountId int64                  `protobuf:"varint,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// starts at 1, the default; at most 10000
+	PageId int32 `protobuf:"varint,3,opt,name=page_id,json=pageId,proto3" json:"page_id,omitempty"`
+	// defaults to 50, at most 200
+	PageSize      int32 `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConsentedAccountTransactionsRequest) Reset() {
+	*x = ListConsentedAccountTransactionsRequest{}
+	mi := &file_rpc_account_consents_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConsentedAccountTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConsentedAccountTransactionsRequest) ProtoMessage() {}
+
+func (x *ListConsentedAccountTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConsentedAccountTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListConsentedAccountTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListConsentedAccountTransactionsRequest) GetConsentId() string {
+	if x != nil {
+		return x.ConsentId
+	}
+	return ""
+}
+
+func (x *ListConsentedAccountTransactionsRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *ListConsentedAccountTransactionsRequest) GetPageId() int32 {
+	if x != nil {
+		return x.PageId
+	}
+	return 0
+}
+
+func (x *ListConsentedAccountTransactionsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListConsentedAccountTransactionsResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Transactions  []*ConsentedTransaction `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConsentedAccountTransactionsResponse) Reset() {
+	*x = ListConsentedAccountTransactionsResponse{}
+	mi := &file_rpc_account_consents_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConsentedAccountTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConsentedAccountTransactionsResponse) ProtoMessage() {}
+
+func (x *ListConsentedAccountTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_account_consents_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConsentedAccountTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListConsentedAccountTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_account_consents_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListConsentedAccountTransactionsResponse) GetTransactions() []*ConsentedTransaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+var File_rpc_account_consents_proto protoreflect.FileDescriptor
+
+const file_rpc_account_consents_proto_rawDesc = "" +
+	"\n" +
+	"\x1arpc_account_consents.proto\x12\x02pb\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9d\x02\n" +
+	"\x0eAccountConsent\x12\x1d\n" +
+	"\n" +
+	"consent_id\x18\x01 \x01(\tR\tconsentId\x12\x1b\n" +
+	"\tclient_id\x18\x02 \x01(\tR\bclientId\x12\x1f\n" +
+	"\vaccount_ids\x18\x03 \x03(\x03R\n" +
+	"accountIds\x12 \n" +
+	"\vpermissions\x18\x04 \x03(\tR\vpermissions\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xb8\x01\n" +
+	"\x1bCreateAccountConsentRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x1f\n" +
+	"\vaccount_ids\x18\x02 \x03(\x03R\n" +
+	"accountIds\x12 \n" +
+	"\vpermissions\x18\x03 \x03(\tR\vpermissions\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"L\n" +
+	"\x1cCreateAccountConsentResponse\x12,\n" +
+	"\aconsent\x18\x01 \x01(\v2\x12.pb.AccountConsentR\aconsent\"\x1c\n" +
+	"\x1aListAccountConsentsRequest\"M\n" +
+	"\x1bListAccountConsentsResponse\x12.\n" +
+	"\bconsents\x18\x01 \x03(\v2\x12.pb.AccountConsentR\bconsents\"<\n" +
+	"\x1bRevokeAccountConsentRequest\x12\x1d\n" +
+	"\n" +
+	"consent_id\x18\x01 \x01(\tR\tconsentId\"\x1e\n" +
+	"\x1cRevokeAccountConsentResponse\"9\n" +
+	"\x18GetAccountConsentRequest\x12\x1d\n" +
+	"\n" +
+	"consent_id\x18\x01 \x01(\tR\tconsentId\"I\n" +
+	"\x19GetAccountConsentResponse\x12,\n" +
+	"\aconsent\x18\x01 \x01(\v2\x12.pb.AccountConsentR\aconsent\"M\n" +
+	"\x10ConsentedAccount\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"=\n" +
+	"\x1cListConsentedAccountsRequest\x12\x1d\n" +
+	"\n" +
+	"consent_id\x18\x01 \x01(\tR\tconsentId\"Q\n" +
+	"\x1dListConsentedAccountsResponse\x120\n" +
+	"\baccounts\x18\x01 \x03(\v2\x14.pb.ConsentedAccountR\baccounts\"a\n" +
+	"!GetConsentedAccountBalanceRequest\x12\x1d\n" +
+	"\n" +
+	"consent_id\x18\x01 \x01(\tR\tconsentId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\x03R\taccountId\"y\n" +
+	"\"GetConsentedAccountBalanceResponse\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\x03R\taccountId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\x03R\abalance\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\"\x90\x01\n" +
+	"\x14ConsentedTransaction\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\x03R\rtransactionId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x9d\x01\n" +
+	"'ListConsentedAccountTransactionsRequest\x12\x1d\n" +
+	"\n" +
+	"consent_id\x18\x01 \x01(\tR\tconsentId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\x03R\taccountId\x12\x17\n" +
+	"\apage_id\x18\x03 \x01(\x05R\x06pageId\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"h\n" +
+	"(ListConsentedAccountTransactionsResponse\x12<\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x18.pb.ConsentedTransactionR\ftransactionsB\x1bZ\x19github.com/0xOnah/bank/pbb\x06proto3"
+
+var (
+	file_rpc_account_consents_proto_rawDescOnce sync.Once
+	file_rpc_account_consents_proto_rawDescData []byte
+)
+
+func file_rpc_account_consents_proto_rawDescGZIP() []byte {
+	file_rpc_account_consents_proto_rawDescOnce.Do(func() {
+		file_rpc_account_consents_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_account_consents_proto_rawDesc), len(file_rpc_account_consents_proto_rawDesc)))
+	})
+	return file_rpc_account_consents_proto_rawDescData
+}
+
+var file_rpc_account_consents_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_rpc_account_consents_proto_goTypes = []any{
+	(*AccountConsent)(nil),                           // 0: pb.AccountConsent
+	(*CreateAccountConsentRequest)(nil),              // 1: pb.CreateAccountConsentRequest
+	(*CreateAccountConsentResponse)(nil),             // 2: pb.CreateAccountConsentResponse
+	(*ListAccountConsentsRequest)(nil),               // 3: pb.ListAccountConsentsRequest
+	(*ListAccountConsentsResponse)(nil),              // 4: pb.ListAccountConsentsResponse
+	(*RevokeAccountConsentRequest)(nil),              // 5: pb.RevokeAccountConsentRequest
+	(*RevokeAccountConsentResponse)(nil),             // 6: pb.RevokeAccountConsentResponse
+	(*GetAccountConsentRequest)(nil),                 // 7: pb.GetAccountConsentRequest
+	(*GetAccountConsentResponse)(nil),                // 8: pb.GetAccountConsentResponse
+	(*ConsentedAccount)(nil),                         // 9: pb.ConsentedAccount
+	(*ListConsentedAccountsRequest)(nil),             // 10: pb.ListConsentedAccountsRequest
+	(*ListConsentedAccountsResponse)(nil),            // 11: pb.ListConsentedAccountsResponse
+	(*GetConsentedAccountBalanceRequest)(nil),        // 12: pb.GetConsentedAccountBalanceRequest
+	(*GetConsentedAccountBalanceResponse)(nil),       // 13: pb.GetConsentedAccountBalanceResponse
+	(*ConsentedTransaction)(nil),                     // 14: pb.ConsentedTransaction
+	(*ListConsentedAccountTransactionsRequest)(nil),  // 15: pb.ListConsentedAccountTransactionsRequest
+	(*ListConsentedAccountTransactionsResponse)(nil), // 16: pb.ListConsentedAccountTransactionsResponse
+	(*timestamppb.Timestamp)(nil),                    // 17: google.protobuf.Timestamp
+}
+var file_rpc_account_consents_proto_depIdxs = []int32{
+	17, // 0: pb.AccountConsent.created_at:type_name -> google.protobuf.Timestamp
+	17, // 1: pb.AccountConsent.expires_at:type_name -> google.protobuf.Timestamp
+	17, // 2: pb.CreateAccountConsentRequest.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 3: pb.CreateAccountConsentResponse.consent:type_name -> pb.AccountConsent
+	0,  // 4: pb.ListAccountConsentsResponse.consents:type_name -> pb.AccountConsent
+	0,  // 5: pb.GetAccountConsentResponse.consent:type_name -> pb.AccountConsent
+	9,  // 6: pb.ListConsentedAccountsResponse.accounts:type_name -> pb.ConsentedAccount
+	17, // 7: pb.ConsentedTransaction.created_at:type_name -> google.protobuf.Timestamp
+	14, // 8: pb.ListConsentedAccountTransactionsResponse.transactions:type_name -> pb.ConsentedTransaction
+	9,  // [9:9] is the sub-list for method output_type
+	9,  // [9:9] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_rpc_account_consents_proto_init() }
+func file_rpc_account_consents_proto_init() {
+	if File_rpc_account_consents_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_account_consents_proto_rawDesc), len(file_rpc_account_consents_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_account_consents_proto_goTypes,
+		DependencyIndexes: file_rpc_account_consents_proto_depIdxs,
+		MessageInfos:      file_rpc_account_consents_proto_msgTypes,
+	}.Build()
+	File_rpc_account_consents_proto = out.File
+	file_rpc_account_consents_proto_goTypes = nil
+	file_rpc_account_consents_proto_depIdxs = nil
+}
@@ -0,0 +1,161 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: rpc_confirm_device.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ConfirmDeviceRequest approves a new sign-in device with the token from the emailed link.
+type ConfirmDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmDeviceRequest) Reset() {
+	*x = ConfirmDeviceRequest{}
+	mi := &file_rpc_confirm_device_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmDeviceRequest) ProtoMessage() {}
+
+func (x *ConfirmDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_confirm_device_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmDeviceRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_confirm_device_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConfirmDeviceRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type ConfirmDeviceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmDeviceResponse) Reset() {
+	*x = ConfirmDeviceResponse{}
+	mi := &file_rpc_confirm_device_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmDeviceResponse) ProtoMessage() {}
+
+func (x *ConfirmDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_confirm_device_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmDeviceResponse.ProtoReflect.Descriptor instead.
+func (*ConfirmDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_confirm_device_proto_rawDescGZIP(), []int{1}
+}
+
+var File_rpc_confirm_device_proto protoreflect.FileDescriptor
+
+const file_rpc_confirm_device_proto_rawDesc = "" +
+	"\n" +
+	"\x18rpc_confirm_device.proto\x12\x02pb\",\n" +
+	"\x14ConfirmDeviceRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x17\n" +
+	"\x15ConfirmDeviceResponseB\x1bZ\x19github.com/0xOnah/bank/pbb\x06proto3"
+
+var (
+	file_rpc_confirm_device_proto_rawDescOnce sync.Once
+	file_rpc_confirm_device_proto_rawDescData []byte
+)
+
+func file_rpc_confirm_device_proto_rawDescGZIP() []byte {
+	file_rpc_confirm_device_proto_rawDescOnce.Do(func() {
+		file_rpc_confirm_device_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_confirm_device_proto_rawDesc), len(file_rpc_confirm_device_proto_rawDesc)))
+	})
+	return file_rpc_confirm_device_proto_rawDescData
+}
+
+var file_rpc_confirm_device_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_confirm_device_proto_goTypes = []any{
+	(*ConfirmDeviceRequest)(nil),  // 0: pb.ConfirmDeviceRequest
+	(*ConfirmDeviceResponse)(nil), // 1: pb.ConfirmDeviceResponse
+}
+var file_rpc_confirm_device_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rpc_confirm_device_proto_init() }
+func file_rpc_confirm_device_proto_init() {
+	if File_rpc_confirm_device_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_confirm_device_proto_rawDesc), len(file_rpc_confirm_device_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_confirm_device_proto_goTypes,
+		DependencyIndexes: file_rpc_confirm_device_proto_depIdxs,
+		MessageInfos:      file_rpc_confirm_device_proto_msgTypes,
+	}.Build()
+	File_rpc_confirm_device_proto = out.File
+	file_rpc_confirm_device_proto_goTypes = nil
+	file_rpc_confirm_device_proto_depIdxs = nil
+}
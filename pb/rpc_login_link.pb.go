@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: rpc_login_link.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RequestLoginLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestLoginLinkRequest) Reset() {
+	*x = RequestLoginLinkRequest{}
+	mi := &file_rpc_login_link_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestLoginLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestLoginLinkRequest) ProtoMessage() {}
+
+func (x *RequestLoginLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_login_link_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestLoginLinkRequest.ProtoReflect.Descriptor instead.
+func (*RequestLoginLinkRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_login_link_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RequestLoginLinkRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type RequestLoginLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestLoginLinkResponse) Reset() {
+	*x = RequestLoginLinkResponse{}
+	mi := &file_rpc_login_link_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestLoginLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestLoginLinkResponse) ProtoMessage() {}
+
+func (x *RequestLoginLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_login_link_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestLoginLinkResponse.ProtoReflect.Descriptor instead.
+func (*RequestLoginLinkResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_login_link_proto_rawDescGZIP(), []int{1}
+}
+
+type ConsumeLoginLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumeLoginLinkRequest) Reset() {
+	*x = ConsumeLoginLinkRequest{}
+	mi := &file_rpc_login_link_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumeLoginLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeLoginLinkRequest) ProtoMessage() {}
+
+func (x *ConsumeLoginLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_login_link_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeLoginLinkRequest.ProtoReflect.Descriptor instead.
+func (*ConsumeLoginLinkRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_login_link_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConsumeLoginLinkRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+var File_rpc_login_link_proto protoreflect.FileDescriptor
+
+const file_rpc_login_link_proto_rawDesc = "" +
+	"\n" +
+	"\x14rpc_login_link.proto\x12\x02pb\"/\n" +
+	"\x17RequestLoginLinkRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"\x1a\n" +
+	"\x18RequestLoginLinkResponse\"/\n" +
+	"\x17ConsumeLoginLinkRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05tokenB\x1bZ\x19github.com/0xOnah/bank/pbb\x06proto3"
+
+var (
+	file_rpc_login_link_proto_rawDescOnce sync.Once
+	file_rpc_login_link_proto_rawDescData []byte
+)
+
+func file_rpc_login_link_proto_rawDescGZIP() []byte {
+	file_rpc_login_link_proto_rawDescOnce.Do(func() {
+		file_rpc_login_link_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_login_link_proto_rawDesc), len(file_rpc_login_link_proto_rawDesc)))
+	})
+	return file_rpc_login_link_proto_rawDescData
+}
+
+var file_rpc_login_link_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_rpc_login_link_proto_goTypes = []any{
+	(*RequestLoginLinkRequest)(nil),  // 0: pb.RequestLoginLinkRequest
+	(*RequestLoginLinkResponse)(nil), // 1: pb.RequestLoginLinkResponse
+	(*ConsumeLoginLinkRequest)(nil),  // 2: pb.ConsumeLoginLinkRequest
+}
+var file_rpc_login_link_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rpc_login_link_proto_init() }
+func file_rpc_login_link_proto_init() {
+	if File_rpc_login_link_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_login_link_proto_rawDesc), len(file_rpc_login_link_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_login_link_proto_goTypes,
+		DependencyIndexes: file_rpc_login_link_proto_depIdxs,
+		MessageInfos:      file_rpc_login_link_proto_msgTypes,
+	}.Build()
+	File_rpc_login_link_proto = out.File
+	file_rpc_login_link_proto_goTypes = nil
+	file_rpc_login_link_proto_depIdxs = nil
+}
@@ -82,8 +82,16 @@ type LoginUserResponse struct {
 	RefreshToken          string                 `protobuf:"bytes,4,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
 	AccessTokenExpiresAt  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=access_token_expires_at,json=accessTokenExpiresAt,proto3" json:"access_token_expires_at,omitempty"`
 	RefreshTokenExpiresAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=refresh_token_expires_at,json=refreshTokenExpiresAt,proto3" json:"refresh_token_expires_at,omitempty"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+	// set when the account has two-factor authentication: no session is created yet,
+	// pass mfa_token and a code to VerifyMFA instead
+	MfaRequired       bool                   `protobuf:"varint,7,opt,name=mfa_required,json=mfaRequired,proto3" json:"mfa_required,omitempty"`
+	MfaToken          string                 `protobuf:"bytes,8,opt,name=mfa_token,json=mfaToken,proto3" json:"mfa_token,omitempty"`
+	MfaTokenExpiresAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=mfa_token_expires_at,json=mfaTokenExpiresAt,proto3" json:"mfa_token_expires_at,omitempty"`
+	// set when the login came from an unrecognized device: no session is created, a link to
+	// approve the device was emailed and the user signs in again after opening it
+	DeviceConfirmationRequired bool `protobuf:"varint,10,opt,name=device_confirmation_required,json=deviceConfirmationRequired,proto3" json:"device_confirmation_required,omitempty"`
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
 }
 
 func (x *LoginUserResponse) Reset() {
@@ -158,6 +166,34 @@ func (x *LoginUserResponse) GetRefreshTokenExpiresAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *LoginUserResponse) GetMfaRequired() bool {
+	if x != nil {
+		return x.MfaRequired
+	}
+	return false
+}
+
+func (x *LoginUserResponse) GetMfaToken() string {
+	if x != nil {
+		return x.MfaToken
+	}
+	return ""
+}
+
+func (x *LoginUserResponse) GetMfaTokenExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MfaTokenExpiresAt
+	}
+	return nil
+}
+
+func (x *LoginUserResponse) GetDeviceConfirmationRequired() bool {
+	if x != nil {
+		return x.DeviceConfirmationRequired
+	}
+	return false
+}
+
 var File_rpc_login_user_proto protoreflect.FileDescriptor
 
 const file_rpc_login_user_proto_rawDesc = "" +
@@ -165,7 +201,7 @@ const file_rpc_login_user_proto_rawDesc = "" +
 	"\x14rpc_login_user.proto\x12\x02pb\x1a\vusers.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"J\n" +
 	"\x10LoginUserRequest\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"\xc0\x02\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"\x8f\x04\n" +
 	"\x11LoginUserResponse\x12\x1c\n" +
 	"\x04user\x18\x01 \x01(\v2\b.pb.UserR\x04user\x12\x1d\n" +
 	"\n" +
@@ -173,7 +209,12 @@ const file_rpc_login_user_proto_rawDesc = "" +
 	"\faccess_token\x18\x03 \x01(\tR\vaccessToken\x12#\n" +
 	"\rrefresh_token\x18\x04 \x01(\tR\frefreshToken\x12Q\n" +
 	"\x17access_token_expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x14accessTokenExpiresAt\x12S\n" +
-	"\x18refresh_token_expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x15refreshTokenExpiresAtB\x1bZ\x19github.com/0xOnah/bank/pbb\x06proto3"
+	"\x18refresh_token_expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x15refreshTokenExpiresAt\x12!\n" +
+	"\fmfa_required\x18\a \x01(\bR\vmfaRequired\x12\x1b\n" +
+	"\tmfa_token\x18\b \x01(\tR\bmfaToken\x12K\n" +
+	"\x14mfa_token_expires_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x11mfaTokenExpiresAt\x12@\n" +
+	"\x1cdevice_confirmation_required\x18\n" +
+	" \x01(\bR\x1adeviceConfirmationRequiredB\x1bZ\x19github.com/0xOnah/bank/pbb\x06proto3"
 
 var (
 	file_rpc_login_user_proto_rawDescOnce sync.Once
@@ -198,11 +239,12 @@ var file_rpc_login_user_proto_depIdxs = []int32{
 	2, // 0: pb.LoginUserResponse.user:type_name -> pb.User
 	3, // 1: pb.LoginUserResponse.access_token_expires_at:type_name -> google.protobuf.Timestamp
 	3, // 2: pb.LoginUserResponse.refresh_token_expires_at:type_name -> google.protobuf.Timestamp
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	3, // 3: pb.LoginUserResponse.mfa_token_expires_at:type_name -> google.protobuf.Timestamp
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_rpc_login_user_proto_init() }
@@ -25,34 +25,243 @@ var File_service_bank_proto protoreflect.FileDescriptor
 
 const file_service_bank_proto_rawDesc = "" +
 	"\n" +
-	"\x12service_bank.proto\x12\x02pb\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto\x1a\x15rpc_update_user.proto\x1a\x1cgoogle/api/annotations.proto2\x94\x02\n" +
+	"\x12service_bank.proto\x12\x02pb\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto\x1a\x15rpc_update_user.proto\x1a\x12rpc_sessions.proto\x1a\x16rpc_verify_email.proto\x1a\x18rpc_password_reset.proto\x1a\rrpc_mfa.proto\x1a\x18rpc_reauthenticate.proto\x1a\x1arpc_service_accounts.proto\x1a\x15rpc_unlock_user.proto\x1a\x18rpc_confirm_device.proto\x1a\x0frpc_audit.proto\x1a\x14rpc_login_link.proto\x1a\x12rpc_passkeys.proto\x1a\x0frpc_oauth.proto\x1a\x1arpc_account_consents.proto\x1a\x1cgoogle/api/annotations.proto2\xf8'\n" +
 	"\vUserService\x12W\n" +
 	"\n" +
 	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/create_user\x12S\n" +
 	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_user\x12W\n" +
 	"\n" +
-	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*2\x0f/v1/update_userB\x1bZ\x19github.com/0xOnah/bank/pbb\x06proto3"
+	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*2\x0f/v1/update_user\x12W\n" +
+	"\fListSessions\x12\x17.pb.ListSessionsRequest\x1a\x18.pb.ListSessionsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/sessions\x12g\n" +
+	"\rRevokeSession\x12\x18.pb.RevokeSessionRequest\x1a\x19.pb.RevokeSessionResponse\"!\x82\xd3\xe4\x93\x02\x1b*\x19/v1/sessions/{session_id}\x12}\n" +
+	"\x13RevokeOtherSessions\x12\x1e.pb.RevokeOtherSessionsRequest\x1a\x1f.pb.RevokeOtherSessionsResponse\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/sessions/revoke_others\x12X\n" +
+	"\vVerifyEmail\x12\x16.pb.VerifyEmailRequest\x1a\x17.pb.VerifyEmailResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/verify_email\x12t\n" +
+	"\x11ResendVerifyEmail\x12\x1c.pb.ResendVerifyEmailRequest\x1a\x1d.pb.ResendVerifyEmailResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/verify_email/resend\x12\x80\x01\n" +
+	"\x14RequestPasswordReset\x12\x1f.pb.RequestPasswordResetRequest\x1a .pb.RequestPasswordResetResponse\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/password_reset/request\x12c\n" +
+	"\rResetPassword\x12\x18.pb.ResetPasswordRequest\x1a\x19.pb.ResetPasswordResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/password_reset\x12W\n" +
+	"\tVerifyMFA\x12\x14.pb.VerifyMFARequest\x1a\x15.pb.LoginUserResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/login_user/mfa\x12p\n" +
+	"\x10RequestLoginLink\x12\x1b.pb.RequestLoginLinkRequest\x1a\x1c.pb.RequestLoginLinkResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/login_link/request\x12a\n" +
+	"\x10ConsumeLoginLink\x12\x1b.pb.ConsumeLoginLinkRequest\x1a\x15.pb.LoginUserResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_link\x12k\n" +
+	"\x11BeginPasskeyLogin\x12\x1c.pb.BeginPasskeyLoginRequest\x1a\x13.pb.PasskeyCeremony\"#\x82\xd3\xe4\x93\x02\x1d:\x01*\"\x18/v1/passkeys/login/begin\x12p\n" +
+	"\x12FinishPasskeyLogin\x12\x1d.pb.FinishPasskeyLoginRequest\x1a\x15.pb.LoginUserResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/passkeys/login/finish\x12|\n" +
+	"\x18BeginPasskeyRegistration\x12#.pb.BeginPasskeyRegistrationRequest\x1a\x13.pb.PasskeyCeremony\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/passkeys/register/begin\x12\x91\x01\n" +
+	"\x19FinishPasskeyRegistration\x12$.pb.FinishPasskeyRegistrationRequest\x1a%.pb.FinishPasskeyRegistrationResponse\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/v1/passkeys/register/finish\x12W\n" +
+	"\fListPasskeys\x12\x17.pb.ListPasskeysRequest\x1a\x18.pb.ListPasskeysResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/passkeys\x12_\n" +
+	"\rDeletePasskey\x12\x18.pb.DeletePasskeyRequest\x1a\x19.pb.DeletePasskeyResponse\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/v1/passkeys/{id}\x12[\n" +
+	"\n" +
+	"EnrollTOTP\x12\x15.pb.EnrollTOTPRequest\x1a\x16.pb.EnrollTOTPResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/mfa/totp/enroll\x12_\n" +
+	"\vConfirmTOTP\x12\x16.pb.ConfirmTOTPRequest\x1a\x17.pb.ConfirmTOTPResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/mfa/totp/confirm\x12_\n" +
+	"\vDisableTOTP\x12\x16.pb.DisableTOTPRequest\x1a\x17.pb.DisableTOTPResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/mfa/totp/disable\x12f\n" +
+	"\x0eReauthenticate\x12\x19.pb.ReauthenticateRequest\x1a\x1a.pb.ReauthenticateResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/reauthenticate\x12z\n" +
+	"\x14CreateServiceAccount\x12\x1f.pb.CreateServiceAccountRequest\x1a .pb.CreateServiceAccountResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/service_accounts\x12h\n" +
+	"\vListAPIKeys\x12\x16.pb.ListAPIKeysRequest\x1a\x17.pb.ListAPIKeysResponse\"(\x82\xd3\xe4\x93\x02\"\x12 /v1/service_accounts/{name}/keys\x12u\n" +
+	"\fRotateAPIKey\x12\x17.pb.RotateAPIKeyRequest\x1a\x18.pb.RotateAPIKeyResponse\"2\x82\xd3\xe4\x93\x02,:\x01*\"'/v1/service_accounts/{name}/keys/rotate\x12`\n" +
+	"\fRevokeAPIKey\x12\x17.pb.RevokeAPIKeyRequest\x1a\x18.pb.RevokeAPIKeyResponse\"\x1d\x82\xd3\xe4\x93\x02\x17*\x15/v1/api_keys/{prefix}\x12c\n" +
+	"\n" +
+	"UnlockUser\x12\x15.pb.UnlockUserRequest\x1a\x16.pb.UnlockUserResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/users/{username}/unlock\x12a\n" +
+	"\rConfirmDevice\x12\x18.pb.ConfirmDeviceRequest\x1a\x19.pb.ConfirmDeviceResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/devices/confirm\x12d\n" +
+	"\x0fListAuditEvents\x12\x1a.pb.ListAuditEventsRequest\x1a\x1b.pb.ListAuditEventsResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/audit_events\x12n\n" +
+	"\x11CreateOAuthClient\x12\x1c.pb.CreateOAuthClientRequest\x1a\x1d.pb.CreateOAuthClientResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/oauth/clients\x12w\n" +
+	"\x11RevokeOAuthClient\x12\x1c.pb.RevokeOAuthClientRequest\x1a\x1d.pb.RevokeOAuthClientResponse\"%\x82\xd3\xe4\x93\x02\x1f*\x1d/v1/oauth/clients/{client_id}\x12y\n" +
+	"\x14AuthorizeOAuthClient\x12\x1f.pb.AuthorizeOAuthClientRequest\x1a .pb.AuthorizeOAuthClientResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/oauth/authorize\x12W\n" +
+	"\n" +
+	"OAuthToken\x12\x15.pb.OAuthTokenRequest\x1a\x16.pb.OAuthTokenResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/oauth/token\x12j\n" +
+	"\x10RevokeOAuthToken\x12\x1b.pb.RevokeOAuthTokenRequest\x1a\x1c.pb.RevokeOAuthTokenResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/oauth/revoke\x12z\n" +
+	"\x14IntrospectOAuthToken\x12\x1f.pb.IntrospectOAuthTokenRequest\x1a .pb.IntrospectOAuthTokenResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/oauth/introspect\x12l\n" +
+	"\x11ListOAuthConsents\x12\x1c.pb.ListOAuthConsentsRequest\x1a\x1d.pb.ListOAuthConsentsResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/oauth/consents\x12{\n" +
+	"\x12RevokeOAuthConsent\x12\x1d.pb.RevokeOAuthConsentRequest\x1a\x1e.pb.RevokeOAuthConsentResponse\"&\x82\xd3\xe4\x93\x02 *\x1e/v1/oauth/consents/{client_id}\x12z\n" +
+	"\x14CreateAccountConsent\x12\x1f.pb.CreateAccountConsentRequest\x1a .pb.CreateAccountConsentResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/account-consents\x12t\n" +
+	"\x13ListAccountConsents\x12\x1e.pb.ListAccountConsentsRequest\x1a\x1f.pb.ListAccountConsentsResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/account-consents\x12\x84\x01\n" +
+	"\x14RevokeAccountConsent\x12\x1f.pb.RevokeAccountConsentRequest\x1a .pb.RevokeAccountConsentResponse\")\x82\xd3\xe4\x93\x02#*!/v1/account-consents/{consent_id}\x12{\n" +
+	"\x11GetAccountConsent\x12\x1c.pb.GetAccountConsentRequest\x1a\x1d.pb.GetAccountConsentResponse\")\x82\xd3\xe4\x93\x02#\x12!/v1/account-consents/{consent_id}\x12\x90\x01\n" +
+	"\x15ListConsentedAccounts\x12 .pb.ListConsentedAccountsRequest\x1a!.pb.ListConsentedAccountsResponse\"2\x82\xd3\xe4\x93\x02,\x12*/v1/account-consents/{consent_id}/accounts\x12\xb4\x01\n" +
+	"\x1aGetConsentedAccountBalance\x12%.pb.GetConsentedAccountBalanceRequest\x1a&.pb.GetConsentedAccountBalanceResponse\"G\x82\xd3\xe4\x93\x02A\x12?/v1/account-consents/{consent_id}/accounts/{account_id}/balance\x12\xcb\x01\n" +
+	" ListConsentedAccountTransactions\x12+.pb.ListConsentedAccountTransactionsRequest\x1a,.pb.ListConsentedAccountTransactionsResponse\"L\x82\xd3\xe4\x93\x02F\x12D/v1/account-consents/{consent_id}/accounts/{account_id}/transactionsB\x1bZ\x19github.com/0xOnah/bank/pbb\x06proto3"
 
 var file_service_bank_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),  // 0: pb.CreateUserRequest
-	(*LoginUserRequest)(nil),   // 1: pb.LoginUserRequest
-	(*UpdateUserRequest)(nil),  // 2: pb.UpdateUserRequest
-	(*CreateUserResponse)(nil), // 3: pb.CreateUserResponse
-	(*LoginUserResponse)(nil),  // 4: pb.LoginUserResponse
-	(*UpdateUserResponse)(nil), // 5: pb.UpdateUserResponse
+	(*CreateUserRequest)(nil),                        // 0: pb.CreateUserRequest
+	(*LoginUserRequest)(nil),                         // 1: pb.LoginUserRequest
+	(*UpdateUserRequest)(nil),                        // 2: pb.UpdateUserRequest
+	(*ListSessionsRequest)(nil),                      // 3: pb.ListSessionsRequest
+	(*RevokeSessionRequest)(nil),                     // 4: pb.RevokeSessionRequest
+	(*RevokeOtherSessionsRequest)(nil),               // 5: pb.RevokeOtherSessionsRequest
+	(*VerifyEmailRequest)(nil),                       // 6: pb.VerifyEmailRequest
+	(*ResendVerifyEmailRequest)(nil),                 // 7: pb.ResendVerifyEmailRequest
+	(*RequestPasswordResetRequest)(nil),              // 8: pb.RequestPasswordResetRequest
+	(*ResetPasswordRequest)(nil),                     // 9: pb.ResetPasswordRequest
+	(*VerifyMFARequest)(nil),                         // 10: pb.VerifyMFARequest
+	(*RequestLoginLinkRequest)(nil),                  // 11: pb.RequestLoginLinkRequest
+	(*ConsumeLoginLinkRequest)(nil),                  // 12: pb.ConsumeLoginLinkRequest
+	(*BeginPasskeyLoginRequest)(nil),                 // 13: pb.BeginPasskeyLoginRequest
+	(*FinishPasskeyLoginRequest)(nil),                // 14: pb.FinishPasskeyLoginRequest
+	(*BeginPasskeyRegistrationRequest)(nil),          // 15: pb.BeginPasskeyRegistrationRequest
+	(*FinishPasskeyRegistrationRequest)(nil),         // 16: pb.FinishPasskeyRegistrationRequest
+	(*ListPasskeysRequest)(nil),                      // 17: pb.ListPasskeysRequest
+	(*DeletePasskeyRequest)(nil),                     // 18: pb.DeletePasskeyRequest
+	(*EnrollTOTPRequest)(nil),                        // 19: pb.EnrollTOTPRequest
+	(*ConfirmTOTPRequest)(nil),                       // 20: pb.ConfirmTOTPRequest
+	(*DisableTOTPRequest)(nil),                       // 21: pb.DisableTOTPRequest
+	(*ReauthenticateRequest)(nil),                    // 22: pb.ReauthenticateRequest
+	(*CreateServiceAccountRequest)(nil),              // 23: pb.CreateServiceAccountRequest
+	(*ListAPIKeysRequest)(nil),                       // 24: pb.ListAPIKeysRequest
+	(*RotateAPIKeyRequest)(nil),                      // 25: pb.RotateAPIKeyRequest
+	(*RevokeAPIKeyRequest)(nil),                      // 26: pb.RevokeAPIKeyRequest
+	(*UnlockUserRequest)(nil),                        // 27: pb.UnlockUserRequest
+	(*ConfirmDeviceRequest)(nil),                     // 28: pb.ConfirmDeviceRequest
+	(*ListAuditEventsRequest)(nil),                   // 29: pb.ListAuditEventsRequest
+	(*CreateOAuthClientRequest)(nil),                 // 30: pb.CreateOAuthClientRequest
+	(*RevokeOAuthClientRequest)(nil),                 // 31: pb.RevokeOAuthClientRequest
+	(*AuthorizeOAuthClientRequest)(nil),              // 32: pb.AuthorizeOAuthClientRequest
+	(*OAuthTokenRequest)(nil),                        // 33: pb.OAuthTokenRequest
+	(*RevokeOAuthTokenRequest)(nil),                  // 34: pb.RevokeOAuthTokenRequest
+	(*IntrospectOAuthTokenRequest)(nil),              // 35: pb.IntrospectOAuthTokenRequest
+	(*ListOAuthConsentsRequest)(nil),                 // 36: pb.ListOAuthConsentsRequest
+	(*RevokeOAuthConsentRequest)(nil),                // 37: pb.RevokeOAuthConsentRequest
+	(*CreateAccountConsentRequest)(nil),              // 38: pb.CreateAccountConsentRequest
+	(*ListAccountConsentsRequest)(nil),               // 39: pb.ListAccountConsentsRequest
+	(*RevokeAccountConsentRequest)(nil),              // 40: pb.RevokeAccountConsentRequest
+	(*GetAccountConsentRequest)(nil),                 // 41: pb.GetAccountConsentRequest
+	(*ListConsentedAccountsRequest)(nil),             // 42: pb.ListConsentedAccountsRequest
+	(*GetConsentedAccountBalanceRequest)(nil),        // 43: pb.GetConsentedAccountBalanceRequest
+	(*ListConsentedAccountTransactionsRequest)(nil),  // 44: pb.ListConsentedAccountTransactionsRequest
+	(*CreateUserResponse)(nil),                       // 45: pb.CreateUserResponse
+	(*LoginUserResponse)(nil),                        // 46: pb.LoginUserResponse
+	(*UpdateUserResponse)(nil),                       // 47: pb.UpdateUserResponse
+	(*ListSessionsResponse)(nil),                     // 48: pb.ListSessionsResponse
+	(*RevokeSessionResponse)(nil),                    // 49: pb.RevokeSessionResponse
+	(*RevokeOtherSessionsResponse)(nil),              // 50: pb.RevokeOtherSessionsResponse
+	(*VerifyEmailResponse)(nil),                      // 51: pb.VerifyEmailResponse
+	(*ResendVerifyEmailResponse)(nil),                // 52: pb.ResendVerifyEmailResponse
+	(*RequestPasswordResetResponse)(nil),             // 53: pb.RequestPasswordResetResponse
+	(*ResetPasswordResponse)(nil),                    // 54: pb.ResetPasswordResponse
+	(*RequestLoginLinkResponse)(nil),                 // 55: pb.RequestLoginLinkResponse
+	(*PasskeyCeremony)(nil),                          // 56: pb.PasskeyCeremony
+	(*FinishPasskeyRegistrationResponse)(nil),        // 57: pb.FinishPasskeyRegistrationResponse
+	(*ListPasskeysResponse)(nil),                     // 58: pb.ListPasskeysResponse
+	(*DeletePasskeyResponse)(nil),                    // 59: pb.DeletePasskeyResponse
+	(*EnrollTOTPResponse)(nil),                       // 60: pb.EnrollTOTPResponse
+	(*ConfirmTOTPResponse)(nil),                      // 61: pb.ConfirmTOTPResponse
+	(*DisableTOTPResponse)(nil),                      // 62: pb.DisableTOTPResponse
+	(*ReauthenticateResponse)(nil),                   // 63: pb.ReauthenticateResponse
+	(*CreateServiceAccountResponse)(nil),             // 64: pb.CreateServiceAccountResponse
+	(*ListAPIKeysResponse)(nil),                      // 65: pb.ListAPIKeysResponse
+	(*RotateAPIKeyResponse)(nil),                     // 66: pb.RotateAPIKeyResponse
+	(*RevokeAPIKeyResponse)(nil),                     // 67: pb.RevokeAPIKeyResponse
+	(*UnlockUserResponse)(nil),                       // 68: pb.UnlockUserResponse
+	(*ConfirmDeviceResponse)(nil),                    // 69: pb.ConfirmDeviceResponse
+	(*ListAuditEventsResponse)(nil),                  // 70: pb.ListAuditEventsResponse
+	(*CreateOAuthClientResponse)(nil),                // 71: pb.CreateOAuthClientResponse
+	(*RevokeOAuthClientResponse)(nil),                // 72: pb.RevokeOAuthClientResponse
+	(*AuthorizeOAuthClientResponse)(nil),             // 73: pb.AuthorizeOAuthClientResponse
+	(*OAuthTokenResponse)(nil),                       // 74: pb.OAuthTokenResponse
+	(*RevokeOAuthTokenResponse)(nil),                 // 75: pb.RevokeOAuthTokenResponse
+	(*IntrospectOAuthTokenResponse)(nil),             // 76: pb.IntrospectOAuthTokenResponse
+	(*ListOAuthConsentsResponse)(nil),                // 77: pb.ListOAuthConsentsResponse
+	(*RevokeOAuthConsentResponse)(nil),               // 78: pb.RevokeOAuthConsentResponse
+	(*CreateAccountConsentResponse)(nil),             // 79: pb.CreateAccountConsentResponse
+	(*ListAccountConsentsResponse)(nil),              // 80: pb.ListAccountConsentsResponse
+	(*RevokeAccountConsentResponse)(nil),             // 81: pb.RevokeAccountConsentResponse
+	(*GetAccountConsentResponse)(nil),                // 82: pb.GetAccountConsentResponse
+	(*ListConsentedAccountsResponse)(nil),            // 83: pb.ListConsentedAccountsResponse
+	(*GetConsentedAccountBalanceResponse)(nil),       // 84: pb.GetConsentedAccountBalanceResponse
+	(*ListConsentedAccountTransactionsResponse)(nil), // 85: pb.ListConsentedAccountTransactionsResponse
 }
 var file_service_bank_proto_depIdxs = []int32{
-	0, // 0: pb.UserService.CreateUser:input_type -> pb.CreateUserRequest
-	1, // 1: pb.UserService.LoginUser:input_type -> pb.LoginUserRequest
-	2, // 2: pb.UserService.UpdateUser:input_type -> pb.UpdateUserRequest
-	3, // 3: pb.UserService.CreateUser:output_type -> pb.CreateUserResponse
-	4, // 4: pb.UserService.LoginUser:output_type -> pb.LoginUserResponse
-	5, // 5: pb.UserService.UpdateUser:output_type -> pb.UpdateUserResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: pb.UserService.CreateUser:input_type -> pb.CreateUserRequest
+	1,  // 1: pb.UserService.LoginUser:input_type -> pb.LoginUserRequest
+	2,  // 2: pb.UserService.UpdateUser:input_type -> pb.UpdateUserRequest
+	3,  // 3: pb.UserService.ListSessions:input_type -> pb.ListSessionsRequest
+	4,  // 4: pb.UserService.RevokeSession:input_type -> pb.RevokeSessionRequest
+	5,  // 5: pb.UserService.RevokeOtherSessions:input_type -> pb.RevokeOtherSessionsRequest
+	6,  // 6: pb.UserService.VerifyEmail:input_type -> pb.VerifyEmailRequest
+	7,  // 7: pb.UserService.ResendVerifyEmail:input_type -> pb.ResendVerifyEmailRequest
+	8,  // 8: pb.UserService.RequestPasswordReset:input_type -> pb.RequestPasswordResetRequest
+	9,  // 9: pb.UserService.ResetPassword:input_type -> pb.ResetPasswordRequest
+	10, // 10: pb.UserService.VerifyMFA:input_type -> pb.VerifyMFARequest
+	11, // 11: pb.UserService.RequestLoginLink:input_type -> pb.RequestLoginLinkRequest
+	12, // 12: pb.UserService.ConsumeLoginLink:input_type -> pb.ConsumeLoginLinkRequest
+	13, // 13: pb.UserService.BeginPasskeyLogin:input_type -> pb.BeginPasskeyLoginRequest
+	14, // 14: pb.UserService.FinishPasskeyLogin:input_type -> pb.FinishPasskeyLoginRequest
+	15, // 15: pb.UserService.BeginPasskeyRegistration:input_type -> pb.BeginPasskeyRegistrationRequest
+	16, // 16: pb.UserService.FinishPasskeyRegistration:input_type -> pb.FinishPasskeyRegistrationRequest
+	17, // 17: pb.UserService.ListPasskeys:input_type -> pb.ListPasskeysRequest
+	18, // 18: pb.UserService.DeletePasskey:input_type -> pb.DeletePasskeyRequest
+	19, // 19: pb.UserService.EnrollTOTP:input_type -> pb.EnrollTOTPRequest
+	20, // 20: pb.UserService.ConfirmTOTP:input_type -> pb.ConfirmTOTPRequest
+	21, // 21: pb.UserService.DisableTOTP:input_type -> pb.DisableTOTPRequest
+	22, // 22: pb.UserService.Reauthenticate:input_type -> pb.ReauthenticateRequest
+	23, // 23: pb.UserService.CreateServiceAccount:input_type -> pb.CreateServiceAccountRequest
+	24, // 24: pb.UserService.ListAPIKeys:input_type -> pb.ListAPIKeysRequest
+	25, // 25: pb.UserService.RotateAPIKey:input_type -> pb.RotateAPIKeyRequest
+	26, // 26: pb.UserService.RevokeAPIKey:input_type -> pb.RevokeAPIKeyRequest
+	27, // 27: pb.UserService.UnlockUser:input_type -> pb.UnlockUserRequest
+	28, // 28: pb.UserService.ConfirmDevice:input_type -> pb.ConfirmDeviceRequest
+	29, // 29: pb.UserService.ListAuditEvents:input_type -> pb.ListAuditEventsRequest
+	30, // 30: pb.UserService.CreateOAuthClient:input_type -> pb.CreateOAuthClientRequest
+	31, // 31: pb.UserService.RevokeOAuthClient:input_type -> pb.RevokeOAuthClientRequest
+	32, // 32: pb.UserService.AuthorizeOAuthClient:input_type -> pb.AuthorizeOAuthClientRequest
+	33, // 33: pb.UserService.OAuthToken:input_type -> pb.OAuthTokenRequest
+	34, // 34: pb.UserService.RevokeOAuthToken:input_type -> pb.RevokeOAuthTokenRequest
+	35, // 35: pb.UserService.IntrospectOAuthToken:input_type -> pb.IntrospectOAuthTokenRequest
+	36, // 36: pb.UserService.ListOAuthConsents:input_type -> pb.ListOAuthConsentsRequest
+	37, // 37: pb.UserService.RevokeOAuthConsent:input_type -> pb.RevokeOAuthConsentRequest
+	38, // 38: pb.UserService.CreateAccountConsent:input_type -> pb.CreateAccountConsentRequest
+	39, // 39: pb.UserService.ListAccountConsents:input_type -> pb.ListAccountConsentsRequest
+	40, // 40: pb.UserService.RevokeAccountConsent:input_type -> pb.RevokeAccountConsentRequest
+	41, // 41: pb.UserService.GetAccountConsent:input_type -> pb.GetAccountConsentRequest
+	42, // 42: pb.UserService.ListConsentedAccounts:input_type -> pb.ListConsentedAccountsRequest
+	43, // 43: pb.UserService.GetConsentedAccountBalance:input_type -> pb.GetConsentedAccountBalanceRequest
+	44, // 44: pb.UserService.ListConsentedAccountTransactions:input_type -> pb.ListConsentedAccountTransactionsRequest
+	45, // 45: pb.UserService.CreateUser:output_type -> pb.CreateUserResponse
+	46, // 46: pb.UserService.LoginUser:output_type -> pb.LoginUserResponse
+	47, // 47: pb.UserService.UpdateUser:output_type -> pb.UpdateUserResponse
+	48, // 48: pb.UserService.ListSessions:output_type -> pb.ListSessionsResponse
+	49, // 49: pb.UserService.RevokeSession:output_type -> pb.RevokeSessionResponse
+	50, // 50: pb.UserService.RevokeOtherSessions:output_type -> pb.RevokeOtherSessionsResponse
+	51, // 51: pb.UserService.VerifyEmail:output_type -> pb.VerifyEmailResponse
+	52, // 52: pb.UserService.ResendVerifyEmail:output_type -> pb.ResendVerifyEmailResponse
+	53, // 53: pb.UserService.RequestPasswordReset:output_type -> pb.RequestPasswordResetResponse
+	54, // 54: pb.UserService.ResetPassword:output_type -> pb.ResetPasswordResponse
+	46, // 55: pb.UserService.VerifyMFA:output_type -> pb.LoginUserResponse
+	55, // 56: pb.UserService.RequestLoginLink:output_type -> pb.RequestLoginLinkResponse
+	46, // 57: pb.UserService.ConsumeLoginLink:output_type -> pb.LoginUserResponse
+	56, // 58: pb.UserService.BeginPasskeyLogin:output_type -> pb.PasskeyCeremony
+	46, // 59: pb.UserService.FinishPasskeyLogin:output_type -> pb.LoginUserResponse
+	56, // 60: pb.UserService.BeginPasskeyRegistration:output_type -> pb.PasskeyCeremony
+	57, // 61: pb.UserService.FinishPasskeyRegistration:output_type -> pb.FinishPasskeyRegistrationResponse
+	58, // 62: pb.UserService.ListPasskeys:output_type -> pb.ListPasskeysResponse
+	59, // 63: pb.UserService.DeletePasskey:output_type -> pb.DeletePasskeyResponse
+	60, // 64: pb.UserService.EnrollTOTP:output_type -> pb.EnrollTOTPResponse
+	61, // 65: pb.UserService.ConfirmTOTP:output_type -> pb.ConfirmTOTPResponse
+	62, // 66: pb.UserService.DisableTOTP:output_type -> pb.DisableTOTPResponse
+	63, // 67: pb.UserService.Reauthenticate:output_type -> pb.ReauthenticateResponse
+	64, // 68: pb.UserService.CreateServiceAccount:output_type -> pb.CreateServiceAccountResponse
+	65, // 69: pb.UserService.ListAPIKeys:output_type -> pb.ListAPIKeysResponse
+	66, // 70: pb.UserService.RotateAPIKey:output_type -> pb.RotateAPIKeyResponse
+	67, // 71: pb.UserService.RevokeAPIKey:output_type -> pb.RevokeAPIKeyResponse
+	68, // 72: pb.UserService.UnlockUser:output_type -> pb.UnlockUserResponse
+	69, // 73: pb.UserService.ConfirmDevice:output_type -> pb.ConfirmDeviceResponse
+	70, // 74: pb.UserService.ListAuditEvents:output_type -> pb.ListAuditEventsResponse
+	71, // 75: pb.UserService.CreateOAuthClient:output_type -> pb.CreateOAuthClientResponse
+	72, // 76: pb.UserService.RevokeOAuthClient:output_type -> pb.RevokeOAuthClientResponse
+	73, // 77: pb.UserService.AuthorizeOAuthClient:output_type -> pb.AuthorizeOAuthClientResponse
+	74, // 78: pb.UserService.OAuthToken:output_type -> pb.OAuthTokenResponse
+	75, // 79: pb.UserService.RevokeOAuthToken:output_type -> pb.RevokeOAuthTokenResponse
+	76, // 80: pb.UserService.IntrospectOAuthToken:output_type -> pb.IntrospectOAuthTokenResponse
+	77, // 81: pb.UserService.ListOAuthConsents:output_type -> pb.ListOAuthConsentsResponse
+	78, // 82: pb.UserService.RevokeOAuthConsent:output_type -> pb.RevokeOAuthConsentResponse
+	79, // 83: pb.UserService.CreateAccountConsent:output_type -> pb.CreateAccountConsentResponse
+	80, // 84: pb.UserService.ListAccountConsents:output_type -> pb.ListAccountConsentsResponse
+	81, // 85: pb.UserService.RevokeAccountConsent:output_type -> pb.RevokeAccountConsentResponse
+	82, // 86: pb.UserService.GetAccountConsent:output_type -> pb.GetAccountConsentResponse
+	83, // 87: pb.UserService.ListConsentedAccounts:output_type -> pb.ListConsentedAccountsResponse
+	84, // 88: pb.UserService.GetConsentedAccountBalance:output_type -> pb.GetConsentedAccountBalanceResponse
+	85, // 89: pb.UserService.ListConsentedAccountTransactions:output_type -> pb.ListConsentedAccountTransactionsResponse
+	45, // [45:90] is the sub-list for method output_type
+	0,  // [0:45] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_service_bank_proto_init() }
@@ -63,6 +272,19 @@ func file_service_bank_proto_init() {
 	file_rpc_create_user_proto_init()
 	file_rpc_login_user_proto_init()
 	file_rpc_update_user_proto_init()
+	file_rpc_sessions_proto_init()
+	file_rpc_verify_email_proto_init()
+	file_rpc_password_reset_proto_init()
+	file_rpc_mfa_proto_init()
+	file_rpc_reauthenticate_proto_init()
+	file_rpc_service_accounts_proto_init()
+	file_rpc_unlock_user_proto_init()
+	file_rpc_confirm_device_proto_init()
+	file_rpc_audit_proto_init()
+	file_rpc_login_link_proto_init()
+	file_rpc_passkeys_proto_init()
+	file_rpc_oauth_proto_init()
+	file_rpc_account_consents_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{